@@ -0,0 +1,250 @@
+// Copyright 2020 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/google/trillian"
+	"github.com/google/trillian/client/backoff"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// ErrSubmitterClosed is returned when submitting leaves to a closed
+// BatchSubmitter.
+var ErrSubmitterClosed = errors.New("batch submitter is closed")
+
+// BatchSubmitterOptions configures a BatchSubmitter. Zero values are replaced
+// by the defaults.
+type BatchSubmitterOptions struct {
+	// MaxLeaves is the max number of leaves in a batch. Defaults to 100.
+	MaxLeaves int
+	// MaxBytes is the max total size of the leaf values and extra data in a
+	// batch. A single leaf larger than this is sent on its own. Defaults to
+	// 1 MiB.
+	MaxBytes int
+	// Linger is how long to wait for more leaves before sending a batch which
+	// isn't full. Defaults to 100ms.
+	Linger time.Duration
+	// QueueSize is the number of leaves which can wait to be sent. Submit
+	// blocks while the queue is full. Defaults to 10 times MaxLeaves.
+	QueueSize int
+	// Timeout is how long to keep retrying a batch for. Defaults to 1 minute.
+	Timeout time.Duration
+	// Backoff sets the pauses between retries of a batch which failed with a
+	// transient error. Defaults to pauses from 100ms to 10s.
+	Backoff backoff.Backoff
+}
+
+func (o *BatchSubmitterOptions) setDefaults() {
+	if o.MaxLeaves <= 0 {
+		o.MaxLeaves = 100
+	}
+	if o.MaxBytes <= 0 {
+		o.MaxBytes = 1 << 20
+	}
+	if o.Linger <= 0 {
+		o.Linger = 100 * time.Millisecond
+	}
+	if o.QueueSize <= 0 {
+		o.QueueSize = 10 * o.MaxLeaves
+	}
+	if o.Timeout <= 0 {
+		o.Timeout = time.Minute
+	}
+	if o.Backoff.Min <= 0 {
+		o.Backoff = backoff.Backoff{
+			Min:    100 * time.Millisecond,
+			Max:    10 * time.Second,
+			Factor: 2,
+			Jitter: true,
+		}
+	}
+}
+
+// LeafFuture is the result of a leaf submitted to a BatchSubmitter.
+type LeafFuture struct {
+	c    *LogClient
+	data []byte
+	leaf *trillian.LogLeaf
+
+	done   chan struct{}
+	queued *trillian.QueuedLogLeaf
+	err    error
+}
+
+// Done returns a channel which is closed once the leaf has been sent.
+func (f *LeafFuture) Done() <-chan struct{} {
+	return f.done
+}
+
+// Queued blocks until the leaf has been sent to the log or ctx is done, and
+// returns the result of queueing it. A leaf which was already in the log has
+// the AlreadyExists status. The error is set if the leaf couldn't be sent.
+func (f *LeafFuture) Queued(ctx context.Context) (*trillian.QueuedLogLeaf, error) {
+	select {
+	case <-f.done:
+		return f.queued, f.err
+	case <-ctx.Done():
+		return nil, status.Errorf(codes.DeadlineExceeded, "%v", ctx.Err())
+	}
+}
+
+// WaitForInclusion blocks until the leaf has been queued, and then until
+// it has been verified with an inclusion proof, with the same semantics as
+// LogClient.WaitForInclusion. AlreadyExists is considered a success case.
+func (f *LeafFuture) WaitForInclusion(ctx context.Context) error {
+	queued, err := f.Queued(ctx)
+	if err != nil {
+		return err
+	}
+	if s := status.FromProto(queued.GetStatus()); s.Code() != codes.OK && s.Code() != codes.AlreadyExists {
+		return s.Err()
+	}
+	return f.c.WaitForInclusion(ctx, f.data)
+}
+
+func (f *LeafFuture) resolve(queued *trillian.QueuedLogLeaf, err error) {
+	f.queued, f.err = queued, err
+	close(f.done)
+}
+
+// BatchSubmitter collects leaves submitted from many goroutines, and queues
+// them to the log in QueueLeaves batches. A batch is sent once it has
+// MaxLeaves leaves or MaxBytes bytes of data, or once its first leaf has
+// waited for Linger. Batches are sent one at a time, and retried while they
+// fail with transient errors. The queue of leaves waiting to be sent is
+// bounded, so that callers slow down when the log can't keep up.
+type BatchSubmitter struct {
+	c     *LogClient
+	opts  BatchSubmitterOptions
+	queue chan *LeafFuture
+	done  chan struct{}
+
+	mu     sync.RWMutex
+	closed bool
+}
+
+// NewBatchSubmitter returns a BatchSubmitter which queues leaves through c.
+// Close must be called to release its resources.
+func NewBatchSubmitter(c *LogClient, opts BatchSubmitterOptions) *BatchSubmitter {
+	opts.setDefaults()
+	b := &BatchSubmitter{
+		c:     c,
+		opts:  opts,
+		queue: make(chan *LeafFuture, opts.QueueSize),
+		done:  make(chan struct{}),
+	}
+	go b.run()
+	return b
+}
+
+// Submit adds a leaf with the given data to the queue, blocking while the
+// queue is full. It returns a future for the result of queueing the leaf.
+func (b *BatchSubmitter) Submit(ctx context.Context, data []byte) (*LeafFuture, error) {
+	f := &LeafFuture{c: b.c, data: data, leaf: b.c.BuildLeaf(data), done: make(chan struct{})}
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	if b.closed {
+		return nil, ErrSubmitterClosed
+	}
+	select {
+	case b.queue <- f:
+		return f, nil
+	case <-ctx.Done():
+		return nil, status.Errorf(codes.DeadlineExceeded, "%v", ctx.Err())
+	}
+}
+
+// Close sends the leaves already submitted, and stops the submitter.
+func (b *BatchSubmitter) Close() {
+	b.mu.Lock()
+	if !b.closed {
+		b.closed = true
+		close(b.queue)
+	}
+	b.mu.Unlock()
+	<-b.done
+}
+
+func (b *BatchSubmitter) run() {
+	defer close(b.done)
+	var batch []*LeafFuture
+	var size int
+	var linger <-chan time.Time
+	flush := func() {
+		if len(batch) > 0 {
+			b.send(batch)
+		}
+		batch, size, linger = nil, 0, nil
+	}
+	for {
+		select {
+		case f, ok := <-b.queue:
+			if !ok {
+				flush()
+				return
+			}
+			leafSize := len(f.leaf.LeafValue) + len(f.leaf.ExtraData)
+			if len(batch) > 0 && size+leafSize > b.opts.MaxBytes {
+				flush()
+			}
+			batch = append(batch, f)
+			size += leafSize
+			if len(batch) == 1 {
+				linger = time.After(b.opts.Linger)
+			}
+			if len(batch) >= b.opts.MaxLeaves || size >= b.opts.MaxBytes {
+				flush()
+			}
+		case <-linger:
+			flush()
+		}
+	}
+}
+
+// send queues a batch of leaves, retrying transient errors, and resolves
+// their futures.
+func (b *BatchSubmitter) send(batch []*LeafFuture) {
+	ctx, cancel := context.WithTimeout(context.Background(), b.opts.Timeout)
+	defer cancel()
+	req := &trillian.QueueLeavesRequest{LogId: b.c.LogID}
+	for _, f := range batch {
+		req.Leaves = append(req.Leaves, f.leaf)
+	}
+
+	var resp *trillian.QueueLeavesResponse
+	bo := b.opts.Backoff
+	err := bo.Retry(ctx, func() error {
+		var err error
+		resp, err = b.c.client.QueueLeaves(ctx, req)
+		return err
+	})
+	if err == nil && len(resp.QueuedLeaves) != len(batch) {
+		err = fmt.Errorf("QueueLeaves() returned %d results for %d leaves", len(resp.QueuedLeaves), len(batch))
+	}
+	for i, f := range batch {
+		if err != nil {
+			f.resolve(nil, err)
+		} else {
+			f.resolve(resp.QueuedLeaves[i], nil)
+		}
+	}
+}
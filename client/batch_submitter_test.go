@@ -0,0 +1,196 @@
+// Copyright 2020 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package client
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/trillian/client/backoff"
+	"github.com/google/trillian/types"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func queuedCode(ctx context.Context, t *testing.T, f *LeafFuture) codes.Code {
+	t.Helper()
+	queued, err := f.Queued(ctx)
+	if err != nil {
+		t.Fatalf("Queued(): %v", err)
+	}
+	return status.FromProto(queued.Status).Code()
+}
+
+func TestBatchSubmitter(t *testing.T) {
+	ctx := context.Background()
+	log, verifier := newRootLog(t)
+	c := New(fakeLogID, log, verifier, types.LogRootV1{})
+	b := NewBatchSubmitter(c, BatchSubmitterOptions{MaxLeaves: 4, Linger: time.Hour})
+
+	var wg sync.WaitGroup
+	futures := make([]*LeafFuture, 10)
+	for i := range futures {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			f, err := b.Submit(ctx, []byte(fmt.Sprintf("leaf%d", i)))
+			if err != nil {
+				t.Errorf("Submit(): %v", err)
+			}
+			futures[i] = f
+		}(i)
+	}
+	wg.Wait()
+	// The last batch isn't full, so it is only sent by Close.
+	b.Close()
+	if _, err := b.Submit(ctx, []byte("late")); err != ErrSubmitterClosed {
+		t.Errorf("Submit() after Close(): %v, want %v", err, ErrSubmitterClosed)
+	}
+
+	for i, f := range futures {
+		if got := queuedCode(ctx, t, f); got != codes.OK {
+			t.Errorf("leaf %d queued with %v, want OK", i, got)
+		}
+	}
+	if got, want := log.batches, []int{4, 4, 2}; !reflect.DeepEqual(got, want) {
+		t.Errorf("QueueLeaves() batches %v, want %v", got, want)
+	}
+	if err := futures[3].WaitForInclusion(ctx); err != nil {
+		t.Errorf("WaitForInclusion(): %v", err)
+	}
+}
+
+func TestBatchSubmitterLimits(t *testing.T) {
+	ctx := context.Background()
+	for _, tc := range []struct {
+		desc        string
+		opts        BatchSubmitterOptions
+		sizes       []int
+		wantBatches []int
+	}{
+		{desc: "bytes", opts: BatchSubmitterOptions{MaxBytes: 10, Linger: time.Hour}, sizes: []int{4, 4, 4, 4, 4}, wantBatches: []int{2, 2, 1}},
+		{desc: "large-leaf", opts: BatchSubmitterOptions{MaxBytes: 10, Linger: time.Hour}, sizes: []int{4, 20, 4}, wantBatches: []int{1, 1, 1}},
+		{desc: "linger", opts: BatchSubmitterOptions{Linger: 50 * time.Millisecond}, sizes: []int{4, 4, 4}, wantBatches: []int{3}},
+	} {
+		t.Run(tc.desc, func(t *testing.T) {
+			log, verifier := newRootLog(t)
+			b := NewBatchSubmitter(New(fakeLogID, log, verifier, types.LogRootV1{}), tc.opts)
+			defer b.Close()
+			var futures []*LeafFuture
+			for i, size := range tc.sizes {
+				data := []byte(fmt.Sprintf("%0*d", size, i))
+				f, err := b.Submit(ctx, data)
+				if err != nil {
+					t.Fatalf("Submit(): %v", err)
+				}
+				futures = append(futures, f)
+			}
+			if tc.opts.Linger == time.Hour {
+				b.Close()
+			}
+			for _, f := range futures {
+				queuedCode(ctx, t, f)
+			}
+			if got := log.batches; !reflect.DeepEqual(got, tc.wantBatches) {
+				t.Errorf("QueueLeaves() batches %v, want %v", got, tc.wantBatches)
+			}
+		})
+	}
+}
+
+func TestBatchSubmitterErrors(t *testing.T) {
+	ctx := context.Background()
+	fastRetry := backoff.Backoff{Min: time.Millisecond, Max: time.Millisecond, Factor: 1}
+
+	t.Run("retry", func(t *testing.T) {
+		log, verifier := newRootLog(t)
+		log.queueErrs = []error{status.Error(codes.Unavailable, "down"), status.Error(codes.ResourceExhausted, "busy")}
+		b := NewBatchSubmitter(New(fakeLogID, log, verifier, types.LogRootV1{}), BatchSubmitterOptions{Linger: time.Millisecond, Backoff: fastRetry})
+		defer b.Close()
+		f, err := b.Submit(ctx, []byte("leaf"))
+		if err != nil {
+			t.Fatalf("Submit(): %v", err)
+		}
+		if got := queuedCode(ctx, t, f); got != codes.OK {
+			t.Errorf("leaf queued with %v, want OK", got)
+		}
+		// A leaf already in the log is reported as such.
+		if f, err = b.Submit(ctx, []byte("leaf")); err != nil {
+			t.Fatalf("Submit(): %v", err)
+		}
+		if got := queuedCode(ctx, t, f); got != codes.AlreadyExists {
+			t.Errorf("duplicate leaf queued with %v, want AlreadyExists", got)
+		}
+		if err := f.WaitForInclusion(ctx); err != nil {
+			t.Errorf("WaitForInclusion() of duplicate leaf: %v", err)
+		}
+	})
+
+	t.Run("permanent", func(t *testing.T) {
+		log, verifier := newRootLog(t)
+		log.queueErrs = []error{status.Error(codes.InvalidArgument, "bad")}
+		b := NewBatchSubmitter(New(fakeLogID, log, verifier, types.LogRootV1{}), BatchSubmitterOptions{Linger: time.Millisecond, Backoff: fastRetry})
+		defer b.Close()
+		f, err := b.Submit(ctx, []byte("leaf"))
+		if err != nil {
+			t.Fatalf("Submit(): %v", err)
+		}
+		if _, err := f.Queued(ctx); status.Code(err) != codes.InvalidArgument {
+			t.Errorf("Queued(): %v, want code %v", err, codes.InvalidArgument)
+		}
+		if err := f.WaitForInclusion(ctx); status.Code(err) != codes.InvalidArgument {
+			t.Errorf("WaitForInclusion(): %v, want code %v", err, codes.InvalidArgument)
+		}
+	})
+
+	t.Run("back-pressure", func(t *testing.T) {
+		log, verifier := newRootLog(t)
+		log.queueErrs = []error{status.Error(codes.Unavailable, "down")}
+		b := NewBatchSubmitter(New(fakeLogID, log, verifier, types.LogRootV1{}), BatchSubmitterOptions{
+			MaxLeaves: 1,
+			QueueSize: 1,
+			Timeout:   100 * time.Millisecond,
+			Backoff:   backoff.Backoff{Min: time.Hour, Max: time.Hour, Factor: 1},
+		})
+		var futures []*LeafFuture
+		for _, data := range []string{"stuck", "waiting"} {
+			f, err := b.Submit(ctx, []byte(data))
+			if err != nil {
+				t.Fatalf("Submit(): %v", err)
+			}
+			futures = append(futures, f)
+		}
+		// The first leaf is being retried, and the second fills the queue.
+		cctx, cancel := context.WithTimeout(ctx, 10*time.Millisecond)
+		defer cancel()
+		if _, err := b.Submit(cctx, []byte("blocked")); status.Code(err) != codes.DeadlineExceeded {
+			t.Errorf("Submit() to full queue: %v, want code %v", err, codes.DeadlineExceeded)
+		}
+		b.Close()
+		if _, err := futures[0].Queued(ctx); err == nil {
+			t.Error("Queued() of leaf which timed out succeeded")
+		}
+		if got := queuedCode(ctx, t, futures[1]); got != codes.OK {
+			t.Errorf("leaf queued with %v, want OK", got)
+		}
+		if got, want := log.batches, []int{1}; !reflect.DeepEqual(got, want) {
+			t.Errorf("QueueLeaves() batches %v, want %v", got, want)
+		}
+	})
+}
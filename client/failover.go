@@ -0,0 +1,435 @@
+// Copyright 2020 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package client
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/google/trillian"
+	"github.com/google/trillian/types"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	tcrypto "github.com/google/trillian/crypto"
+)
+
+// DefaultRetryAfter is how long a FailoverClient skips a replica for after
+// it fails, by default.
+const DefaultRetryAfter = 30 * time.Second
+
+// Replica is one of the servers of a log.
+type Replica struct {
+	// Name identifies the replica in errors, e.g. by its address.
+	Name string
+	// Client sends requests to the replica.
+	Client trillian.TrillianLogClient
+}
+
+// DialReplicas connects to the servers at addrs, and returns them as replicas
+// named by their addresses, along with a function which closes the
+// connections.
+func DialReplicas(addrs []string, opts ...grpc.DialOption) ([]Replica, func(), error) {
+	var conns []*grpc.ClientConn
+	closeAll := func() {
+		for _, c := range conns {
+			c.Close()
+		}
+	}
+	replicas := make([]Replica, 0, len(addrs))
+	for _, addr := range addrs {
+		conn, err := grpc.Dial(addr, opts...)
+		if err != nil {
+			closeAll()
+			return nil, nil, fmt.Errorf("failed to dial %v: %v", addr, err)
+		}
+		conns = append(conns, conn)
+		replicas = append(replicas, Replica{Name: addr, Client: trillian.NewTrillianLogClient(conn)})
+	}
+	return replicas, closeAll, nil
+}
+
+// SplitViewError is returned when two replicas of a log serve roots which
+// are not consistent with each other. The signed roots are the evidence that
+// the log has presented different views to its clients.
+type SplitViewError struct {
+	// Replicas are the names of the replicas which served the roots.
+	Replicas [2]string
+	// Roots are the signed roots, with the smaller one first.
+	Roots [2]*trillian.SignedLogRoot
+	// Err says why the roots are not consistent.
+	Err error
+}
+
+func (e *SplitViewError) Error() string {
+	return fmt.Sprintf("replicas %s and %s serve inconsistent roots: %v", e.Replicas[0], e.Replicas[1], e.Err)
+}
+
+// Unwrap returns the reason the roots are not consistent.
+func (e *SplitViewError) Unwrap() error {
+	return e.Err
+}
+
+type replica struct {
+	Replica
+	// downUntil is the time until which the replica is skipped.
+	downUntil time.Time
+}
+
+// observedRoot is a root served by a replica.
+type observedRoot struct {
+	replica *replica
+	slr     *trillian.SignedLogRoot
+	root    *types.LogRootV1
+}
+
+// FailoverClient is a TrillianLogClient which sends each request to one of
+// several replicas of the log server. It keeps using the same replica until
+// that fails with Unavailable or DeadlineExceeded, then retries the request
+// on the next one, skipping the replicas which have failed recently.
+//
+// It also checks that the roots served by different replicas are consistent
+// with each other. When a replica serves a root, it is compared with the
+// largest root served by another replica, using a consistency proof fetched
+// from the replica with the larger root, or from any replica which can serve
+// it. If none can, the check is made with a later root. A mismatch is
+// returned as a *SplitViewError. A replica whose root is smaller than the
+// FirstTreeSize of a GetLatestSignedLogRoot request is treated as failed, so
+// that clients don't mistake a lagging replica for a rolled back log.
+type FailoverClient struct {
+	verifier *LogVerifier
+	replicas []*replica
+
+	// RetryAfter is how long a replica is skipped for after it fails.
+	RetryAfter time.Duration
+
+	mu      sync.Mutex
+	current int
+	largest *observedRoot
+}
+
+// NewFailoverClient returns a FailoverClient which sends requests to the
+// replicas, in order of preference. Roots are verified with verifier.
+func NewFailoverClient(verifier *LogVerifier, replicas []Replica) (*FailoverClient, error) {
+	if len(replicas) == 0 {
+		return nil, errors.New("no replicas")
+	}
+	f := &FailoverClient{verifier: verifier, RetryAfter: DefaultRetryAfter}
+	for _, r := range replicas {
+		f.replicas = append(f.replicas, &replica{Replica: r})
+	}
+	return f, nil
+}
+
+// order returns the replicas in the order to try them: the healthy ones
+// starting from the current replica, then the ones which have failed.
+func (f *FailoverClient) order() []int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	now := time.Now()
+	var healthy, down []int
+	for i := range f.replicas {
+		idx := (f.current + i) % len(f.replicas)
+		if now.Before(f.replicas[idx].downUntil) {
+			down = append(down, idx)
+		} else {
+			healthy = append(healthy, idx)
+		}
+	}
+	return append(healthy, down...)
+}
+
+// shouldFailover returns whether a request which failed with err should be
+// retried on another replica.
+func shouldFailover(ctx context.Context, err error) bool {
+	if ctx.Err() != nil {
+		return false
+	}
+	switch status.Code(err) {
+	case codes.Unavailable, codes.DeadlineExceeded:
+		return true
+	}
+	return false
+}
+
+// call runs fn on each replica in turn, until one doesn't fail in a way that
+// warrants failing over.
+func (f *FailoverClient) call(ctx context.Context, fn func(r *replica) error) error {
+	var err error
+	for _, idx := range f.order() {
+		r := f.replicas[idx]
+		if err = fn(r); !shouldFailover(ctx, err) {
+			f.mu.Lock()
+			f.current = idx
+			f.mu.Unlock()
+			return err
+		}
+		f.mu.Lock()
+		r.downUntil = time.Now().Add(f.RetryAfter)
+		f.mu.Unlock()
+	}
+	return err
+}
+
+// checkRoot verifies the root served by r, and checks that it is consistent
+// with the largest root served by another replica.
+func (f *FailoverClient) checkRoot(ctx context.Context, logID int64, r *replica, slr *trillian.SignedLogRoot) (*types.LogRootV1, error) {
+	root, err := tcrypto.VerifySignedLogRoot(f.verifier.PubKey, f.verifier.SigHash, slr)
+	if err != nil {
+		return nil, fmt.Errorf("replica %s: %v", r.Name, err)
+	}
+	got := &observedRoot{replica: r, slr: slr, root: root}
+
+	f.mu.Lock()
+	largest := f.largest
+	f.mu.Unlock()
+	if largest != nil && largest.replica != r {
+		small, large := largest, got
+		if root.TreeSize < largest.root.TreeSize {
+			small, large = got, largest
+		}
+		proved, err := f.checkConsistency(ctx, logID, small, large)
+		if err != nil {
+			return nil, err
+		}
+		if !proved {
+			return root, nil
+		}
+	}
+
+	f.mu.Lock()
+	if f.largest == nil || root.TreeSize >= f.largest.root.TreeSize {
+		f.largest = got
+	}
+	f.mu.Unlock()
+	return root, nil
+}
+
+// checkConsistency checks that the large root is an append-only update of
+// the small one. It returns false if no replica could provide a proof.
+func (f *FailoverClient) checkConsistency(ctx context.Context, logID int64, small, large *observedRoot) (bool, error) {
+	splitView := func(err error) error {
+		return &SplitViewError{
+			Replicas: [2]string{small.replica.Name, large.replica.Name},
+			Roots:    [2]*trillian.SignedLogRoot{small.slr, large.slr},
+			Err:      err,
+		}
+	}
+	first, second := small.root.TreeSize, large.root.TreeSize
+	if first == second {
+		if !bytes.Equal(small.root.RootHash, large.root.RootHash) {
+			return false, splitView(fmt.Errorf("different root hashes for tree size %d", first))
+		}
+		return true, nil
+	}
+	if first == 0 {
+		return true, nil
+	}
+
+	// Any replica whose tree has grown to the larger size can prove
+	// consistency, but the one which served it is the most likely to.
+	candidates := []*replica{large.replica}
+	for _, r := range f.replicas {
+		if r != large.replica && r != small.replica {
+			candidates = append(candidates, r)
+		}
+	}
+	for _, r := range candidates {
+		resp, err := r.Client.GetConsistencyProof(ctx, &trillian.GetConsistencyProofRequest{
+			LogId:          logID,
+			FirstTreeSize:  int64(first),
+			SecondTreeSize: int64(second),
+		})
+		if err != nil {
+			continue
+		}
+		if err := f.verifier.v.VerifyConsistencyProof(int64(first), int64(second), small.root.RootHash, large.root.RootHash, resp.GetProof().GetHashes()); err != nil {
+			return false, splitView(fmt.Errorf("consistency proof from replica %s: %v", r.Name, err))
+		}
+		return true, nil
+	}
+	return false, nil
+}
+
+// GetLatestSignedLogRoot sends the request to the first healthy replica
+// whose root is at least FirstTreeSize, and checks that root against the ones
+// served by the other replicas.
+func (f *FailoverClient) GetLatestSignedLogRoot(ctx context.Context, req *trillian.GetLatestSignedLogRootRequest, opts ...grpc.CallOption) (*trillian.GetLatestSignedLogRootResponse, error) {
+	var resp *trillian.GetLatestSignedLogRootResponse
+	err := f.call(ctx, func(r *replica) error {
+		var err error
+		if resp, err = r.Client.GetLatestSignedLogRoot(ctx, req, opts...); err != nil {
+			return err
+		}
+		root, err := f.checkRoot(ctx, req.LogId, r, resp.GetSignedLogRoot())
+		if err != nil {
+			return err
+		}
+		if root.TreeSize < uint64(req.FirstTreeSize) {
+			return status.Errorf(codes.Unavailable, "replica %s has tree size %d, less than %d", r.Name, root.TreeSize, req.FirstTreeSize)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// QueueLeaf sends the request to the first healthy replica.
+func (f *FailoverClient) QueueLeaf(ctx context.Context, req *trillian.QueueLeafRequest, opts ...grpc.CallOption) (*trillian.QueueLeafResponse, error) {
+	var resp *trillian.QueueLeafResponse
+	err := f.call(ctx, func(r *replica) (err error) {
+		resp, err = r.Client.QueueLeaf(ctx, req, opts...)
+		return err
+	})
+	return resp, err
+}
+
+// AddSequencedLeaf sends the request to the first healthy replica.
+func (f *FailoverClient) AddSequencedLeaf(ctx context.Context, req *trillian.AddSequencedLeafRequest, opts ...grpc.CallOption) (*trillian.AddSequencedLeafResponse, error) {
+	var resp *trillian.AddSequencedLeafResponse
+	err := f.call(ctx, func(r *replica) (err error) {
+		resp, err = r.Client.AddSequencedLeaf(ctx, req, opts...)
+		return err
+	})
+	return resp, err
+}
+
+// GetInclusionProof sends the request to the first healthy replica.
+func (f *FailoverClient) GetInclusionProof(ctx context.Context, req *trillian.GetInclusionProofRequest, opts ...grpc.CallOption) (*trillian.GetInclusionProofResponse, error) {
+	var resp *trillian.GetInclusionProofResponse
+	err := f.call(ctx, func(r *replica) (err error) {
+		resp, err = r.Client.GetInclusionProof(ctx, req, opts...)
+		return err
+	})
+	return resp, err
+}
+
+// GetInclusionProofByHash sends the request to the first healthy replica.
+func (f *FailoverClient) GetInclusionProofByHash(ctx context.Context, req *trillian.GetInclusionProofByHashRequest, opts ...grpc.CallOption) (*trillian.GetInclusionProofByHashResponse, error) {
+	var resp *trillian.GetInclusionProofByHashResponse
+	err := f.call(ctx, func(r *replica) (err error) {
+		resp, err = r.Client.GetInclusionProofByHash(ctx, req, opts...)
+		return err
+	})
+	return resp, err
+}
+
+// GetConsistencyProof sends the request to the first healthy replica.
+func (f *FailoverClient) GetConsistencyProof(ctx context.Context, req *trillian.GetConsistencyProofRequest, opts ...grpc.CallOption) (*trillian.GetConsistencyProofResponse, error) {
+	var resp *trillian.GetConsistencyProofResponse
+	err := f.call(ctx, func(r *replica) (err error) {
+		resp, err = r.Client.GetConsistencyProof(ctx, req, opts...)
+		return err
+	})
+	return resp, err
+}
+
+// GetSequencedLeafCount sends the request to the first healthy replica.
+func (f *FailoverClient) GetSequencedLeafCount(ctx context.Context, req *trillian.GetSequencedLeafCountRequest, opts ...grpc.CallOption) (*trillian.GetSequencedLeafCountResponse, error) {
+	var resp *trillian.GetSequencedLeafCountResponse
+	err := f.call(ctx, func(r *replica) (err error) {
+		resp, err = r.Client.GetSequencedLeafCount(ctx, req, opts...)
+		return err
+	})
+	return resp, err
+}
+
+// GetEntryAndProof sends the request to the first healthy replica.
+func (f *FailoverClient) GetEntryAndProof(ctx context.Context, req *trillian.GetEntryAndProofRequest, opts ...grpc.CallOption) (*trillian.GetEntryAndProofResponse, error) {
+	var resp *trillian.GetEntryAndProofResponse
+	err := f.call(ctx, func(r *replica) (err error) {
+		resp, err = r.Client.GetEntryAndProof(ctx, req, opts...)
+		return err
+	})
+	return resp, err
+}
+
+// InitLog sends the request to the first healthy replica.
+func (f *FailoverClient) InitLog(ctx context.Context, req *trillian.InitLogRequest, opts ...grpc.CallOption) (*trillian.InitLogResponse, error) {
+	var resp *trillian.InitLogResponse
+	err := f.call(ctx, func(r *replica) (err error) {
+		resp, err = r.Client.InitLog(ctx, req, opts...)
+		return err
+	})
+	return resp, err
+}
+
+// QueueLeaves sends the request to the first healthy replica.
+func (f *FailoverClient) QueueLeaves(ctx context.Context, req *trillian.QueueLeavesRequest, opts ...grpc.CallOption) (*trillian.QueueLeavesResponse, error) {
+	var resp *trillian.QueueLeavesResponse
+	err := f.call(ctx, func(r *replica) (err error) {
+		resp, err = r.Client.QueueLeaves(ctx, req, opts...)
+		return err
+	})
+	return resp, err
+}
+
+// AddSequencedLeaves sends the request to the first healthy replica.
+func (f *FailoverClient) AddSequencedLeaves(ctx context.Context, req *trillian.AddSequencedLeavesRequest, opts ...grpc.CallOption) (*trillian.AddSequencedLeavesResponse, error) {
+	var resp *trillian.AddSequencedLeavesResponse
+	err := f.call(ctx, func(r *replica) (err error) {
+		resp, err = r.Client.AddSequencedLeaves(ctx, req, opts...)
+		return err
+	})
+	return resp, err
+}
+
+// AddSequencedLeavesStream opens the stream to the first healthy replica. The
+// stream isn't moved to another replica if that one fails later.
+func (f *FailoverClient) AddSequencedLeavesStream(ctx context.Context, opts ...grpc.CallOption) (trillian.TrillianLog_AddSequencedLeavesStreamClient, error) {
+	var stream trillian.TrillianLog_AddSequencedLeavesStreamClient
+	err := f.call(ctx, func(r *replica) (err error) {
+		stream, err = r.Client.AddSequencedLeavesStream(ctx, opts...)
+		return err
+	})
+	return stream, err
+}
+
+// GetLeavesByIndex sends the request to the first healthy replica.
+func (f *FailoverClient) GetLeavesByIndex(ctx context.Context, req *trillian.GetLeavesByIndexRequest, opts ...grpc.CallOption) (*trillian.GetLeavesByIndexResponse, error) {
+	var resp *trillian.GetLeavesByIndexResponse
+	err := f.call(ctx, func(r *replica) (err error) {
+		resp, err = r.Client.GetLeavesByIndex(ctx, req, opts...)
+		return err
+	})
+	return resp, err
+}
+
+// GetLeavesByRange sends the request to the first healthy replica.
+func (f *FailoverClient) GetLeavesByRange(ctx context.Context, req *trillian.GetLeavesByRangeRequest, opts ...grpc.CallOption) (*trillian.GetLeavesByRangeResponse, error) {
+	var resp *trillian.GetLeavesByRangeResponse
+	err := f.call(ctx, func(r *replica) (err error) {
+		resp, err = r.Client.GetLeavesByRange(ctx, req, opts...)
+		return err
+	})
+	return resp, err
+}
+
+// GetLeavesByHash sends the request to the first healthy replica.
+func (f *FailoverClient) GetLeavesByHash(ctx context.Context, req *trillian.GetLeavesByHashRequest, opts ...grpc.CallOption) (*trillian.GetLeavesByHashResponse, error) {
+	var resp *trillian.GetLeavesByHashResponse
+	err := f.call(ctx, func(r *replica) (err error) {
+		resp, err = r.Client.GetLeavesByHash(ctx, req, opts...)
+		return err
+	})
+	return resp, err
+}
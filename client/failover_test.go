@@ -0,0 +1,188 @@
+// Copyright 2020 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package client
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/trillian"
+	"github.com/google/trillian/types"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// flakyReplica is a rootLog which can be taken down, and counts the roots it
+// serves.
+type flakyReplica struct {
+	*rootLog
+	down  bool
+	roots int
+}
+
+func (f *flakyReplica) GetLatestSignedLogRoot(ctx context.Context, req *trillian.GetLatestSignedLogRootRequest, opts ...grpc.CallOption) (*trillian.GetLatestSignedLogRootResponse, error) {
+	if f.down {
+		return nil, status.Error(codes.Unavailable, "replica is down")
+	}
+	f.roots++
+	return f.rootLog.GetLatestSignedLogRoot(ctx, req, opts...)
+}
+
+func (f *flakyReplica) GetConsistencyProof(ctx context.Context, req *trillian.GetConsistencyProofRequest, opts ...grpc.CallOption) (*trillian.GetConsistencyProofResponse, error) {
+	if f.down {
+		return nil, status.Error(codes.Unavailable, "replica is down")
+	}
+	if req.SecondTreeSize > f.tree.LeafCount() {
+		return nil, status.Error(codes.InvalidArgument, "tree is too small")
+	}
+	return f.rootLog.GetConsistencyProof(ctx, req, opts...)
+}
+
+// newReplicas returns replicas a and b of a log, and a FailoverClient which
+// prefers a.
+func newReplicas(t *testing.T) (*flakyReplica, *flakyReplica, *FailoverClient, *LogVerifier) {
+	t.Helper()
+	logA, verifier := newRootLog(t)
+	logB, _ := newRootLog(t)
+	a, b := &flakyReplica{rootLog: logA}, &flakyReplica{rootLog: logB}
+	f, err := NewFailoverClient(verifier, []Replica{{Name: "a", Client: a}, {Name: "b", Client: b}})
+	if err != nil {
+		t.Fatalf("NewFailoverClient(): %v", err)
+	}
+	return a, b, f, verifier
+}
+
+func TestFailoverClient(t *testing.T) {
+	ctx := context.Background()
+	a, b, f, verifier := newReplicas(t)
+	a.add("leaf", 4)
+	b.add("leaf", 4)
+	c := New(fakeLogID, f, verifier, types.LogRootV1{})
+	update := func(want uint64) {
+		t.Helper()
+		root, err := c.UpdateRoot(ctx)
+		if err != nil {
+			t.Fatalf("UpdateRoot(): %v", err)
+		}
+		if root != nil && root.TreeSize != want {
+			t.Errorf("UpdateRoot() = size %d, want %d", root.TreeSize, want)
+		}
+	}
+
+	update(4)
+	if a.roots != 1 || b.roots != 0 {
+		t.Errorf("replicas served %d, %d roots, want 1, 0", a.roots, b.roots)
+	}
+
+	// When a is down, b serves the requests, and is checked against a.
+	a.down = true
+	b.add("leaf", 3)
+	update(7)
+	update(7)
+	if b.roots != 2 {
+		t.Errorf("replica b served %d roots, want 2", b.roots)
+	}
+
+	// b stays the current replica once a recovers.
+	a.down = false
+	a.add("leaf", 5)
+	b.add("leaf", 2)
+	update(9)
+	if a.roots != 1 {
+		t.Errorf("replica a served %d roots, want 1", a.roots)
+	}
+
+	// A replica which is behind the client's root is treated as failed,
+	// rather than as a rolled back log.
+	b.add("leaf", 5)
+	update(14)
+	b.down = true
+	if _, err := c.UpdateRoot(ctx); status.Code(err) != codes.Unavailable {
+		t.Errorf("UpdateRoot() with lagging replica: %v, want code %v", err, codes.Unavailable)
+	}
+	a.add("leaf", 5)
+	update(14)
+}
+
+func TestFailoverClientSplitView(t *testing.T) {
+	ctx := context.Background()
+	for _, tc := range []struct {
+		desc   string
+		growA  func(r *flakyReplica)
+		growB  func(r *flakyReplica)
+		wantSV bool
+	}{
+		{desc: "consistent", growA: func(r *flakyReplica) { r.add("leaf", 2) }, growB: func(r *flakyReplica) { r.add("leaf", 5) }},
+		{desc: "same-size", growA: func(r *flakyReplica) { r.add("a", 2) }, growB: func(r *flakyReplica) { r.add("b", 2) }, wantSV: true},
+		{desc: "b-larger", growA: func(r *flakyReplica) { r.add("a", 2) }, growB: func(r *flakyReplica) { r.add("b", 5) }, wantSV: true},
+		{desc: "b-smaller", growA: func(r *flakyReplica) { r.add("a", 5) }, growB: func(r *flakyReplica) { r.add("b", 1) }, wantSV: true},
+	} {
+		t.Run(tc.desc, func(t *testing.T) {
+			a, b, f, _ := newReplicas(t)
+			a.add("leaf", 4)
+			b.add("leaf", 4)
+			tc.growA(a)
+			tc.growB(b)
+
+			req := &trillian.GetLatestSignedLogRootRequest{LogId: fakeLogID}
+			respA, err := f.GetLatestSignedLogRoot(ctx, req)
+			if err != nil {
+				t.Fatalf("GetLatestSignedLogRoot(a): %v", err)
+			}
+			// Fail over to b, while a can still serve consistency proofs.
+			f.replicas[0].downUntil = time.Now().Add(time.Hour)
+			respB, err := f.GetLatestSignedLogRoot(ctx, req)
+
+			var svErr *SplitViewError
+			if got := errors.As(err, &svErr); got != tc.wantSV {
+				t.Fatalf("GetLatestSignedLogRoot(b): %v, want SplitViewError: %v", err, tc.wantSV)
+			}
+			if !tc.wantSV {
+				if err != nil {
+					t.Errorf("GetLatestSignedLogRoot(b): %v", err)
+				}
+				if got, want := b.roots, 1; got != want {
+					t.Errorf("replica b served %d roots, want %d", got, want)
+				}
+				return
+			}
+			if respB != nil {
+				t.Errorf("GetLatestSignedLogRoot(b) returned %v with error", respB)
+			}
+			bRoot, err := b.rootLog.GetLatestSignedLogRoot(ctx, req)
+			if err != nil {
+				t.Fatalf("GetLatestSignedLogRoot(): %v", err)
+			}
+			want := [2][]byte{respA.SignedLogRoot.LogRoot, bRoot.SignedLogRoot.LogRoot}
+			wantNames := [2]string{"a", "b"}
+			if a.tree.LeafCount() > b.tree.LeafCount() {
+				want[0], want[1] = want[1], want[0]
+				wantNames[0], wantNames[1] = wantNames[1], wantNames[0]
+			}
+			for i := range want {
+				if got := svErr.Roots[i].GetLogRoot(); !bytes.Equal(got, want[i]) {
+					t.Errorf("SplitViewError.Roots[%d] = %x, want %x", i, got, want[i])
+				}
+			}
+			if svErr.Replicas != wantNames {
+				t.Errorf("SplitViewError.Replicas = %v, want %v", svErr.Replicas, wantNames)
+			}
+		})
+	}
+}
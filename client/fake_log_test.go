@@ -0,0 +1,276 @@
+// Copyright 2020 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package client
+
+import (
+	"bytes"
+	"context"
+	"crypto"
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/google/trillian"
+	"github.com/google/trillian/crypto/keys/pem"
+	"github.com/google/trillian/merkle"
+	"github.com/google/trillian/merkle/rfc6962"
+	"github.com/google/trillian/testonly"
+	"github.com/google/trillian/types"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	tcrypto "github.com/google/trillian/crypto"
+)
+
+const fakeLogID = 5
+
+// rootLog is a log which serves the signed roots, leaves and proofs of an
+// in-memory tree. Queued leaves are integrated straight away.
+type rootLog struct {
+	trillian.TrillianLogClient
+	signer *tcrypto.Signer
+
+	mu        sync.Mutex
+	tree      *merkle.InMemoryMerkleTree
+	leaves    [][]byte
+	timestamp uint64
+	// fetched is the start index of each GetLeavesByRange request.
+	fetched []int64
+	// batches is the number of leaves in each QueueLeaves request.
+	batches []int
+	// queueErrs are returned by the next QueueLeaves requests.
+	queueErrs []error
+}
+
+func newRootLog(t *testing.T) (*rootLog, *LogVerifier) {
+	t.Helper()
+	key, err := pem.UnmarshalPrivateKey(testonly.DemoPrivateKey, testonly.DemoPrivateKeyPass)
+	if err != nil {
+		t.Fatalf("UnmarshalPrivateKey(): %v", err)
+	}
+	l := &rootLog{
+		signer: tcrypto.NewSigner(0, key, crypto.SHA256),
+		tree:   merkle.NewInMemoryMerkleTree(rfc6962.DefaultHasher),
+	}
+	return l, NewLogVerifier(rfc6962.DefaultHasher, key.Public(), crypto.SHA256)
+}
+
+// add adds n leaves to the tree, with values starting with prefix.
+func (l *rootLog) add(prefix string, n int) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	for i := 0; i < n; i++ {
+		value := []byte(fmt.Sprintf("%s%d", prefix, l.tree.LeafCount()))
+		l.tree.AddLeaf(value)
+		l.leaves = append(l.leaves, value)
+	}
+	l.timestamp++
+}
+
+func (l *rootLog) GetLatestSignedLogRoot(ctx context.Context, req *trillian.GetLatestSignedLogRootRequest, opts ...grpc.CallOption) (*trillian.GetLatestSignedLogRootResponse, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	slr, err := l.signedRoot()
+	if err != nil {
+		return nil, err
+	}
+	resp := &trillian.GetLatestSignedLogRootResponse{SignedLogRoot: slr}
+	if first, size := req.FirstTreeSize, l.tree.LeafCount(); first > 0 && first < size {
+		resp.Proof = &trillian.Proof{Hashes: l.proof(first, size)}
+	}
+	return resp, nil
+}
+
+// signedRoot returns the signed root of the tree. Must be called with l.mu
+// held.
+func (l *rootLog) signedRoot() (*trillian.SignedLogRoot, error) {
+	return l.signer.SignLogRoot(&types.LogRootV1{TreeSize: uint64(l.tree.LeafCount()), RootHash: l.tree.CurrentRoot().Hash(), TimestampNanos: l.timestamp})
+}
+
+// proof returns a consistency proof. Must be called with l.mu held.
+func (l *rootLog) proof(first, second int64) [][]byte {
+	var hashes [][]byte
+	for _, n := range l.tree.SnapshotConsistency(first, second) {
+		hashes = append(hashes, n.Value.Hash())
+	}
+	return hashes
+}
+
+func (l *rootLog) GetConsistencyProof(ctx context.Context, req *trillian.GetConsistencyProofRequest, opts ...grpc.CallOption) (*trillian.GetConsistencyProofResponse, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	slr, err := l.signedRoot()
+	if err != nil {
+		return nil, err
+	}
+	return &trillian.GetConsistencyProofResponse{
+		Proof:         &trillian.Proof{Hashes: l.proof(req.FirstTreeSize, req.SecondTreeSize)},
+		SignedLogRoot: slr,
+	}, nil
+}
+
+func (l *rootLog) GetLeavesByRange(ctx context.Context, req *trillian.GetLeavesByRangeRequest, opts ...grpc.CallOption) (*trillian.GetLeavesByRangeResponse, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.fetched = append(l.fetched, req.StartIndex)
+	resp := &trillian.GetLeavesByRangeResponse{}
+	for i := req.StartIndex; i < req.StartIndex+req.Count && i < int64(len(l.leaves)); i++ {
+		resp.Leaves = append(resp.Leaves, &trillian.LogLeaf{LeafIndex: i, LeafValue: l.leaves[i]})
+	}
+	return resp, nil
+}
+
+func (l *rootLog) GetLeavesByIndex(ctx context.Context, req *trillian.GetLeavesByIndexRequest, opts ...grpc.CallOption) (*trillian.GetLeavesByIndexResponse, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	slr, err := l.signedRoot()
+	if err != nil {
+		return nil, err
+	}
+	resp := &trillian.GetLeavesByIndexResponse{SignedLogRoot: slr}
+	for _, i := range req.LeafIndex {
+		if i < 0 || i >= int64(len(l.leaves)) {
+			return nil, status.Errorf(codes.OutOfRange, "leaf %d is not in tree", i)
+		}
+		resp.Leaves = append(resp.Leaves, &trillian.LogLeaf{LeafIndex: i, LeafValue: l.leaves[i]})
+	}
+	return resp, nil
+}
+
+func (l *rootLog) QueueLeaves(ctx context.Context, req *trillian.QueueLeavesRequest, opts ...grpc.CallOption) (*trillian.QueueLeavesResponse, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if len(l.queueErrs) > 0 {
+		err := l.queueErrs[0]
+		l.queueErrs = l.queueErrs[1:]
+		return nil, err
+	}
+	l.batches = append(l.batches, len(req.Leaves))
+	resp := &trillian.QueueLeavesResponse{}
+	for _, leaf := range req.Leaves {
+		s := status.New(codes.OK, "")
+		if l.index(leaf.LeafValue) >= 0 {
+			s = status.New(codes.AlreadyExists, "leaf already exists")
+		} else {
+			l.tree.AddLeaf(leaf.LeafValue)
+			l.leaves = append(l.leaves, leaf.LeafValue)
+		}
+		resp.QueuedLeaves = append(resp.QueuedLeaves, &trillian.QueuedLogLeaf{Leaf: leaf, Status: s.Proto()})
+	}
+	l.timestamp++
+	return resp, nil
+}
+
+// index returns the index of the leaf with the given value, or -1. Must be
+// called with l.mu held.
+func (l *rootLog) index(value []byte) int64 {
+	for i, v := range l.leaves {
+		if bytes.Equal(v, value) {
+			return int64(i)
+		}
+	}
+	return -1
+}
+
+func (l *rootLog) GetInclusionProofByHash(ctx context.Context, req *trillian.GetInclusionProofByHashRequest, opts ...grpc.CallOption) (*trillian.GetInclusionProofByHashResponse, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	slr, err := l.signedRoot()
+	if err != nil {
+		return nil, err
+	}
+	for i := int64(0); i < req.TreeSize && i < l.tree.LeafCount(); i++ {
+		if !bytes.Equal(l.tree.LeafHash(i+1), req.LeafHash) {
+			continue
+		}
+		return &trillian.GetInclusionProofByHashResponse{Proof: []*trillian.Proof{l.inclusion(i, req.TreeSize)}, SignedLogRoot: slr}, nil
+	}
+	return nil, status.Error(codes.NotFound, "leaf not found")
+}
+
+// inclusion returns an inclusion proof. Must be called with l.mu held.
+func (l *rootLog) inclusion(index, size int64) *trillian.Proof {
+	proof := &trillian.Proof{LeafIndex: index}
+	for _, n := range l.tree.PathToRootAtSnapshot(index+1, size) {
+		proof.Hashes = append(proof.Hashes, n.Value.Hash())
+	}
+	return proof
+}
+
+func (l *rootLog) GetInclusionProof(ctx context.Context, req *trillian.GetInclusionProofRequest, opts ...grpc.CallOption) (*trillian.GetInclusionProofResponse, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	slr, err := l.signedRoot()
+	if err != nil {
+		return nil, err
+	}
+	if req.LeafIndex >= req.TreeSize {
+		return nil, status.Error(codes.InvalidArgument, "leaf is not in tree")
+	}
+	// Like the log server, there's no proof for trees larger than the
+	// current one.
+	if req.TreeSize > l.tree.LeafCount() {
+		return &trillian.GetInclusionProofResponse{SignedLogRoot: slr}, nil
+	}
+	return &trillian.GetInclusionProofResponse{Proof: l.inclusion(req.LeafIndex, req.TreeSize), SignedLogRoot: slr}, nil
+}
+
+// GetEntryAndProof proves inclusion in the current tree if the requested tree
+// is larger, like the log server.
+func (l *rootLog) GetEntryAndProof(ctx context.Context, req *trillian.GetEntryAndProofRequest, opts ...grpc.CallOption) (*trillian.GetEntryAndProofResponse, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	slr, err := l.signedRoot()
+	if err != nil {
+		return nil, err
+	}
+	size := req.TreeSize
+	if size > l.tree.LeafCount() {
+		size = l.tree.LeafCount()
+	}
+	if req.LeafIndex >= size {
+		return nil, status.Error(codes.InvalidArgument, "leaf is not in tree")
+	}
+	return &trillian.GetEntryAndProofResponse{
+		Proof:         l.inclusion(req.LeafIndex, size),
+		Leaf:          &trillian.LogLeaf{LeafIndex: req.LeafIndex, LeafValue: l.leaves[req.LeafIndex], MerkleLeafHash: l.tree.LeafHash(req.LeafIndex + 1)},
+		SignedLogRoot: slr,
+	}, nil
+}
@@ -18,6 +18,7 @@ package client
 import (
 	"bytes"
 	"context"
+	"errors"
 	"fmt"
 	"sort"
 	"sync"
@@ -37,6 +38,7 @@ type LogClient struct {
 	MinMergeDelay time.Duration
 	client        trillian.TrillianLogClient
 	root          types.LogRootV1
+	store         TrustedRootStore
 	rootLock      sync.Mutex
 	updateLock    sync.Mutex
 }
@@ -51,6 +53,21 @@ func New(logID int64, client trillian.TrillianLogClient, verifier *LogVerifier,
 	}
 }
 
+// NewWithStore returns a new LogClient which trusts the root of the log kept
+// in store, if any, and saves each newly trusted root back to it.
+func NewWithStore(ctx context.Context, logID int64, client trillian.TrillianLogClient, verifier *LogVerifier, store TrustedRootStore) (*LogClient, error) {
+	root, err := store.Load(ctx, logID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load trusted root of log %d: %v", logID, err)
+	}
+	if root == nil {
+		root = &types.LogRootV1{}
+	}
+	c := New(logID, client, verifier, *root)
+	c.store = store
+	return c, nil
+}
+
 // NewFromTree creates a new LogClient given a tree config.
 func NewFromTree(client trillian.TrillianLogClient, config *trillian.Tree, root types.LogRootV1) (*LogClient, error) {
 	verifier, err := NewLogVerifierFromTree(config)
@@ -187,6 +204,9 @@ func (c *LogClient) getAndVerifyLatestRoot(ctx context.Context, trusted *types.L
 		// Tree has not been updated.
 		return &logRoot, nil
 	}
+	if logRoot.TreeSize < trusted.TreeSize {
+		return nil, &RollbackError{Trusted: *trusted, Root: logRoot, Err: errors.New("tree size decreased")}
+	}
 
 	// Verify root update if the tree / the latest signed log root isn't empty.
 	if logRoot.TreeSize > 0 {
@@ -209,7 +229,10 @@ func (c *LogClient) GetRoot() *types.LogRootV1 {
 
 // UpdateRoot retrieves the current SignedLogRoot, verifying it against roots this client has
 // seen in the past, and updating the currently trusted root if the new root verifies, and is
-// newer than the currently trusted root.
+// newer than the currently trusted root. If the client has a TrustedRootStore,
+// the new root is saved to it before being trusted. A root which is smaller
+// than, or inconsistent with, the trusted root is reported as a
+// *RollbackError.
 func (c *LogClient) UpdateRoot(ctx context.Context) (*types.LogRootV1, error) {
 	// Only one root update should be running at any point in time, because
 	// the update involves a consistency proof from the old value, and if the
@@ -240,6 +263,12 @@ func (c *LogClient) UpdateRoot(ctx context.Context) (*types.LogRootV1, error) {
 	if newTrusted.TimestampNanos > currentlyTrusted.TimestampNanos &&
 		newTrusted.TreeSize >= currentlyTrusted.TreeSize {
 
+		if c.store != nil {
+			if err := c.store.Store(ctx, c.LogID, newTrusted); err != nil {
+				return nil, fmt.Errorf("failed to store trusted root: %v", err)
+			}
+		}
+
 		// Take a copy of the new trusted root in order to prevent clients from modifying it.
 		c.root = *newTrusted
 
@@ -0,0 +1,255 @@
+// Copyright 2020 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/google/trillian"
+	"github.com/google/trillian/merkle/compact"
+	"github.com/google/trillian/types"
+)
+
+// DefaultFollowerBatchSize is the default number of leaves a LogFollower
+// fetches per request.
+const DefaultFollowerBatchSize = 1000
+
+// FollowerState is a checkpoint of a LogFollower, from which a new follower
+// can resume.
+type FollowerState struct {
+	// Start is the index of the first leaf handed to the callback.
+	Start uint64
+	// Size is the number of leaves of the log verified so far.
+	Size uint64
+	// Hashes is the compact range of the leaves verified so far.
+	Hashes [][]byte
+}
+
+// LeavesFunc processes the leaves handed to it by a LogFollower, which are
+// in order of index and have been verified against a signed log root.
+type LeavesFunc func(ctx context.Context, leaves []*trillian.LogLeaf) error
+
+// LogFollower tails a log, and hands its leaves to a callback once they have
+// been verified. It hashes the leaves it fetches into a compact range, and
+// checks that the range matches each root trusted by the LogClient. It also
+// fetches and hashes the leaves before its start index, as they are needed to
+// verify the later ones.
+type LogFollower struct {
+	client *LogClient
+	fact   *compact.RangeFactory
+	start  uint64
+
+	// BatchSize is the max number of leaves fetched per request.
+	BatchSize int64
+	// Checkpoint, if not nil, is called with the state of the follower after
+	// each batch of leaves is processed. An error stops the follower.
+	Checkpoint func(ctx context.Context, state FollowerState) error
+
+	mu sync.Mutex
+	cr *compact.Range
+}
+
+// NewLogFollower returns a LogFollower which hands the leaves of the log from
+// index start onwards to its callback.
+func NewLogFollower(client *LogClient, start uint64) *LogFollower {
+	fact := &compact.RangeFactory{Hash: client.Hasher.HashChildren}
+	return &LogFollower{
+		client:    client,
+		fact:      fact,
+		start:     start,
+		BatchSize: DefaultFollowerBatchSize,
+		cr:        fact.NewEmptyRange(0),
+	}
+}
+
+// NewLogFollowerFromState returns a LogFollower which resumes from state.
+func NewLogFollowerFromState(client *LogClient, state FollowerState) (*LogFollower, error) {
+	f := NewLogFollower(client, state.Start)
+	cr, err := f.fact.NewRange(0, state.Size, state.Hashes)
+	if err != nil {
+		return nil, fmt.Errorf("invalid follower state: %v", err)
+	}
+	f.cr = cr
+	return f, nil
+}
+
+// State returns the current state of the follower.
+func (f *LogFollower) State() FollowerState {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	hashes := make([][]byte, len(f.cr.Hashes()))
+	copy(hashes, f.cr.Hashes())
+	return FollowerState{Start: f.start, Size: f.cr.End(), Hashes: hashes}
+}
+
+// Follow hands the verified leaves of the log to fn, in batches, until ctx is
+// done or an error occurs. The leaves of a batch are not handed to fn again
+// once it has returned nil for them.
+func (f *LogFollower) Follow(ctx context.Context, fn LeavesFunc) error {
+	for {
+		if root := f.client.GetRoot(); root.TreeSize > 0 {
+			if err := f.catchUp(ctx, root, fn); err != nil {
+				return err
+			}
+		}
+		if _, err := f.client.WaitForRootUpdate(ctx); err != nil {
+			return err
+		}
+	}
+}
+
+// catchUp processes the leaves up to the size of root.
+func (f *LogFollower) catchUp(ctx context.Context, root *types.LogRootV1, fn LeavesFunc) error {
+	f.mu.Lock()
+	cr := f.cr
+	f.mu.Unlock()
+	if cr.End() >= root.TreeSize {
+		return f.verify(ctx, cr, root)
+	}
+
+	for cr.End() < root.TreeSize {
+		leaves, err := f.fetch(ctx, cr.End(), root.TreeSize)
+		if err != nil {
+			return err
+		}
+		next, err := cloneRange(f.fact, cr)
+		if err != nil {
+			return err
+		}
+		for _, l := range leaves {
+			if err := next.Append(f.client.Hasher.HashLeaf(l.LeafValue), nil); err != nil {
+				return err
+			}
+		}
+		if err := f.verify(ctx, next, root); err != nil {
+			return err
+		}
+
+		var verified []*trillian.LogLeaf
+		for _, l := range leaves {
+			if uint64(l.LeafIndex) >= f.start {
+				verified = append(verified, l)
+			}
+		}
+		if len(verified) > 0 {
+			if err := fn(ctx, verified); err != nil {
+				return err
+			}
+		}
+		f.mu.Lock()
+		f.cr = next
+		f.mu.Unlock()
+		cr = next
+		if f.Checkpoint != nil {
+			if err := f.Checkpoint(ctx, f.State()); err != nil {
+				return fmt.Errorf("failed to checkpoint follower: %v", err)
+			}
+		}
+	}
+	return nil
+}
+
+// fetch returns the next leaves from index start, and before index end.
+func (f *LogFollower) fetch(ctx context.Context, start, end uint64) ([]*trillian.LogLeaf, error) {
+	count := end - start
+	if max := uint64(f.BatchSize); max > 0 && count > max {
+		count = max
+	}
+	resp, err := f.client.client.GetLeavesByRange(ctx, &trillian.GetLeavesByRangeRequest{
+		LogId:      f.client.LogID,
+		StartIndex: int64(start),
+		Count:      int64(count),
+	})
+	if err != nil {
+		return nil, err
+	}
+	// The server may return fewer leaves than requested, but not none.
+	leaves := resp.Leaves
+	if len(leaves) == 0 {
+		return nil, fmt.Errorf("GetLeavesByRange(%d, %d) returned no leaves", start, count)
+	}
+	if uint64(len(leaves)) > count {
+		leaves = leaves[:count]
+	}
+	for i, l := range leaves {
+		if want := int64(start) + int64(i); l.LeafIndex != want {
+			return nil, fmt.Errorf("Leaves[%d].LeafIndex=%d, want %d", i, l.LeafIndex, want)
+		}
+	}
+	return leaves, nil
+}
+
+// verify checks that the leaves hashed into cr are consistent with root,
+// fetching a consistency proof between them if they have different sizes.
+func (f *LogFollower) verify(ctx context.Context, cr *compact.Range, root *types.LogRootV1) error {
+	hash, err := cr.GetRootHash(nil)
+	if err != nil {
+		return err
+	}
+	size := cr.End()
+	if size <= root.TreeSize {
+		proof, err := f.consistencyProof(ctx, size, root.TreeSize)
+		if err != nil {
+			return err
+		}
+		if err := f.client.v.VerifyConsistencyProof(int64(size), int64(root.TreeSize), hash, root.RootHash, proof); err != nil {
+			return fmt.Errorf("leaves [0, %d) don't match log root of size %d: %v", size, root.TreeSize, err)
+		}
+		return nil
+	}
+
+	// The follower has verified more leaves than are in the root it was
+	// given, which must be a prefix of them.
+	proof, err := f.consistencyProof(ctx, root.TreeSize, size)
+	if err != nil {
+		return err
+	}
+	if err := f.client.v.VerifyConsistencyProof(int64(root.TreeSize), int64(size), root.RootHash, hash, proof); err != nil {
+		return &RollbackError{
+			Trusted: types.LogRootV1{TreeSize: size, RootHash: hash},
+			Root:    *root,
+			Err:     errors.New("log root is not a prefix of the verified leaves"),
+		}
+	}
+	return nil
+}
+
+// consistencyProof fetches the proof between the two tree sizes, if one is
+// needed.
+func (f *LogFollower) consistencyProof(ctx context.Context, first, second uint64) ([][]byte, error) {
+	if first == 0 || first == second {
+		return nil, nil
+	}
+	resp, err := f.client.client.GetConsistencyProof(ctx, &trillian.GetConsistencyProofRequest{
+		LogId:          f.client.LogID,
+		FirstTreeSize:  int64(first),
+		SecondTreeSize: int64(second),
+	})
+	if err != nil {
+		return nil, err
+	}
+	return resp.GetProof().GetHashes(), nil
+}
+
+// cloneRange returns a copy of the compact range that can be appended to
+// without affecting the original.
+func cloneRange(fact *compact.RangeFactory, cr *compact.Range) (*compact.Range, error) {
+	hashes := make([][]byte, len(cr.Hashes()))
+	copy(hashes, cr.Hashes())
+	return fact.NewRange(cr.Begin(), cr.End(), hashes)
+}
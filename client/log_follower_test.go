@@ -0,0 +1,157 @@
+// Copyright 2020 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package client
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/google/trillian"
+	"github.com/google/trillian/types"
+)
+
+// follow runs the follower until it has handed over the leaf with index last,
+// and returns the batches of indices it handed over.
+func follow(ctx context.Context, t *testing.T, f *LogFollower, last int64) [][]int64 {
+	t.Helper()
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	var batches [][]int64
+	err := f.Follow(ctx, func(ctx context.Context, leaves []*trillian.LogLeaf) error {
+		var batch []int64
+		for _, l := range leaves {
+			batch = append(batch, l.LeafIndex)
+		}
+		batches = append(batches, batch)
+		if leaves[len(leaves)-1].LeafIndex >= last {
+			cancel()
+		}
+		return nil
+	})
+	if ctx.Err() == nil {
+		t.Fatalf("Follow(): %v", err)
+	}
+	return batches
+}
+
+func TestLogFollower(t *testing.T) {
+	ctx := context.Background()
+	log, verifier := newRootLog(t)
+	log.add("leaf", 10)
+
+	f := NewLogFollower(New(fakeLogID, log, verifier, types.LogRootV1{}), 3)
+	f.BatchSize = 4
+	var states []FollowerState
+	f.Checkpoint = func(ctx context.Context, state FollowerState) error {
+		states = append(states, state)
+		return nil
+	}
+	if got, want := follow(ctx, t, f, 9), [][]int64{{3}, {4, 5, 6, 7}, {8, 9}}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Follow() handed over %v, want %v", got, want)
+	}
+	var sizes []uint64
+	for _, s := range states {
+		sizes = append(sizes, s.Size)
+	}
+	if got, want := sizes, []uint64{4, 8, 10}; !reflect.DeepEqual(got, want) {
+		t.Errorf("checkpoints of sizes %v, want %v", got, want)
+	}
+
+	// A follower resumed from the last checkpoint only fetches the new leaves.
+	log.add("leaf", 5)
+	log.fetched = nil
+	f, err := NewLogFollowerFromState(New(fakeLogID, log, verifier, types.LogRootV1{}), states[len(states)-1])
+	if err != nil {
+		t.Fatalf("NewLogFollowerFromState(): %v", err)
+	}
+	if got, want := follow(ctx, t, f, 14), [][]int64{{10, 11, 12, 13, 14}}; !reflect.DeepEqual(got, want) {
+		t.Errorf("resumed Follow() handed over %v, want %v", got, want)
+	}
+	if got, want := log.fetched, []int64{10}; !reflect.DeepEqual(got, want) {
+		t.Errorf("resumed follower fetched from %v, want %v", got, want)
+	}
+}
+
+func TestLogFollowerErrors(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("bad-leaf", func(t *testing.T) {
+		log, verifier := newRootLog(t)
+		log.add("leaf", 10)
+		log.leaves[5] = []byte("bad")
+		f := NewLogFollower(New(fakeLogID, log, verifier, types.LogRootV1{}), 0)
+		f.BatchSize = 4
+		var got []int64
+		err := f.Follow(ctx, func(ctx context.Context, leaves []*trillian.LogLeaf) error {
+			for _, l := range leaves {
+				got = append(got, l.LeafIndex)
+			}
+			return nil
+		})
+		if err == nil {
+			t.Fatal("Follow() with bad leaf succeeded")
+		}
+		if want := []int64{0, 1, 2, 3}; !reflect.DeepEqual(got, want) {
+			t.Errorf("Follow() handed over %v, want %v", got, want)
+		}
+		if got, want := f.State().Size, uint64(4); got != want {
+			t.Errorf("State().Size = %d, want %d", got, want)
+		}
+	})
+
+	t.Run("callback", func(t *testing.T) {
+		log, verifier := newRootLog(t)
+		log.add("leaf", 10)
+		f := NewLogFollower(New(fakeLogID, log, verifier, types.LogRootV1{}), 0)
+		f.BatchSize = 4
+		wantErr := errors.New("callback failed")
+		err := f.Follow(ctx, func(ctx context.Context, leaves []*trillian.LogLeaf) error {
+			if leaves[0].LeafIndex == 4 {
+				return wantErr
+			}
+			return nil
+		})
+		if err != wantErr {
+			t.Errorf("Follow(): %v, want %v", err, wantErr)
+		}
+		if got, want := f.State().Size, uint64(4); got != want {
+			t.Errorf("State().Size = %d, want %d", got, want)
+		}
+	})
+
+	t.Run("rollback", func(t *testing.T) {
+		log, verifier := newRootLog(t)
+		log.add("leaf", 10)
+		f := NewLogFollower(New(fakeLogID, log, verifier, types.LogRootV1{}), 0)
+		follow(ctx, t, f, 9)
+
+		forked, _ := newRootLog(t)
+		forked.add("fork", 6)
+		f, err := NewLogFollowerFromState(New(fakeLogID, forked, verifier, types.LogRootV1{}), f.State())
+		if err != nil {
+			t.Fatalf("NewLogFollowerFromState(): %v", err)
+		}
+		err = f.Follow(ctx, func(ctx context.Context, leaves []*trillian.LogLeaf) error {
+			t.Errorf("Follow() handed over leaves of forked log")
+			return nil
+		})
+		var rbErr *RollbackError
+		if !errors.As(err, &rbErr) {
+			t.Errorf("Follow(): %v, want RollbackError", err)
+		}
+	})
+}
@@ -81,8 +81,32 @@ func NewLogVerifierFromTree(config *trillian.Tree) (*LogVerifier, error) {
 	return NewLogVerifier(logHasher, logPubKey, sigHash), nil
 }
 
+// RollbackError is returned when a log root is not an append-only update of
+// the trusted root: either the tree is smaller, or there is no proof that the
+// tree is consistent with the trusted one. Either way the log may have been
+// rolled back or forked.
+type RollbackError struct {
+	// Trusted is the root trusted by the client.
+	Trusted types.LogRootV1
+	// Root is the root which failed verification.
+	Root types.LogRootV1
+	// Err is the reason the root failed verification.
+	Err error
+}
+
+func (e *RollbackError) Error() string {
+	return fmt.Sprintf("log root %d %x is not consistent with trusted root %d %x: %v", e.Root.TreeSize, e.Root.RootHash, e.Trusted.TreeSize, e.Trusted.RootHash, e.Err)
+}
+
+// Unwrap returns the reason the root failed verification.
+func (e *RollbackError) Unwrap() error {
+	return e.Err
+}
+
 // VerifyRoot verifies that newRoot is a valid append-only operation from
 // trusted. If trusted.TreeSize is zero, a consistency proof is not needed.
+// It returns a *RollbackError if newRoot is smaller than trusted or isn't
+// consistent with it.
 func (c *LogVerifier) VerifyRoot(trusted *types.LogRootV1, newRoot *trillian.SignedLogRoot, consistency [][]byte) (*types.LogRootV1, error) {
 
 	if trusted == nil {
@@ -100,9 +124,12 @@ func (c *LogVerifier) VerifyRoot(trusted *types.LogRootV1, newRoot *trillian.Sig
 
 	// Implicitly trust the first root we get.
 	if trusted.TreeSize != 0 {
+		if r.TreeSize < trusted.TreeSize {
+			return nil, &RollbackError{Trusted: *trusted, Root: *r, Err: errors.New("tree size decreased")}
+		}
 		// Verify consistency proof.
 		if err := c.v.VerifyConsistencyProof(int64(trusted.TreeSize), int64(r.TreeSize), trusted.RootHash, r.RootHash, consistency); err != nil {
-			return nil, fmt.Errorf("failed to verify consistency proof from %d->%d %x->%x: %v", trusted.TreeSize, r.TreeSize, trusted.RootHash, r.RootHash, err)
+			return nil, &RollbackError{Trusted: *trusted, Root: *r, Err: fmt.Errorf("failed to verify consistency proof: %v", err)}
 		}
 	}
 	return r, nil
@@ -0,0 +1,159 @@
+// Copyright 2020 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package client
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/trillian"
+	"github.com/google/trillian/client/backoff"
+	"github.com/google/trillian/types"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// MapMutationFunc computes the leaves to write to a map, and the metadata of
+// the new map root, from the current leaves and the root they were read at.
+// It may be called more than once for a single update, so it must not have
+// side effects. Returning no leaves means there is nothing to write.
+type MapMutationFunc func(ctx context.Context, leaves []*trillian.MapLeaf, root *types.MapRootV1) ([]*trillian.MapLeaf, []byte, error)
+
+// MapWriteClient writes to a Trillian Map with optimistic concurrency
+// control: mutations are computed from verified reads, and written with the
+// revision following the one read, so that the write fails if another writer
+// got there first.
+type MapWriteClient struct {
+	*MapClient
+	Write trillian.TrillianMapWriteClient
+	// Backoff sets the pauses between attempts after a revision conflict.
+	Backoff backoff.Backoff
+}
+
+// NewMapWriteClient returns a MapWriteClient which reads the map through c,
+// and writes to it through write.
+func NewMapWriteClient(c *MapClient, write trillian.TrillianMapWriteClient) *MapWriteClient {
+	return &MapWriteClient{
+		MapClient: c,
+		Write:     write,
+		Backoff: backoff.Backoff{
+			Min:    10 * time.Millisecond,
+			Max:    5 * time.Second,
+			Factor: 2,
+			Jitter: true,
+		},
+	}
+}
+
+// Update reads the leaves at indexes, passes them to mutate, and writes the
+// leaves it returns at the next revision of the map. If another writer
+// creates that revision first, Update reads the leaves again and retries
+// until ctx is done. A write which fails with FailedPrecondition is only
+// retried if the map has a newer revision than the one read, as writes also
+// fail that way for other reasons, such as the map being frozen. Once the
+// write succeeds, it verifies the new map root and the written leaves, and
+// returns the root. If there is nothing to write, it returns the root the
+// leaves were read at.
+func (c *MapWriteClient) Update(ctx context.Context, indexes [][]byte, mutate MapMutationFunc) (*types.MapRootV1, error) {
+	b := c.Backoff
+	for {
+		root, conflict, err := c.tryUpdate(ctx, indexes, mutate)
+		if !conflict {
+			return root, err
+		}
+		select {
+		case <-ctx.Done():
+			return nil, status.Errorf(codes.DeadlineExceeded, "%v, last conflict: %v", ctx.Err(), err)
+		case <-time.After(b.Duration()):
+		}
+	}
+}
+
+// tryUpdate makes a single attempt at an update. It returns true if the
+// write failed because of a revision conflict.
+func (c *MapWriteClient) tryUpdate(ctx context.Context, indexes [][]byte, mutate MapMutationFunc) (*types.MapRootV1, bool, error) {
+	leaves, root, err := c.GetAndVerifyMapLeaves(ctx, indexes)
+	if err != nil {
+		return nil, false, err
+	}
+	writes, metadata, err := mutate(ctx, leaves, root)
+	if err != nil {
+		return nil, false, err
+	}
+	if len(writes) == 0 {
+		return root, false, nil
+	}
+
+	rev := int64(root.Revision) + 1
+	resp, err := c.Write.WriteLeaves(ctx, &trillian.WriteMapLeavesRequest{
+		MapId:          c.MapID,
+		Leaves:         writes,
+		Metadata:       metadata,
+		ExpectRevision: rev,
+	})
+	if status.Code(err) == codes.FailedPrecondition {
+		conflict, rerr := c.revisionConflict(ctx, rev)
+		if rerr != nil {
+			return nil, false, fmt.Errorf("%v, and failed to read the map root: %v", err, rerr)
+		}
+		return nil, conflict, err
+	} else if err != nil {
+		return nil, false, err
+	}
+	if resp.Revision != rev {
+		return nil, false, fmt.Errorf("WriteLeaves() wrote revision %d, want %d", resp.Revision, rev)
+	}
+	return c.verifyWrite(ctx, rev, writes)
+}
+
+// revisionConflict returns true if the map has moved on to revision rev or
+// beyond since it was read, so that a write expecting rev failed because of
+// another writer. FailedPrecondition is also returned for other reasons, e.g.
+// if the map is frozen, which retrying won't fix.
+func (c *MapWriteClient) revisionConflict(ctx context.Context, rev int64) (bool, error) {
+	root, err := c.GetAndVerifyLatestMapRoot(ctx)
+	if err != nil {
+		return false, err
+	}
+	return int64(root.Revision) >= rev, nil
+}
+
+// verifyWrite checks that the map root of revision rev is signed, and that
+// the leaves written at that revision are included in it.
+func (c *MapWriteClient) verifyWrite(ctx context.Context, rev int64, writes []*trillian.MapLeaf) (*types.MapRootV1, bool, error) {
+	root, err := c.GetAndVerifyMapRootByRevision(ctx, rev)
+	if err != nil {
+		return nil, false, err
+	}
+	indexes := make([][]byte, 0, len(writes))
+	for _, l := range writes {
+		indexes = append(indexes, l.Index)
+	}
+	leaves, leavesRoot, err := c.GetAndVerifyMapLeavesByRevision(ctx, rev, indexes)
+	if err != nil {
+		return nil, false, err
+	}
+	if !bytes.Equal(leavesRoot.RootHash, root.RootHash) {
+		return nil, false, fmt.Errorf("map root of revision %d has hash %x, but its leaves were served with root hash %x", rev, root.RootHash, leavesRoot.RootHash)
+	}
+	for i, l := range leaves {
+		if !bytes.Equal(l.Index, writes[i].Index) || !bytes.Equal(l.LeafValue, writes[i].LeafValue) {
+			return nil, false, fmt.Errorf("leaf %x at revision %d doesn't have the written value", writes[i].Index, rev)
+		}
+	}
+	return root, false, nil
+}
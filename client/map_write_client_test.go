@@ -0,0 +1,377 @@
+// Copyright 2020 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package client
+
+import (
+	"context"
+	"crypto"
+	"crypto/sha256"
+	"errors"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/google/trillian"
+	"github.com/google/trillian/client/backoff"
+	"github.com/google/trillian/crypto/keys/pem"
+	"github.com/google/trillian/merkle/hashers"
+	"github.com/google/trillian/storage/testonly"
+	"github.com/google/trillian/types"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	tcrypto "github.com/google/trillian/crypto"
+	ttestonly "github.com/google/trillian/testonly"
+)
+
+const fakeMapID = 7
+
+// fakeMap is a map which keeps every revision in memory, and serves signed
+// roots and inclusion proofs computed from scratch.
+type fakeMap struct {
+	trillian.TrillianMapClient
+	hasher hashers.MapHasher
+	signer *tcrypto.Signer
+
+	mu sync.Mutex
+	// revisions holds the leaf values of each revision, by index.
+	revisions []map[string][]byte
+	// interfere is the number of upcoming writes which another writer gets in
+	// ahead of, by incrementing the counter at interfereIndex.
+	interfere      int
+	interfereIndex []byte
+	// writeErr, if set, fails all writes.
+	writeErr error
+}
+
+// fakeMapWriter is the write API of a fakeMap.
+type fakeMapWriter struct {
+	trillian.TrillianMapWriteClient
+	m *fakeMap
+}
+
+func newFakeMap(t *testing.T) (*fakeMap, *MapWriteClient) {
+	t.Helper()
+	key, err := pem.UnmarshalPrivateKey(ttestonly.DemoPrivateKey, ttestonly.DemoPrivateKeyPass)
+	if err != nil {
+		t.Fatalf("UnmarshalPrivateKey(): %v", err)
+	}
+	tree := proto.Clone(testonly.MapTree).(*trillian.Tree)
+	tree.TreeId = fakeMapID
+	hasher, err := hashers.NewMapHasher(tree.HashStrategy)
+	if err != nil {
+		t.Fatalf("NewMapHasher(): %v", err)
+	}
+	m := &fakeMap{
+		hasher:    hasher,
+		signer:    tcrypto.NewSigner(0, key, crypto.SHA256),
+		revisions: []map[string][]byte{{}},
+	}
+	c, err := NewMapClientFromTree(m, tree)
+	if err != nil {
+		t.Fatalf("NewMapClientFromTree(): %v", err)
+	}
+	w := NewMapWriteClient(c, &fakeMapWriter{m: m})
+	w.Backoff = backoff.Backoff{Min: time.Millisecond, Max: time.Millisecond, Factor: 1}
+	return m, w
+}
+
+func bit(index []byte, i int) int {
+	return int(index[i/8]>>(7-uint(i%8))) & 1
+}
+
+// prefix returns index with bit depth set to b, and all the bits after it
+// cleared.
+func prefix(index []byte, depth, b int) []byte {
+	p := make([]byte, len(index))
+	copy(p, index)
+	p[depth/8] &^= 0xff >> uint(depth%8)
+	p[depth/8] |= byte(b << (7 - uint(depth%8)))
+	for i := depth/8 + 1; i < len(p); i++ {
+		p[i] = 0
+	}
+	return p
+}
+
+// subtreeHash returns the hash of the subtree at depth which holds leaves, or
+// nil if it is empty. The indices of leaves must share their first depth bits.
+func (m *fakeMap) subtreeHash(depth int, leaves map[string][]byte) []byte {
+	if len(leaves) == 0 {
+		return nil
+	}
+	bitLen := m.hasher.BitLen()
+	var index []byte
+	children := []map[string][]byte{{}, {}}
+	for k, v := range leaves {
+		index = []byte(k)
+		if depth < bitLen {
+			children[bit(index, depth)][k] = v
+		}
+	}
+	if depth == bitLen {
+		return m.hasher.HashLeaf(fakeMapID, index, leaves[string(index)])
+	}
+	hashes := make([][]byte, 2)
+	for b, child := range children {
+		if hashes[b] = m.subtreeHash(depth+1, child); hashes[b] == nil {
+			hashes[b] = m.hasher.HashEmpty(fakeMapID, prefix(index, depth, b), bitLen-depth-1)
+		}
+	}
+	return m.hasher.HashChildren(hashes[0], hashes[1])
+}
+
+func (m *fakeMap) rootHash(leaves map[string][]byte) []byte {
+	if h := m.subtreeHash(0, leaves); h != nil {
+		return h
+	}
+	return m.hasher.HashEmpty(fakeMapID, make([]byte, m.hasher.Size()), m.hasher.BitLen())
+}
+
+// proof returns the inclusion proof of index, with nil for empty siblings.
+func (m *fakeMap) proof(index []byte, leaves map[string][]byte) [][]byte {
+	bitLen := m.hasher.BitLen()
+	proof := make([][]byte, bitLen)
+	for height := range proof {
+		depth := bitLen - height
+		sibling := map[string][]byte{}
+		for k, v := range leaves {
+			other := []byte(k)
+			if bit(other, depth-1) == bit(index, depth-1) {
+				continue
+			}
+			same := true
+			for i := 0; i < depth-1 && same; i++ {
+				same = bit(other, i) == bit(index, i)
+			}
+			if same {
+				sibling[k] = v
+			}
+		}
+		proof[height] = m.subtreeHash(depth, sibling)
+	}
+	return proof
+}
+
+func (m *fakeMap) signedRoot(rev int64) (*trillian.SignedMapRoot, error) {
+	if rev < 0 || rev >= int64(len(m.revisions)) {
+		return nil, status.Errorf(codes.NotFound, "no revision %d", rev)
+	}
+	return m.signer.SignMapRoot(&types.MapRootV1{
+		RootHash:       m.rootHash(m.revisions[rev]),
+		TimestampNanos: uint64(rev),
+		Revision:       uint64(rev),
+	})
+}
+
+func (m *fakeMap) GetSignedMapRoot(ctx context.Context, req *trillian.GetSignedMapRootRequest, opts ...grpc.CallOption) (*trillian.GetSignedMapRootResponse, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	root, err := m.signedRoot(int64(len(m.revisions)) - 1)
+	if err != nil {
+		return nil, err
+	}
+	return &trillian.GetSignedMapRootResponse{MapRoot: root}, nil
+}
+
+func (m *fakeMap) GetSignedMapRootByRevision(ctx context.Context, req *trillian.GetSignedMapRootByRevisionRequest, opts ...grpc.CallOption) (*trillian.GetSignedMapRootResponse, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	root, err := m.signedRoot(req.Revision)
+	if err != nil {
+		return nil, err
+	}
+	return &trillian.GetSignedMapRootResponse{MapRoot: root}, nil
+}
+
+func (m *fakeMap) GetLeaves(ctx context.Context, req *trillian.GetMapLeavesRequest, opts ...grpc.CallOption) (*trillian.GetMapLeavesResponse, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.leaves(int64(len(m.revisions))-1, req.Index)
+}
+
+func (m *fakeMap) GetLeavesByRevision(ctx context.Context, req *trillian.GetMapLeavesByRevisionRequest, opts ...grpc.CallOption) (*trillian.GetMapLeavesResponse, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.leaves(req.Revision, req.Index)
+}
+
+func (m *fakeMap) leaves(rev int64, indexes [][]byte) (*trillian.GetMapLeavesResponse, error) {
+	root, err := m.signedRoot(rev)
+	if err != nil {
+		return nil, err
+	}
+	leaves := m.revisions[rev]
+	resp := &trillian.GetMapLeavesResponse{MapRoot: root}
+	for _, index := range indexes {
+		resp.MapLeafInclusion = append(resp.MapLeafInclusion, &trillian.MapLeafInclusion{
+			Leaf:      &trillian.MapLeaf{Index: index, LeafValue: leaves[string(index)]},
+			Inclusion: m.proof(index, leaves),
+		})
+	}
+	return resp, nil
+}
+
+// write adds a revision with the given leaves. Leaves with empty values are
+// removed.
+func (m *fakeMap) write(writes []*trillian.MapLeaf) int64 {
+	leaves := map[string][]byte{}
+	for k, v := range m.revisions[len(m.revisions)-1] {
+		leaves[k] = v
+	}
+	for _, l := range writes {
+		if len(l.LeafValue) == 0 {
+			delete(leaves, string(l.Index))
+		} else {
+			leaves[string(l.Index)] = l.LeafValue
+		}
+	}
+	m.revisions = append(m.revisions, leaves)
+	return int64(len(m.revisions)) - 1
+}
+
+func (w *fakeMapWriter) WriteLeaves(ctx context.Context, req *trillian.WriteMapLeavesRequest, opts ...grpc.CallOption) (*trillian.WriteMapLeavesResponse, error) {
+	m := w.m
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.writeErr != nil {
+		return nil, m.writeErr
+	}
+	if m.interfere > 0 {
+		m.interfere--
+		m.write([]*trillian.MapLeaf{{Index: m.interfereIndex, LeafValue: increment(m.revisions[len(m.revisions)-1][string(m.interfereIndex)])}})
+	}
+	if next := int64(len(m.revisions)); req.ExpectRevision != 0 && req.ExpectRevision != next {
+		return nil, status.Errorf(codes.FailedPrecondition, "can't write to revision %v", req.ExpectRevision)
+	}
+	return &trillian.WriteMapLeavesResponse{Revision: m.write(req.Leaves)}, nil
+}
+
+// increment returns the decimal counter value incremented by one.
+func increment(value []byte) []byte {
+	n, _ := strconv.Atoi(string(value))
+	return []byte(strconv.Itoa(n + 1))
+}
+
+func mapIndex(key string) []byte {
+	h := sha256.Sum256([]byte(key))
+	return h[:]
+}
+
+func TestMapWriteClientUpdate(t *testing.T) {
+	ctx := context.Background()
+	m, c := newFakeMap(t)
+	counter, other := mapIndex("counter"), mapIndex("other")
+	m.write([]*trillian.MapLeaf{{Index: other, LeafValue: []byte("value")}})
+
+	calls := 0
+	incr := func(ctx context.Context, leaves []*trillian.MapLeaf, root *types.MapRootV1) ([]*trillian.MapLeaf, []byte, error) {
+		calls++
+		return []*trillian.MapLeaf{{Index: counter, LeafValue: increment(leaves[0].LeafValue)}}, []byte("incr"), nil
+	}
+	for _, tc := range []struct {
+		desc      string
+		interfere int
+		wantCalls int
+		wantValue string
+		wantRev   uint64
+	}{
+		{desc: "first", wantCalls: 1, wantValue: "1", wantRev: 2},
+		{desc: "conflicts", interfere: 2, wantCalls: 3, wantValue: "4", wantRev: 5},
+	} {
+		t.Run(tc.desc, func(t *testing.T) {
+			calls = 0
+			m.interfere, m.interfereIndex = tc.interfere, counter
+			root, err := c.Update(ctx, [][]byte{counter}, incr)
+			if err != nil {
+				t.Fatalf("Update(): %v", err)
+			}
+			if calls != tc.wantCalls {
+				t.Errorf("Update() called mutate %d times, want %d", calls, tc.wantCalls)
+			}
+			if root.Revision != tc.wantRev {
+				t.Errorf("Update() = revision %d, want %d", root.Revision, tc.wantRev)
+			}
+			leaves, _, err := c.GetAndVerifyMapLeaves(ctx, [][]byte{counter, other})
+			if err != nil {
+				t.Fatalf("GetAndVerifyMapLeaves(): %v", err)
+			}
+			if got := string(leaves[0].LeafValue); got != tc.wantValue {
+				t.Errorf("counter = %q, want %q", got, tc.wantValue)
+			}
+			if got, want := string(leaves[1].LeafValue), "value"; got != want {
+				t.Errorf("other leaf = %q, want %q", got, want)
+			}
+		})
+	}
+}
+
+func TestMapWriteClientUpdateErrors(t *testing.T) {
+	ctx := context.Background()
+	index := mapIndex("key")
+
+	t.Run("no-op", func(t *testing.T) {
+		m, c := newFakeMap(t)
+		root, err := c.Update(ctx, [][]byte{index}, func(ctx context.Context, leaves []*trillian.MapLeaf, root *types.MapRootV1) ([]*trillian.MapLeaf, []byte, error) {
+			return nil, nil, nil
+		})
+		if err != nil {
+			t.Fatalf("Update(): %v", err)
+		}
+		if root.Revision != 0 || len(m.revisions) != 1 {
+			t.Errorf("Update() without leaves wrote revision %d", root.Revision)
+		}
+	})
+
+	t.Run("mutate", func(t *testing.T) {
+		_, c := newFakeMap(t)
+		wantErr := errors.New("mutate failed")
+		if _, err := c.Update(ctx, [][]byte{index}, func(ctx context.Context, leaves []*trillian.MapLeaf, root *types.MapRootV1) ([]*trillian.MapLeaf, []byte, error) {
+			return nil, nil, wantErr
+		}); err != wantErr {
+			t.Errorf("Update(): %v, want %v", err, wantErr)
+		}
+	})
+
+	t.Run("precondition", func(t *testing.T) {
+		// A FailedPrecondition without a new revision is not retried.
+		m, c := newFakeMap(t)
+		m.writeErr = status.Error(codes.FailedPrecondition, "map is frozen")
+		calls := 0
+		if _, err := c.Update(ctx, [][]byte{index}, func(ctx context.Context, leaves []*trillian.MapLeaf, root *types.MapRootV1) ([]*trillian.MapLeaf, []byte, error) {
+			calls++
+			return []*trillian.MapLeaf{{Index: index, LeafValue: []byte("value")}}, nil, nil
+		}); status.Code(err) != codes.FailedPrecondition {
+			t.Errorf("Update() to frozen map: %v, want code %v", err, codes.FailedPrecondition)
+		}
+		if calls != 1 {
+			t.Errorf("Update() to frozen map called mutate %d times, want 1", calls)
+		}
+	})
+
+	t.Run("deadline", func(t *testing.T) {
+		m, c := newFakeMap(t)
+		m.interfere, m.interfereIndex = 1000, mapIndex("busy")
+		cctx, cancel := context.WithTimeout(ctx, 20*time.Millisecond)
+		defer cancel()
+		if _, err := c.Update(cctx, [][]byte{index}, func(ctx context.Context, leaves []*trillian.MapLeaf, root *types.MapRootV1) ([]*trillian.MapLeaf, []byte, error) {
+			return []*trillian.MapLeaf{{Index: index, LeafValue: []byte("value")}}, nil, nil
+		}); status.Code(err) != codes.DeadlineExceeded {
+			t.Errorf("Update() with constant conflicts: %v, want code %v", err, codes.DeadlineExceeded)
+		}
+	})
+}
@@ -0,0 +1,443 @@
+// Copyright 2020 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package client
+
+import (
+	"bytes"
+	"context"
+	"crypto"
+	"crypto/x509"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/google/trillian"
+	"github.com/google/trillian/crypto/keyspb"
+	"github.com/google/trillian/crypto/sigpb"
+	"github.com/google/trillian/types"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+)
+
+// ProofBundleVersion is the version of the proof bundle format written by
+// this package.
+const ProofBundleVersion = 1
+
+// ProofBundle is self-contained evidence that a leaf is in a log or a map. It
+// holds everything needed to verify the leaf offline: the tree's public key
+// and hash strategy, the leaf, its inclusion proof, and the signed root the
+// proof is for. The bundle's signatures are checked with its own key, so
+// verifiers must also check that the key is the tree's. A log bundle may also
+// hold later roots of the log, each with a consistency proof from the root
+// before it.
+type ProofBundle struct {
+	Version int         `json:"version"`
+	Tree    *BundleTree `json:"tree"`
+
+	// LogLeaf, Inclusion and SignedLogRoot are set in log bundles.
+	LogLeaf       *trillian.LogLeaf       `json:"log_leaf,omitempty"`
+	Inclusion     *trillian.Proof         `json:"inclusion,omitempty"`
+	SignedLogRoot *trillian.SignedLogRoot `json:"signed_log_root,omitempty"`
+	// Consistency holds later roots of the log, in increasing order.
+	Consistency []*BundleConsistency `json:"consistency,omitempty"`
+
+	// MapLeaf and SignedMapRoot are set in map bundles.
+	MapLeaf       *trillian.MapLeafInclusion `json:"map_leaf,omitempty"`
+	SignedMapRoot *trillian.SignedMapRoot    `json:"signed_map_root,omitempty"`
+}
+
+// BundleTree holds the parameters of a tree needed to verify its proofs. The
+// enums are held by name.
+type BundleTree struct {
+	TreeID        int64  `json:"tree_id"`
+	TreeType      string `json:"tree_type"`
+	HashStrategy  string `json:"hash_strategy"`
+	HashAlgorithm string `json:"hash_algorithm"`
+	// PublicKey is the DER encoding of the tree's public key.
+	PublicKey []byte `json:"public_key"`
+}
+
+// BundleConsistency is a later root of a log, with a proof that it is
+// consistent with the previous root in the bundle.
+type BundleConsistency struct {
+	SignedLogRoot *trillian.SignedLogRoot `json:"signed_log_root"`
+	Proof         [][]byte                `json:"proof"`
+}
+
+// NewBundleTree returns the verification parameters of tree.
+func NewBundleTree(tree *trillian.Tree) *BundleTree {
+	return &BundleTree{
+		TreeID:        tree.TreeId,
+		TreeType:      tree.TreeType.String(),
+		HashStrategy:  tree.HashStrategy.String(),
+		HashAlgorithm: tree.HashAlgorithm.String(),
+		PublicKey:     tree.GetPublicKey().GetDer(),
+	}
+}
+
+// Tree returns a tree config holding the verification parameters, which can
+// be passed to NewLogVerifierFromTree or NewMapVerifierFromTree.
+func (t *BundleTree) Tree() (*trillian.Tree, error) {
+	treeType, ok := trillian.TreeType_value[t.TreeType]
+	if !ok {
+		return nil, fmt.Errorf("unknown tree type %q", t.TreeType)
+	}
+	strategy, ok := trillian.HashStrategy_value[t.HashStrategy]
+	if !ok {
+		return nil, fmt.Errorf("unknown hash strategy %q", t.HashStrategy)
+	}
+	hash, ok := sigpb.DigitallySigned_HashAlgorithm_value[t.HashAlgorithm]
+	if !ok {
+		return nil, fmt.Errorf("unknown hash algorithm %q", t.HashAlgorithm)
+	}
+	return &trillian.Tree{
+		TreeId:        t.TreeID,
+		TreeType:      trillian.TreeType(treeType),
+		HashStrategy:  trillian.HashStrategy(strategy),
+		HashAlgorithm: sigpb.DigitallySigned_HashAlgorithm(hash),
+		PublicKey:     &keyspb.PublicKey{Der: t.PublicKey},
+	}, nil
+}
+
+// MarshalJSON encodes the bundle as JSON. The protobuf fields are encoded
+// with protojson, using the field names of the .proto files, so that the
+// format only changes with the protobuf definitions.
+func (b *ProofBundle) MarshalJSON() ([]byte, error) {
+	j := bundleJSON{Version: b.Version, Tree: b.Tree, Consistency: b.Consistency}
+	var err error
+	for _, f := range []struct {
+		dst *json.RawMessage
+		m   proto.Message
+	}{
+		{dst: &j.LogLeaf, m: b.LogLeaf},
+		{dst: &j.Inclusion, m: b.Inclusion},
+		{dst: &j.SignedLogRoot, m: b.SignedLogRoot},
+		{dst: &j.MapLeaf, m: b.MapLeaf},
+		{dst: &j.SignedMapRoot, m: b.SignedMapRoot},
+	} {
+		if *f.dst, err = marshalProto(f.m); err != nil {
+			return nil, err
+		}
+	}
+	return json.Marshal(j)
+}
+
+// UnmarshalJSON parses a bundle encoded by MarshalJSON.
+func (b *ProofBundle) UnmarshalJSON(data []byte) error {
+	var j bundleJSON
+	if err := json.Unmarshal(data, &j); err != nil {
+		return err
+	}
+	*b = ProofBundle{Version: j.Version, Tree: j.Tree, Consistency: j.Consistency}
+	if j.LogLeaf != nil {
+		b.LogLeaf = &trillian.LogLeaf{}
+		if err := protojson.Unmarshal(j.LogLeaf, b.LogLeaf); err != nil {
+			return fmt.Errorf("log_leaf: %v", err)
+		}
+	}
+	if j.Inclusion != nil {
+		b.Inclusion = &trillian.Proof{}
+		if err := protojson.Unmarshal(j.Inclusion, b.Inclusion); err != nil {
+			return fmt.Errorf("inclusion: %v", err)
+		}
+	}
+	if j.SignedLogRoot != nil {
+		b.SignedLogRoot = &trillian.SignedLogRoot{}
+		if err := protojson.Unmarshal(j.SignedLogRoot, b.SignedLogRoot); err != nil {
+			return fmt.Errorf("signed_log_root: %v", err)
+		}
+	}
+	if j.MapLeaf != nil {
+		b.MapLeaf = &trillian.MapLeafInclusion{}
+		if err := protojson.Unmarshal(j.MapLeaf, b.MapLeaf); err != nil {
+			return fmt.Errorf("map_leaf: %v", err)
+		}
+	}
+	if j.SignedMapRoot != nil {
+		b.SignedMapRoot = &trillian.SignedMapRoot{}
+		if err := protojson.Unmarshal(j.SignedMapRoot, b.SignedMapRoot); err != nil {
+			return fmt.Errorf("signed_map_root: %v", err)
+		}
+	}
+	return nil
+}
+
+// MarshalJSON encodes the root with protojson, like the roots of ProofBundle.
+func (c *BundleConsistency) MarshalJSON() ([]byte, error) {
+	root, err := marshalProto(c.SignedLogRoot)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(consistencyJSON{SignedLogRoot: root, Proof: c.Proof})
+}
+
+// UnmarshalJSON parses a root encoded by MarshalJSON.
+func (c *BundleConsistency) UnmarshalJSON(data []byte) error {
+	var j consistencyJSON
+	if err := json.Unmarshal(data, &j); err != nil {
+		return err
+	}
+	*c = BundleConsistency{Proof: j.Proof}
+	if j.SignedLogRoot != nil {
+		c.SignedLogRoot = &trillian.SignedLogRoot{}
+		if err := protojson.Unmarshal(j.SignedLogRoot, c.SignedLogRoot); err != nil {
+			return fmt.Errorf("signed_log_root: %v", err)
+		}
+	}
+	return nil
+}
+
+// marshalProto encodes a protobuf field of a bundle, or returns nil if it is
+// not set.
+func marshalProto(m proto.Message) (json.RawMessage, error) {
+	if !m.ProtoReflect().IsValid() {
+		return nil, nil
+	}
+	return protojson.MarshalOptions{UseProtoNames: true}.Marshal(m)
+}
+
+// bundleJSON is the JSON encoding of a ProofBundle, with its protobuf fields
+// encoded by protojson.
+type bundleJSON struct {
+	Version       int                  `json:"version"`
+	Tree          *BundleTree          `json:"tree"`
+	LogLeaf       json.RawMessage      `json:"log_leaf,omitempty"`
+	Inclusion     json.RawMessage      `json:"inclusion,omitempty"`
+	SignedLogRoot json.RawMessage      `json:"signed_log_root,omitempty"`
+	Consistency   []*BundleConsistency `json:"consistency,omitempty"`
+	MapLeaf       json.RawMessage      `json:"map_leaf,omitempty"`
+	SignedMapRoot json.RawMessage      `json:"signed_map_root,omitempty"`
+}
+
+// consistencyJSON is the JSON encoding of a BundleConsistency.
+type consistencyJSON struct {
+	SignedLogRoot json.RawMessage `json:"signed_log_root"`
+	Proof         [][]byte        `json:"proof"`
+}
+
+// MarshalProofBundle returns the JSON encoding of b.
+func MarshalProofBundle(b *ProofBundle) ([]byte, error) {
+	return json.MarshalIndent(b, "", "  ")
+}
+
+// UnmarshalProofBundle parses a JSON encoded proof bundle, and checks that
+// this package supports its version. The bundle still needs verifying.
+func UnmarshalProofBundle(data []byte) (*ProofBundle, error) {
+	var b ProofBundle
+	if err := json.Unmarshal(data, &b); err != nil {
+		return nil, fmt.Errorf("failed to parse proof bundle: %v", err)
+	}
+	if b.Version != ProofBundleVersion {
+		return nil, fmt.Errorf("proof bundle version %d, want %d", b.Version, ProofBundleVersion)
+	}
+	if b.Tree == nil {
+		return nil, errors.New("proof bundle has no tree")
+	}
+	return &b, nil
+}
+
+// VerifyLog verifies a log bundle offline: the signatures of its roots, the
+// inclusion of its leaf in the first root, and the consistency of each later
+// root with the one before it. It returns the last root.
+func (b *ProofBundle) VerifyLog() (*types.LogRootV1, error) {
+	if b.LogLeaf == nil || b.Inclusion == nil || b.SignedLogRoot == nil {
+		return nil, errors.New("not a log proof bundle")
+	}
+	tree, err := b.Tree.Tree()
+	if err != nil {
+		return nil, err
+	}
+	v, err := NewLogVerifierFromTree(tree)
+	if err != nil {
+		return nil, err
+	}
+
+	root, err := v.VerifyRoot(&types.LogRootV1{}, b.SignedLogRoot, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to verify log root: %v", err)
+	}
+	leaf := v.BuildLeaf(b.LogLeaf.LeafValue)
+	if !bytes.Equal(leaf.MerkleLeafHash, b.LogLeaf.MerkleLeafHash) {
+		return nil, fmt.Errorf("leaf has hash %x, want %x", b.LogLeaf.MerkleLeafHash, leaf.MerkleLeafHash)
+	}
+	if b.Inclusion.LeafIndex != b.LogLeaf.LeafIndex {
+		return nil, fmt.Errorf("inclusion proof is for index %d, want %d", b.Inclusion.LeafIndex, b.LogLeaf.LeafIndex)
+	}
+	if err := v.VerifyInclusionByHash(root, leaf.MerkleLeafHash, b.Inclusion); err != nil {
+		return nil, fmt.Errorf("failed to verify inclusion proof: %v", err)
+	}
+	for i, c := range b.Consistency {
+		if root, err = v.VerifyRoot(root, c.SignedLogRoot, c.Proof); err != nil {
+			return nil, fmt.Errorf("failed to verify later root %d: %v", i, err)
+		}
+	}
+	return root, nil
+}
+
+// VerifyMap verifies a map bundle offline: the signature of its root, and the
+// inclusion of its leaf in it. It returns the root.
+func (b *ProofBundle) VerifyMap() (*types.MapRootV1, error) {
+	if b.MapLeaf == nil || b.SignedMapRoot == nil {
+		return nil, errors.New("not a map proof bundle")
+	}
+	tree, err := b.Tree.Tree()
+	if err != nil {
+		return nil, err
+	}
+	v, err := NewMapVerifierFromTree(tree)
+	if err != nil {
+		return nil, err
+	}
+	root, err := v.VerifySignedMapRoot(b.SignedMapRoot)
+	if err != nil {
+		return nil, fmt.Errorf("failed to verify map root: %v", err)
+	}
+	if err := v.VerifyMapLeafInclusionHash(root.RootHash, b.MapLeaf); err != nil {
+		return nil, fmt.Errorf("failed to verify inclusion proof: %v", err)
+	}
+	return root, nil
+}
+
+// checkBundleTree checks that tree is the config of the tree with the given ID
+// and public key, so that the bundle carries the key the client verified with.
+func checkBundleTree(tree *trillian.Tree, treeID int64, pubKey crypto.PublicKey) error {
+	if tree.GetTreeId() != treeID {
+		return fmt.Errorf("tree config is for tree %d, want %d", tree.GetTreeId(), treeID)
+	}
+	der, err := x509.MarshalPKIXPublicKey(pubKey)
+	if err != nil {
+		return fmt.Errorf("failed to marshal verifier key: %v", err)
+	}
+	if !bytes.Equal(tree.GetPublicKey().GetDer(), der) {
+		return errors.New("tree config has a different public key than the verifier")
+	}
+	return nil
+}
+
+// ProofBundle returns a bundle proving that data is in the log, which can be
+// verified offline. The bundle holds the latest root of the log, verified
+// against the root trusted by the client. tree must be the config of the log,
+// with the public key the client verifies the log with.
+func (c *LogClient) ProofBundle(ctx context.Context, tree *trillian.Tree, data []byte) (*ProofBundle, error) {
+	if err := checkBundleTree(tree, c.LogID, c.PubKey); err != nil {
+		return nil, err
+	}
+	slr, root, err := c.getAndVerifySignedRoot(ctx, c.GetRoot())
+	if err != nil {
+		return nil, err
+	}
+	leaf := c.BuildLeaf(data)
+	resp, err := c.client.GetInclusionProofByHash(ctx, &trillian.GetInclusionProofByHashRequest{
+		LogId:    c.LogID,
+		LeafHash: leaf.MerkleLeafHash,
+		TreeSize: int64(root.TreeSize),
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(resp.Proof) < 1 {
+		return nil, fmt.Errorf("leaf %x is not in the log of size %d", leaf.MerkleLeafHash, root.TreeSize)
+	}
+	proof := resp.Proof[0]
+	if err := c.VerifyInclusionByHash(root, leaf.MerkleLeafHash, proof); err != nil {
+		return nil, fmt.Errorf("VerifyInclusionByHash(): %v", err)
+	}
+	leaf.LeafIndex = proof.LeafIndex
+	return &ProofBundle{
+		Version:       ProofBundleVersion,
+		Tree:          NewBundleTree(tree),
+		LogLeaf:       leaf,
+		Inclusion:     proof,
+		SignedLogRoot: slr,
+	}, nil
+}
+
+// ExtendProofBundle adds the latest root of the log to a log bundle, with a
+// consistency proof from the last root in the bundle, so that the bundle also
+// shows that the log has not dropped the leaf since. Nothing is added if the
+// log hasn't grown.
+func (c *LogClient) ExtendProofBundle(ctx context.Context, b *ProofBundle) error {
+	last := b.SignedLogRoot
+	if n := len(b.Consistency); n > 0 {
+		last = b.Consistency[n-1].SignedLogRoot
+	}
+	var trusted types.LogRootV1
+	if err := trusted.UnmarshalBinary(last.GetLogRoot()); err != nil {
+		return fmt.Errorf("failed to parse last root of bundle: %v", err)
+	}
+	slr, root, err := c.getAndVerifySignedRoot(ctx, &trusted)
+	if err != nil {
+		return err
+	}
+	if root.TreeSize == trusted.TreeSize {
+		return nil
+	}
+	resp, err := c.client.GetConsistencyProof(ctx, &trillian.GetConsistencyProofRequest{
+		LogId:          c.LogID,
+		FirstTreeSize:  int64(trusted.TreeSize),
+		SecondTreeSize: int64(root.TreeSize),
+	})
+	if err != nil {
+		return err
+	}
+	proof := resp.GetProof().GetHashes()
+	if _, err := c.VerifyRoot(&trusted, slr, proof); err != nil {
+		return err
+	}
+	b.Consistency = append(b.Consistency, &BundleConsistency{SignedLogRoot: slr, Proof: proof})
+	return nil
+}
+
+// getAndVerifySignedRoot fetches the latest signed root of the log, and
+// verifies it against trusted.
+func (c *LogClient) getAndVerifySignedRoot(ctx context.Context, trusted *types.LogRootV1) (*trillian.SignedLogRoot, *types.LogRootV1, error) {
+	resp, err := c.client.GetLatestSignedLogRoot(ctx, &trillian.GetLatestSignedLogRootRequest{
+		LogId:         c.LogID,
+		FirstTreeSize: int64(trusted.TreeSize),
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	root, err := c.VerifyRoot(trusted, resp.GetSignedLogRoot(), resp.GetProof().GetHashes())
+	if err != nil {
+		return nil, nil, err
+	}
+	return resp.GetSignedLogRoot(), root, nil
+}
+
+// ProofBundle returns a bundle proving the value of the map leaf at index,
+// which can be verified offline. tree must be the config of the map, with the
+// public key the client verifies the map with.
+func (c *MapClient) ProofBundle(ctx context.Context, tree *trillian.Tree, index []byte) (*ProofBundle, error) {
+	if err := checkBundleTree(tree, c.MapID, c.PubKey); err != nil {
+		return nil, err
+	}
+	resp, err := c.Conn.GetLeaves(ctx, &trillian.GetMapLeavesRequest{
+		MapId: c.MapID,
+		Index: [][]byte{index},
+	})
+	if err != nil {
+		return nil, err
+	}
+	if _, _, err := c.VerifyMapLeavesResponse([][]byte{index}, -1, resp); err != nil {
+		return nil, err
+	}
+	return &ProofBundle{
+		Version:       ProofBundleVersion,
+		Tree:          NewBundleTree(tree),
+		MapLeaf:       resp.MapLeafInclusion[0],
+		SignedMapRoot: resp.MapRoot,
+	}, nil
+}
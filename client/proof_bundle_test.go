@@ -0,0 +1,219 @@
+// Copyright 2020 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package client
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/golang/protobuf/ptypes/timestamp"
+	"github.com/google/trillian"
+	"github.com/google/trillian/storage/testonly"
+	"github.com/google/trillian/types"
+)
+
+// fakeLogTree returns the config of the logs served by rootLog.
+func fakeLogTree() *trillian.Tree {
+	tree := proto.Clone(testonly.LogTree).(*trillian.Tree)
+	tree.TreeId = fakeLogID
+	tree.PublicKey = testonly.MapTree.PublicKey
+	return tree
+}
+
+// roundTrip returns a copy of b, made by marshaling and parsing it.
+func roundTrip(t *testing.T, b *ProofBundle) *ProofBundle {
+	t.Helper()
+	data, err := MarshalProofBundle(b)
+	if err != nil {
+		t.Fatalf("MarshalProofBundle(): %v", err)
+	}
+	b, err = UnmarshalProofBundle(data)
+	if err != nil {
+		t.Fatalf("UnmarshalProofBundle(): %v", err)
+	}
+	return b
+}
+
+func TestProofBundleJSON(t *testing.T) {
+	b := &ProofBundle{
+		Version: ProofBundleVersion,
+		Tree:    NewBundleTree(fakeLogTree()),
+		LogLeaf: &trillian.LogLeaf{
+			LeafValue:      []byte("leaf"),
+			LeafIndex:      3,
+			QueueTimestamp: &timestamp.Timestamp{Seconds: 1},
+		},
+		Inclusion:     &trillian.Proof{LeafIndex: 3, Hashes: [][]byte{{1}, {2}}},
+		SignedLogRoot: &trillian.SignedLogRoot{LogRoot: []byte("root")},
+		Consistency: []*BundleConsistency{
+			{SignedLogRoot: &trillian.SignedLogRoot{LogRoot: []byte("later root")}, Proof: [][]byte{{3}}},
+		},
+	}
+	data, err := MarshalProofBundle(b)
+	if err != nil {
+		t.Fatalf("MarshalProofBundle(): %v", err)
+	}
+	// The protobuf fields are encoded with protojson and the names of the
+	// .proto files, e.g. timestamps as RFC 3339 strings.
+	for _, want := range []string{`"queue_timestamp": "1970-01-01T00:00:01Z"`, `"leaf_index": "3"`} {
+		if !bytes.Contains(data, []byte(want)) {
+			t.Errorf("MarshalProofBundle() = %s, want %s", data, want)
+		}
+	}
+	if got := roundTrip(t, b); !proto.Equal(got.LogLeaf, b.LogLeaf) || !proto.Equal(got.Inclusion, b.Inclusion) ||
+		!proto.Equal(got.SignedLogRoot, b.SignedLogRoot) || !proto.Equal(got.Consistency[0].SignedLogRoot, b.Consistency[0].SignedLogRoot) ||
+		got.MapLeaf != nil || got.SignedMapRoot != nil {
+		t.Errorf("roundTrip() = %+v, want %+v", got, b)
+	}
+}
+
+func TestLogProofBundle(t *testing.T) {
+	ctx := context.Background()
+	log, verifier := newRootLog(t)
+	log.add("leaf", 5)
+	c := New(fakeLogID, log, verifier, types.LogRootV1{})
+
+	b, err := c.ProofBundle(ctx, fakeLogTree(), []byte("leaf3"))
+	if err != nil {
+		t.Fatalf("ProofBundle(): %v", err)
+	}
+	root, err := roundTrip(t, b).VerifyLog()
+	if err != nil {
+		t.Fatalf("VerifyLog(): %v", err)
+	}
+	if root.TreeSize != 5 {
+		t.Errorf("VerifyLog() = size %d, want 5", root.TreeSize)
+	}
+
+	// Extending a bundle only adds roots once the log has grown.
+	if err := c.ExtendProofBundle(ctx, b); err != nil {
+		t.Fatalf("ExtendProofBundle(): %v", err)
+	}
+	for _, n := range []int{3, 4} {
+		log.add("leaf", n)
+		if err := c.ExtendProofBundle(ctx, b); err != nil {
+			t.Fatalf("ExtendProofBundle(): %v", err)
+		}
+	}
+	if got, want := len(b.Consistency), 2; got != want {
+		t.Errorf("ExtendProofBundle() added %d roots, want %d", got, want)
+	}
+	if root, err = roundTrip(t, b).VerifyLog(); err != nil {
+		t.Fatalf("VerifyLog() of extended bundle: %v", err)
+	}
+	if root.TreeSize != 12 {
+		t.Errorf("VerifyLog() = size %d, want 12", root.TreeSize)
+	}
+
+	if _, err := c.ProofBundle(ctx, fakeLogTree(), []byte("missing")); err == nil {
+		t.Error("ProofBundle() of missing leaf succeeded")
+	}
+
+	// The tree config must match the key and ID the client verifies with.
+	otherKey := fakeLogTree()
+	otherKey.PublicKey = testonly.LogTree.PublicKey
+	otherID := fakeLogTree()
+	otherID.TreeId++
+	for _, tree := range []*trillian.Tree{otherKey, otherID} {
+		if _, err := c.ProofBundle(ctx, tree, []byte("leaf3")); err == nil {
+			t.Errorf("ProofBundle() with tree %d and key %x succeeded", tree.TreeId, tree.PublicKey.Der)
+		}
+	}
+}
+
+func TestLogProofBundleTampered(t *testing.T) {
+	ctx := context.Background()
+	log, verifier := newRootLog(t)
+	log.add("leaf", 5)
+	c := New(fakeLogID, log, verifier, types.LogRootV1{})
+	b, err := c.ProofBundle(ctx, fakeLogTree(), []byte("leaf3"))
+	if err != nil {
+		t.Fatalf("ProofBundle(): %v", err)
+	}
+	log.add("leaf", 2)
+	if err := c.ExtendProofBundle(ctx, b); err != nil {
+		t.Fatalf("ExtendProofBundle(): %v", err)
+	}
+
+	for _, tc := range []struct {
+		desc   string
+		tamper func(b *ProofBundle)
+	}{
+		{desc: "value", tamper: func(b *ProofBundle) { b.LogLeaf.LeafValue = []byte("leaf4") }},
+		{desc: "index", tamper: func(b *ProofBundle) { b.Inclusion.LeafIndex = 4; b.LogLeaf.LeafIndex = 4 }},
+		{desc: "proof", tamper: func(b *ProofBundle) { b.Inclusion.Hashes = b.Inclusion.Hashes[1:] }},
+		{desc: "signature", tamper: func(b *ProofBundle) { b.SignedLogRoot.LogRootSignature[10] ^= 1 }},
+		{desc: "consistency", tamper: func(b *ProofBundle) { b.Consistency[0].Proof = b.Consistency[0].Proof[1:] }},
+		{desc: "key", tamper: func(b *ProofBundle) { b.Tree.PublicKey = fakeLogTree().PublicKey.Der[1:] }},
+		{desc: "strategy", tamper: func(b *ProofBundle) { b.Tree.HashStrategy = "UNKNOWN" }},
+		{desc: "map", tamper: func(b *ProofBundle) { b.SignedLogRoot = nil }},
+	} {
+		t.Run(tc.desc, func(t *testing.T) {
+			b := roundTrip(t, b)
+			tc.tamper(b)
+			if _, err := b.VerifyLog(); err == nil {
+				t.Error("VerifyLog() of tampered bundle succeeded")
+			}
+		})
+	}
+
+	b.Version = ProofBundleVersion + 1
+	data, err := MarshalProofBundle(b)
+	if err != nil {
+		t.Fatalf("MarshalProofBundle(): %v", err)
+	}
+	if _, err := UnmarshalProofBundle(data); err == nil {
+		t.Error("UnmarshalProofBundle() of unknown version succeeded")
+	}
+}
+
+func TestMapProofBundle(t *testing.T) {
+	ctx := context.Background()
+	m, c := newFakeMap(t)
+	index, missing := mapIndex("key"), mapIndex("missing")
+	m.write([]*trillian.MapLeaf{{Index: index, LeafValue: []byte("value")}})
+	tree := proto.Clone(testonly.MapTree).(*trillian.Tree)
+	tree.TreeId = fakeMapID
+
+	for _, idx := range [][]byte{index, missing} {
+		b, err := c.ProofBundle(ctx, tree, idx)
+		if err != nil {
+			t.Fatalf("ProofBundle(): %v", err)
+		}
+		b = roundTrip(t, b)
+		root, err := b.VerifyMap()
+		if err != nil {
+			t.Fatalf("VerifyMap(): %v", err)
+		}
+		if root.Revision != 1 {
+			t.Errorf("VerifyMap() = revision %d, want 1", root.Revision)
+		}
+		if _, err := b.VerifyLog(); err == nil {
+			t.Error("VerifyLog() of map bundle succeeded")
+		}
+
+		b.MapLeaf.Leaf.LeafValue = []byte("other")
+		if _, err := b.VerifyMap(); err == nil {
+			t.Error("VerifyMap() of tampered bundle succeeded")
+		}
+	}
+
+	tree.PublicKey = testonly.LogTree.PublicKey
+	if _, err := c.ProofBundle(ctx, tree, index); err == nil {
+		t.Error("ProofBundle() with another key succeeded")
+	}
+}
@@ -0,0 +1,118 @@
+// Copyright 2020 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package client
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/google/trillian/types"
+)
+
+// TrustedRootStore persists the roots trusted by log clients, so that a
+// client keeps checking the consistency of the log across restarts. It must
+// be safe for concurrent use.
+type TrustedRootStore interface {
+	// Load returns the trusted root of the log, or nil if there is none.
+	Load(ctx context.Context, logID int64) (*types.LogRootV1, error)
+	// Store replaces the trusted root of the log. Either the new root is
+	// stored, or the old one is kept.
+	Store(ctx context.Context, logID int64, root *types.LogRootV1) error
+}
+
+// MemoryRootStore is a TrustedRootStore which keeps the roots in memory.
+type MemoryRootStore struct {
+	mu    sync.Mutex
+	roots map[int64]types.LogRootV1
+}
+
+// NewMemoryRootStore returns an empty MemoryRootStore.
+func NewMemoryRootStore() *MemoryRootStore {
+	return &MemoryRootStore{roots: make(map[int64]types.LogRootV1)}
+}
+
+// Load returns a copy of the root of the log.
+func (m *MemoryRootStore) Load(ctx context.Context, logID int64) (*types.LogRootV1, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	root, ok := m.roots[logID]
+	if !ok {
+		return nil, nil
+	}
+	return &root, nil
+}
+
+// Store keeps a copy of the root of the log.
+func (m *MemoryRootStore) Store(ctx context.Context, logID int64, root *types.LogRootV1) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.roots[logID] = *root
+	return nil
+}
+
+// FileRootStore is a TrustedRootStore which keeps the root of each log in the
+// file <Dir>/<logID>.root, in the serialized form of LogRootV1.
+type FileRootStore struct {
+	Dir string
+}
+
+func (f FileRootStore) path(logID int64) string {
+	return filepath.Join(f.Dir, fmt.Sprintf("%d.root", logID))
+}
+
+// Load reads the root of the log.
+func (f FileRootStore) Load(ctx context.Context, logID int64) (*types.LogRootV1, error) {
+	data, err := ioutil.ReadFile(f.path(logID))
+	if os.IsNotExist(err) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+	var root types.LogRootV1
+	if err := root.UnmarshalBinary(data); err != nil {
+		return nil, fmt.Errorf("failed to parse trusted root of log %d: %v", logID, err)
+	}
+	return &root, nil
+}
+
+// Store writes the root of the log to a temporary file, and then renames it
+// over the old one.
+func (f FileRootStore) Store(ctx context.Context, logID int64, root *types.LogRootV1) error {
+	data, err := root.MarshalBinary()
+	if err != nil {
+		return err
+	}
+	tmp, err := ioutil.TempFile(f.Dir, fmt.Sprintf("%d.root.*", logID))
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), f.path(logID))
+}
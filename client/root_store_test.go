@@ -0,0 +1,150 @@
+// Copyright 2020 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package client
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/google/trillian/types"
+)
+
+func TestRootStores(t *testing.T) {
+	ctx := context.Background()
+	for _, tc := range []struct {
+		desc  string
+		store TrustedRootStore
+	}{
+		{desc: "memory", store: NewMemoryRootStore()},
+		{desc: "file", store: FileRootStore{Dir: t.TempDir()}},
+	} {
+		t.Run(tc.desc, func(t *testing.T) {
+			if got, err := tc.store.Load(ctx, fakeLogID); err != nil || got != nil {
+				t.Fatalf("Load() = %v, %v; want nil, nil", got, err)
+			}
+			for _, root := range []*types.LogRootV1{
+				{TreeSize: 1, RootHash: []byte("hash1"), TimestampNanos: 10, Metadata: []byte{}},
+				{TreeSize: 20, RootHash: []byte("hash20"), TimestampNanos: 20, Revision: 3, Metadata: []byte("meta")},
+			} {
+				if err := tc.store.Store(ctx, fakeLogID, root); err != nil {
+					t.Fatalf("Store(): %v", err)
+				}
+				got, err := tc.store.Load(ctx, fakeLogID)
+				if err != nil {
+					t.Fatalf("Load(): %v", err)
+				}
+				if !reflect.DeepEqual(got, root) {
+					t.Errorf("Load() = %+v, want %+v", got, root)
+				}
+			}
+			if got, err := tc.store.Load(ctx, fakeLogID+1); err != nil || got != nil {
+				t.Errorf("Load(other log) = %v, %v; want nil, nil", got, err)
+			}
+		})
+	}
+}
+
+func TestNewWithStore(t *testing.T) {
+	ctx := context.Background()
+	log, verifier := newRootLog(t)
+	store := FileRootStore{Dir: t.TempDir()}
+
+	log.add("leaf", 5)
+	c, err := NewWithStore(ctx, fakeLogID, log, verifier, store)
+	if err != nil {
+		t.Fatalf("NewWithStore(): %v", err)
+	}
+	if _, err := c.UpdateRoot(ctx); err != nil {
+		t.Fatalf("UpdateRoot(): %v", err)
+	}
+
+	// A restarted client trusts the stored root, and verifies that new roots
+	// are consistent with it.
+	log.add("leaf", 3)
+	c, err = NewWithStore(ctx, fakeLogID, log, verifier, store)
+	if err != nil {
+		t.Fatalf("NewWithStore(): %v", err)
+	}
+	if got, want := c.GetRoot().TreeSize, uint64(5); got != want {
+		t.Fatalf("restarted client trusts root of size %d, want %d", got, want)
+	}
+	root, err := c.UpdateRoot(ctx)
+	if err != nil {
+		t.Fatalf("UpdateRoot(): %v", err)
+	}
+	stored, err := store.Load(ctx, fakeLogID)
+	if err != nil {
+		t.Fatalf("Load(): %v", err)
+	}
+	if !reflect.DeepEqual(stored, root) {
+		t.Errorf("stored root %+v, want %+v", stored, root)
+	}
+}
+
+func TestUpdateRootRollback(t *testing.T) {
+	ctx := context.Background()
+	for _, tc := range []struct {
+		desc string
+		// serve adds leaves to a new log which has the same first 4 leaves as
+		// the trusted log of size 8.
+		serve func(l *rootLog)
+	}{
+		{desc: "smaller", serve: func(l *rootLog) {}},
+		{desc: "empty", serve: nil},
+		{desc: "fork-same-size", serve: func(l *rootLog) { l.add("fork", 4) }},
+		{desc: "fork-larger", serve: func(l *rootLog) { l.add("fork", 10) }},
+	} {
+		t.Run(tc.desc, func(t *testing.T) {
+			trustedLog, verifier := newRootLog(t)
+			trustedLog.add("leaf", 8)
+			store := NewMemoryRootStore()
+			c, err := NewWithStore(ctx, fakeLogID, trustedLog, verifier, store)
+			if err != nil {
+				t.Fatalf("NewWithStore(): %v", err)
+			}
+			trusted, err := c.UpdateRoot(ctx)
+			if err != nil {
+				t.Fatalf("UpdateRoot(): %v", err)
+			}
+
+			badLog, _ := newRootLog(t)
+			if tc.serve != nil {
+				badLog.add("leaf", 4)
+				tc.serve(badLog)
+			}
+			badLog.timestamp = trustedLog.timestamp + 1
+			c, err = NewWithStore(ctx, fakeLogID, badLog, verifier, store)
+			if err != nil {
+				t.Fatalf("NewWithStore(): %v", err)
+			}
+			_, err = c.UpdateRoot(ctx)
+			var rbErr *RollbackError
+			if !errors.As(err, &rbErr) {
+				t.Fatalf("UpdateRoot(): %v, want RollbackError", err)
+			}
+			if !reflect.DeepEqual(&rbErr.Trusted, trusted) {
+				t.Errorf("RollbackError.Trusted = %+v, want %+v", rbErr.Trusted, trusted)
+			}
+			if got := c.GetRoot(); !reflect.DeepEqual(got, trusted) {
+				t.Errorf("GetRoot() = %+v after rollback, want %+v", got, trusted)
+			}
+			if got, _ := store.Load(ctx, fakeLogID); !reflect.DeepEqual(got, trusted) {
+				t.Errorf("stored root %+v after rollback, want %+v", got, trusted)
+			}
+		})
+	}
+}
@@ -0,0 +1,406 @@
+// Copyright 2020 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package client
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/google/trillian"
+	"github.com/google/trillian/types"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	tcrypto "github.com/google/trillian/crypto"
+)
+
+// VerificationError is returned by the VerifyingInterceptor when a response
+// fails verification. It has the Internal gRPC status code.
+type VerificationError struct {
+	// Method is the gRPC method whose response failed verification.
+	Method string
+	// Err is the reason the response failed verification.
+	Err error
+}
+
+func (e *VerificationError) Error() string {
+	return fmt.Sprintf("%s: response failed verification: %v", e.Method, e.Err)
+}
+
+// Unwrap returns the reason the response failed verification.
+func (e *VerificationError) Unwrap() error {
+	return e.Err
+}
+
+// GRPCStatus returns the gRPC status of the error.
+func (e *VerificationError) GRPCStatus() *status.Status {
+	return status.New(codes.Internal, e.Error())
+}
+
+// VerifyingInterceptor is a gRPC client interceptor which verifies the
+// responses of the Trillian Log and Map APIs, so that code using the raw
+// gRPC stubs gets verified data, or an error.
+//
+// Signed log and map roots are checked in all responses. Inclusion and
+// consistency proofs are checked against either the root in the same
+// response, or the latest log root seen through GetLatestSignedLogRoot, which
+// is itself checked for consistency with the previous one when the request
+// asks for a proof from it. Proofs for other tree sizes can't be checked, and
+// fail verification.
+//
+// GetInclusionProof responses don't hold the leaf, so the interceptor fetches
+// it with GetLeavesByIndex on the same connection, and checks the proof
+// against its hash.
+//
+// Requests for trees the interceptor wasn't configured with fail without being
+// sent, rather than go unverified.
+type VerifyingInterceptor struct {
+	logs map[int64]*verifiedLog
+	maps map[int64]*MapVerifier
+}
+
+// logRequest is implemented by the requests of the Log API.
+type logRequest interface {
+	GetLogId() int64
+}
+
+// mapRequest is implemented by the requests of the Map API.
+type mapRequest interface {
+	GetMapId() int64
+}
+
+// logRootResponse is implemented by the responses which hold a log root.
+type logRootResponse interface {
+	GetSignedLogRoot() *trillian.SignedLogRoot
+}
+
+// mapRootResponse is implemented by the responses which hold a map root.
+type mapRootResponse interface {
+	GetMapRoot() *trillian.SignedMapRoot
+}
+
+// getLeavesByIndexMethod is the method used to fetch the leaves of
+// GetInclusionProof responses.
+const getLeavesByIndexMethod = "/trillian.TrillianLog/GetLeavesByIndex"
+
+// leafFetcher returns the leaf at an index of a log.
+type leafFetcher func(logID, index int64) (*trillian.LogLeaf, error)
+
+// verifiedLog holds the verifier of a log, and the latest root seen for it.
+type verifiedLog struct {
+	*LogVerifier
+	mu      sync.Mutex
+	trusted types.LogRootV1
+}
+
+// NewVerifyingInterceptor returns an interceptor which verifies responses
+// for the given log and map trees, using their public keys and hash
+// strategies.
+func NewVerifyingInterceptor(trees ...*trillian.Tree) (*VerifyingInterceptor, error) {
+	v := &VerifyingInterceptor{
+		logs: make(map[int64]*verifiedLog),
+		maps: make(map[int64]*MapVerifier),
+	}
+	for _, tree := range trees {
+		switch tree.GetTreeType() {
+		case trillian.TreeType_LOG, trillian.TreeType_PREORDERED_LOG:
+			verifier, err := NewLogVerifierFromTree(tree)
+			if err != nil {
+				return nil, err
+			}
+			v.logs[tree.TreeId] = &verifiedLog{LogVerifier: verifier}
+		case trillian.TreeType_MAP:
+			verifier, err := NewMapVerifierFromTree(tree)
+			if err != nil {
+				return nil, err
+			}
+			v.maps[tree.TreeId] = verifier
+		default:
+			return nil, fmt.Errorf("tree %d has unsupported type %v", tree.GetTreeId(), tree.GetTreeType())
+		}
+	}
+	return v, nil
+}
+
+// UnaryClientInterceptor returns the interceptor, to be passed to
+// grpc.WithUnaryInterceptor.
+func (v *VerifyingInterceptor) UnaryClientInterceptor() grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		if err := v.check(req); err != nil {
+			return &VerificationError{Method: method, Err: err}
+		}
+		if err := invoker(ctx, method, req, reply, cc, opts...); err != nil {
+			return err
+		}
+		fetch := func(logID, index int64) (*trillian.LogLeaf, error) {
+			var resp trillian.GetLeavesByIndexResponse
+			req := &trillian.GetLeavesByIndexRequest{LogId: logID, LeafIndex: []int64{index}}
+			if err := cc.Invoke(ctx, getLeavesByIndexMethod, req, &resp, opts...); err != nil {
+				return nil, fmt.Errorf("failed to fetch leaf %d: %v", index, err)
+			}
+			if len(resp.Leaves) != 1 || resp.Leaves[0].LeafIndex != index {
+				return nil, fmt.Errorf("got %d leaves, want leaf %d", len(resp.Leaves), index)
+			}
+			return resp.Leaves[0], nil
+		}
+		if err := v.verify(req, reply, fetch); err != nil {
+			return &VerificationError{Method: method, Err: err}
+		}
+		return nil
+	}
+}
+
+// check returns an error if the response to req can't be verified.
+func (v *VerifyingInterceptor) check(req interface{}) error {
+	switch r := req.(type) {
+	case logRequest:
+		if _, ok := v.logs[r.GetLogId()]; !ok {
+			return fmt.Errorf("no verifier for log %d", r.GetLogId())
+		}
+	case mapRequest:
+		if _, ok := v.maps[r.GetMapId()]; !ok {
+			return fmt.Errorf("no verifier for map %d", r.GetMapId())
+		}
+	}
+	return nil
+}
+
+func (v *VerifyingInterceptor) verify(req, reply interface{}, fetch leafFetcher) error {
+	switch r := req.(type) {
+	case logRequest:
+		l, ok := v.logs[r.GetLogId()]
+		if !ok {
+			return fmt.Errorf("no verifier for log %d", r.GetLogId())
+		}
+		return l.verify(req, reply, fetch)
+	case mapRequest:
+		m, ok := v.maps[r.GetMapId()]
+		if !ok {
+			return fmt.Errorf("no verifier for map %d", r.GetMapId())
+		}
+		return verifyMap(m, req, reply)
+	}
+	return nil
+}
+
+func (l *verifiedLog) verify(req, reply interface{}, fetch leafFetcher) error {
+	var root *types.LogRootV1
+	if r, ok := reply.(logRootResponse); ok && r.GetSignedLogRoot() != nil {
+		var err error
+		if root, err = tcrypto.VerifySignedLogRoot(l.PubKey, l.SigHash, r.GetSignedLogRoot()); err != nil {
+			return fmt.Errorf("failed to verify log root: %v", err)
+		}
+	}
+
+	switch resp := reply.(type) {
+	case *trillian.GetLatestSignedLogRootResponse:
+		r, ok := req.(*trillian.GetLatestSignedLogRootRequest)
+		if !ok || root == nil {
+			return errors.New("no log root")
+		}
+		return l.update(r, resp, root)
+
+	case *trillian.GetInclusionProofResponse:
+		r, ok := req.(*trillian.GetInclusionProofRequest)
+		if !ok {
+			return fmt.Errorf("unexpected request %T", req)
+		}
+		if resp.Proof == nil {
+			// The server has no proof for trees larger than its current one.
+			if root != nil && r.TreeSize > int64(root.TreeSize) {
+				return nil
+			}
+			return errors.New("no inclusion proof")
+		}
+		rootHash, err := l.rootHash(r.TreeSize, root)
+		if err != nil {
+			return err
+		}
+		leaf, err := fetch(r.LogId, r.LeafIndex)
+		if err != nil {
+			return err
+		}
+		leafHash := l.Hasher.HashLeaf(leaf.LeafValue)
+		if err := l.v.VerifyInclusionProof(r.LeafIndex, r.TreeSize, resp.Proof.Hashes, rootHash, leafHash); err != nil {
+			return fmt.Errorf("failed to verify inclusion proof: %v", err)
+		}
+
+	case *trillian.GetInclusionProofByHashResponse:
+		r, ok := req.(*trillian.GetInclusionProofByHashRequest)
+		if !ok {
+			return fmt.Errorf("unexpected request %T", req)
+		}
+		rootHash, err := l.rootHash(r.TreeSize, root)
+		if err != nil {
+			return err
+		}
+		for _, p := range resp.Proof {
+			if err := l.v.VerifyInclusionProof(p.LeafIndex, r.TreeSize, p.Hashes, rootHash, r.LeafHash); err != nil {
+				return fmt.Errorf("failed to verify inclusion proof: %v", err)
+			}
+		}
+
+	case *trillian.GetEntryAndProofResponse:
+		r, ok := req.(*trillian.GetEntryAndProofRequest)
+		if !ok || resp.Leaf == nil || resp.Proof == nil {
+			return errors.New("no leaf or inclusion proof")
+		}
+		// The server proves inclusion in its current tree if the requested
+		// tree is larger.
+		size := r.TreeSize
+		if root != nil && size > int64(root.TreeSize) {
+			size = int64(root.TreeSize)
+		}
+		rootHash, err := l.rootHash(size, root)
+		if err != nil {
+			return err
+		}
+		leafHash := l.Hasher.HashLeaf(resp.Leaf.LeafValue)
+		if !bytes.Equal(leafHash, resp.Leaf.MerkleLeafHash) {
+			return fmt.Errorf("leaf has hash %x, want %x", resp.Leaf.MerkleLeafHash, leafHash)
+		}
+		if resp.Leaf.LeafIndex != r.LeafIndex {
+			return fmt.Errorf("leaf has index %d, want %d", resp.Leaf.LeafIndex, r.LeafIndex)
+		}
+		if err := l.v.VerifyInclusionProof(r.LeafIndex, size, resp.Proof.Hashes, rootHash, leafHash); err != nil {
+			return fmt.Errorf("failed to verify inclusion proof: %v", err)
+		}
+
+	case *trillian.GetConsistencyProofResponse:
+		r, ok := req.(*trillian.GetConsistencyProofRequest)
+		if !ok || resp.Proof == nil {
+			return errors.New("no consistency proof")
+		}
+		hash1, err := l.rootHash(r.FirstTreeSize, root)
+		if err != nil {
+			return err
+		}
+		hash2, err := l.rootHash(r.SecondTreeSize, root)
+		if err != nil {
+			return err
+		}
+		if err := l.v.VerifyConsistencyProof(r.FirstTreeSize, r.SecondTreeSize, hash1, hash2, resp.Proof.Hashes); err != nil {
+			return fmt.Errorf("failed to verify consistency proof: %v", err)
+		}
+	}
+	return nil
+}
+
+// update checks the latest root of the log against the previous one, and
+// makes it the latest root seen if they are consistent.
+func (l *verifiedLog) update(req *trillian.GetLatestSignedLogRootRequest, resp *trillian.GetLatestSignedLogRootResponse, root *types.LogRootV1) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	trusted := l.trusted
+	switch {
+	case trusted.TreeSize == 0:
+		l.trusted = *root
+	case root.TreeSize < trusted.TreeSize:
+		// The server may be lagging, and this root is older than ours.
+	case root.TreeSize == trusted.TreeSize:
+		if !bytes.Equal(root.RootHash, trusted.RootHash) {
+			return &RollbackError{Trusted: trusted, Root: *root, Err: errors.New("root hash differs")}
+		}
+	case req.FirstTreeSize == int64(trusted.TreeSize):
+		if _, err := l.VerifyRoot(&trusted, resp.SignedLogRoot, resp.GetProof().GetHashes()); err != nil {
+			return err
+		}
+		l.trusted = *root
+	}
+	// Otherwise the consistency proof, if any, is from a root we don't know,
+	// so this root can't be chained onto ours.
+	return nil
+}
+
+// rootHash returns the hash of a verified root of the given size: either
+// root, from the response, or the latest root seen.
+func (l *verifiedLog) rootHash(size int64, root *types.LogRootV1) ([]byte, error) {
+	if root != nil && int64(root.TreeSize) == size {
+		return root.RootHash, nil
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.trusted.TreeSize > 0 && int64(l.trusted.TreeSize) == size {
+		return l.trusted.RootHash, nil
+	}
+	return nil, fmt.Errorf("no verified root of tree size %d", size)
+}
+
+func verifyMap(m *MapVerifier, req, reply interface{}) error {
+	switch resp := reply.(type) {
+	case *trillian.GetMapLeavesResponse:
+		switch r := req.(type) {
+		case *trillian.GetMapLeavesRequest:
+			return verifyMapLeaves(m, r.Index, -1, resp)
+		case *trillian.GetMapLeavesByRevisionRequest:
+			return verifyMapLeaves(m, r.Index, r.Revision, resp)
+		}
+		return fmt.Errorf("unexpected request %T", req)
+
+	case *trillian.GetMapLeafResponse:
+		index, revision := []byte(nil), int64(-1)
+		switch r := req.(type) {
+		case *trillian.GetMapLeafRequest:
+			index = r.Index
+		case *trillian.GetMapLeafByRevisionRequest:
+			index, revision = r.Index, r.Revision
+		default:
+			return fmt.Errorf("unexpected request %T", req)
+		}
+		if resp.MapLeafInclusion == nil {
+			return errors.New("no map leaf")
+		}
+		return verifyMapLeaves(m, [][]byte{index}, revision, &trillian.GetMapLeavesResponse{
+			MapLeafInclusion: []*trillian.MapLeafInclusion{resp.MapLeafInclusion},
+			MapRoot:          resp.MapRoot,
+		})
+
+	case *trillian.GetSignedMapRootResponse:
+		root, err := m.VerifySignedMapRoot(resp.MapRoot)
+		if err != nil {
+			return fmt.Errorf("failed to verify map root: %v", err)
+		}
+		if r, ok := req.(*trillian.GetSignedMapRootByRevisionRequest); ok && int64(root.Revision) != r.Revision {
+			return fmt.Errorf("map root of revision %d, want %d", root.Revision, r.Revision)
+		}
+
+	case mapRootResponse:
+		if smr := resp.GetMapRoot(); smr != nil {
+			if _, err := m.VerifySignedMapRoot(smr); err != nil {
+				return fmt.Errorf("failed to verify map root: %v", err)
+			}
+		}
+	}
+	return nil
+}
+
+// verifyMapLeaves verifies the root and inclusion proofs of map leaves, and
+// that they are the requested leaves.
+func verifyMapLeaves(m *MapVerifier, indexes [][]byte, revision int64, resp *trillian.GetMapLeavesResponse) error {
+	leaves, _, err := m.VerifyMapLeavesResponse(indexes, revision, resp)
+	if err != nil {
+		return err
+	}
+	for i, l := range leaves {
+		if !bytes.Equal(l.Index, indexes[i]) {
+			return fmt.Errorf("leaf %d has index %x, want %x", i, l.Index, indexes[i])
+		}
+	}
+	return nil
+}
@@ -0,0 +1,337 @@
+// Copyright 2020 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/google/trillian"
+	"github.com/google/trillian/merkle/rfc6962"
+	"github.com/google/trillian/storage/testonly"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// fakeServer is the last interceptor of a connection, which serves requests
+// from a log and a map instead of sending them, and lets tests tamper with the
+// responses.
+type fakeServer struct {
+	log    *rootLog
+	m      *fakeMap
+	tamper func(reply interface{})
+	// calls is the number of requests served.
+	calls int
+}
+
+func (f *fakeServer) intercept(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+	f.calls++
+	var resp proto.Message
+	var err error
+	switch r := req.(type) {
+	case *trillian.GetLatestSignedLogRootRequest:
+		resp, err = f.log.GetLatestSignedLogRoot(ctx, r)
+	case *trillian.GetInclusionProofRequest:
+		resp, err = f.log.GetInclusionProof(ctx, r)
+	case *trillian.GetInclusionProofByHashRequest:
+		resp, err = f.log.GetInclusionProofByHash(ctx, r)
+	case *trillian.GetLeavesByIndexRequest:
+		resp, err = f.log.GetLeavesByIndex(ctx, r)
+	case *trillian.GetEntryAndProofRequest:
+		resp, err = f.log.GetEntryAndProof(ctx, r)
+	case *trillian.GetConsistencyProofRequest:
+		resp, err = f.log.GetConsistencyProof(ctx, r)
+	case *trillian.GetMapLeavesRequest:
+		resp, err = f.m.GetLeaves(ctx, r)
+	case *trillian.GetSignedMapRootByRevisionRequest:
+		resp, err = f.m.GetSignedMapRootByRevision(ctx, r)
+	default:
+		return fmt.Errorf("unexpected request %T", req)
+	}
+	if err != nil {
+		return err
+	}
+	proto.Merge(reply.(proto.Message), resp)
+	if f.tamper != nil {
+		f.tamper(reply)
+	}
+	return nil
+}
+
+// dialVerifying returns a connection whose responses are served by f, and
+// verified by an interceptor for the trees of f. The connection must be
+// closed.
+func dialVerifying(t *testing.T, f *fakeServer) *grpc.ClientConn {
+	t.Helper()
+	mapTree := proto.Clone(testonly.MapTree).(*trillian.Tree)
+	mapTree.TreeId = fakeMapID
+	v, err := NewVerifyingInterceptor(fakeLogTree(), mapTree)
+	if err != nil {
+		t.Fatalf("NewVerifyingInterceptor(): %v", err)
+	}
+	// The connection is never used, as the fake server doesn't invoke it.
+	cc, err := grpc.Dial("fake", grpc.WithInsecure(), grpc.WithChainUnaryInterceptor(v.UnaryClientInterceptor(), f.intercept))
+	if err != nil {
+		t.Fatalf("Dial(): %v", err)
+	}
+	return cc
+}
+
+func checkVerificationError(t *testing.T, err error) {
+	t.Helper()
+	var vErr *VerificationError
+	if !errors.As(err, &vErr) {
+		t.Fatalf("got error %v, want VerificationError", err)
+	}
+	if got, want := status.Code(err), codes.Internal; got != want {
+		t.Errorf("got code %v, want %v", got, want)
+	}
+}
+
+func TestVerifyingInterceptorLog(t *testing.T) {
+	ctx := context.Background()
+	log, _ := newRootLog(t)
+	log.add("leaf", 5)
+	f := &fakeServer{log: log}
+	cc := dialVerifying(t, f)
+	defer cc.Close()
+	c := trillian.NewTrillianLogClient(cc)
+
+	if _, err := c.GetLatestSignedLogRoot(ctx, &trillian.GetLatestSignedLogRootRequest{LogId: fakeLogID}); err != nil {
+		t.Fatalf("GetLatestSignedLogRoot(): %v", err)
+	}
+	log.add("leaf", 3)
+	if _, err := c.GetLatestSignedLogRoot(ctx, &trillian.GetLatestSignedLogRootRequest{LogId: fakeLogID, FirstTreeSize: 5}); err != nil {
+		t.Fatalf("GetLatestSignedLogRoot(): %v", err)
+	}
+	for _, size := range []int64{8, 100} {
+		if _, err := c.GetInclusionProof(ctx, &trillian.GetInclusionProofRequest{LogId: fakeLogID, LeafIndex: 2, TreeSize: size}); err != nil {
+			t.Errorf("GetInclusionProof(size %d): %v", size, err)
+		}
+	}
+	leafHash := log.tree.LeafHash(3)
+	if _, err := c.GetInclusionProofByHash(ctx, &trillian.GetInclusionProofByHashRequest{LogId: fakeLogID, LeafHash: leafHash, TreeSize: 8}); err != nil {
+		t.Errorf("GetInclusionProofByHash(): %v", err)
+	}
+	for _, size := range []int64{8, 100} {
+		if _, err := c.GetEntryAndProof(ctx, &trillian.GetEntryAndProofRequest{LogId: fakeLogID, LeafIndex: 2, TreeSize: size}); err != nil {
+			t.Errorf("GetEntryAndProof(size %d): %v", size, err)
+		}
+	}
+
+	log.add("leaf", 4)
+	if _, err := c.GetConsistencyProof(ctx, &trillian.GetConsistencyProofRequest{LogId: fakeLogID, FirstTreeSize: 8, SecondTreeSize: 12}); err != nil {
+		t.Errorf("GetConsistencyProof(): %v", err)
+	}
+
+	// There's no verified root of size 5 any more.
+	_, err := c.GetConsistencyProof(ctx, &trillian.GetConsistencyProofRequest{LogId: fakeLogID, FirstTreeSize: 5, SecondTreeSize: 12})
+	checkVerificationError(t, err)
+
+	// Requests which can't be verified aren't sent: there's no verifier for
+	// log 6.
+	calls := f.calls
+	_, err = c.GetLatestSignedLogRoot(ctx, &trillian.GetLatestSignedLogRootRequest{LogId: fakeLogID + 1})
+	checkVerificationError(t, err)
+	if f.calls != calls {
+		t.Errorf("unverifiable requests were sent to the server")
+	}
+}
+
+func TestVerifyingInterceptorLogTampered(t *testing.T) {
+	ctx := context.Background()
+	for _, tc := range []struct {
+		desc   string
+		call   func(c trillian.TrillianLogClient) error
+		tamper func(reply interface{})
+	}{
+		{
+			desc: "root-signature",
+			call: func(c trillian.TrillianLogClient) error {
+				_, err := c.GetLatestSignedLogRoot(ctx, &trillian.GetLatestSignedLogRootRequest{LogId: fakeLogID})
+				return err
+			},
+			tamper: func(reply interface{}) {
+				reply.(*trillian.GetLatestSignedLogRootResponse).SignedLogRoot.LogRootSignature[10] ^= 1
+			},
+		},
+		{
+			desc: "consistency-in-root",
+			call: func(c trillian.TrillianLogClient) error {
+				_, err := c.GetLatestSignedLogRoot(ctx, &trillian.GetLatestSignedLogRootRequest{LogId: fakeLogID, FirstTreeSize: 5})
+				return err
+			},
+			tamper: func(reply interface{}) {
+				p := reply.(*trillian.GetLatestSignedLogRootResponse).Proof
+				p.Hashes = p.Hashes[1:]
+			},
+		},
+		{
+			desc: "inclusion",
+			call: func(c trillian.TrillianLogClient) error {
+				_, err := c.GetInclusionProof(ctx, &trillian.GetInclusionProofRequest{LogId: fakeLogID, LeafIndex: 3, TreeSize: 8})
+				return err
+			},
+			tamper: func(reply interface{}) {
+				if resp, ok := reply.(*trillian.GetInclusionProofResponse); ok {
+					resp.Proof.Hashes[0] = resp.Proof.Hashes[1]
+				}
+			},
+		},
+		{
+			desc: "inclusion-leaf",
+			call: func(c trillian.TrillianLogClient) error {
+				_, err := c.GetInclusionProof(ctx, &trillian.GetInclusionProofRequest{LogId: fakeLogID, LeafIndex: 3, TreeSize: 8})
+				return err
+			},
+			tamper: func(reply interface{}) {
+				if resp, ok := reply.(*trillian.GetLeavesByIndexResponse); ok {
+					resp.Leaves[0].LeafValue = []byte("other")
+				}
+			},
+		},
+		{
+			desc: "inclusion-by-hash",
+			call: func(c trillian.TrillianLogClient) error {
+				leafHash := rfc6962.DefaultHasher.HashLeaf([]byte("leaf3"))
+				_, err := c.GetInclusionProofByHash(ctx, &trillian.GetInclusionProofByHashRequest{LogId: fakeLogID, LeafHash: leafHash, TreeSize: 8})
+				return err
+			},
+			tamper: func(reply interface{}) {
+				p := reply.(*trillian.GetInclusionProofByHashResponse).Proof[0]
+				p.Hashes[0] = p.Hashes[1]
+			},
+		},
+		{
+			desc: "entry-value",
+			call: func(c trillian.TrillianLogClient) error {
+				_, err := c.GetEntryAndProof(ctx, &trillian.GetEntryAndProofRequest{LogId: fakeLogID, LeafIndex: 2, TreeSize: 8})
+				return err
+			},
+			tamper: func(reply interface{}) {
+				reply.(*trillian.GetEntryAndProofResponse).Leaf.LeafValue = []byte("other")
+			},
+		},
+		{
+			desc: "consistency",
+			call: func(c trillian.TrillianLogClient) error {
+				_, err := c.GetConsistencyProof(ctx, &trillian.GetConsistencyProofRequest{LogId: fakeLogID, FirstTreeSize: 5, SecondTreeSize: 8})
+				return err
+			},
+			tamper: func(reply interface{}) {
+				p := reply.(*trillian.GetConsistencyProofResponse).Proof
+				p.Hashes[0] = p.Hashes[1]
+			},
+		},
+	} {
+		t.Run(tc.desc, func(t *testing.T) {
+			log, _ := newRootLog(t)
+			log.add("leaf", 5)
+			f := &fakeServer{log: log}
+			cc := dialVerifying(t, f)
+			defer cc.Close()
+			c := trillian.NewTrillianLogClient(cc)
+			if _, err := c.GetLatestSignedLogRoot(ctx, &trillian.GetLatestSignedLogRootRequest{LogId: fakeLogID}); err != nil {
+				t.Fatalf("GetLatestSignedLogRoot(): %v", err)
+			}
+			log.add("leaf", 3)
+			f.tamper = tc.tamper
+			checkVerificationError(t, tc.call(c))
+		})
+	}
+}
+
+func TestVerifyingInterceptorFork(t *testing.T) {
+	ctx := context.Background()
+	log, _ := newRootLog(t)
+	log.add("leaf", 5)
+	f := &fakeServer{log: log}
+	cc := dialVerifying(t, f)
+	defer cc.Close()
+	c := trillian.NewTrillianLogClient(cc)
+	if _, err := c.GetLatestSignedLogRoot(ctx, &trillian.GetLatestSignedLogRootRequest{LogId: fakeLogID}); err != nil {
+		t.Fatalf("GetLatestSignedLogRoot(): %v", err)
+	}
+
+	f.log, _ = newRootLog(t)
+	f.log.add("fork", 5)
+	_, err := c.GetLatestSignedLogRoot(ctx, &trillian.GetLatestSignedLogRootRequest{LogId: fakeLogID, FirstTreeSize: 5})
+	checkVerificationError(t, err)
+	var rbErr *RollbackError
+	if !errors.As(err, &rbErr) {
+		t.Errorf("GetLatestSignedLogRoot() of fork: %v, want RollbackError", err)
+	}
+}
+
+func TestVerifyingInterceptorMap(t *testing.T) {
+	ctx := context.Background()
+	m, _ := newFakeMap(t)
+	index, other := mapIndex("key"), mapIndex("other")
+	m.write([]*trillian.MapLeaf{{Index: index, LeafValue: []byte("value")}, {Index: other, LeafValue: []byte("other")}})
+	m.write([]*trillian.MapLeaf{{Index: index, LeafValue: []byte("value2")}})
+	f := &fakeServer{m: m}
+	cc := dialVerifying(t, f)
+	defer cc.Close()
+	c := trillian.NewTrillianMapClient(cc)
+
+	getLeaves := func() error {
+		_, err := c.GetLeaves(ctx, &trillian.GetMapLeavesRequest{MapId: fakeMapID, Index: [][]byte{index}})
+		return err
+	}
+	getRoot := func() error {
+		_, err := c.GetSignedMapRootByRevision(ctx, &trillian.GetSignedMapRootByRevisionRequest{MapId: fakeMapID, Revision: 1})
+		return err
+	}
+	if err := getLeaves(); err != nil {
+		t.Errorf("GetLeaves(): %v", err)
+	}
+	if err := getRoot(); err != nil {
+		t.Errorf("GetSignedMapRootByRevision(): %v", err)
+	}
+
+	for _, tc := range []struct {
+		desc   string
+		call   func() error
+		tamper func(reply interface{})
+	}{
+		{desc: "value", call: getLeaves, tamper: func(reply interface{}) {
+			reply.(*trillian.GetMapLeavesResponse).MapLeafInclusion[0].Leaf.LeafValue = []byte("value")
+		}},
+		{desc: "index", call: getLeaves, tamper: func(reply interface{}) {
+			resp, err := m.leaves(2, [][]byte{other})
+			if err != nil {
+				t.Fatalf("leaves(): %v", err)
+			}
+			reply.(*trillian.GetMapLeavesResponse).MapLeafInclusion = resp.MapLeafInclusion
+		}},
+		{desc: "revision", call: getRoot, tamper: func(reply interface{}) {
+			root, err := m.signedRoot(2)
+			if err != nil {
+				t.Fatalf("signedRoot(): %v", err)
+			}
+			reply.(*trillian.GetSignedMapRootResponse).MapRoot = root
+		}},
+	} {
+		t.Run(tc.desc, func(t *testing.T) {
+			f.tamper = tc.tamper
+			defer func() { f.tamper = nil }()
+			checkVerificationError(t, tc.call())
+		})
+	}
+}
@@ -0,0 +1,226 @@
+// Copyright 2020 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/golang/glog"
+	"github.com/google/trillian"
+	"github.com/google/trillian/client"
+	"github.com/google/trillian/client/backoff"
+	"google.golang.org/grpc/codes"
+)
+
+// importer adds leaves to a PREORDERED_LOG, numbering them from zero in the
+// order they are read.
+//
+// The log reports leaves which it already holds as AlreadyExists, or as
+// FailedPrecondition if their index or identity hash conflicts with a leaf
+// added before, e.g. by an earlier run of the import. Such leaves aren't
+// necessarily the ones in the log at their index, so the importer remembers
+// them and checks them once the log has integrated them.
+type importer struct {
+	log       trillian.TrillianLogClient
+	logID     int64
+	batchSize int
+	workers   int
+
+	mu        sync.Mutex
+	conflicts map[int64]*trillian.LogLeaf
+}
+
+type batch struct {
+	start  int64
+	leaves []*trillian.LogLeaf
+}
+
+// Import reads all leaves from r, and adds those from index start onwards to
+// the log. It returns the number of leaves read.
+func (im *importer) Import(ctx context.Context, r leafReader, start int64) (int64, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	if im.conflicts == nil {
+		im.conflicts = make(map[int64]*trillian.LogLeaf)
+	}
+
+	batches := make(chan batch)
+	errs := make(chan error, im.workers)
+	var wg sync.WaitGroup
+	for i := 0; i < im.workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for b := range batches {
+				if err := im.add(ctx, b); err != nil {
+					errs <- err
+					cancel()
+					return
+				}
+			}
+		}()
+	}
+
+	count, err := im.read(ctx, r, start, batches)
+	close(batches)
+	wg.Wait()
+	close(errs)
+	// A failing worker cancels the reader, so its error takes precedence.
+	if werr := <-errs; werr != nil {
+		return count, werr
+	}
+	return count, err
+}
+
+// read sends batches of the leaves from index start onwards to out.
+func (im *importer) read(ctx context.Context, r leafReader, start int64, out chan<- batch) (int64, error) {
+	send := func(b batch) error {
+		if len(b.leaves) == 0 {
+			return nil
+		}
+		select {
+		case out <- b:
+			return nil
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+	var b batch
+	for index := int64(0); ; index++ {
+		leaf, err := r.Next()
+		if err == io.EOF {
+			return index, send(b)
+		} else if err != nil {
+			return index, err
+		}
+		if index < start {
+			continue
+		}
+		if len(b.leaves) == 0 {
+			b.start = index
+		}
+		leaf.LeafIndex = index
+		b.leaves = append(b.leaves, leaf)
+		if len(b.leaves) == im.batchSize {
+			if err := send(b); err != nil {
+				return index + 1, err
+			}
+			b = batch{}
+		}
+	}
+}
+
+// add adds a batch of leaves to the log, retrying transient errors.
+func (im *importer) add(ctx context.Context, b batch) error {
+	bo := &backoff.Backoff{
+		Min:    100 * time.Millisecond,
+		Max:    10 * time.Second,
+		Factor: 2,
+		Jitter: true,
+	}
+	var resp *trillian.AddSequencedLeavesResponse
+	if err := bo.Retry(ctx, func() error {
+		var err error
+		resp, err = im.log.AddSequencedLeaves(ctx, &trillian.AddSequencedLeavesRequest{LogId: im.logID, Leaves: b.leaves})
+		return err
+	}); err != nil {
+		return fmt.Errorf("failed to add leaves [%d, %d): %v", b.start, b.start+int64(len(b.leaves)), err)
+	}
+	if got, want := len(resp.Results), len(b.leaves); got != want {
+		return fmt.Errorf("got %d results for leaves [%d, %d), want %d", got, b.start, b.start+int64(want), want)
+	}
+
+	im.mu.Lock()
+	defer im.mu.Unlock()
+	for i, res := range resp.Results {
+		switch c := codes.Code(res.GetStatus().GetCode()); c {
+		case codes.OK:
+		case codes.AlreadyExists, codes.FailedPrecondition:
+			im.conflicts[b.leaves[i].LeafIndex] = b.leaves[i]
+		default:
+			return fmt.Errorf("failed to add leaf %d: %v: %s", b.leaves[i].LeafIndex, c, res.GetStatus().GetMessage())
+		}
+	}
+	return nil
+}
+
+// Conflicts returns the number of leaves which the log reported as already
+// present.
+func (im *importer) Conflicts() int {
+	im.mu.Lock()
+	defer im.mu.Unlock()
+	return len(im.conflicts)
+}
+
+// Verify waits until the log has integrated size leaves, and checks that the
+// conflicting leaves are the ones in the log. If rootHash is not empty, it
+// also checks the root hash of the log.
+func (im *importer) Verify(ctx context.Context, lc *client.LogClient, size int64, rootHash []byte) error {
+	root := lc.GetRoot()
+	for int64(root.TreeSize) < size {
+		glog.Infof("Waiting for the log to integrate %d leaves, has %d", size, root.TreeSize)
+		var err error
+		if root, err = lc.WaitForRootUpdate(ctx); err != nil {
+			// A leaf whose identity hash duplicates an earlier leaf is never
+			// stored, which leaves a gap the log can't integrate past.
+			return fmt.Errorf("log didn't integrate %d leaves, check for duplicate leaves: %v", size, err)
+		}
+	}
+	if int64(root.TreeSize) > size {
+		return fmt.Errorf("log has %d leaves, more than the %d imported", root.TreeSize, size)
+	}
+	if len(rootHash) > 0 && !bytes.Equal(root.RootHash, rootHash) {
+		return fmt.Errorf("log root hash is %x, want %x", root.RootHash, rootHash)
+	}
+
+	im.mu.Lock()
+	defer im.mu.Unlock()
+	indices := make([]int64, 0, len(im.conflicts))
+	for index := range im.conflicts {
+		indices = append(indices, index)
+	}
+	sort.Slice(indices, func(i, j int) bool { return indices[i] < indices[j] })
+	for len(indices) > 0 {
+		n := im.batchSize
+		if n > len(indices) {
+			n = len(indices)
+		}
+		resp, err := im.log.GetLeavesByIndex(ctx, &trillian.GetLeavesByIndexRequest{LogId: im.logID, LeafIndex: indices[:n]})
+		if err != nil {
+			return fmt.Errorf("failed to get leaves: %v", err)
+		}
+		for _, got := range resp.Leaves {
+			want, ok := im.conflicts[got.LeafIndex]
+			if !ok {
+				return fmt.Errorf("got unrequested leaf %d", got.LeafIndex)
+			}
+			if !bytes.Equal(got.LeafValue, want.LeafValue) || !bytes.Equal(got.ExtraData, want.ExtraData) {
+				return fmt.Errorf("leaf %d in the log differs from the imported leaf", got.LeafIndex)
+			}
+			delete(im.conflicts, got.LeafIndex)
+		}
+		indices = indices[n:]
+	}
+	if len(im.conflicts) > 0 {
+		return fmt.Errorf("log didn't return %d of the conflicting leaves", len(im.conflicts))
+	}
+	return nil
+}
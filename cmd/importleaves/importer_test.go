@@ -0,0 +1,249 @@
+// Copyright 2020 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"context"
+	"crypto"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/trillian"
+	"github.com/google/trillian/client"
+	"github.com/google/trillian/crypto/keys/pem"
+	"github.com/google/trillian/merkle"
+	"github.com/google/trillian/merkle/rfc6962"
+	"github.com/google/trillian/testonly"
+	"github.com/google/trillian/types"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	tcrypto "github.com/google/trillian/crypto"
+)
+
+const testLogID = 5
+
+// fakeLog is a PREORDERED_LOG which reports leaves with existing indices as
+// FailedPrecondition, and integrates its leaves on request.
+type fakeLog struct {
+	trillian.TrillianLogClient
+	signer *tcrypto.Signer
+
+	mu         sync.Mutex
+	leaves     map[int64]*trillian.LogLeaf
+	tree       *merkle.InMemoryMerkleTree
+	integrated int64
+	failIndex  int64
+}
+
+func newFakeLog(t *testing.T) *fakeLog {
+	t.Helper()
+	key, err := pem.UnmarshalPrivateKey(testonly.DemoPrivateKey, testonly.DemoPrivateKeyPass)
+	if err != nil {
+		t.Fatalf("UnmarshalPrivateKey(): %v", err)
+	}
+	return &fakeLog{
+		signer:    tcrypto.NewSigner(0, key, crypto.SHA256),
+		leaves:    make(map[int64]*trillian.LogLeaf),
+		tree:      merkle.NewInMemoryMerkleTree(rfc6962.DefaultHasher),
+		failIndex: -1,
+	}
+}
+
+func (f *fakeLog) integrate() {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for ; f.leaves[f.integrated] != nil; f.integrated++ {
+		f.tree.AddLeaf(f.leaves[f.integrated].LeafValue)
+	}
+}
+
+func (f *fakeLog) AddSequencedLeaves(ctx context.Context, req *trillian.AddSequencedLeavesRequest, opts ...grpc.CallOption) (*trillian.AddSequencedLeavesResponse, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	resp := &trillian.AddSequencedLeavesResponse{}
+	for i, l := range req.Leaves {
+		if want := req.Leaves[0].LeafIndex + int64(i); l.LeafIndex != want {
+			return nil, status.Errorf(codes.FailedPrecondition, "Leaves[%d].LeafIndex=%d, want %d", i, l.LeafIndex, want)
+		}
+		s := status.New(codes.OK, "")
+		switch {
+		case l.LeafIndex == f.failIndex:
+			s = status.New(codes.InvalidArgument, "bad leaf")
+		case f.leaves[l.LeafIndex] != nil:
+			s = status.New(codes.FailedPrecondition, "conflicting LeafIndex")
+		default:
+			f.leaves[l.LeafIndex] = l
+		}
+		resp.Results = append(resp.Results, &trillian.QueuedLogLeaf{Leaf: l, Status: s.Proto()})
+	}
+	return resp, nil
+}
+
+func (f *fakeLog) GetLatestSignedLogRoot(ctx context.Context, req *trillian.GetLatestSignedLogRootRequest, opts ...grpc.CallOption) (*trillian.GetLatestSignedLogRootResponse, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, status.Error(codes.DeadlineExceeded, err.Error())
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	root := &types.LogRootV1{TreeSize: uint64(f.integrated), RootHash: f.tree.CurrentRoot().Hash(), TimestampNanos: uint64(time.Now().UnixNano())}
+	slr, err := f.signer.SignLogRoot(root)
+	if err != nil {
+		return nil, err
+	}
+	resp := &trillian.GetLatestSignedLogRootResponse{SignedLogRoot: slr}
+	if first := req.FirstTreeSize; first > 0 && first < f.integrated {
+		resp.Proof = &trillian.Proof{}
+		for _, n := range f.tree.SnapshotConsistency(first, f.integrated) {
+			resp.Proof.Hashes = append(resp.Proof.Hashes, n.Value.Hash())
+		}
+	}
+	return resp, nil
+}
+
+func (f *fakeLog) GetLeavesByIndex(ctx context.Context, req *trillian.GetLeavesByIndexRequest, opts ...grpc.CallOption) (*trillian.GetLeavesByIndexResponse, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	resp := &trillian.GetLeavesByIndexResponse{}
+	for _, i := range req.LeafIndex {
+		if i < f.integrated {
+			resp.Leaves = append(resp.Leaves, f.leaves[i])
+		}
+	}
+	return resp, nil
+}
+
+// sliceReader is a leafReader for leaves with values "0", "1", ...
+type sliceReader struct {
+	next, size int
+}
+
+func (s *sliceReader) Next() (*trillian.LogLeaf, error) {
+	if s.next == s.size {
+		return nil, io.EOF
+	}
+	s.next++
+	return &trillian.LogLeaf{LeafValue: []byte(fmt.Sprint(s.next - 1))}, nil
+}
+
+func TestImport(t *testing.T) {
+	ctx := context.Background()
+	const size = 50
+
+	for _, tc := range []struct {
+		desc          string
+		start         int64
+		preexisting   []int64
+		failIndex     int64
+		wantConflicts int
+		wantErr       bool
+	}{
+		{desc: "all", failIndex: -1},
+		{desc: "resume", start: 20, failIndex: -1},
+		{desc: "resume-unintegrated", start: 20, preexisting: []int64{20, 21, 22}, failIndex: -1, wantConflicts: 3},
+		{desc: "leaf-error", failIndex: 33, wantErr: true},
+	} {
+		t.Run(tc.desc, func(t *testing.T) {
+			f := newFakeLog(t)
+			f.failIndex = tc.failIndex
+			for i := int64(0); i < tc.start; i++ {
+				f.leaves[i] = &trillian.LogLeaf{LeafValue: []byte(fmt.Sprint(i)), LeafIndex: i}
+			}
+			for _, i := range tc.preexisting {
+				f.leaves[i] = &trillian.LogLeaf{LeafValue: []byte(fmt.Sprint(i)), LeafIndex: i}
+			}
+
+			im := &importer{log: f, logID: testLogID, batchSize: 7, workers: 3}
+			count, err := im.Import(ctx, &sliceReader{size: size}, tc.start)
+			if gotErr := err != nil; gotErr != tc.wantErr {
+				t.Fatalf("Import(): %v, wantErr %v", err, tc.wantErr)
+			}
+			if tc.wantErr {
+				// The worker's error, not the cancellation it causes.
+				if want := fmt.Sprintf("failed to add leaf %d", tc.failIndex); !strings.Contains(err.Error(), want) {
+					t.Errorf("Import(): %v, want error containing %q", err, want)
+				}
+				return
+			}
+			if count != size {
+				t.Errorf("Import() = %d, want %d", count, size)
+			}
+			if got := im.Conflicts(); got != tc.wantConflicts {
+				t.Errorf("Conflicts() = %d, want %d", got, tc.wantConflicts)
+			}
+			for i := int64(0); i < size; i++ {
+				if got, want := string(f.leaves[i].GetLeafValue()), fmt.Sprint(i); got != want {
+					t.Errorf("leaf %d = %q, want %q", i, got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestVerify(t *testing.T) {
+	ctx := context.Background()
+	const size = 10
+
+	for _, tc := range []struct {
+		desc     string
+		conflict string
+		rootHash func(f *fakeLog) []byte
+		wantErr  bool
+	}{
+		{desc: "ok", conflict: "3"},
+		{desc: "conflict-differs", conflict: "not 3", wantErr: true},
+		{desc: "root-hash", conflict: "3", rootHash: func(f *fakeLog) []byte { return f.tree.CurrentRoot().Hash() }},
+		{desc: "wrong-root-hash", conflict: "3", rootHash: func(*fakeLog) []byte { return []byte("wrong") }, wantErr: true},
+	} {
+		t.Run(tc.desc, func(t *testing.T) {
+			f := newFakeLog(t)
+			f.leaves[3] = &trillian.LogLeaf{LeafValue: []byte(tc.conflict), LeafIndex: 3}
+			im := &importer{log: f, logID: testLogID, batchSize: 4, workers: 2}
+			if _, err := im.Import(ctx, &sliceReader{size: size}, 0); err != nil {
+				t.Fatalf("Import(): %v", err)
+			}
+			f.integrate()
+
+			verifier := client.NewLogVerifier(rfc6962.DefaultHasher, f.signer.Public(), crypto.SHA256)
+			lc := client.New(testLogID, f, verifier, types.LogRootV1{})
+			var rootHash []byte
+			if tc.rootHash != nil {
+				rootHash = tc.rootHash(f)
+			}
+			err := im.Verify(ctx, lc, size, rootHash)
+			if gotErr := err != nil; gotErr != tc.wantErr {
+				t.Errorf("Verify(): %v, wantErr %v", err, tc.wantErr)
+			}
+		})
+	}
+}
+
+func TestVerifyTimesOut(t *testing.T) {
+	f := newFakeLog(t)
+	im := &importer{log: f, logID: testLogID, batchSize: 4, workers: 1}
+	verifier := client.NewLogVerifier(rfc6962.DefaultHasher, f.signer.Public(), crypto.SHA256)
+	lc := client.New(testLogID, f, verifier, types.LogRootV1{})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+	defer cancel()
+	if err := im.Verify(ctx, lc, 1, nil); err == nil || !strings.Contains(err.Error(), "duplicate") {
+		t.Errorf("Verify(): %v, want error about duplicate leaves", err)
+	}
+}
@@ -0,0 +1,172 @@
+// Copyright 2020 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package main contains the implementation and entry point for the
+// importleaves command, which adds the leaves read from files to a
+// PREORDERED_LOG tree.
+//
+// Leaves are numbered from zero in the order of the files and the records in
+// them. The import resumes from the size of the log, so it can be run again
+// with the same files if it fails.
+//
+// Example usage:
+// $ ./importleaves --log_server=host:port --log_id=logid --format=jsonl leaves1.jsonl leaves2.jsonl
+package main
+
+import (
+	"context"
+	"encoding/hex"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/golang/glog"
+	"github.com/google/trillian"
+	"github.com/google/trillian/client"
+	"github.com/google/trillian/client/rpcflags"
+	"github.com/google/trillian/types"
+	"google.golang.org/grpc"
+
+	// Register key ProtoHandlers
+	_ "github.com/google/trillian/crypto/keys/der/proto"
+	_ "github.com/google/trillian/crypto/keys/pem/proto"
+
+	// Load hashers
+	_ "github.com/google/trillian/merkle/rfc6962"
+)
+
+var (
+	logServerAddr      = flag.String("log_server", "", "Address of the gRPC Trillian Log Server (host:port)")
+	adminServerAddr    = flag.String("admin_server", "", "Address of the gRPC Trillian Admin Server (defaults to --log_server)")
+	logID              = flag.Int64("log_id", 0, "Trillian LogID of the PREORDERED_LOG to import into")
+	format             = flag.String("format", "jsonl", "Format of the input files: jsonl, proto (varint size delimited LogLeaf protos) or csv")
+	batchSize          = flag.Int("batch_size", 1000, "Max number of leaves per AddSequencedLeaves request")
+	workers            = flag.Int("workers", 4, "Number of AddSequencedLeaves requests to send in parallel")
+	expectedRootHash   = flag.String("expected_root_hash", "", "If set, the hex encoded root hash which the log must have once all leaves are integrated")
+	integrationTimeout = flag.Duration("integration_timeout", 10*time.Minute, "Maximum time to wait for the log to integrate the leaves when verifying them")
+)
+
+// fileLeafReader reads the leaves from a list of files, one after the other.
+type fileLeafReader struct {
+	format string
+	files  []string
+	cur    leafReader
+	close  func() error
+}
+
+func (f *fileLeafReader) Next() (*trillian.LogLeaf, error) {
+	for {
+		if f.cur == nil {
+			if len(f.files) == 0 {
+				return nil, io.EOF
+			}
+			file, err := os.Open(f.files[0])
+			if err != nil {
+				return nil, err
+			}
+			if f.cur, err = newLeafReader(f.format, file); err != nil {
+				file.Close()
+				return nil, err
+			}
+			f.close = file.Close
+		}
+		leaf, err := f.cur.Next()
+		if err != io.EOF {
+			if err != nil {
+				err = fmt.Errorf("%s: %v", f.files[0], err)
+			}
+			return leaf, err
+		}
+		f.close()
+		f.cur, f.files = nil, f.files[1:]
+	}
+}
+
+func run(ctx context.Context) error {
+	if flag.NArg() == 0 {
+		return errors.New("no input files")
+	}
+	rootHash, err := hex.DecodeString(*expectedRootHash)
+	if err != nil {
+		return fmt.Errorf("invalid --expected_root_hash: %v", err)
+	}
+	if _, err := newLeafReader(*format, nil); err != nil {
+		return err
+	}
+
+	dialOpts, err := rpcflags.NewClientDialOptionsFromFlags()
+	if err != nil {
+		return fmt.Errorf("failed to determine dial options: %v", err)
+	}
+	conn, err := grpc.Dial(*logServerAddr, dialOpts...)
+	if err != nil {
+		return fmt.Errorf("failed to dial %v: %v", *logServerAddr, err)
+	}
+	defer conn.Close()
+	adminConn := conn
+	if *adminServerAddr != "" {
+		if adminConn, err = grpc.Dial(*adminServerAddr, dialOpts...); err != nil {
+			return fmt.Errorf("failed to dial %v: %v", *adminServerAddr, err)
+		}
+		defer adminConn.Close()
+	}
+
+	tree, err := trillian.NewTrillianAdminClient(adminConn).GetTree(ctx, &trillian.GetTreeRequest{TreeId: *logID})
+	if err != nil {
+		return fmt.Errorf("failed to get tree %d: %v", *logID, err)
+	}
+	if tree.TreeType != trillian.TreeType_PREORDERED_LOG {
+		return fmt.Errorf("tree %d has type %v, want %v", *logID, tree.TreeType, trillian.TreeType_PREORDERED_LOG)
+	}
+	logClient := trillian.NewTrillianLogClient(conn)
+	lc, err := client.NewFromTree(logClient, tree, types.LogRootV1{})
+	if err != nil {
+		return err
+	}
+	if _, err := lc.UpdateRoot(ctx); err != nil {
+		return fmt.Errorf("failed to get log root: %v", err)
+	}
+	start := int64(lc.GetRoot().TreeSize)
+	glog.Infof("Log %d has %d leaves, importing the following ones", *logID, start)
+
+	im := &importer{log: logClient, logID: *logID, batchSize: *batchSize, workers: *workers}
+	count, err := im.Import(ctx, &fileLeafReader{format: *format, files: flag.Args()}, start)
+	if err != nil {
+		return err
+	}
+	glog.Infof("Added leaves [%d, %d), %d of which the log already had", start, count, im.Conflicts())
+
+	if im.Conflicts() == 0 && len(rootHash) == 0 {
+		return nil
+	}
+	ctx, cancel := context.WithTimeout(ctx, *integrationTimeout)
+	defer cancel()
+	if err := im.Verify(ctx, lc, count, rootHash); err != nil {
+		return err
+	}
+	glog.Infof("Verified the %d leaves of log %d", count, *logID)
+	return nil
+}
+
+func main() {
+	flag.Parse()
+	defer glog.Flush()
+
+	if err := run(context.Background()); err != nil {
+		glog.Exitf("Import failed: %v", err)
+	}
+}
@@ -0,0 +1,123 @@
+// Copyright 2020 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bufio"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/golang/protobuf/proto" //nolint:staticcheck
+	"github.com/google/trillian"
+)
+
+// leafReader reads leaves from a file. Next returns io.EOF after the last leaf.
+type leafReader interface {
+	Next() (*trillian.LogLeaf, error)
+}
+
+// newLeafReader returns a leafReader for the given format. In the jsonl
+// format, each line holds a JSON object with the base64 encoded "leaf_value"
+// and optional "extra_data" and "leaf_identity_hash" fields. In the proto
+// format, each LogLeaf proto is prefixed by its size as a varint. In the csv
+// format, each record holds the base64 encoded leaf value, and optionally the
+// extra data and leaf identity hash; lines starting with '#' are ignored.
+func newLeafReader(format string, r io.Reader) (leafReader, error) {
+	switch format {
+	case "jsonl":
+		return &jsonlReader{dec: json.NewDecoder(r)}, nil
+	case "proto":
+		return &protoReader{r: bufio.NewReader(r)}, nil
+	case "csv":
+		cr := csv.NewReader(r)
+		cr.Comment = '#'
+		cr.FieldsPerRecord = -1
+		return &csvReader{r: cr}, nil
+	}
+	return nil, fmt.Errorf("unknown format %q, want jsonl, proto or csv", format)
+}
+
+type jsonlReader struct {
+	dec   *json.Decoder
+	count int
+}
+
+func (j *jsonlReader) Next() (*trillian.LogLeaf, error) {
+	var l struct {
+		LeafValue        []byte `json:"leaf_value"`
+		ExtraData        []byte `json:"extra_data"`
+		LeafIdentityHash []byte `json:"leaf_identity_hash"`
+	}
+	if err := j.dec.Decode(&l); err != nil {
+		if err == io.EOF {
+			return nil, err
+		}
+		return nil, fmt.Errorf("record %d: %v", j.count+1, err)
+	}
+	j.count++
+	return &trillian.LogLeaf{LeafValue: l.LeafValue, ExtraData: l.ExtraData, LeafIdentityHash: l.LeafIdentityHash}, nil
+}
+
+type protoReader struct {
+	r     *bufio.Reader
+	count int
+}
+
+func (p *protoReader) Next() (*trillian.LogLeaf, error) {
+	size, err := binary.ReadUvarint(p.r)
+	if err != nil {
+		if err == io.EOF {
+			return nil, err
+		}
+		return nil, fmt.Errorf("record %d: failed to read size: %v", p.count+1, err)
+	}
+	buf := make([]byte, size)
+	if _, err := io.ReadFull(p.r, buf); err != nil {
+		return nil, fmt.Errorf("record %d: %v", p.count+1, err)
+	}
+	var leaf trillian.LogLeaf
+	if err := proto.Unmarshal(buf, &leaf); err != nil {
+		return nil, fmt.Errorf("record %d: %v", p.count+1, err)
+	}
+	p.count++
+	return &trillian.LogLeaf{LeafValue: leaf.LeafValue, ExtraData: leaf.ExtraData, LeafIdentityHash: leaf.LeafIdentityHash}, nil
+}
+
+type csvReader struct {
+	r     *csv.Reader
+	count int
+}
+
+func (c *csvReader) Next() (*trillian.LogLeaf, error) {
+	rec, err := c.r.Read()
+	if err != nil {
+		return nil, err
+	}
+	c.count++
+	if len(rec) > 3 {
+		return nil, fmt.Errorf("record %d: got %d fields, want 1 to 3", c.count, len(rec))
+	}
+	var fields [3][]byte
+	for i, f := range rec {
+		if fields[i], err = base64.StdEncoding.DecodeString(f); err != nil {
+			return nil, fmt.Errorf("record %d: field %d: %v", c.count, i+1, err)
+		}
+	}
+	return &trillian.LogLeaf{LeafValue: fields[0], ExtraData: fields[1], LeafIdentityHash: fields[2]}, nil
+}
@@ -0,0 +1,117 @@
+// Copyright 2020 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/golang/protobuf/proto" //nolint:staticcheck
+	"github.com/google/trillian"
+)
+
+func readAll(t *testing.T, format string, r io.Reader) ([]*trillian.LogLeaf, error) {
+	t.Helper()
+	lr, err := newLeafReader(format, r)
+	if err != nil {
+		t.Fatalf("newLeafReader(%q): %v", format, err)
+	}
+	var leaves []*trillian.LogLeaf
+	for {
+		leaf, err := lr.Next()
+		if err == io.EOF {
+			return leaves, nil
+		} else if err != nil {
+			return leaves, err
+		}
+		leaves = append(leaves, leaf)
+	}
+}
+
+func delimited(t *testing.T, leaves ...*trillian.LogLeaf) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	for _, leaf := range leaves {
+		data, err := proto.Marshal(leaf)
+		if err != nil {
+			t.Fatalf("Marshal(): %v", err)
+		}
+		var size [binary.MaxVarintLen64]byte
+		buf.Write(size[:binary.PutUvarint(size[:], uint64(len(data)))])
+		buf.Write(data)
+	}
+	return buf.Bytes()
+}
+
+func TestLeafReader(t *testing.T) {
+	want := []*trillian.LogLeaf{
+		{LeafValue: []byte("one")},
+		{LeafValue: []byte("two"), ExtraData: []byte("extra")},
+		{LeafValue: []byte("three"), ExtraData: []byte("extra"), LeafIdentityHash: []byte("id")},
+	}
+	for _, tc := range []struct {
+		format  string
+		input   []byte
+		wantErr bool
+	}{
+		{
+			format: "jsonl",
+			input: []byte(`{"leaf_value": "b25l"}
+{"leaf_value": "dHdv", "extra_data": "ZXh0cmE="}
+{"leaf_value": "dGhyZWU=", "extra_data": "ZXh0cmE=", "leaf_identity_hash": "aWQ="}
+`),
+		},
+		{format: "proto", input: delimited(t, want...)},
+		{
+			format: "csv",
+			input: []byte(`# value,extra,id
+b25l
+dHdv,ZXh0cmE=
+dGhyZWU=,ZXh0cmE=,aWQ=
+`),
+		},
+		{format: "jsonl", input: []byte(`{"leaf_value": "b25l"}` + "\n{"), wantErr: true},
+		{format: "proto", input: delimited(t, want...)[:10], wantErr: true},
+		{format: "csv", input: []byte("b25l\nnot base64\n"), wantErr: true},
+		{format: "csv", input: []byte("b25l,b25l,b25l,b25l\n"), wantErr: true},
+	} {
+		t.Run(tc.format, func(t *testing.T) {
+			got, err := readAll(t, tc.format, bytes.NewReader(tc.input))
+			if gotErr := err != nil; gotErr != tc.wantErr {
+				t.Fatalf("Next(): %v, wantErr %v", err, tc.wantErr)
+			}
+			if tc.wantErr {
+				return
+			}
+			if len(got) != len(want) {
+				t.Fatalf("got %d leaves, want %d", len(got), len(want))
+			}
+			for i := range want {
+				if !proto.Equal(got[i], want[i]) {
+					t.Errorf("leaf %d = %v, want %v", i, got[i], want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestLeafReaderUnknownFormat(t *testing.T) {
+	if _, err := newLeafReader("xml", strings.NewReader("")); err == nil {
+		t.Error("newLeafReader(xml) succeeded, want error")
+	}
+}
@@ -19,6 +19,7 @@ import (
 	"context"
 	"net"
 	"net/http"
+	"sync/atomic"
 	"time"
 
 	"github.com/golang/glog"
@@ -50,6 +51,11 @@ const (
 	// hard-deleting them.
 	// Actual runs happen randomly between [minInterval,2*minInterval).
 	DefaultTreeDeleteMinInterval = 4 * time.Hour
+
+	// DefaultTreeSchedulerMinInterval is the suggested min interval between sweeps for due
+	// scheduled tree state transitions.
+	// Actual runs happen randomly between [minInterval,2*minInterval).
+	DefaultTreeSchedulerMinInterval = 1 * time.Minute
 )
 
 // Main encapsulates the data and logic to start a Trillian server (Log or Map).
@@ -79,6 +85,11 @@ type Main struct {
 	// IsHealthy() call.
 	HealthyDeadline time.Duration
 
+	// DrainFn, if set, is called when a termination signal is received, and the
+	// server is only stopped once it returns. While it runs, the /healthz
+	// endpoint reports that the server is draining.
+	DrainFn func()
+
 	// AllowedTreeTypes determines which types of trees may be created through the Admin Server
 	// bound by Main. nil means unrestricted.
 	AllowedTreeTypes []trillian.TreeType
@@ -87,11 +98,23 @@ type Main struct {
 	TreeDeleteThreshold   time.Duration
 	TreeDeleteMinInterval time.Duration
 
+	// TreeSchedulerEnabled makes the server apply scheduled tree state transitions to storage.
+	TreeSchedulerEnabled     bool
+	TreeSchedulerMinInterval time.Duration
+
 	// These will be added to the GRPC server options.
 	ExtraOptions []grpc.ServerOption
+
+	// draining is set to 1 while DrainFn runs.
+	draining int32
 }
 
 func (m *Main) healthz(rw http.ResponseWriter, req *http.Request) {
+	if atomic.LoadInt32(&m.draining) != 0 {
+		rw.WriteHeader(http.StatusServiceUnavailable)
+		rw.Write([]byte("draining"))
+		return
+	}
 	if m.IsHealthy != nil {
 		ctx, cancel := context.WithTimeout(req.Context(), m.HealthyDeadline)
 		defer cancel()
@@ -152,7 +175,14 @@ func (m *Main) Run(ctx context.Context) error {
 	if err != nil {
 		return err
 	}
-	go util.AwaitSignal(ctx, srv.Stop)
+	go util.AwaitSignal(ctx, func() {
+		if m.DrainFn != nil {
+			glog.Info("Draining before stopping the server")
+			atomic.StoreInt32(&m.draining, 1)
+			m.DrainFn()
+		}
+		srv.Stop()
+	})
 
 	if m.TreeGCEnabled {
 		go func() {
@@ -166,6 +196,17 @@ func (m *Main) Run(ctx context.Context) error {
 		}()
 	}
 
+	if m.TreeSchedulerEnabled {
+		go func() {
+			glog.Info("Tree state scheduler started")
+			ts := admin.NewTreeStateScheduler(
+				m.Registry.AdminStorage,
+				m.TreeSchedulerMinInterval,
+				m.Registry.MetricFactory)
+			ts.Run(ctx)
+		}()
+	}
+
 	if err := srv.Serve(lis); err != nil {
 		glog.Errorf("RPC server terminated: %v", err)
 	}
@@ -190,6 +231,7 @@ func (m *Main) newGRPCServer() (*grpc.Server, error) {
 			interceptor.ErrorWrapper,
 			ti.UnaryInterceptor,
 		)),
+		grpc.StreamInterceptor(ti.StreamInterceptor),
 	}
 	serverOpts = append(serverOpts, m.ExtraOptions...)
 
@@ -0,0 +1,116 @@
+// Copyright 2020 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// The log_mirror binary keeps a PREORDERED_LOG tree in sync with a source log.
+//
+// It follows the verified roots of the source log, copies its leaves into the
+// mirror with AddSequencedLeaves, and checks that each integrated root of the
+// mirror matches the source.
+//
+// Example usage:
+// $ ./log_mirror --source_server=host:port --source_log_id=1 --mirror_server=host:port --mirror_log_id=2
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/golang/glog"
+	"github.com/google/trillian"
+	"github.com/google/trillian/client/rpcflags"
+	"github.com/google/trillian/monitoring"
+	"github.com/google/trillian/monitoring/prometheus"
+	"github.com/google/trillian/util"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"google.golang.org/grpc"
+
+	// Register key ProtoHandlers
+	_ "github.com/google/trillian/crypto/keys/der/proto"
+	_ "github.com/google/trillian/crypto/keys/pem/proto"
+
+	// Load hashers
+	_ "github.com/google/trillian/merkle/rfc6962"
+)
+
+var (
+	sourceServer    = flag.String("source_server", "", "Address of the Trillian server with the source log (host:port)")
+	sourceLogID     = flag.Int64("source_log_id", 0, "Tree ID of the source log")
+	mirrorServer    = flag.String("mirror_server", "", "Address of the Trillian server with the mirror log (host:port)")
+	mirrorLogID     = flag.Int64("mirror_log_id", 0, "Tree ID of the mirror, which must be a PREORDERED_LOG")
+	stateFile       = flag.String("state_file", "", "File holding the trusted root of the source log across restarts; if empty, the first root seen is trusted on each start")
+	batchSize       = flag.Int64("batch_size", 1000, "Max number of leaves to copy per request")
+	pollInterval    = flag.Duration("poll_interval", 5*time.Second, "Time to wait for new source leaves once the mirror has caught up")
+	metricsEndpoint = flag.String("metrics_endpoint", "", "Endpoint for serving metrics; if left empty, metrics will not be exposed")
+)
+
+func main() {
+	flag.Parse()
+	defer glog.Flush()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go util.AwaitSignal(ctx, cancel)
+
+	if err := innerMain(ctx); err != nil && err != context.Canceled {
+		glog.Exit(err)
+	}
+}
+
+func innerMain(ctx context.Context) error {
+	var mf monitoring.MetricFactory
+	if *metricsEndpoint != "" {
+		mf = prometheus.MetricFactory{}
+		http.Handle("/metrics", promhttp.Handler())
+		server := http.Server{Addr: *metricsEndpoint, Handler: nil}
+		glog.Infof("Serving metrics at %v", *metricsEndpoint)
+		go func() {
+			err := server.ListenAndServe()
+			glog.Warningf("Metrics server exited: %v", err)
+		}()
+	}
+
+	dialOpts, err := rpcflags.NewClientDialOptionsFromFlags()
+	if err != nil {
+		return fmt.Errorf("failed to determine dial options: %v", err)
+	}
+	srcConn, err := grpc.Dial(*sourceServer, dialOpts...)
+	if err != nil {
+		return fmt.Errorf("failed to dial %v: %v", *sourceServer, err)
+	}
+	defer srcConn.Close()
+	dstConn, err := grpc.Dial(*mirrorServer, dialOpts...)
+	if err != nil {
+		return fmt.Errorf("failed to dial %v: %v", *mirrorServer, err)
+	}
+	defer dstConn.Close()
+
+	srcTree, err := trillian.NewTrillianAdminClient(srcConn).GetTree(ctx, &trillian.GetTreeRequest{TreeId: *sourceLogID})
+	if err != nil {
+		return fmt.Errorf("failed to get source tree %d: %v", *sourceLogID, err)
+	}
+	dstTree, err := trillian.NewTrillianAdminClient(dstConn).GetTree(ctx, &trillian.GetTreeRequest{TreeId: *mirrorLogID})
+	if err != nil {
+		return fmt.Errorf("failed to get mirror tree %d: %v", *mirrorLogID, err)
+	}
+
+	m, err := newMirror(trillian.NewTrillianLogClient(srcConn), srcTree, trillian.NewTrillianLogClient(dstConn), dstTree, *batchSize, *stateFile, mf)
+	if err != nil {
+		return err
+	}
+	glog.Infof("Mirroring log %d from %v to log %d on %v", *sourceLogID, *sourceServer, *mirrorLogID, *mirrorServer)
+	return m.Run(ctx, *pollInterval)
+}
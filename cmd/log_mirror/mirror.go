@@ -0,0 +1,288 @@
+// Copyright 2020 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/golang/glog"
+	"github.com/google/trillian"
+	"github.com/google/trillian/client"
+	"github.com/google/trillian/merkle"
+	"github.com/google/trillian/monitoring"
+	"github.com/google/trillian/types"
+	"google.golang.org/grpc/codes"
+)
+
+var timeNow = time.Now
+
+var (
+	once             sync.Once
+	sourceTreeSize   monitoring.Gauge
+	mirrorTreeSize   monitoring.Gauge
+	lagLeaves        monitoring.Gauge
+	lagSeconds       monitoring.Gauge
+	leavesCopied     monitoring.Counter
+	consistencyFails monitoring.Counter
+)
+
+func createMetrics(mf monitoring.MetricFactory) {
+	if mf == nil {
+		mf = monitoring.InertMetricFactory{}
+	}
+	sourceTreeSize = mf.NewGauge("mirror_source_tree_size", "Size of the latest verified source tree", monitoring.TreeIDLabel)
+	mirrorTreeSize = mf.NewGauge("mirror_tree_size", "Size of the latest integrated mirror tree", monitoring.TreeIDLabel)
+	lagLeaves = mf.NewGauge("mirror_lag_leaves", "Number of source leaves not yet integrated in the mirror", monitoring.TreeIDLabel)
+	lagSeconds = mf.NewGauge("mirror_lag_seconds", "Seconds since the mirror last matched the verified source root", monitoring.TreeIDLabel)
+	leavesCopied = mf.NewCounter("mirror_leaves_copied", "Number of leaves written to the mirror", monitoring.TreeIDLabel)
+	consistencyFails = mf.NewCounter("mirror_consistency_failures", "Number of mirror roots which did not match the source", monitoring.TreeIDLabel)
+}
+
+// errDiverged is returned when the mirror's root doesn't match the source.
+// The mirror can't recover from this, as its leaves can't be removed.
+var errDiverged = errors.New("mirror has diverged from the source")
+
+// mirror copies the leaves of a source log into a PREORDERED_LOG tree.
+//
+// The mirror doesn't keep track of the leaves it has written. After a restart
+// it continues from the size of the mirror's latest root, and writes again
+// any leaves which were not integrated yet. The PREORDERED_LOG rejects these
+// as duplicates, which is harmless: if the existing leaves differ from the
+// source, the mirror's root won't match the source and the check fails.
+type mirror struct {
+	src, dst   *client.LogClient
+	srcLog     trillian.TrillianLogClient
+	dstLog     trillian.TrillianLogClient
+	v          merkle.LogVerifier
+	batchSize  int64
+	stateFile  string
+	label      string
+	next       int64
+	lastInSync time.Time
+}
+
+// newMirror returns a mirror from the log src to the PREORDERED_LOG dst.
+// The trusted root of src is loaded from stateFile if it exists, and stored
+// in it whenever it changes. An empty stateFile disables this.
+func newMirror(srcLog trillian.TrillianLogClient, srcTree *trillian.Tree, dstLog trillian.TrillianLogClient, dstTree *trillian.Tree, batchSize int64, stateFile string, mf monitoring.MetricFactory) (*mirror, error) {
+	if dstTree.TreeType != trillian.TreeType_PREORDERED_LOG {
+		return nil, fmt.Errorf("mirror tree %d has type %v, want %v", dstTree.TreeId, dstTree.TreeType, trillian.TreeType_PREORDERED_LOG)
+	}
+	if batchSize <= 0 {
+		return nil, fmt.Errorf("batch size %d must be positive", batchSize)
+	}
+	var trusted types.LogRootV1
+	if stateFile != "" {
+		data, err := ioutil.ReadFile(stateFile)
+		switch {
+		case os.IsNotExist(err):
+			glog.Infof("No state in %q, trusting the first source root", stateFile)
+		case err != nil:
+			return nil, err
+		default:
+			if err := trusted.UnmarshalBinary(data); err != nil {
+				return nil, fmt.Errorf("failed to parse state in %q: %v", stateFile, err)
+			}
+		}
+	}
+	src, err := client.NewFromTree(srcLog, srcTree, trusted)
+	if err != nil {
+		return nil, err
+	}
+	dst, err := client.NewFromTree(dstLog, dstTree, types.LogRootV1{})
+	if err != nil {
+		return nil, err
+	}
+	once.Do(func() { createMetrics(mf) })
+	return &mirror{
+		src:        src,
+		dst:        dst,
+		srcLog:     srcLog,
+		dstLog:     dstLog,
+		v:          merkle.NewLogVerifier(src.Hasher),
+		batchSize:  batchSize,
+		stateFile:  stateFile,
+		label:      strconv.FormatInt(dstTree.TreeId, 10),
+		lastInSync: timeNow(),
+	}, nil
+}
+
+// Run copies leaves until ctx is done or the mirror diverges from the source.
+// It waits for pollInterval whenever there is nothing to copy, or a step
+// fails.
+func (m *mirror) Run(ctx context.Context, pollInterval time.Duration) error {
+	for {
+		copied, err := m.step(ctx)
+		switch {
+		case errors.Is(err, errDiverged):
+			return err
+		case err != nil:
+			glog.Warningf("Mirror step failed: %v", err)
+		}
+		if copied > 0 && err == nil {
+			continue
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(pollInterval):
+		}
+	}
+}
+
+// step verifies the latest roots of the source and the mirror, and then
+// writes the next batch of leaves to the mirror. It returns the number of
+// leaves written.
+func (m *mirror) step(ctx context.Context) (int, error) {
+	if _, err := m.src.UpdateRoot(ctx); err != nil {
+		return 0, fmt.Errorf("failed to update source root: %v", err)
+	}
+	srcRoot := m.src.GetRoot()
+	if err := m.saveState(srcRoot); err != nil {
+		return 0, err
+	}
+	if _, err := m.dst.UpdateRoot(ctx); err != nil {
+		return 0, fmt.Errorf("failed to update mirror root: %v", err)
+	}
+	dstRoot := m.dst.GetRoot()
+
+	if err := m.check(ctx, srcRoot, dstRoot); err != nil {
+		consistencyFails.Inc(m.label)
+		return 0, err
+	}
+	m.updateMetrics(srcRoot, dstRoot)
+
+	if m.next < int64(dstRoot.TreeSize) {
+		m.next = int64(dstRoot.TreeSize)
+	}
+	end := m.next + m.batchSize
+	if size := int64(srcRoot.TreeSize); end > size {
+		end = size
+	}
+	if end <= m.next {
+		return 0, nil
+	}
+	leaves, err := m.src.ListByIndex(ctx, m.next, end-m.next)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get source leaves [%d, %d): %v", m.next, end, err)
+	}
+	if err := m.write(ctx, leaves); err != nil {
+		return 0, err
+	}
+	m.next = end
+	leavesCopied.Add(float64(len(leaves)), m.label)
+	return len(leaves), nil
+}
+
+// check verifies that the mirror's root is the root of the source at the
+// same size, using a consistency proof from the source if the mirror is
+// behind.
+func (m *mirror) check(ctx context.Context, srcRoot, dstRoot *types.LogRootV1) error {
+	switch {
+	case dstRoot.TreeSize == 0:
+		return nil
+	case dstRoot.TreeSize > srcRoot.TreeSize:
+		return fmt.Errorf("%w: mirror size %d is larger than source size %d", errDiverged, dstRoot.TreeSize, srcRoot.TreeSize)
+	case dstRoot.TreeSize == srcRoot.TreeSize:
+		if !bytes.Equal(dstRoot.RootHash, srcRoot.RootHash) {
+			return fmt.Errorf("%w: mirror root %x != source root %x at size %d", errDiverged, dstRoot.RootHash, srcRoot.RootHash, srcRoot.TreeSize)
+		}
+		return nil
+	}
+	resp, err := m.srcLog.GetConsistencyProof(ctx, &trillian.GetConsistencyProofRequest{
+		LogId:          m.src.LogID,
+		FirstTreeSize:  int64(dstRoot.TreeSize),
+		SecondTreeSize: int64(srcRoot.TreeSize),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to get source consistency proof: %v", err)
+	}
+	if err := m.v.VerifyConsistencyProof(int64(dstRoot.TreeSize), int64(srcRoot.TreeSize), dstRoot.RootHash, srcRoot.RootHash, resp.GetProof().GetHashes()); err != nil {
+		// A mismatch can't tell a diverged mirror apart from a bad proof
+		// served by the source. Both need an operator to look into them.
+		var mismatch merkle.RootMismatchError
+		if errors.As(err, &mismatch) {
+			return fmt.Errorf("%w: mirror root at size %d: %v", errDiverged, dstRoot.TreeSize, err)
+		}
+		return fmt.Errorf("failed to verify source consistency proof: %v", err)
+	}
+	return nil
+}
+
+// write adds leaves to the mirror, keeping their indices.
+func (m *mirror) write(ctx context.Context, leaves []*trillian.LogLeaf) error {
+	req := &trillian.AddSequencedLeavesRequest{LogId: m.dst.LogID}
+	for _, l := range leaves {
+		req.Leaves = append(req.Leaves, &trillian.LogLeaf{
+			LeafValue:        l.LeafValue,
+			ExtraData:        l.ExtraData,
+			LeafIndex:        l.LeafIndex,
+			LeafIdentityHash: l.LeafIdentityHash,
+		})
+	}
+	resp, err := m.dstLog.AddSequencedLeaves(ctx, req)
+	if err != nil {
+		return fmt.Errorf("failed to add leaves to mirror: %v", err)
+	}
+	for _, r := range resp.Results {
+		switch c := codes.Code(r.GetStatus().GetCode()); c {
+		case codes.OK, codes.AlreadyExists, codes.FailedPrecondition:
+			// Duplicates of leaves written before a restart are reported as
+			// FailedPrecondition, see the comment on mirror.
+		default:
+			return fmt.Errorf("failed to add leaf %d to mirror: %v: %s", r.GetLeaf().GetLeafIndex(), c, r.GetStatus().GetMessage())
+		}
+	}
+	return nil
+}
+
+func (m *mirror) updateMetrics(srcRoot, dstRoot *types.LogRootV1) {
+	now := timeNow()
+	if dstRoot.TreeSize == srcRoot.TreeSize {
+		m.lastInSync = now
+	}
+	sourceTreeSize.Set(float64(srcRoot.TreeSize), m.label)
+	mirrorTreeSize.Set(float64(dstRoot.TreeSize), m.label)
+	lagLeaves.Set(float64(srcRoot.TreeSize-dstRoot.TreeSize), m.label)
+	lagSeconds.Set(now.Sub(m.lastInSync).Seconds(), m.label)
+}
+
+// saveState stores the trusted source root in the state file, so that the
+// mirror only trusts roots consistent with it after a restart.
+func (m *mirror) saveState(root *types.LogRootV1) error {
+	if m.stateFile == "" {
+		return nil
+	}
+	data, err := root.MarshalBinary()
+	if err != nil {
+		return err
+	}
+	tmp := m.stateFile + ".tmp"
+	if err := ioutil.WriteFile(tmp, data, 0644); err != nil {
+		return fmt.Errorf("failed to write state: %v", err)
+	}
+	if err := os.Rename(tmp, m.stateFile); err != nil {
+		return fmt.Errorf("failed to write state: %v", err)
+	}
+	return nil
+}
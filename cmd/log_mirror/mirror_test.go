@@ -0,0 +1,229 @@
+// Copyright 2020 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"context"
+	"crypto"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/trillian"
+	"github.com/google/trillian/crypto/keys/pem"
+	"github.com/google/trillian/crypto/keyspb"
+	"github.com/google/trillian/crypto/sigpb"
+	"github.com/google/trillian/merkle"
+	"github.com/google/trillian/merkle/rfc6962"
+	"github.com/google/trillian/testonly"
+	"github.com/google/trillian/types"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	tcrypto "github.com/google/trillian/crypto"
+	ktestonly "github.com/google/trillian/crypto/keys/testonly"
+)
+
+const (
+	srcID = 1
+	dstID = 2
+)
+
+func testTree(id int64, treeType trillian.TreeType) *trillian.Tree {
+	return &trillian.Tree{
+		TreeId:             id,
+		TreeState:          trillian.TreeState_ACTIVE,
+		TreeType:           treeType,
+		HashStrategy:       trillian.HashStrategy_RFC6962_SHA256,
+		HashAlgorithm:      sigpb.DigitallySigned_SHA256,
+		SignatureAlgorithm: sigpb.DigitallySigned_ECDSA,
+		PublicKey:          &keyspb.PublicKey{Der: ktestonly.MustMarshalPublicPEMToDER(testonly.DemoPublicKey)},
+	}
+}
+
+// fakeLog is a log which integrates its leaves on request.
+type fakeLog struct {
+	trillian.TrillianLogClient
+	signer     *tcrypto.Signer
+	tree       *merkle.InMemoryMerkleTree
+	leaves     map[int64]*trillian.LogLeaf
+	integrated int64
+	timestamp  uint64
+}
+
+func newFakeLog(t *testing.T) *fakeLog {
+	t.Helper()
+	key, err := pem.UnmarshalPrivateKey(testonly.DemoPrivateKey, testonly.DemoPrivateKeyPass)
+	if err != nil {
+		t.Fatalf("UnmarshalPrivateKey(): %v", err)
+	}
+	return &fakeLog{
+		signer: tcrypto.NewSigner(0, key, crypto.SHA256),
+		tree:   merkle.NewInMemoryMerkleTree(rfc6962.DefaultHasher),
+		leaves: make(map[int64]*trillian.LogLeaf),
+	}
+}
+
+// add adds leaves with the given values after the existing ones.
+func (f *fakeLog) add(values ...string) {
+	for _, v := range values {
+		idx := int64(len(f.leaves))
+		f.leaves[idx] = &trillian.LogLeaf{LeafValue: []byte(v), LeafIndex: idx}
+	}
+}
+
+// integrate integrates the contiguous leaves after the current tree.
+func (f *fakeLog) integrate() {
+	for ; f.leaves[f.integrated] != nil; f.integrated++ {
+		f.tree.AddLeaf(f.leaves[f.integrated].LeafValue)
+	}
+	f.timestamp++
+}
+
+func (f *fakeLog) GetLatestSignedLogRoot(ctx context.Context, req *trillian.GetLatestSignedLogRootRequest, opts ...grpc.CallOption) (*trillian.GetLatestSignedLogRootResponse, error) {
+	root := &types.LogRootV1{TreeSize: uint64(f.integrated), RootHash: f.tree.CurrentRoot().Hash(), TimestampNanos: f.timestamp}
+	slr, err := f.signer.SignLogRoot(root)
+	if err != nil {
+		return nil, err
+	}
+	resp := &trillian.GetLatestSignedLogRootResponse{SignedLogRoot: slr}
+	if first := req.FirstTreeSize; first > 0 && first < f.integrated {
+		resp.Proof = &trillian.Proof{Hashes: f.proof(first, f.integrated)}
+	}
+	return resp, nil
+}
+
+func (f *fakeLog) proof(first, second int64) [][]byte {
+	var hashes [][]byte
+	for _, n := range f.tree.SnapshotConsistency(first, second) {
+		hashes = append(hashes, n.Value.Hash())
+	}
+	return hashes
+}
+
+func (f *fakeLog) GetConsistencyProof(ctx context.Context, req *trillian.GetConsistencyProofRequest, opts ...grpc.CallOption) (*trillian.GetConsistencyProofResponse, error) {
+	return &trillian.GetConsistencyProofResponse{Proof: &trillian.Proof{Hashes: f.proof(req.FirstTreeSize, req.SecondTreeSize)}}, nil
+}
+
+func (f *fakeLog) GetLeavesByRange(ctx context.Context, req *trillian.GetLeavesByRangeRequest, opts ...grpc.CallOption) (*trillian.GetLeavesByRangeResponse, error) {
+	resp := &trillian.GetLeavesByRangeResponse{}
+	for i := req.StartIndex; i < req.StartIndex+req.Count && i < f.integrated; i++ {
+		resp.Leaves = append(resp.Leaves, f.leaves[i])
+	}
+	return resp, nil
+}
+
+func (f *fakeLog) AddSequencedLeaves(ctx context.Context, req *trillian.AddSequencedLeavesRequest, opts ...grpc.CallOption) (*trillian.AddSequencedLeavesResponse, error) {
+	resp := &trillian.AddSequencedLeavesResponse{}
+	for _, l := range req.Leaves {
+		s := status.New(codes.OK, "")
+		if _, ok := f.leaves[l.LeafIndex]; ok {
+			s = status.New(codes.FailedPrecondition, "conflicting LeafIndex")
+		} else {
+			f.leaves[l.LeafIndex] = l
+		}
+		resp.Results = append(resp.Results, &trillian.QueuedLogLeaf{Leaf: l, Status: s.Proto()})
+	}
+	return resp, nil
+}
+
+func newTestMirror(t *testing.T, src, dst *fakeLog, stateFile string) *mirror {
+	t.Helper()
+	m, err := newMirror(src, testTree(srcID, trillian.TreeType_LOG), dst, testTree(dstID, trillian.TreeType_PREORDERED_LOG), 2 /* batchSize */, stateFile, nil)
+	if err != nil {
+		t.Fatalf("newMirror(): %v", err)
+	}
+	return m
+}
+
+// catchUp runs steps of m, integrating dst after each, until it has caught up.
+func catchUp(ctx context.Context, t *testing.T, m *mirror, dst *fakeLog) {
+	t.Helper()
+	for i := 0; i < 100; i++ {
+		n, err := m.step(ctx)
+		if err != nil {
+			t.Fatalf("step(): %v", err)
+		}
+		dst.integrate()
+		if n == 0 && m.dst.GetRoot().TreeSize == m.src.GetRoot().TreeSize {
+			return
+		}
+	}
+	t.Fatal("mirror didn't catch up")
+}
+
+func TestMirror(t *testing.T) {
+	ctx := context.Background()
+	src, dst := newFakeLog(t), newFakeLog(t)
+	src.add("a", "b", "c", "d", "e")
+	src.integrate()
+	stateFile := filepath.Join(t.TempDir(), "state")
+
+	m := newTestMirror(t, src, dst, stateFile)
+	catchUp(ctx, t, m, dst)
+	if got, want := dst.integrated, int64(5); got != want {
+		t.Fatalf("mirror size = %d, want %d", got, want)
+	}
+
+	// Restart with leaves written to the mirror but not integrated.
+	src.add("f", "g", "h")
+	src.integrate()
+	if _, err := m.step(ctx); err != nil {
+		t.Fatalf("step(): %v", err)
+	}
+	m = newTestMirror(t, src, dst, stateFile)
+	if got, want := m.src.GetRoot().TreeSize, uint64(8); got != want {
+		t.Errorf("restored source root size = %d, want %d", got, want)
+	}
+	catchUp(ctx, t, m, dst)
+	if got, want := dst.integrated, int64(8); got != want {
+		t.Fatalf("mirror size = %d, want %d", got, want)
+	}
+	for i := int64(0); i < 8; i++ {
+		if got, want := string(dst.leaves[i].LeafValue), string(src.leaves[i].LeafValue); got != want {
+			t.Errorf("mirror leaf %d = %q, want %q", i, got, want)
+		}
+	}
+}
+
+func TestMirrorDiverged(t *testing.T) {
+	ctx := context.Background()
+	for _, size := range []int{2, 3} {
+		t.Run(fmt.Sprintf("size%d", size), func(t *testing.T) {
+			src, dst := newFakeLog(t), newFakeLog(t)
+			src.add("a", "b", "c")
+			src.integrate()
+			dst.add("a", "x", "c")
+			if size == 2 {
+				delete(dst.leaves, 2)
+			}
+			dst.integrate()
+
+			m := newTestMirror(t, src, dst, "")
+			if _, err := m.step(ctx); !errors.Is(err, errDiverged) {
+				t.Errorf("step(): %v, want %v", err, errDiverged)
+			}
+		})
+	}
+}
+
+func TestNewMirrorRejectsLog(t *testing.T) {
+	src, dst := newFakeLog(t), newFakeLog(t)
+	if _, err := newMirror(src, testTree(srcID, trillian.TreeType_LOG), dst, testTree(dstID, trillian.TreeType_LOG), 1, "", nil); err == nil {
+		t.Error("newMirror() with LOG mirror succeeded, want error")
+	}
+}
@@ -0,0 +1,186 @@
+// Copyright 2020 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// The log_replica binary serves the read-only RPCs of a source log from a
+// verified local copy of it, to take read load off the source.
+//
+// It copies the leaves of the source log into a PREORDERED_LOG tree in local
+// storage, and serves the signed roots of the source once the local tree has
+// been checked against them. Inclusion and consistency proofs are computed
+// from the local tree.
+//
+// Example usage:
+// $ ./log_replica --source_server=host:port --source_log_id=1 --source_public_key=log_key.pem --storage_system=mysql --replica_log_id=2
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/pem"
+	"errors"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/golang/glog"
+	"github.com/golang/protobuf/proto" //nolint:staticcheck
+	"github.com/google/trillian"
+	"github.com/google/trillian/client/rpcflags"
+	"github.com/google/trillian/crypto/keys/der"
+	"github.com/google/trillian/crypto/keyspb"
+	"github.com/google/trillian/extension"
+	"github.com/google/trillian/monitoring/prometheus"
+	"github.com/google/trillian/quota"
+	"github.com/google/trillian/storage"
+	"github.com/google/trillian/util"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"google.golang.org/grpc"
+
+	// Register key ProtoHandlers
+	_ "github.com/google/trillian/crypto/keys/der/proto"
+	_ "github.com/google/trillian/crypto/keys/pem/proto"
+
+	// Register supported storage providers.
+	_ "github.com/google/trillian/storage/memory"
+	_ "github.com/google/trillian/storage/mysql"
+
+	// Load hashers
+	_ "github.com/google/trillian/merkle/rfc6962"
+)
+
+var (
+	sourceServer    = flag.String("source_server", "", "Address of the Trillian server with the source log (host:port)")
+	sourceLogID     = flag.Int64("source_log_id", 0, "Tree ID of the source log")
+	sourcePublicKey = flag.String("source_public_key", "", "PEM file with the public key of the source log, which the key returned by the source must match")
+	replicaLogID    = flag.Int64("replica_log_id", 0, "Tree ID of the local PREORDERED_LOG holding the copy of the source log; if 0, a new tree is created")
+	rpcEndpoint     = flag.String("rpc_endpoint", "localhost:8090", "Endpoint for RPC requests (host:port)")
+	batchSize       = flag.Int64("batch_size", 1000, "Max number of leaves to copy per request")
+	pollInterval    = flag.Duration("poll_interval", 5*time.Second, "Time to wait between syncs with the source log")
+	metricsEndpoint = flag.String("metrics_endpoint", "", "Endpoint for serving metrics; if left empty, metrics will not be exposed")
+)
+
+func main() {
+	flag.Parse()
+	defer glog.Flush()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go util.AwaitSignal(ctx, cancel)
+
+	if err := innerMain(ctx); err != nil && err != context.Canceled {
+		glog.Exit(err)
+	}
+}
+
+func innerMain(ctx context.Context) error {
+	mf := prometheus.MetricFactory{}
+	if *metricsEndpoint != "" {
+		http.Handle("/metrics", promhttp.Handler())
+		server := http.Server{Addr: *metricsEndpoint, Handler: nil}
+		glog.Infof("Serving metrics at %v", *metricsEndpoint)
+		go func() {
+			err := server.ListenAndServe()
+			glog.Warningf("Metrics server exited: %v", err)
+		}()
+	}
+
+	dialOpts, err := rpcflags.NewClientDialOptionsFromFlags()
+	if err != nil {
+		return fmt.Errorf("failed to determine dial options: %v", err)
+	}
+	srcConn, err := grpc.Dial(*sourceServer, dialOpts...)
+	if err != nil {
+		return fmt.Errorf("failed to dial %v: %v", *sourceServer, err)
+	}
+	defer srcConn.Close()
+	srcTree, err := trillian.NewTrillianAdminClient(srcConn).GetTree(ctx, &trillian.GetTreeRequest{TreeId: *sourceLogID})
+	if err != nil {
+		return fmt.Errorf("failed to get source tree %d: %v", *sourceLogID, err)
+	}
+	if err := checkSourceKey(srcTree); err != nil {
+		return err
+	}
+
+	sp, err := storage.NewProviderFromFlags(mf)
+	if err != nil {
+		return fmt.Errorf("failed to get storage provider: %v", err)
+	}
+	defer sp.Close()
+	registry := extension.Registry{
+		AdminStorage:  sp.AdminStorage(),
+		LogStorage:    sp.LogStorage(),
+		QuotaManager:  quota.Noop(),
+		MetricFactory: mf,
+		NewKeyProto: func(ctx context.Context, spec *keyspb.Specification) (proto.Message, error) {
+			return der.NewProtoFromSpec(spec)
+		},
+	}
+
+	localID := *replicaLogID
+	if localID == 0 {
+		tree, err := createLocalTree(ctx, registry, srcTree)
+		if err != nil {
+			return err
+		}
+		localID = tree.TreeId
+		glog.Infof("Created local tree %d; pass --replica_log_id=%d to reuse it", localID, localID)
+	}
+	r, err := newReplica(ctx, trillian.NewTrillianLogClient(srcConn), srcTree, registry, localID, *batchSize)
+	if err != nil {
+		return err
+	}
+
+	lis, err := net.Listen("tcp", *rpcEndpoint)
+	if err != nil {
+		return fmt.Errorf("failed to listen on %v: %v", *rpcEndpoint, err)
+	}
+	s := grpc.NewServer()
+	trillian.RegisterTrillianLogServer(s, r)
+	go func() {
+		<-ctx.Done()
+		s.GracefulStop()
+	}()
+	go func() {
+		if err := s.Serve(lis); err != nil {
+			glog.Errorf("RPC server exited: %v", err)
+		}
+	}()
+
+	glog.Infof("Serving log %d from %v at %v, using local tree %d", *sourceLogID, *sourceServer, *rpcEndpoint, localID)
+	return r.Run(ctx, *pollInterval)
+}
+
+// checkSourceKey checks the key of the source tree against the
+// --source_public_key file. The tree comes from the source itself, so its key
+// proves nothing unless it is known to be the log's.
+func checkSourceKey(tree *trillian.Tree) error {
+	if *sourcePublicKey == "" {
+		return errors.New("--source_public_key must be set")
+	}
+	data, err := ioutil.ReadFile(*sourcePublicKey)
+	if err != nil {
+		return fmt.Errorf("failed to read source public key: %v", err)
+	}
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return fmt.Errorf("no PEM block in %s", *sourcePublicKey)
+	}
+	if !bytes.Equal(block.Bytes, tree.GetPublicKey().GetDer()) {
+		return fmt.Errorf("source tree %d has a different public key than %s", tree.TreeId, *sourcePublicKey)
+	}
+	return nil
+}
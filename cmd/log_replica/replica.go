@@ -0,0 +1,559 @@
+// Copyright 2020 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/golang/glog"
+	"github.com/golang/protobuf/ptypes"
+	"github.com/google/trillian"
+	"github.com/google/trillian/client"
+	"github.com/google/trillian/crypto/keyspb"
+	"github.com/google/trillian/crypto/sigpb"
+	"github.com/google/trillian/extension"
+	"github.com/google/trillian/log"
+	"github.com/google/trillian/merkle"
+	"github.com/google/trillian/merkle/compact"
+	"github.com/google/trillian/monitoring"
+	"github.com/google/trillian/quota"
+	"github.com/google/trillian/server"
+	"github.com/google/trillian/server/admin"
+	"github.com/google/trillian/storage"
+	"github.com/google/trillian/storage/tree"
+	"github.com/google/trillian/trees"
+	"github.com/google/trillian/types"
+	"github.com/google/trillian/util/clock"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+var (
+	once           sync.Once
+	sourceTreeSize monitoring.Gauge
+	servedTreeSize monitoring.Gauge
+	leavesCopied   monitoring.Counter
+	syncFailures   monitoring.Counter
+)
+
+func createMetrics(mf monitoring.MetricFactory) {
+	if mf == nil {
+		mf = monitoring.InertMetricFactory{}
+	}
+	sourceTreeSize = mf.NewGauge("replica_source_tree_size", "Size of the latest verified source tree", monitoring.TreeIDLabel)
+	servedTreeSize = mf.NewGauge("replica_served_tree_size", "Size of the source tree served by the replica", monitoring.TreeIDLabel)
+	leavesCopied = mf.NewCounter("replica_leaves_copied", "Number of leaves written to the local tree", monitoring.TreeIDLabel)
+	syncFailures = mf.NewCounter("replica_sync_failures", "Number of failed attempts to sync with the source", monitoring.TreeIDLabel)
+}
+
+// maxTreeDepth is the depth of log trees, used for tree.NodeID creation.
+const maxTreeDepth = 64
+
+// errDiverged is returned when the local tree doesn't match the source.
+// The replica can't recover from this, as its leaves can't be removed.
+var errDiverged = errors.New("replica has diverged from the source")
+
+// replica keeps a copy of a source log in a local PREORDERED_LOG tree, and
+// serves the read-only RPCs of the source log from it.
+//
+// The replica serves the latest signed root of the source which the local
+// tree has been checked against: the local root at the same size has the
+// same hash. Proofs and leaves are computed from the local tree at sizes up
+// to this root only, so leaves copied from the source are not served before
+// they are covered by a root signed by the source. Each batch of leaves is
+// verified against the source root before it is stored, so a source serving
+// the wrong leaves can't make the local tree diverge from it.
+//
+// Requests name the source log ID, and responses carry the signed roots of
+// the source, so clients can verify them as if they came from the source.
+type replica struct {
+	trillian.UnimplementedTrillianLogServer
+
+	src       *client.LogClient
+	srcLog    trillian.TrillianLogClient
+	tree      *trillian.Tree
+	fact      *compact.RangeFactory
+	ls        storage.LogStorage
+	local     *server.TrillianLogRPCServer
+	seq       *log.Sequencer
+	v         merkle.LogVerifier
+	batchSize int64
+	label     string
+
+	mu     sync.RWMutex
+	served *trillian.SignedLogRoot
+	root   types.LogRootV1
+}
+
+// createLocalTree creates a PREORDERED_LOG tree for a replica of srcTree.
+// The key of the tree is generated by the registry, and only signs the roots
+// kept in storage: the replica serves the roots signed by the source.
+func createLocalTree(ctx context.Context, registry extension.Registry, srcTree *trillian.Tree) (*trillian.Tree, error) {
+	tree, err := admin.New(registry, []trillian.TreeType{trillian.TreeType_PREORDERED_LOG}).CreateTree(ctx, &trillian.CreateTreeRequest{
+		Tree: &trillian.Tree{
+			TreeState:          trillian.TreeState_ACTIVE,
+			TreeType:           trillian.TreeType_PREORDERED_LOG,
+			HashStrategy:       srcTree.HashStrategy,
+			HashAlgorithm:      sigpb.DigitallySigned_SHA256,
+			SignatureAlgorithm: sigpb.DigitallySigned_ECDSA,
+			DisplayName:        fmt.Sprintf("Replica of log %d", srcTree.TreeId),
+			MaxRootDuration:    ptypes.DurationProto(0),
+		},
+		KeySpec: &keyspb.Specification{
+			Params: &keyspb.Specification_EcdsaParams{EcdsaParams: &keyspb.Specification_ECDSA{}},
+		},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create local tree: %v", err)
+	}
+	return tree, nil
+}
+
+// newReplica returns a replica of the log srcTree, read through srcLog, in
+// the local PREORDERED_LOG tree localID of the registry's storage. The local
+// tree is initialised if needed, but nothing is served until the first call
+// to sync succeeds.
+func newReplica(ctx context.Context, srcLog trillian.TrillianLogClient, srcTree *trillian.Tree, registry extension.Registry, localID, batchSize int64) (*replica, error) {
+	if batchSize <= 0 {
+		return nil, fmt.Errorf("batch size %d must be positive", batchSize)
+	}
+	tree, err := trees.GetTree(ctx, registry.AdminStorage, localID, trees.NewGetOpts(trees.SequenceLog, trillian.TreeType_PREORDERED_LOG))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get local tree %d: %v", localID, err)
+	}
+	if tree.HashStrategy != srcTree.HashStrategy {
+		return nil, fmt.Errorf("local tree %d has hash strategy %v, want %v", localID, tree.HashStrategy, srcTree.HashStrategy)
+	}
+	src, err := client.NewFromTree(srcLog, srcTree, types.LogRootV1{})
+	if err != nil {
+		return nil, err
+	}
+	signer, err := trees.Signer(ctx, tree)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create signer for local tree: %v", err)
+	}
+	qm := registry.QuotaManager
+	if qm == nil {
+		qm = quota.Noop()
+	}
+
+	local := server.NewTrillianLogRPCServer(registry, clock.System)
+	if _, err := local.InitLog(ctx, &trillian.InitLogRequest{LogId: localID}); status.Code(err) != codes.AlreadyExists && err != nil {
+		return nil, fmt.Errorf("failed to init local tree: %v", err)
+	}
+
+	once.Do(func() { createMetrics(registry.MetricFactory) })
+	return &replica{
+		src:       src,
+		srcLog:    srcLog,
+		tree:      tree,
+		fact:      &compact.RangeFactory{Hash: src.Hasher.HashChildren},
+		ls:        registry.LogStorage,
+		local:     local,
+		seq:       log.NewSequencer(src.Hasher, clock.System, registry.LogStorage, signer, registry.MetricFactory, qm),
+		v:         merkle.NewLogVerifier(src.Hasher),
+		batchSize: batchSize,
+		label:     strconv.FormatInt(srcTree.TreeId, 10),
+	}, nil
+}
+
+// Run syncs the replica until ctx is done or the replica diverges from the
+// source. It waits for pollInterval after each sync.
+func (r *replica) Run(ctx context.Context, pollInterval time.Duration) error {
+	for {
+		err := r.sync(ctx)
+		switch {
+		case errors.Is(err, errDiverged):
+			return err
+		case err != nil:
+			syncFailures.Inc(r.label)
+			glog.Warningf("Replica sync failed: %v", err)
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(pollInterval):
+		}
+	}
+}
+
+// sync verifies the latest root of the source, copies and verifies the leaves
+// the local tree is missing and integrates them, and starts serving the source root
+// once the local tree matches it.
+func (r *replica) sync(ctx context.Context) error {
+	localRoot, err := r.localRoot(ctx)
+	if err != nil {
+		return err
+	}
+	resp, err := r.srcLog.GetLatestSignedLogRoot(ctx, &trillian.GetLatestSignedLogRootRequest{
+		LogId:         r.src.LogID,
+		FirstTreeSize: int64(localRoot.TreeSize),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to get source root: %v", err)
+	}
+	// Only check the signature here. The trusted state is the local tree,
+	// which check verifies the source root against.
+	srcRoot, err := r.src.VerifyRoot(&types.LogRootV1{}, resp.GetSignedLogRoot(), nil)
+	if err != nil {
+		return fmt.Errorf("failed to verify source root: %v", err)
+	}
+	if err := r.check(localRoot, srcRoot, resp.GetProof().GetHashes()); err != nil {
+		return err
+	}
+	sourceTreeSize.Set(float64(srcRoot.TreeSize), r.label)
+
+	if localRoot.TreeSize < srcRoot.TreeSize {
+		cr, err := r.localRange(ctx, localRoot)
+		if err != nil {
+			return err
+		}
+		for localRoot.TreeSize < srcRoot.TreeSize {
+			if cr, err = r.copyBatch(ctx, cr, srcRoot); err != nil {
+				return err
+			}
+			if localRoot, err = r.localRoot(ctx); err != nil {
+				return err
+			}
+			if localRoot.TreeSize != cr.End() {
+				return fmt.Errorf("local tree has size %d after copying leaves up to %d", localRoot.TreeSize, cr.End())
+			}
+		}
+	}
+	if !bytes.Equal(localRoot.RootHash, srcRoot.RootHash) {
+		return fmt.Errorf("%w: local root %x != source root %x at size %d", errDiverged, localRoot.RootHash, srcRoot.RootHash, srcRoot.TreeSize)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.served == nil || srcRoot.TreeSize > r.root.TreeSize || srcRoot.TimestampNanos > r.root.TimestampNanos {
+		r.served, r.root = resp.SignedLogRoot, *srcRoot
+		servedTreeSize.Set(float64(srcRoot.TreeSize), r.label)
+	}
+	return nil
+}
+
+// check verifies that the local root is the root of the source at the same
+// size, using a consistency proof from the source if the local tree is
+// behind.
+func (r *replica) check(localRoot, srcRoot *types.LogRootV1, proof [][]byte) error {
+	switch {
+	case localRoot.TreeSize == 0:
+		return nil
+	case localRoot.TreeSize > srcRoot.TreeSize:
+		// The source may be served by several servers, which can lag behind
+		// each other, so this is retried.
+		return fmt.Errorf("source size %d is smaller than local size %d", srcRoot.TreeSize, localRoot.TreeSize)
+	case localRoot.TreeSize == srcRoot.TreeSize:
+		if !bytes.Equal(localRoot.RootHash, srcRoot.RootHash) {
+			return fmt.Errorf("%w: local root %x != source root %x at size %d", errDiverged, localRoot.RootHash, srcRoot.RootHash, srcRoot.TreeSize)
+		}
+		return nil
+	}
+	if err := r.v.VerifyConsistencyProof(int64(localRoot.TreeSize), int64(srcRoot.TreeSize), localRoot.RootHash, srcRoot.RootHash, proof); err != nil {
+		var mismatch merkle.RootMismatchError
+		if errors.As(err, &mismatch) {
+			return fmt.Errorf("%w: local root at size %d: %v", errDiverged, localRoot.TreeSize, err)
+		}
+		return fmt.Errorf("failed to verify source consistency proof: %v", err)
+	}
+	return nil
+}
+
+// copyBatch copies the next batch of source leaves after the compact range
+// of the local tree, up to the size of the source root, and integrates them.
+// The leaves are only added to the local tree once they are verified against
+// the source root, using a consistency proof from the source if the batch
+// doesn't reach its size. It returns the compact range of the new local tree.
+func (r *replica) copyBatch(ctx context.Context, cr *compact.Range, srcRoot *types.LogRootV1) (*compact.Range, error) {
+	start, end := int64(cr.End()), int64(srcRoot.TreeSize)
+	if end > start+r.batchSize {
+		end = start + r.batchSize
+	}
+	srcLeaves, err := r.src.ListByIndex(ctx, start, end-start)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get source leaves [%d, %d): %v", start, end, err)
+	}
+	if got, want := int64(len(srcLeaves)), end-start; got != want {
+		return nil, fmt.Errorf("got %d source leaves from index %d, want %d", got, start, want)
+	}
+	next, err := r.fact.NewRange(cr.Begin(), cr.End(), append([][]byte(nil), cr.Hashes()...))
+	if err != nil {
+		return nil, err
+	}
+	leaves := make([]*trillian.LogLeaf, 0, len(srcLeaves))
+	for i, l := range srcLeaves {
+		if want := start + int64(i); l.LeafIndex != want {
+			return nil, fmt.Errorf("got source leaf %d, want %d", l.LeafIndex, want)
+		}
+		// The leaf hashes are computed locally, so that they match the
+		// verified leaf values.
+		leaf := r.src.BuildLeaf(l.LeafValue)
+		leaf.ExtraData = l.ExtraData
+		leaf.LeafIndex = l.LeafIndex
+		leaf.LeafIdentityHash = l.LeafIdentityHash
+		if len(leaf.LeafIdentityHash) == 0 {
+			leaf.LeafIdentityHash = leaf.MerkleLeafHash
+		}
+		if err := next.Append(leaf.MerkleLeafHash, nil); err != nil {
+			return nil, err
+		}
+		leaves = append(leaves, leaf)
+	}
+	if err := r.verifyRange(ctx, next, srcRoot); err != nil {
+		return nil, err
+	}
+
+	res, err := r.ls.AddSequencedLeaves(ctx, r.tree, leaves, clock.System.Now())
+	if err != nil {
+		return nil, fmt.Errorf("failed to add leaves to local tree: %v", err)
+	}
+	for i, q := range res {
+		switch c := codes.Code(q.GetStatus().GetCode()); c {
+		case codes.OK, codes.AlreadyExists, codes.FailedPrecondition:
+			// Leaves written before a restart, but not integrated, are
+			// reported as duplicates. If they differ from the source, the
+			// local root won't match it.
+		default:
+			return nil, fmt.Errorf("failed to add leaf %d to local tree: %v: %s", leaves[i].LeafIndex, c, q.GetStatus().GetMessage())
+		}
+	}
+	n, err := r.seq.IntegrateBatch(ctx, r.tree, int(r.batchSize), 0, 0)
+	if err != nil {
+		return nil, fmt.Errorf("failed to integrate local leaves: %v", err)
+	}
+	if n == 0 {
+		return nil, fmt.Errorf("no local leaves integrated from index %d", start)
+	}
+	leavesCopied.Add(float64(n), r.label)
+	return next, nil
+}
+
+// verifyRange checks that the leaves hashed into cr are the first leaves of
+// the source root.
+func (r *replica) verifyRange(ctx context.Context, cr *compact.Range, srcRoot *types.LogRootV1) error {
+	hash, err := cr.GetRootHash(nil)
+	if err != nil {
+		return err
+	}
+	size := int64(cr.End())
+	var proof [][]byte
+	if size < int64(srcRoot.TreeSize) {
+		resp, err := r.srcLog.GetConsistencyProof(ctx, &trillian.GetConsistencyProofRequest{
+			LogId:          r.src.LogID,
+			FirstTreeSize:  size,
+			SecondTreeSize: int64(srcRoot.TreeSize),
+		})
+		if err != nil {
+			return fmt.Errorf("failed to get source consistency proof: %v", err)
+		}
+		proof = resp.GetProof().GetHashes()
+	}
+	if err := r.v.VerifyConsistencyProof(size, int64(srcRoot.TreeSize), hash, srcRoot.RootHash, proof); err != nil {
+		return fmt.Errorf("source leaves [0, %d) don't match the source root of size %d: %v", size, srcRoot.TreeSize, err)
+	}
+	return nil
+}
+
+// localRange returns the compact range of the local tree at root.
+func (r *replica) localRange(ctx context.Context, root *types.LogRootV1) (*compact.Range, error) {
+	if root.TreeSize == 0 {
+		return r.fact.NewEmptyRange(0), nil
+	}
+	ids := compact.RangeNodes(0, root.TreeSize)
+	nodeIDs := make([]tree.NodeID, 0, len(ids))
+	for _, id := range ids {
+		nodeID, err := tree.NewNodeIDForTreeCoords(int64(id.Level), int64(id.Index), maxTreeDepth)
+		if err != nil {
+			return nil, err
+		}
+		nodeIDs = append(nodeIDs, nodeID)
+	}
+
+	tx, err := r.ls.SnapshotForTree(ctx, r.tree)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read local tree: %v", err)
+	}
+	defer tx.Close()
+	nodes, err := tx.GetMerkleNodes(ctx, int64(root.Revision), nodeIDs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read local tree nodes: %v", err)
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+	if got, want := len(nodes), len(nodeIDs); got != want {
+		return nil, fmt.Errorf("got %d local tree nodes at revision %d, want %d", got, root.Revision, want)
+	}
+	hashes := make([][]byte, 0, len(nodes))
+	for i, n := range nodes {
+		if !n.NodeID.Equivalent(nodeIDs[i]) {
+			return nil, fmt.Errorf("got local tree node %v, want %v", n.NodeID, nodeIDs[i])
+		}
+		hashes = append(hashes, n.Hash)
+	}
+	cr, err := r.fact.NewRange(0, root.TreeSize, hashes)
+	if err != nil {
+		return nil, err
+	}
+	// The range must match the root, or the verified leaves wouldn't follow
+	// on from the local ones.
+	hash, err := cr.GetRootHash(nil)
+	if err != nil {
+		return nil, err
+	}
+	if !bytes.Equal(hash, root.RootHash) {
+		return nil, fmt.Errorf("local tree nodes have root hash %x, want %x", hash, root.RootHash)
+	}
+	return cr, nil
+}
+
+// localRoot returns the latest root of the local tree.
+func (r *replica) localRoot(ctx context.Context) (*types.LogRootV1, error) {
+	tx, err := r.ls.SnapshotForTree(ctx, r.tree)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read local root: %v", err)
+	}
+	defer tx.Close()
+	slr, err := tx.LatestSignedLogRoot(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read local root: %v", err)
+	}
+	var root types.LogRootV1
+	if err := root.UnmarshalBinary(slr.LogRoot); err != nil {
+		return nil, fmt.Errorf("failed to parse local root: %v", err)
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+	return &root, nil
+}
+
+// servedRoot returns the source root served for requests to logID.
+func (r *replica) servedRoot(logID int64) (*trillian.SignedLogRoot, uint64, error) {
+	if logID != r.src.LogID {
+		return nil, 0, status.Errorf(codes.NotFound, "log %d is not replicated here", logID)
+	}
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	if r.served == nil {
+		return nil, 0, status.Errorf(codes.Unavailable, "replica of log %d has not been synced yet", logID)
+	}
+	return r.served, r.root.TreeSize, nil
+}
+
+// GetLatestSignedLogRoot returns the served source root, with a consistency
+// proof from the requested size.
+func (r *replica) GetLatestSignedLogRoot(ctx context.Context, req *trillian.GetLatestSignedLogRootRequest) (*trillian.GetLatestSignedLogRootResponse, error) {
+	slr, size, err := r.servedRoot(req.LogId)
+	if err != nil {
+		return nil, err
+	}
+	resp := &trillian.GetLatestSignedLogRootResponse{SignedLogRoot: slr}
+	if req.FirstTreeSize == 0 {
+		return resp, nil
+	}
+	proof, err := r.local.GetConsistencyProof(ctx, &trillian.GetConsistencyProofRequest{
+		LogId:          r.tree.TreeId,
+		FirstTreeSize:  req.FirstTreeSize,
+		SecondTreeSize: int64(size),
+	})
+	if err != nil {
+		return nil, err
+	}
+	resp.Proof = proof.Proof
+	return resp, nil
+}
+
+// GetConsistencyProof returns a consistency proof computed from the local
+// tree, if the second size is not beyond the served root.
+func (r *replica) GetConsistencyProof(ctx context.Context, req *trillian.GetConsistencyProofRequest) (*trillian.GetConsistencyProofResponse, error) {
+	slr, size, err := r.servedRoot(req.LogId)
+	if err != nil {
+		return nil, err
+	}
+	if uint64(req.SecondTreeSize) > size {
+		return &trillian.GetConsistencyProofResponse{SignedLogRoot: slr}, nil
+	}
+	resp, err := r.local.GetConsistencyProof(ctx, &trillian.GetConsistencyProofRequest{
+		LogId:          r.tree.TreeId,
+		FirstTreeSize:  req.FirstTreeSize,
+		SecondTreeSize: req.SecondTreeSize,
+	})
+	if err != nil {
+		return nil, err
+	}
+	resp.SignedLogRoot = slr
+	return resp, nil
+}
+
+// GetInclusionProof returns an inclusion proof computed from the local tree,
+// if the tree size is not beyond the served root.
+func (r *replica) GetInclusionProof(ctx context.Context, req *trillian.GetInclusionProofRequest) (*trillian.GetInclusionProofResponse, error) {
+	slr, size, err := r.servedRoot(req.LogId)
+	if err != nil {
+		return nil, err
+	}
+	if uint64(req.TreeSize) > size {
+		return &trillian.GetInclusionProofResponse{SignedLogRoot: slr}, nil
+	}
+	resp, err := r.local.GetInclusionProof(ctx, &trillian.GetInclusionProofRequest{
+		LogId:     r.tree.TreeId,
+		LeafIndex: req.LeafIndex,
+		TreeSize:  req.TreeSize,
+	})
+	if err != nil {
+		return nil, err
+	}
+	resp.SignedLogRoot = slr
+	return resp, nil
+}
+
+// GetLeavesByRange returns the requested leaves of the local tree which are
+// covered by the served root.
+func (r *replica) GetLeavesByRange(ctx context.Context, req *trillian.GetLeavesByRangeRequest) (*trillian.GetLeavesByRangeResponse, error) {
+	slr, size, err := r.servedRoot(req.LogId)
+	if err != nil {
+		return nil, err
+	}
+	count := req.Count
+	if end := uint64(req.StartIndex) + uint64(count); req.StartIndex >= 0 && count > 0 && end > size {
+		if uint64(req.StartIndex) >= size {
+			return &trillian.GetLeavesByRangeResponse{SignedLogRoot: slr}, nil
+		}
+		count = int64(size) - req.StartIndex
+	}
+	resp, err := r.local.GetLeavesByRange(ctx, &trillian.GetLeavesByRangeRequest{
+		LogId:      r.tree.TreeId,
+		StartIndex: req.StartIndex,
+		Count:      count,
+	})
+	if err != nil {
+		return nil, err
+	}
+	for i, l := range resp.Leaves {
+		if want := req.StartIndex + int64(i); l.LeafIndex != want {
+			return nil, status.Errorf(codes.Internal, "local leaf %d has index %d", want, l.LeafIndex)
+		}
+	}
+	resp.SignedLogRoot = slr
+	return resp, nil
+}
@@ -0,0 +1,393 @@
+// Copyright 2020 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bytes"
+	"context"
+	"crypto"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/golang/protobuf/proto" //nolint:staticcheck
+	"github.com/google/trillian"
+	"github.com/google/trillian/client"
+	"github.com/google/trillian/crypto/keys/der"
+	"github.com/google/trillian/crypto/keys/pem"
+	"github.com/google/trillian/crypto/keyspb"
+	"github.com/google/trillian/crypto/sigpb"
+	"github.com/google/trillian/extension"
+	"github.com/google/trillian/merkle"
+	"github.com/google/trillian/merkle/rfc6962"
+	"github.com/google/trillian/monitoring"
+	"github.com/google/trillian/quota"
+	"github.com/google/trillian/storage/memory"
+	"github.com/google/trillian/testonly"
+	"github.com/google/trillian/types"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	tcrypto "github.com/google/trillian/crypto"
+	ktestonly "github.com/google/trillian/crypto/keys/testonly"
+)
+
+const srcID = 1
+
+func srcTree() *trillian.Tree {
+	return &trillian.Tree{
+		TreeId:             srcID,
+		TreeState:          trillian.TreeState_ACTIVE,
+		TreeType:           trillian.TreeType_LOG,
+		HashStrategy:       trillian.HashStrategy_RFC6962_SHA256,
+		HashAlgorithm:      sigpb.DigitallySigned_SHA256,
+		SignatureAlgorithm: sigpb.DigitallySigned_ECDSA,
+		PublicKey:          &keyspb.PublicKey{Der: ktestonly.MustMarshalPublicPEMToDER(testonly.DemoPublicKey)},
+	}
+}
+
+// fakeLog is a source log which integrates its leaves on request.
+type fakeLog struct {
+	trillian.TrillianLogClient
+	signer  *tcrypto.Signer
+	tree    *merkle.InMemoryMerkleTree
+	leaves  []string
+	tamper  map[int64]string
+	badSigs bool
+}
+
+func newFakeLog(t *testing.T) *fakeLog {
+	t.Helper()
+	key, err := pem.UnmarshalPrivateKey(testonly.DemoPrivateKey, testonly.DemoPrivateKeyPass)
+	if err != nil {
+		t.Fatalf("UnmarshalPrivateKey(): %v", err)
+	}
+	return &fakeLog{
+		signer: tcrypto.NewSigner(0, key, crypto.SHA256),
+		tree:   merkle.NewInMemoryMerkleTree(rfc6962.DefaultHasher),
+		tamper: make(map[int64]string),
+	}
+}
+
+// add adds and integrates leaves with the given values.
+func (f *fakeLog) add(values ...string) {
+	for _, v := range values {
+		f.leaves = append(f.leaves, v)
+		f.tree.AddLeaf([]byte(v))
+	}
+}
+
+func (f *fakeLog) size() int64 {
+	return int64(len(f.leaves))
+}
+
+// root returns the root of the log at the given size.
+func (f *fakeLog) root(size int64) *types.LogRootV1 {
+	hash := rfc6962.DefaultHasher.EmptyRoot()
+	if size > 0 {
+		hash = f.tree.RootAtSnapshot(size).Hash()
+	}
+	return &types.LogRootV1{TreeSize: uint64(size), RootHash: hash, TimestampNanos: uint64(size)}
+}
+
+func (f *fakeLog) signedRoot() (*trillian.SignedLogRoot, error) {
+	slr, err := f.signer.SignLogRoot(f.root(f.size()))
+	if err != nil {
+		return nil, err
+	}
+	if f.badSigs {
+		slr.LogRootSignature[10] ^= 1
+	}
+	return slr, nil
+}
+
+func (f *fakeLog) GetLatestSignedLogRoot(ctx context.Context, req *trillian.GetLatestSignedLogRootRequest, opts ...grpc.CallOption) (*trillian.GetLatestSignedLogRootResponse, error) {
+	slr, err := f.signedRoot()
+	if err != nil {
+		return nil, err
+	}
+	resp := &trillian.GetLatestSignedLogRootResponse{SignedLogRoot: slr}
+	if first := req.FirstTreeSize; first > 0 && first < f.size() {
+		for _, n := range f.tree.SnapshotConsistency(first, f.size()) {
+			resp.Proof = &trillian.Proof{Hashes: append(resp.GetProof().GetHashes(), n.Value.Hash())}
+		}
+	}
+	return resp, nil
+}
+
+func (f *fakeLog) GetLeavesByRange(ctx context.Context, req *trillian.GetLeavesByRangeRequest, opts ...grpc.CallOption) (*trillian.GetLeavesByRangeResponse, error) {
+	resp := &trillian.GetLeavesByRangeResponse{}
+	for i := req.StartIndex; i < req.StartIndex+req.Count && i < f.size(); i++ {
+		v := f.leaves[i]
+		if t, ok := f.tamper[i]; ok {
+			v = t
+		}
+		resp.Leaves = append(resp.Leaves, &trillian.LogLeaf{LeafValue: []byte(v), LeafIndex: i})
+	}
+	return resp, nil
+}
+
+func (f *fakeLog) GetConsistencyProof(ctx context.Context, req *trillian.GetConsistencyProofRequest, opts ...grpc.CallOption) (*trillian.GetConsistencyProofResponse, error) {
+	if req.SecondTreeSize > f.size() {
+		return nil, status.Errorf(codes.InvalidArgument, "tree size %d beyond %d", req.SecondTreeSize, f.size())
+	}
+	resp := &trillian.GetConsistencyProofResponse{Proof: &trillian.Proof{}}
+	for _, n := range f.tree.SnapshotConsistency(req.FirstTreeSize, req.SecondTreeSize) {
+		resp.Proof.Hashes = append(resp.Proof.Hashes, n.Value.Hash())
+	}
+	return resp, nil
+}
+
+func newRegistry() extension.Registry {
+	ts := memory.NewTreeStorage()
+	return extension.Registry{
+		AdminStorage:  memory.NewAdminStorage(ts),
+		LogStorage:    memory.NewLogStorage(ts, nil),
+		QuotaManager:  quota.Noop(),
+		MetricFactory: monitoring.InertMetricFactory{},
+		NewKeyProto: func(ctx context.Context, spec *keyspb.Specification) (proto.Message, error) {
+			return der.NewProtoFromSpec(spec)
+		},
+	}
+}
+
+// newTestReplica returns a replica of src, in a new local tree of registry
+// unless localID is set.
+func newTestReplica(ctx context.Context, t *testing.T, src *fakeLog, registry extension.Registry, localID int64) *replica {
+	t.Helper()
+	if localID == 0 {
+		tree, err := createLocalTree(ctx, registry, srcTree())
+		if err != nil {
+			t.Fatalf("createLocalTree(): %v", err)
+		}
+		localID = tree.TreeId
+	}
+	r, err := newReplica(ctx, src, srcTree(), registry, localID, 2 /* batchSize */)
+	if err != nil {
+		t.Fatalf("newReplica(): %v", err)
+	}
+	return r
+}
+
+// checkServed checks that r serves the latest root of src, and verifiable
+// proofs and leaves up to its size.
+func checkServed(ctx context.Context, t *testing.T, r *replica, src *fakeLog) {
+	t.Helper()
+	v, err := client.NewLogVerifierFromTree(srcTree())
+	if err != nil {
+		t.Fatalf("NewLogVerifierFromTree(): %v", err)
+	}
+	size := src.size()
+	want := src.root(size)
+
+	for first := int64(0); first <= size; first++ {
+		resp, err := r.GetLatestSignedLogRoot(ctx, &trillian.GetLatestSignedLogRootRequest{LogId: srcID, FirstTreeSize: first})
+		if err != nil {
+			t.Fatalf("GetLatestSignedLogRoot(%d): %v", first, err)
+		}
+		root, err := v.VerifyRoot(src.root(first), resp.SignedLogRoot, resp.GetProof().GetHashes())
+		if err != nil {
+			t.Fatalf("VerifyRoot(%d): %v", first, err)
+		}
+		if root.TreeSize != want.TreeSize || !bytes.Equal(root.RootHash, want.RootHash) {
+			t.Fatalf("GetLatestSignedLogRoot(%d) = %+v, want %+v", first, root, want)
+		}
+	}
+
+	for i := int64(0); i < size; i++ {
+		resp, err := r.GetInclusionProof(ctx, &trillian.GetInclusionProofRequest{LogId: srcID, LeafIndex: i, TreeSize: size})
+		if err != nil {
+			t.Fatalf("GetInclusionProof(%d): %v", i, err)
+		}
+		if err := v.VerifyInclusionAtIndex(want, []byte(src.leaves[i]), i, resp.GetProof().GetHashes()); err != nil {
+			t.Errorf("VerifyInclusionAtIndex(%d): %v", i, err)
+		}
+	}
+	if size > 1 {
+		resp, err := r.GetConsistencyProof(ctx, &trillian.GetConsistencyProofRequest{LogId: srcID, FirstTreeSize: 1, SecondTreeSize: size})
+		if err != nil {
+			t.Fatalf("GetConsistencyProof(): %v", err)
+		}
+		if _, err := v.VerifyRoot(src.root(1), resp.SignedLogRoot, resp.GetProof().GetHashes()); err != nil {
+			t.Errorf("VerifyRoot() with consistency proof: %v", err)
+		}
+	}
+
+	resp, err := r.GetLeavesByRange(ctx, &trillian.GetLeavesByRangeRequest{LogId: srcID, StartIndex: 0, Count: size + 10})
+	if err != nil {
+		t.Fatalf("GetLeavesByRange(): %v", err)
+	}
+	if got := int64(len(resp.Leaves)); got != size {
+		t.Fatalf("GetLeavesByRange() returned %d leaves, want %d", got, size)
+	}
+	for i, l := range resp.Leaves {
+		if got, want := string(l.LeafValue), src.leaves[i]; got != want {
+			t.Errorf("GetLeavesByRange() leaf %d = %q, want %q", i, got, want)
+		}
+	}
+}
+
+// checkBeyond checks that r serves nothing at or beyond size.
+func checkBeyond(ctx context.Context, t *testing.T, r *replica, size int64) {
+	t.Helper()
+	incl, err := r.GetInclusionProof(ctx, &trillian.GetInclusionProofRequest{LogId: srcID, LeafIndex: 0, TreeSize: size + 1})
+	if err != nil || incl.Proof != nil {
+		t.Errorf("GetInclusionProof() beyond size %d = %v, %v; want no proof", size, incl, err)
+	}
+	cons, err := r.GetConsistencyProof(ctx, &trillian.GetConsistencyProofRequest{LogId: srcID, FirstTreeSize: 1, SecondTreeSize: size + 1})
+	if err != nil || cons.Proof != nil {
+		t.Errorf("GetConsistencyProof() beyond size %d = %v, %v; want no proof", size, cons, err)
+	}
+	leaves, err := r.GetLeavesByRange(ctx, &trillian.GetLeavesByRangeRequest{LogId: srcID, StartIndex: size, Count: 1})
+	if err != nil || len(leaves.Leaves) != 0 {
+		t.Errorf("GetLeavesByRange() beyond size %d = %v, %v; want no leaves", size, leaves, err)
+	}
+}
+
+func TestReplica(t *testing.T) {
+	ctx := context.Background()
+	src := newFakeLog(t)
+	src.add("a", "b", "c", "d", "e")
+	registry := newRegistry()
+	r := newTestReplica(ctx, t, src, registry, 0)
+
+	if _, err := r.GetLatestSignedLogRoot(ctx, &trillian.GetLatestSignedLogRootRequest{LogId: srcID}); status.Code(err) != codes.Unavailable {
+		t.Errorf("GetLatestSignedLogRoot() before sync: %v, want code %v", err, codes.Unavailable)
+	}
+	if err := r.sync(ctx); err != nil {
+		t.Fatalf("sync(): %v", err)
+	}
+	checkServed(ctx, t, r, src)
+	checkBeyond(ctx, t, r, 5)
+	if _, err := r.GetLatestSignedLogRoot(ctx, &trillian.GetLatestSignedLogRootRequest{LogId: r.tree.TreeId}); status.Code(err) != codes.NotFound {
+		t.Errorf("GetLatestSignedLogRoot() of local tree: %v, want code %v", err, codes.NotFound)
+	}
+
+	src.add("f", "g", "h")
+	if err := r.sync(ctx); err != nil {
+		t.Fatalf("sync(): %v", err)
+	}
+	checkServed(ctx, t, r, src)
+
+	// A restarted replica checks its local tree before serving it again.
+	r = newTestReplica(ctx, t, src, registry, r.tree.TreeId)
+	if _, err := r.GetLatestSignedLogRoot(ctx, &trillian.GetLatestSignedLogRootRequest{LogId: srcID}); status.Code(err) != codes.Unavailable {
+		t.Errorf("GetLatestSignedLogRoot() before sync: %v, want code %v", err, codes.Unavailable)
+	}
+	src.add("i")
+	if err := r.sync(ctx); err != nil {
+		t.Fatalf("sync(): %v", err)
+	}
+	checkServed(ctx, t, r, src)
+}
+
+func TestReplicaUnverified(t *testing.T) {
+	ctx := context.Background()
+	src := newFakeLog(t)
+	src.add("a", "b", "c")
+	r := newTestReplica(ctx, t, src, newRegistry(), 0)
+	if err := r.sync(ctx); err != nil {
+		t.Fatalf("sync(): %v", err)
+	}
+
+	// Roots with bad signatures are not trusted, and nothing is copied.
+	src.add("d", "e")
+	src.badSigs = true
+	if err := r.sync(ctx); err == nil || errors.Is(err, errDiverged) {
+		t.Errorf("sync() with bad signature: %v, want non-divergence error", err)
+	}
+	checkBeyond(ctx, t, r, 3)
+
+	// Leaves which don't match the source root are never stored locally,
+	// whether or not their batch reaches the size of the root.
+	src.badSigs = false
+	src.add("f")
+	for _, tc := range []struct {
+		index    int64
+		wantSize uint64
+	}{
+		{index: 3, wantSize: 3},
+		{index: 5, wantSize: 5}, // The batch before the tampered leaf is copied.
+	} {
+		src.tamper[tc.index] = "x"
+		if err := r.sync(ctx); err == nil || errors.Is(err, errDiverged) {
+			t.Errorf("sync() with tampered leaf %d: %v, want non-divergence error", tc.index, err)
+		}
+		delete(src.tamper, tc.index)
+		if root, err := r.localRoot(ctx); err != nil || root.TreeSize != tc.wantSize {
+			t.Fatalf("localRoot() with tampered leaf %d = %v, %v; want size %d", tc.index, root, err, tc.wantSize)
+		}
+	}
+	checkBeyond(ctx, t, r, 3)
+	resp, err := r.GetLatestSignedLogRoot(ctx, &trillian.GetLatestSignedLogRootRequest{LogId: srcID})
+	if err != nil {
+		t.Fatalf("GetLatestSignedLogRoot(): %v", err)
+	}
+	var root types.LogRootV1
+	if err := root.UnmarshalBinary(resp.SignedLogRoot.LogRoot); err != nil {
+		t.Fatalf("UnmarshalBinary(): %v", err)
+	}
+	if root.TreeSize != 3 {
+		t.Errorf("GetLatestSignedLogRoot() = size %d, want 3", root.TreeSize)
+	}
+
+	// The replica carries on once the source serves the right leaves.
+	if err := r.sync(ctx); err != nil {
+		t.Fatalf("sync(): %v", err)
+	}
+	checkServed(ctx, t, r, src)
+}
+
+func TestCheckSourceKey(t *testing.T) {
+	dir, err := ioutil.TempDir("", "log_replica")
+	if err != nil {
+		t.Fatalf("TempDir(): %v", err)
+	}
+	defer os.RemoveAll(dir)
+	keyFile := filepath.Join(dir, "key.pem")
+	if err := ioutil.WriteFile(keyFile, []byte(testonly.DemoPublicKey), 0600); err != nil {
+		t.Fatalf("WriteFile(): %v", err)
+	}
+	noPEM := filepath.Join(dir, "key.der")
+	if err := ioutil.WriteFile(noPEM, srcTree().PublicKey.Der, 0600); err != nil {
+		t.Fatalf("WriteFile(): %v", err)
+	}
+	other := srcTree()
+	other.PublicKey.Der[len(other.PublicKey.Der)-1] ^= 1
+	defer func(key string) { *sourcePublicKey = key }(*sourcePublicKey)
+
+	for _, tc := range []struct {
+		desc    string
+		keyFile string
+		tree    *trillian.Tree
+		wantErr bool
+	}{
+		{desc: "match", keyFile: keyFile, tree: srcTree()},
+		{desc: "mismatch", keyFile: keyFile, tree: other, wantErr: true},
+		{desc: "no-key", keyFile: "", tree: srcTree(), wantErr: true},
+		{desc: "missing-file", keyFile: filepath.Join(dir, "missing.pem"), tree: srcTree(), wantErr: true},
+		{desc: "no-pem", keyFile: noPEM, tree: srcTree(), wantErr: true},
+	} {
+		t.Run(tc.desc, func(t *testing.T) {
+			*sourcePublicKey = tc.keyFile
+			if err := checkSourceKey(tc.tree); (err != nil) != tc.wantErr {
+				t.Errorf("checkSourceKey(): %v, wantErr %v", err, tc.wantErr)
+			}
+		})
+	}
+}
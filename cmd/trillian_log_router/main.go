@@ -0,0 +1,99 @@
+// Copyright 2020 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package main contains the implementation and entry point for the
+// trillian_log_router command, which serves the TrillianLog API for logs
+// which are sharded by leaf timestamp over several trees.
+//
+// Example usage:
+// $ ./trillian_log_router --log_server=host:port --config=router.textproto
+package main
+
+import (
+	"context"
+	"flag"
+	"io/ioutil"
+	"net"
+
+	"github.com/golang/glog"
+	"github.com/golang/protobuf/proto" //nolint:staticcheck
+	"github.com/google/trillian"
+	"github.com/google/trillian/client/rpcflags"
+	"github.com/google/trillian/sharding"
+	"github.com/google/trillian/sharding/shardingpb"
+	"github.com/google/trillian/util"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/reflection"
+)
+
+var (
+	rpcEndpoint   = flag.String("rpc_endpoint", "localhost:8094", "Endpoint for RPC requests (host:port)")
+	logServerAddr = flag.String("log_server", "", "Address of the gRPC Trillian Log Server holding the shards (host:port)")
+	configFile    = flag.String("config", "", "Path to a text format RouterConfig proto with the sharded logs")
+)
+
+func loadConfig(path string) (*shardingpb.RouterConfig, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	cfg := &shardingpb.RouterConfig{}
+	if err := proto.UnmarshalText(string(data), cfg); err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
+
+func main() {
+	flag.Parse()
+	defer glog.Flush()
+	ctx := context.Background()
+
+	cfg, err := loadConfig(*configFile)
+	if err != nil {
+		glog.Exitf("Failed to read config %q: %v", *configFile, err)
+	}
+
+	dialOpts, err := rpcflags.NewClientDialOptionsFromFlags()
+	if err != nil {
+		glog.Exitf("Failed to determine dial options: %v", err)
+	}
+	conn, err := grpc.Dial(*logServerAddr, dialOpts...)
+	if err != nil {
+		glog.Exitf("Failed to dial %v: %v", *logServerAddr, err)
+	}
+	defer conn.Close()
+
+	router, err := sharding.NewRouter(cfg, trillian.NewTrillianLogClient(conn), sharding.QueueTimestamp)
+	if err != nil {
+		glog.Exitf("Invalid config %q: %v", *configFile, err)
+	}
+
+	srv := grpc.NewServer()
+	trillian.RegisterTrillianLogServer(srv, router)
+	shardingpb.RegisterShardAdminServer(srv, router)
+	reflection.Register(srv)
+
+	glog.Infof("RPC server starting on %v", *rpcEndpoint)
+	lis, err := net.Listen("tcp", *rpcEndpoint)
+	if err != nil {
+		glog.Exitf("Failed to listen on %v: %v", *rpcEndpoint, err)
+	}
+	go util.AwaitSignal(ctx, srv.Stop)
+
+	if err := srv.Serve(lis); err != nil {
+		glog.Errorf("RPC server terminated: %v", err)
+	}
+	glog.Infof("Stopping server, about to exit")
+}
@@ -18,10 +18,14 @@ package main
 
 import (
 	"context"
+	"crypto"
 	"flag"
+	"fmt"
+	"net/http"
 	_ "net/http/pprof" // Register pprof HTTP handlers.
 	"os"
 	"runtime/pprof"
+	"strings"
 	"time"
 
 	"github.com/golang/glog"
@@ -30,8 +34,10 @@ import (
 	"github.com/google/trillian/cmd"
 	"github.com/google/trillian/cmd/internal/serverutil"
 	"github.com/google/trillian/crypto/keys/der"
+	"github.com/google/trillian/crypto/keys/pem"
 	"github.com/google/trillian/crypto/keyspb"
 	"github.com/google/trillian/extension"
+	"github.com/google/trillian/log/admission"
 	"github.com/google/trillian/monitoring"
 	"github.com/google/trillian/monitoring/opencensus"
 	"github.com/google/trillian/monitoring/prometheus"
@@ -40,6 +46,7 @@ import (
 	"github.com/google/trillian/quota/etcd/quotaapi"
 	"github.com/google/trillian/quota/etcd/quotapb"
 	"github.com/google/trillian/server"
+	"github.com/google/trillian/server/interceptor"
 	"github.com/google/trillian/storage"
 	"github.com/google/trillian/util/clock"
 	etcdutil "github.com/google/trillian/util/etcd"
@@ -75,6 +82,15 @@ var (
 	treeGCEnabled            = flag.Bool("tree_gc", true, "If true, tree garbage collection (hard-deletion) is periodically performed")
 	treeDeleteThreshold      = flag.Duration("tree_delete_threshold", serverutil.DefaultTreeDeleteThreshold, "Minimum period a tree has to remain deleted before being hard-deleted")
 	treeDeleteMinRunInterval = flag.Duration("tree_delete_min_run_interval", serverutil.DefaultTreeDeleteMinInterval, "Minimum interval between tree garbage collection sweeps. Actual runs happen randomly between [minInterval,2*minInterval).")
+	treeSchedulerEnabled     = flag.Bool("tree_scheduler", true, "If true, scheduled tree state transitions are periodically applied to storage")
+	treeSchedulerMinInterval = flag.Duration("tree_scheduler_min_run_interval", serverutil.DefaultTreeSchedulerMinInterval, "Minimum interval between sweeps for due scheduled tree state transitions. Actual runs happen randomly between [minInterval,2*minInterval).")
+
+	maxLeafValueSize = flag.Int("max_leaf_value_size", 0, "If set, reject leaves whose leaf_value has more bytes than this")
+	maxExtraDataSize = flag.Int("max_extra_data_size", 0, "If set, reject leaves whose extra_data has more bytes than this")
+	leafSigningKeys  = flag.String("leaf_signing_keys", "", "Comma-separated PEM public key files; if set, reject leaves whose extra_data is not a signature of their leaf_value by one of these keys")
+	leafSigningHash  = flag.String("leaf_signing_hash", "SHA256", "Hash algorithm of the leaf signatures checked with --leaf_signing_keys")
+
+	checkpointOriginPrefix = flag.String("checkpoint_origin_prefix", "", "If set, serve the latest root of each log at /checkpoint/<log ID> on --http_endpoint, as a signed note checkpoint whose origin is this prefix followed by the log ID")
 
 	tracing          = flag.Bool("tracing", false, "If true opencensus Stackdriver tracing will be enabled. See https://opencensus.io/.")
 	tracingProjectID = flag.String("tracing_project_id", "", "project ID to pass to stackdriver. Can be empty for GCP, consult docs for other platforms.")
@@ -136,11 +152,17 @@ func main() {
 		glog.Exitf("Error creating quota manager: %v", err)
 	}
 
+	leafValidator, err := newLeafValidator()
+	if err != nil {
+		glog.Exitf("Error creating leaf validator: %v", err)
+	}
+
 	registry := extension.Registry{
 		AdminStorage:  sp.AdminStorage(),
 		LogStorage:    sp.LogStorage(),
 		QuotaManager:  qm,
 		MetricFactory: mf,
+		LeafValidator: leafValidator,
 		NewKeyProto: func(ctx context.Context, spec *keyspb.Specification) (proto.Message, error) {
 			return der.NewProtoFromSpec(spec)
 		},
@@ -169,6 +191,10 @@ func main() {
 				return err
 			}
 			trillian.RegisterTrillianLogServer(s, logServer)
+			if *checkpointOriginPrefix != "" && *httpEndpoint != "" {
+				ti := interceptor.New(registry.AdminStorage, registry.QuotaManager, *quotaDryRun, registry.MetricFactory)
+				http.Handle("/checkpoint/", server.NewCheckpointHandler(logServer, registry.AdminStorage, ti, *checkpointOriginPrefix))
+			}
 			if *quota.System == etcd.QuotaManagerName {
 				quotapb.RegisterQuotaServer(s, quotaapi.NewServer(client))
 			}
@@ -178,11 +204,13 @@ func main() {
 			as := sp.AdminStorage()
 			return as.CheckDatabaseAccessible(ctx)
 		},
-		HealthyDeadline:       *healthzTimeout,
-		AllowedTreeTypes:      []trillian.TreeType{trillian.TreeType_LOG, trillian.TreeType_PREORDERED_LOG},
-		TreeGCEnabled:         *treeGCEnabled,
-		TreeDeleteThreshold:   *treeDeleteThreshold,
-		TreeDeleteMinInterval: *treeDeleteMinRunInterval,
+		HealthyDeadline:          *healthzTimeout,
+		AllowedTreeTypes:         []trillian.TreeType{trillian.TreeType_LOG, trillian.TreeType_PREORDERED_LOG},
+		TreeGCEnabled:            *treeGCEnabled,
+		TreeDeleteThreshold:      *treeDeleteThreshold,
+		TreeDeleteMinInterval:    *treeDeleteMinRunInterval,
+		TreeSchedulerEnabled:     *treeSchedulerEnabled,
+		TreeSchedulerMinInterval: *treeSchedulerMinInterval,
 	}
 
 	if err := m.Run(ctx); err != nil {
@@ -202,3 +230,38 @@ func mustCreate(fileName string) *os.File {
 	}
 	return f
 }
+
+// newLeafValidator returns the admission.Validator selected by the flags,
+// which is nil if leaves are not checked.
+func newLeafValidator() (admission.Validator, error) {
+	var all admission.All
+	if *maxLeafValueSize > 0 || *maxExtraDataSize > 0 {
+		all = append(all, admission.MaxSize{LeafValue: *maxLeafValueSize, ExtraData: *maxExtraDataSize})
+	}
+	if *leafSigningKeys != "" {
+		hash, ok := hashes[*leafSigningHash]
+		if !ok {
+			return nil, fmt.Errorf("unknown --leaf_signing_hash %q", *leafSigningHash)
+		}
+		sig := admission.Signature{Hash: hash}
+		for _, file := range strings.Split(*leafSigningKeys, ",") {
+			key, err := pem.ReadPublicKeyFile(file)
+			if err != nil {
+				return nil, err
+			}
+			sig.Keys = append(sig.Keys, key)
+		}
+		all = append(all, sig)
+	}
+	if len(all) == 0 {
+		return nil, nil
+	}
+	return all, nil
+}
+
+// hashes are the hash algorithms which --leaf_signing_hash accepts.
+var hashes = map[string]crypto.Hash{
+	"SHA256": crypto.SHA256,
+	"SHA384": crypto.SHA384,
+	"SHA512": crypto.SHA512,
+}
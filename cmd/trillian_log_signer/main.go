@@ -17,8 +17,10 @@ package main
 
 import (
 	"context"
+	"errors"
 	"flag"
 	"fmt"
+	"net/http"
 	_ "net/http/pprof" // Register pprof HTTP handlers.
 	"os"
 	"runtime/pprof"
@@ -29,6 +31,9 @@ import (
 	"github.com/google/trillian/cmd/internal/serverutil"
 	"github.com/google/trillian/extension"
 	"github.com/google/trillian/log"
+	"github.com/google/trillian/log/export"
+	"github.com/google/trillian/log/export/exportpb"
+	"github.com/google/trillian/log/tiles"
 	"github.com/google/trillian/monitoring"
 	"github.com/google/trillian/monitoring/opencensus"
 	"github.com/google/trillian/monitoring/prometheus"
@@ -40,6 +45,7 @@ import (
 	"github.com/google/trillian/util/election"
 	"github.com/google/trillian/util/election2"
 	etcdelect "github.com/google/trillian/util/election2/etcd"
+	"github.com/google/trillian/util/election2/sqlelect"
 	etcdutil "github.com/google/trillian/util/etcd"
 	"google.golang.org/grpc"
 
@@ -52,7 +58,8 @@ import (
 
 	// Register supported storage providers.
 	_ "github.com/google/trillian/storage/cloudspanner"
-	_ "github.com/google/trillian/storage/mysql"
+	"github.com/google/trillian/storage/mysql"
+	"github.com/google/trillian/storage/postgres"
 
 	// Load hashers
 	_ "github.com/google/trillian/merkle/rfc6962"
@@ -68,12 +75,26 @@ var (
 	tlsKeyFile               = flag.String("tls_key_file", "", "Path to the TLS server key. If unset, the server will use unsecured connections.")
 	sequencerIntervalFlag    = flag.Duration("sequencer_interval", 100*time.Millisecond, "Time between each sequencing pass through all logs")
 	batchSizeFlag            = flag.Int("batch_size", 1000, "Max number of leaves to process per batch")
+	minBatchSizeFlag         = flag.Int("min_batch_size", 1, "Lower bound of the batch size when --max_batch_size is set")
+	maxBatchSizeFlag         = flag.Int("max_batch_size", 0, "If set, adapt the batch size of each log between --min_batch_size and this value, starting from --batch_size")
+	batchTargetLatencyFlag   = flag.Duration("batch_target_latency", 5*time.Second, "Batch sequencing latency above which adaptive batch sizing makes batches smaller (0 means latency is not taken into account)")
 	numSeqFlag               = flag.Int("num_sequencers", 10, "Number of sequencer workers to run in parallel")
 	sequencerGuardWindowFlag = flag.Duration("sequencer_guard_window", 0, "If set, the time elapsed before submitted leaves are eligible for sequencing")
+	pipelinedSequencing      = flag.Bool("pipelined_sequencing", false, "If true, keep the compact range of each log in memory between batches, hash each batch while its sequence numbers are written, and prefetch the next batch while the current one commits")
 	forceMaster              = flag.Bool("force_master", false, "If true, assume master for all logs")
 	etcdHTTPService          = flag.String("etcd_http_service", "trillian-logsigner-http", "Service name to announce our HTTP endpoint under")
 	lockDir                  = flag.String("lock_file_path", "/test/multimaster", "etcd lock file directory path")
+	mysqlElection            = flag.Bool("mysql_election", false, "If true, elect log masters using lease rows in the MySQL or PostgreSQL storage database instead of etcd")
+	electionLeaseTTL         = flag.Duration("election_lease_ttl", sqlelect.DefaultLeaseTTL, "Duration of mastership leases; only effective with --mysql_election")
+	consistentHashing        = flag.Bool("consistent_hash_assignment", false, "If true, share out the logs between the signers registered in etcd using consistent hashing, and only run elections for the logs assigned to this signer")
+	rebalanceInterval        = flag.Duration("rebalance_interval", 10*time.Second, "Minimum time between releasing mastership of two logs assigned to other signers; only effective with --consistent_hash_assignment")
 	healthzTimeout           = flag.Duration("healthz_timeout", time.Second*5, "Timeout used during healthz checks")
+	drainTimeout             = flag.Duration("drain_timeout", 20*time.Second, "Maximum time to finish in-flight batches and resign mastership on SIGTERM before exiting (0 means exit straight away)")
+	exportDir                = flag.String("export_dir", "", "If set, append the leaves integrated into each log to the file <log ID>.leaves in this directory")
+	exportServer             = flag.String("export_server", "", "If set, send the leaves integrated into each log to this LeafExport gRPC server (host:port)")
+	exportCursorDir          = flag.String("export_cursor_dir", "", "Directory for the files recording the next leaf to export from each log; required with --export_dir or --export_server")
+	tilesDir                 = flag.String("tiles_dir", "", "If set, write the tiles and checkpoint of each log to the directory <log ID> in this directory, to be served as static files")
+	exportTimeout            = flag.Duration("export_timeout", time.Minute, "Max time spent exporting the leaves or tiles of a log for one root; exports run in the background of sequencing")
 
 	quotaIncreaseFactor = flag.Float64("quota_increase_factor", log.QuotaIncreaseFactor,
 		"Increase factor for tokens replenished by sequencing-based quotas (1 means a 1:1 relationship between sequenced leaves and replenished tokens)."+
@@ -122,7 +143,9 @@ func main() {
 
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
-	go util.AwaitSignal(ctx, cancel)
+	if *drainTimeout <= 0 {
+		go util.AwaitSignal(ctx, cancel)
+	}
 
 	hostname, _ := os.Hostname()
 	instanceID := fmt.Sprintf("%s.%d", hostname, os.Getpid())
@@ -131,10 +154,25 @@ func main() {
 	case *forceMaster:
 		glog.Warning("**** Acting as master for all logs ****")
 		electionFactory = election2.NoopFactory{}
+	case *mysqlElection:
+		electionFactory = newSQLElectionFactory(instanceID)
 	case client != nil:
 		electionFactory = etcdelect.NewFactory(instanceID, client, *lockDir)
 	default:
-		glog.Exit("Either --force_master or --etcd_servers must be supplied")
+		glog.Exit("Either --force_master, --mysql_election or --etcd_servers must be supplied")
+	}
+	var membership election2.Membership
+	if *consistentHashing {
+		if *forceMaster || client == nil {
+			glog.Exit("--consistent_hash_assignment requires --etcd_servers and no --force_master")
+		}
+		// The membership outlives ctx, so that it can be closed on exit.
+		m, err := etcdelect.NewMembership(context.Background(), instanceID, client, *lockDir+"/members")
+		if err != nil {
+			glog.Exitf("Failed to register signer membership: %v", err)
+		}
+		defer m.Close(context.Background())
+		membership = m
 	}
 
 	qm, err := quota.NewManagerFromFlags()
@@ -142,12 +180,19 @@ func main() {
 		glog.Exitf("Error creating quota manager: %v", err)
 	}
 
+	exportHook, closeExport, err := newExportHook(sp.LogStorage(), mf)
+	if err != nil {
+		glog.Exitf("Failed to set up leaf export: %v", err)
+	}
+	defer closeExport()
+
 	registry := extension.Registry{
 		AdminStorage:    sp.AdminStorage(),
 		LogStorage:      sp.LogStorage(),
 		ElectionFactory: electionFactory,
 		QuotaManager:    qm,
 		MetricFactory:   mf,
+		ExportHook:      exportHook,
 	}
 
 	// Start HTTP server (optional)
@@ -163,20 +208,32 @@ func main() {
 	log.QuotaIncreaseFactor = *quotaIncreaseFactor
 	sequencerManager := log.NewSequencerManager(registry, *sequencerGuardWindowFlag)
 	info := log.OperationInfo{
-		Registry:    registry,
-		BatchSize:   *batchSizeFlag,
-		NumWorkers:  *numSeqFlag,
-		RunInterval: *sequencerIntervalFlag,
-		TimeSource:  clock.System,
+		Registry:  registry,
+		BatchSize: *batchSizeFlag,
+		BatchSizing: log.BatchSizeConfig{
+			MinBatchSize:  *minBatchSizeFlag,
+			MaxBatchSize:  *maxBatchSizeFlag,
+			TargetLatency: *batchTargetLatencyFlag,
+		},
+		NumWorkers:          *numSeqFlag,
+		RunInterval:         *sequencerIntervalFlag,
+		TimeSource:          clock.System,
+		PipelinedSequencing: *pipelinedSequencing,
 		ElectionConfig: election.RunnerConfig{
 			PreElectionPause:   *preElectionPause,
 			MasterHoldInterval: *masterHoldInterval,
 			MasterHoldJitter:   *masterHoldJitter,
 			TimeSource:         clock.System,
 		},
+		Membership:        membership,
+		RebalanceInterval: *rebalanceInterval,
 	}
 	sequencerTask := log.NewOperationManager(info, sequencerManager)
 	go sequencerTask.OperationLoop(ctx)
+	statusServer := log.NewStatusServer(sequencerTask)
+	if *httpEndpoint != "" {
+		http.Handle("/status", statusServer)
+	}
 
 	// Enable CPU profile if requested
 	if *cpuProfile != "" {
@@ -194,12 +251,24 @@ func main() {
 		DBClose:      sp.Close,
 		Registry:     registry,
 		RegisterServerFn: func(s *grpc.Server, _ extension.Registry) error {
-			tpb.RegisterTrillianLogSequencerServer(s, &struct{}{})
+			tpb.RegisterTrillianLogSequencerServer(s, statusServer)
 			return nil
 		},
 		IsHealthy:       sp.AdminStorage().CheckDatabaseAccessible,
 		HealthyDeadline: *healthzTimeout,
 	}
+	if *drainTimeout > 0 {
+		m.DrainFn = func() {
+			// Let the in-flight batches complete and hand over mastership before
+			// canceling everything.
+			defer cancel()
+			dctx, dcancel := context.WithTimeout(context.Background(), *drainTimeout)
+			defer dcancel()
+			if err := sequencerTask.Drain(dctx); err != nil {
+				glog.Warningf("Failed to drain log operation manager: %v", err)
+			}
+		}
+	}
 
 	if err := m.Run(ctx); err != nil {
 		glog.Exitf("Server exited with error: %v", err)
@@ -222,3 +291,79 @@ func mustCreate(fileName string) *os.File {
 	}
 	return f
 }
+
+// newExportHook returns the export.Hook selected by the flags, which is nil if
+// neither leaves nor tiles are exported, and a function to close it. Each
+// newSQLElectionFactory returns an election factory which uses the database
+// of the storage provider selected by --storage_system.
+func newSQLElectionFactory(instanceID string) election2.Factory {
+	switch system := storage.SystemFromFlags(); system {
+	case "mysql":
+		db, err := mysql.GetDatabase()
+		if err != nil {
+			glog.Exitf("Failed to get MySQL database for election: %v", err)
+		}
+		return sqlelect.NewMySQLFactory(instanceID, db, *lockDir, *electionLeaseTTL)
+	case "postgres":
+		db, err := postgres.GetDatabase()
+		if err != nil {
+			glog.Exitf("Failed to get PostgreSQL database for election: %v", err)
+		}
+		return sqlelect.NewPostgresFactory(instanceID, db, *lockDir, *electionLeaseTTL)
+	default:
+		glog.Exitf("--mysql_election requires --storage_system=mysql or postgres, got %q", system)
+		return nil
+	}
+}
+
+// export runs in the background, so that it doesn't hold up sequencing.
+func newExportHook(ls storage.LogStorage, mf monitoring.MetricFactory) (export.Hook, func(), error) {
+	var hooks export.Hooks
+	var asyncs []*export.Async
+	addHook := func(h export.Hook) {
+		a := export.NewAsync(h, *exportTimeout)
+		hooks, asyncs = append(hooks, a), append(asyncs, a)
+	}
+	if *tilesDir != "" {
+		addHook(tiles.NewExporter(*tilesDir, ls))
+	}
+
+	var sink export.Sink
+	closeSink := func() {}
+	switch {
+	case *exportDir != "" && *exportServer != "":
+		return nil, nil, errors.New("only one of --export_dir and --export_server can be set")
+	case *exportDir != "":
+		s := export.NewFileSink(*exportDir)
+		sink, closeSink = s, func() { s.Close() }
+	case *exportServer != "":
+		conn, err := grpc.Dial(*exportServer, grpc.WithInsecure())
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to dial %v: %v", *exportServer, err)
+		}
+		s := export.NewGRPCSink(exportpb.NewLeafExportClient(conn))
+		sink, closeSink = s, func() { s.Close(); conn.Close() }
+	}
+	if sink != nil {
+		if *exportCursorDir == "" {
+			closeSink()
+			return nil, nil, errors.New("--export_cursor_dir must be set to export leaves")
+		}
+		addHook(export.NewExporter(sink, export.FileCursors{Dir: *exportCursorDir}, ls, mf))
+	}
+
+	// The background exports have to stop before their sink is closed.
+	closeExport := func() {
+		for _, a := range asyncs {
+			a.Close()
+		}
+		closeSink()
+	}
+	switch len(hooks) {
+	case 0:
+		return nil, closeExport, nil
+	case 1:
+		return hooks[0], closeExport, nil
+	}
+	return hooks, closeExport, nil
+}
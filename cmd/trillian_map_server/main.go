@@ -71,6 +71,8 @@ var (
 	treeGCEnabled            = flag.Bool("tree_gc", true, "If true, tree garbage collection (hard-deletion) is periodically performed")
 	treeDeleteThreshold      = flag.Duration("tree_delete_threshold", serverutil.DefaultTreeDeleteThreshold, "Minimum period a tree has to remain deleted before being hard-deleted")
 	treeDeleteMinRunInterval = flag.Duration("tree_delete_min_run_interval", serverutil.DefaultTreeDeleteMinInterval, "Minimum interval between tree garbage collection sweeps. Actual runs happen randomly between [minInterval,2*minInterval).")
+	treeSchedulerEnabled     = flag.Bool("tree_scheduler", true, "If true, scheduled tree state transitions are periodically applied to storage")
+	treeSchedulerMinInterval = flag.Duration("tree_scheduler_min_run_interval", serverutil.DefaultTreeSchedulerMinInterval, "Minimum interval between sweeps for due scheduled tree state transitions. Actual runs happen randomly between [minInterval,2*minInterval).")
 
 	tracing          = flag.Bool("tracing", false, "If true opencensus Stackdriver tracing will be enabled. See https://opencensus.io/.")
 	tracingProjectID = flag.String("tracing_project_id", "", "project ID to pass to Stackdriver client. Can be empty for GCP, consult docs for other platforms.")
@@ -182,11 +184,13 @@ func main() {
 			as := sp.AdminStorage()
 			return as.CheckDatabaseAccessible(ctx)
 		},
-		HealthyDeadline:       *healthzTimeout,
-		AllowedTreeTypes:      []trillian.TreeType{trillian.TreeType_MAP},
-		TreeGCEnabled:         *treeGCEnabled,
-		TreeDeleteThreshold:   *treeDeleteThreshold,
-		TreeDeleteMinInterval: *treeDeleteMinRunInterval,
+		HealthyDeadline:          *healthzTimeout,
+		AllowedTreeTypes:         []trillian.TreeType{trillian.TreeType_MAP},
+		TreeGCEnabled:            *treeGCEnabled,
+		TreeDeleteThreshold:      *treeDeleteThreshold,
+		TreeDeleteMinInterval:    *treeDeleteMinRunInterval,
+		TreeSchedulerEnabled:     *treeSchedulerEnabled,
+		TreeSchedulerMinInterval: *treeSchedulerMinInterval,
 	}
 
 	ctx := context.Background()
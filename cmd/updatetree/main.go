@@ -18,6 +18,9 @@
 // Example usage:
 // $ ./updatetree --admin_server=host:port --tree_id=123456789 --tree_state=FROZEN
 //
+// To schedule a state change instead:
+// $ ./updatetree --admin_server=host:port --tree_id=123456789 --scheduled_state=DRAINING --scheduled_time=2021-01-01T00:00:00Z
+//
 // The output is minimal to allow for easy usage in automated scripts.
 package main
 
@@ -30,6 +33,7 @@ import (
 
 	"github.com/golang/glog"
 	"github.com/golang/protobuf/proto" //nolint:staticcheck
+	"github.com/golang/protobuf/ptypes"
 	"github.com/google/trillian"
 	"github.com/google/trillian/client/rpcflags"
 	"google.golang.org/genproto/protobuf/field_mask"
@@ -46,9 +50,24 @@ var (
 	treeID          = flag.Int64("tree_id", 0, "The ID of the tree to be set updated")
 	treeState       = flag.String("tree_state", "", "If set the tree state will be updated")
 	treeType        = flag.String("tree_type", "", "If set the tree type will be updated")
+	scheduledState  = flag.String("scheduled_state", "", "If set the tree will be scheduled to move to this state at --scheduled_time")
+	scheduledTime   = flag.String("scheduled_time", "", "Time of the scheduled state change, in RFC 3339 format")
+	clearSchedule   = flag.Bool("clear_schedule", false, "If true the scheduled state change of the tree, if any, will be removed")
 	printTree       = flag.Bool("print", false, "Print the resulting tree")
 )
 
+func parseTreeState(name string) (trillian.TreeState, error) {
+	m, err := protoregistry.GlobalTypes.FindEnumByName("trillian.TreeState")
+	if err != nil {
+		return 0, fmt.Errorf("can't find enum value map for states: %w", err)
+	}
+	state := m.Descriptor().Values().ByName(protoreflect.Name(name))
+	if state == nil {
+		return 0, fmt.Errorf("invalid tree state: %v", name)
+	}
+	return trillian.TreeState(state.Number()), nil
+}
+
 // TODO(Martin2112): Pass everything needed into this and don't refer to flags.
 func updateTree(ctx context.Context) (*trillian.Tree, error) {
 	if *adminServerAddr == "" {
@@ -59,15 +78,11 @@ func updateTree(ctx context.Context) (*trillian.Tree, error) {
 	paths := make([]string, 0)
 
 	if len(*treeState) > 0 {
-		m, err := protoregistry.GlobalTypes.FindEnumByName("trillian.TreeState")
+		state, err := parseTreeState(*treeState)
 		if err != nil {
-			return nil, fmt.Errorf("can't find enum value map for states: %w", err)
+			return nil, err
 		}
-		newState := m.Descriptor().Values().ByName(protoreflect.Name(*treeState))
-		if newState == nil {
-			return nil, fmt.Errorf("invalid tree state: %v", *treeState)
-		}
-		tree.TreeState = trillian.TreeState(newState.Number())
+		tree.TreeState = state
 		paths = append(paths, "tree_state")
 	}
 
@@ -84,6 +99,28 @@ func updateTree(ctx context.Context) (*trillian.Tree, error) {
 		paths = append(paths, "tree_type")
 	}
 
+	switch {
+	case *clearSchedule && len(*scheduledState) > 0:
+		return nil, errors.New("--clear_schedule and --scheduled_state are mutually exclusive")
+	case *clearSchedule:
+		paths = append(paths, "scheduled_transition")
+	case len(*scheduledState) > 0:
+		state, err := parseTreeState(*scheduledState)
+		if err != nil {
+			return nil, err
+		}
+		at, err := time.Parse(time.RFC3339, *scheduledTime)
+		if err != nil {
+			return nil, fmt.Errorf("invalid --scheduled_time: %v", err)
+		}
+		ts, err := ptypes.TimestampProto(at)
+		if err != nil {
+			return nil, fmt.Errorf("invalid --scheduled_time: %v", err)
+		}
+		tree.ScheduledTransition = &trillian.ScheduledTreeTransition{TreeState: state, TransitionTime: ts}
+		paths = append(paths, "scheduled_transition")
+	}
+
 	if len(paths) == 0 {
 		return nil, errors.New("nothing to change")
 	}
@@ -22,9 +22,12 @@ import (
 	"time"
 
 	"github.com/golang/mock/gomock"
+	"github.com/golang/protobuf/proto" //nolint:staticcheck
+	"github.com/golang/protobuf/ptypes/timestamp"
 	"github.com/google/trillian"
 	"github.com/google/trillian/testonly"
 	"github.com/google/trillian/testonly/flagsaver"
+	"google.golang.org/genproto/protobuf/field_mask"
 )
 
 type testCase struct {
@@ -32,6 +35,7 @@ type testCase struct {
 	setFlags   func()
 	updateErr  error
 	wantRPC    bool
+	wantReq    *trillian.UpdateTreeRequest
 	updateTree *trillian.Tree
 	wantErr    bool
 	wantState  trillian.TreeState
@@ -71,6 +75,56 @@ func TestFreezeTree(t *testing.T) {
 			},
 			wantErr: true,
 		},
+		{
+			desc: "validSchedule",
+			setFlags: func() {
+				*treeID = 12345
+				*scheduledState = "DRAINING"
+				*scheduledTime = "2021-01-01T00:00:00Z"
+			},
+			wantRPC: true,
+			wantReq: &trillian.UpdateTreeRequest{
+				Tree: &trillian.Tree{
+					TreeId: 12345,
+					ScheduledTransition: &trillian.ScheduledTreeTransition{
+						TreeState:      trillian.TreeState_DRAINING,
+						TransitionTime: &timestamp.Timestamp{Seconds: 1609459200},
+					},
+				},
+				UpdateMask: &field_mask.FieldMask{Paths: []string{"scheduled_transition"}},
+			},
+			updateTree: &trillian.Tree{
+				TreeId:    12345,
+				TreeState: trillian.TreeState_ACTIVE,
+			},
+			wantState: trillian.TreeState_ACTIVE,
+		},
+		{
+			desc: "clearSchedule",
+			setFlags: func() {
+				*treeID = 12345
+				*clearSchedule = true
+			},
+			wantRPC: true,
+			wantReq: &trillian.UpdateTreeRequest{
+				Tree:       &trillian.Tree{TreeId: 12345},
+				UpdateMask: &field_mask.FieldMask{Paths: []string{"scheduled_transition"}},
+			},
+			updateTree: &trillian.Tree{
+				TreeId:    12345,
+				TreeState: trillian.TreeState_ACTIVE,
+			},
+			wantState: trillian.TreeState_ACTIVE,
+		},
+		{
+			desc: "scheduleInvalidTime",
+			setFlags: func() {
+				*treeID = 12345
+				*scheduledState = "DRAINING"
+				*scheduledTime = "tomorrow"
+			},
+			wantErr: true,
+		},
 		{
 			desc: "unknownTree",
 			setFlags: func() {
@@ -129,7 +183,13 @@ func runTest(t *testing.T, tests []*testCase) {
 
 			// We might not get as far as updating the tree on the admin server.
 			if tc.wantRPC {
-				call := s.Admin.EXPECT().UpdateTree(gomock.Any(), gomock.Any()).Return(tc.updateTree, tc.updateErr)
+				call := s.Admin.EXPECT().UpdateTree(gomock.Any(), gomock.Any()).DoAndReturn(
+					func(ctx context.Context, req *trillian.UpdateTreeRequest) (*trillian.Tree, error) {
+						if tc.wantReq != nil && !proto.Equal(req, tc.wantReq) {
+							t.Errorf("UpdateTree() request = %v, want %v", req, tc.wantReq)
+						}
+						return tc.updateTree, tc.updateErr
+					})
 				expectCalls(call, tc.updateErr)
 			}
 
@@ -0,0 +1,123 @@
+// Copyright 2020 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package main contains the implementation and entry point for the
+// verifybundle command, which checks a proof bundle written by the client
+// package without contacting any Trillian server.
+//
+// Example usage:
+// $ ./verifybundle --bundle=proof.json --public_key=log_key.pem
+package main
+
+import (
+	"bytes"
+	"encoding/pem"
+	"errors"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+
+	"github.com/golang/glog"
+	"github.com/google/trillian"
+	"github.com/google/trillian/client"
+
+	_ "github.com/google/trillian/merkle/coniks"
+	_ "github.com/google/trillian/merkle/maphasher"
+	_ "github.com/google/trillian/merkle/rfc6962"
+)
+
+var (
+	bundleFile = flag.String("bundle", "", "Proof bundle to verify, or - to read it from stdin")
+	publicKey  = flag.String("public_key", "", "PEM file with the public key of the tree, which the key in the bundle must match")
+)
+
+func readBundle() (*client.ProofBundle, error) {
+	var data []byte
+	var err error
+	switch *bundleFile {
+	case "":
+		return nil, errors.New("--bundle must be set")
+	case "-":
+		data, err = ioutil.ReadAll(os.Stdin)
+	default:
+		data, err = ioutil.ReadFile(*bundleFile)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read bundle: %v", err)
+	}
+	return client.UnmarshalProofBundle(data)
+}
+
+// checkKey checks the key in the bundle against the --public_key file. The
+// bundle is only evidence of anything if its key is known to be the tree's.
+func checkKey(b *client.ProofBundle) error {
+	if *publicKey == "" {
+		return errors.New("--public_key must be set")
+	}
+	data, err := ioutil.ReadFile(*publicKey)
+	if err != nil {
+		return fmt.Errorf("failed to read public key: %v", err)
+	}
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return fmt.Errorf("no PEM block in %s", *publicKey)
+	}
+	if !bytes.Equal(block.Bytes, b.Tree.PublicKey) {
+		return fmt.Errorf("bundle is for a different public key than %s", *publicKey)
+	}
+	return nil
+}
+
+func verify() (string, error) {
+	b, err := readBundle()
+	if err != nil {
+		return "", err
+	}
+	if err := checkKey(b); err != nil {
+		return "", err
+	}
+	var out string
+	switch b.Tree.TreeType {
+	case trillian.TreeType_LOG.String(), trillian.TreeType_PREORDERED_LOG.String():
+		root, err := b.VerifyLog()
+		if err != nil {
+			return "", err
+		}
+		out = fmt.Sprintf("OK: leaf %d with hash %x is in log %d\n", b.LogLeaf.LeafIndex, b.LogLeaf.MerkleLeafHash, b.Tree.TreeID)
+		out += fmt.Sprintf("Latest verified root: size %d, hash %x, timestamp %d\n", root.TreeSize, root.RootHash, root.TimestampNanos)
+	case trillian.TreeType_MAP.String():
+		root, err := b.VerifyMap()
+		if err != nil {
+			return "", err
+		}
+		leaf := b.MapLeaf.Leaf
+		out = fmt.Sprintf("OK: leaf %x in map %d has value %q\n", leaf.Index, b.Tree.TreeID, leaf.LeafValue)
+		out += fmt.Sprintf("Verified root: revision %d, hash %x, timestamp %d\n", root.Revision, root.RootHash, root.TimestampNanos)
+	default:
+		return "", fmt.Errorf("unsupported tree type %q", b.Tree.TreeType)
+	}
+	return out, nil
+}
+
+func main() {
+	flag.Parse()
+	defer glog.Flush()
+
+	out, err := verify()
+	if err != nil {
+		glog.Exitf("Proof bundle failed verification: %v", err)
+	}
+	fmt.Print(out)
+}
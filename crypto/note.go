@@ -0,0 +1,192 @@
+// Copyright 2020 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package crypto
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/google/trillian/types"
+	"golang.org/x/crypto/ed25519"
+)
+
+// Signed notes follow the format of golang.org/x/mod/sumdb/note: the text of
+// the note, a blank line, then one line per signature made of an em dash, the
+// name of the signer, and the base64 encoding of the 4-byte key hash of the
+// signer followed by the signature of the text.
+//
+// The key hash is the first 4 bytes of SHA-256(name || "\n" || alg || key), in
+// which alg and key depend on the type of the public key:
+//   - Ed25519: alg is 0x01 and key is the 32-byte public key. The text is
+//     signed as is, so these are the signatures of the note package.
+//   - ECDSA: alg is 0x02 and key is the PKIX DER public key. The SHA-256 hash
+//     of the text is signed, and the signature is ASN.1 encoded.
+const (
+	noteAlgEd25519 = 0x01
+	noteAlgECDSA   = 0x02
+)
+
+const noteSigPrefix = "— "
+
+// NoteKeyHash returns the key hash which identifies the signatures by pub, on
+// behalf of the signer called name, in signed notes.
+func NoteKeyHash(name string, pub crypto.PublicKey) (uint32, error) {
+	if !isNoteName(name) {
+		return 0, fmt.Errorf("invalid note signer name %q", name)
+	}
+	var key []byte
+	switch pub := pub.(type) {
+	case ed25519.PublicKey:
+		key = append([]byte{noteAlgEd25519}, pub...)
+	case *ecdsa.PublicKey:
+		der, err := x509.MarshalPKIXPublicKey(pub)
+		if err != nil {
+			return 0, err
+		}
+		key = append([]byte{noteAlgECDSA}, der...)
+	default:
+		return 0, fmt.Errorf("unsupported public key type for notes: %T", pub)
+	}
+	h := sha256.New()
+	h.Write([]byte(name + "\n"))
+	h.Write(key)
+	return binary.BigEndian.Uint32(h.Sum(nil)), nil
+}
+
+// SignCheckpoint returns the checkpoint as a note signed by s on behalf of the
+// signer called name, which is usually the origin of the checkpoint.
+func (s *Signer) SignCheckpoint(name string, c *types.Checkpoint) ([]byte, error) {
+	text, err := c.MarshalText()
+	if err != nil {
+		return nil, err
+	}
+	return s.SignNote(name, text)
+}
+
+// SignNote returns the text, which must end with a newline, as a note signed
+// by s on behalf of the signer called name.
+func (s *Signer) SignNote(name string, text []byte) ([]byte, error) {
+	if !bytes.HasSuffix(text, []byte("\n")) || bytes.Contains(text, []byte("\n\n")) {
+		return nil, errors.New("note text must end with a newline and have no blank lines")
+	}
+	if _, ok := s.Public().(*ecdsa.PublicKey); ok && s.Hash != crypto.SHA256 {
+		return nil, fmt.Errorf("ECDSA note signatures use SHA-256, not %v", s.Hash)
+	}
+	keyHash, err := NoteKeyHash(name, s.Public())
+	if err != nil {
+		return nil, err
+	}
+	sig, err := s.Sign(text)
+	if err != nil {
+		return nil, err
+	}
+	var b bytes.Buffer
+	b.Write(text)
+	fmt.Fprintf(&b, "\n%s%s %s\n", noteSigPrefix, name, noteSignature(keyHash, sig))
+	return b.Bytes(), nil
+}
+
+// VerifyCheckpoint verifies that the note is signed by pub on behalf of the
+// signer called name, and returns the checkpoint in it. Signatures by other
+// signers are ignored. The caller should check that the origin of the
+// checkpoint is that of the expected log.
+func VerifyCheckpoint(pub crypto.PublicKey, name string, note []byte) (*types.Checkpoint, error) {
+	text, err := VerifyNote(pub, name, note)
+	if err != nil {
+		return nil, err
+	}
+	var c types.Checkpoint
+	if err := c.UnmarshalText(text); err != nil {
+		return nil, err
+	}
+	return &c, nil
+}
+
+// VerifyNote verifies that the note is signed by pub on behalf of the signer
+// called name, and returns its text. Signatures by other signers are ignored.
+func VerifyNote(pub crypto.PublicKey, name string, note []byte) ([]byte, error) {
+	if !utf8.Valid(note) {
+		return nil, errors.New("note is not valid UTF-8")
+	}
+	i := bytes.LastIndex(note, []byte("\n\n"))
+	if i < 0 || !bytes.HasSuffix(note, []byte("\n")) {
+		return nil, errors.New("malformed note")
+	}
+	text, sigs := note[:i+1], note[i+2:]
+	if len(sigs) == 0 {
+		return nil, errors.New("note has no signatures")
+	}
+	keyHash, err := NoteKeyHash(name, pub)
+	if err != nil {
+		return nil, err
+	}
+
+	found := false
+	for _, line := range strings.Split(string(sigs[:len(sigs)-1]), "\n") {
+		if !strings.HasPrefix(line, noteSigPrefix) {
+			return nil, fmt.Errorf("malformed note signature line %q", line)
+		}
+		fields := strings.Fields(strings.TrimPrefix(line, noteSigPrefix))
+		if len(fields) != 2 {
+			return nil, fmt.Errorf("malformed note signature line %q", line)
+		}
+		sig, err := base64.StdEncoding.DecodeString(fields[1])
+		if err != nil || len(sig) < 5 {
+			return nil, fmt.Errorf("malformed note signature line %q", line)
+		}
+		if fields[0] != name || binary.BigEndian.Uint32(sig) != keyHash {
+			continue
+		}
+		if err := verifyNoteSignature(pub, text, sig[4:]); err != nil {
+			return nil, err
+		}
+		found = true
+	}
+	if !found {
+		return nil, fmt.Errorf("note has no signature by %q with key hash %08x", name, keyHash)
+	}
+	return text, nil
+}
+
+// verifyNoteSignature verifies a signature of the text of a note.
+func verifyNoteSignature(pub crypto.PublicKey, text, sig []byte) error {
+	if pub, ok := pub.(ed25519.PublicKey); ok {
+		return verifyEd25519(pub, text, sig)
+	}
+	return Verify(pub, crypto.SHA256, text, sig)
+}
+
+// noteSignature returns the base64 encoding of a signature in a note.
+func noteSignature(keyHash uint32, sig []byte) string {
+	b := make([]byte, 4, 4+len(sig))
+	binary.BigEndian.PutUint32(b, keyHash)
+	return base64.StdEncoding.EncodeToString(append(b, sig...))
+}
+
+// isNoteName reports whether name is a valid signer name in notes, which is
+// non-empty and has no spaces or plus signs.
+func isNoteName(name string) bool {
+	return name != "" && utf8.ValidString(name) && strings.IndexFunc(name, unicode.IsSpace) < 0 && !strings.Contains(name, "+")
+}
@@ -0,0 +1,104 @@
+// Copyright 2020 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package crypto
+
+import (
+	"bytes"
+	"crypto"
+	"encoding/base64"
+	"strings"
+	"testing"
+
+	"github.com/google/trillian/crypto/keys/pem"
+	"github.com/google/trillian/testonly"
+	"github.com/google/trillian/types"
+	"golang.org/x/crypto/ed25519"
+)
+
+func TestNoteKeyHash(t *testing.T) {
+	// The verifier key of the example in golang.org/x/mod/sumdb/note.
+	key, err := base64.StdEncoding.DecodeString("ARpc2QcUPDhMQegwxbzhKqiBfsVkmqq/LDE4izWy10TW")
+	if err != nil {
+		t.Fatalf("DecodeString(): %v", err)
+	}
+	got, err := NoteKeyHash("PeterNeumann", ed25519.PublicKey(key[1:]))
+	if err != nil {
+		t.Fatalf("NoteKeyHash(): %v", err)
+	}
+	if want := uint32(0xc74f20a3); got != want {
+		t.Errorf("NoteKeyHash() = %08x, want %08x", got, want)
+	}
+
+	for _, name := range []string{"", "a b", "a+b"} {
+		if _, err := NoteKeyHash(name, ed25519.PublicKey(key[1:])); err == nil {
+			t.Errorf("NoteKeyHash(%q) succeeded, want error", name)
+		}
+	}
+}
+
+func TestSignCheckpoint(t *testing.T) {
+	const origin = "example.com/log"
+	root := &types.LogRootV1{TreeSize: 12, RootHash: bytes.Repeat([]byte{7}, 32)}
+
+	for _, test := range []struct {
+		name, pem, password string
+	}{
+		{name: "ECDSA", pem: testonly.DemoPrivateKey, password: testonly.DemoPrivateKeyPass},
+		{name: "Ed25519", pem: ed25519PEM},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			key, err := pem.UnmarshalPrivateKey(test.pem, test.password)
+			if err != nil {
+				t.Fatalf("UnmarshalPrivateKey(): %v", err)
+			}
+			signer := NewSigner(0, key, crypto.SHA256)
+			note, err := signer.SignCheckpoint(origin, root.Checkpoint(origin))
+			if err != nil {
+				t.Fatalf("SignCheckpoint(): %v", err)
+			}
+			if want := origin + "\n12\nBwcHBwcHBwcHBwcHBwcHBwcHBwcHBwcHBwcHBwcHBwc=\n\n— " + origin + " "; !strings.HasPrefix(string(note), want) {
+				t.Errorf("SignCheckpoint() = %q, want prefix %q", note, want)
+			}
+
+			c, err := VerifyCheckpoint(key.Public(), origin, note)
+			if err != nil {
+				t.Fatalf("VerifyCheckpoint(): %v", err)
+			}
+			if c.Origin != origin || c.TreeSize != root.TreeSize || !bytes.Equal(c.RootHash, root.RootHash) {
+				t.Errorf("VerifyCheckpoint() = %+v, want root %+v", c, root)
+			}
+
+			// Signatures by other signers are ignored.
+			other := append(append([]byte{}, note...), "— other.example AAAAAAAA\n"...)
+			if _, err := VerifyCheckpoint(key.Public(), origin, other); err != nil {
+				t.Errorf("VerifyCheckpoint() with another signature: %v", err)
+			}
+
+			for _, bad := range []struct {
+				desc, name string
+				note       []byte
+			}{
+				{desc: "other name", name: "other.example", note: note},
+				{desc: "tampered size", name: origin, note: bytes.Replace(note, []byte("\n12\n"), []byte("\n13\n"), 1)},
+				{desc: "no signatures", name: origin, note: note[:bytes.Index(note, []byte("\n\n"))+2]},
+				{desc: "no blank line", name: origin, note: bytes.Replace(note, []byte("\n\n"), []byte("\n"), 1)},
+			} {
+				if _, err := VerifyCheckpoint(key.Public(), bad.name, bad.note); err == nil {
+					t.Errorf("VerifyCheckpoint() with %s succeeded, want error", bad.desc)
+				}
+			}
+		})
+	}
+}
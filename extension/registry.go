@@ -18,6 +18,8 @@ package extension
 
 import (
 	"github.com/google/trillian/crypto/keys"
+	"github.com/google/trillian/log/admission"
+	"github.com/google/trillian/log/export"
 	"github.com/google/trillian/monitoring"
 	"github.com/google/trillian/quota"
 	"github.com/google/trillian/storage"
@@ -43,6 +45,12 @@ type Registry struct {
 	// NewKeyProto creates a new private key based on a key specification.
 	// It returns a proto that can be passed to a keys.ProtoHandler to get a crypto.Signer.
 	NewKeyProto keys.ProtoGenerator
+	// LeafValidator, if set, checks the leaves added to logs, and rejects
+	// those which fail its checks.
+	LeafValidator admission.Validator
+	// ExportHook, if set, is called by the log signer with the leaves it
+	// integrates.
+	ExportHook export.Hook
 	// SetProcessStatus sets the current process status for diagnostic purposes.
 	SetProcessStatus func(string)
 }
@@ -17,6 +17,7 @@ package storagetest
 import (
 	"bytes"
 	"context"
+	"crypto"
 	"crypto/sha256"
 	"fmt"
 	"reflect"
@@ -28,11 +29,13 @@ import (
 	"github.com/google/go-cmp/cmp"
 	"github.com/google/trillian"
 	"github.com/google/trillian/storage"
+	"github.com/google/trillian/testonly"
 	"github.com/google/trillian/types"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
 	"google.golang.org/protobuf/testing/protocmp"
 
+	tcrypto "github.com/google/trillian/crypto"
 	storageto "github.com/google/trillian/storage/testonly"
 )
 
@@ -267,6 +270,66 @@ func (t *addSequencedLeavesTest) verifySequencedLeaves(start, count int64, exp [
 	}
 }
 
+func (*logTests) TestFencingToken(ctx context.Context, t *testing.T, s storage.LogStorage, as storage.AdminStorage) {
+	tree := mustCreateTree(ctx, t, as, storageto.LogTree)
+	mustSignAndStoreLogRoot(ctx, t, s, tree, &types.LogRootV1{})
+	signer := tcrypto.NewSigner(0, testonly.NewSignerWithFixedSig(nil, []byte("notnil")), crypto.SHA256)
+
+	var revision uint64
+	for _, tc := range []struct {
+		desc    string
+		token   int64
+		wantErr error
+	}{
+		{desc: "first-master", token: 5},
+		{desc: "former-master", token: 3, wantErr: storage.ErrStaleFencingToken},
+		{desc: "same-master", token: 5},
+		{desc: "unfenced", token: 0, wantErr: storage.ErrStaleFencingToken},
+		{desc: "new-master", token: 7},
+		{desc: "overtaken-master", token: 5, wantErr: storage.ErrStaleFencingToken},
+	} {
+		t.Run(tc.desc, func(t *testing.T) {
+			root, err := signer.SignLogRoot(&types.LogRootV1{Revision: revision + 1, TimestampNanos: revision + 1})
+			if err != nil {
+				t.Fatalf("SignLogRoot(): %v", err)
+			}
+			err = s.ReadWriteTransaction(storage.WithFencingToken(ctx, tc.token), tree, func(ctx context.Context, tx storage.LogTreeTX) error {
+				return tx.StoreSignedLogRoot(ctx, root)
+			})
+			if err != tc.wantErr {
+				t.Fatalf("ReadWriteTransaction(): %v, want %v", err, tc.wantErr)
+			}
+			if err == nil {
+				revision++
+			}
+		})
+	}
+}
+
+func (*logTests) TestLogRootMetadata(ctx context.Context, t *testing.T, s storage.LogStorage, as storage.AdminStorage) {
+	tree := mustCreateTree(ctx, t, as, storageto.LogTree)
+	mustSignAndStoreLogRoot(ctx, t, s, tree, &types.LogRootV1{RootHash: []byte{0}})
+	want := &types.LogRootV1{RootHash: []byte{0}, TimestampNanos: 1, Revision: 1, Metadata: []byte("final")}
+	mustSignAndStoreLogRoot(ctx, t, s, tree, want)
+
+	tx, err := s.SnapshotForTree(ctx, tree)
+	if err != nil {
+		t.Fatalf("SnapshotForTree(): %v", err)
+	}
+	defer tx.Close()
+	slr, err := tx.LatestSignedLogRoot(ctx)
+	if err != nil {
+		t.Fatalf("LatestSignedLogRoot(): %v", err)
+	}
+	var got types.LogRootV1
+	if err := got.UnmarshalBinary(slr.LogRoot); err != nil {
+		t.Fatalf("UnmarshalBinary(): %v", err)
+	}
+	if !reflect.DeepEqual(&got, want) {
+		t.Errorf("LatestSignedLogRoot() = %+v, want %+v", got, want)
+	}
+}
+
 func (*logTests) TestAddSequencedLeavesUnordered(ctx context.Context, t *testing.T, s storage.LogStorage, as storage.AdminStorage) {
 	const chunk = 5
 	const count = chunk * 5
@@ -0,0 +1,138 @@
+// Copyright 2020 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package admission provides checks which leaves must pass before the log
+// server stores them.
+package admission
+
+import (
+	"context"
+	"crypto"
+
+	"github.com/google/trillian"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	tcrypto "github.com/google/trillian/crypto"
+)
+
+// Validator checks the leaves added to logs through QueueLeaves and
+// AddSequencedLeaves. It must be safe for concurrent use.
+type Validator interface {
+	// Validate returns nil if the leaf can be added to the tree, or an error
+	// saying why not. Errors which are not gRPC statuses are reported to the
+	// client as InvalidArgument.
+	Validate(ctx context.Context, tree *trillian.Tree, leaf *trillian.LogLeaf) error
+}
+
+// ValidatorFunc adapts a function to the Validator interface.
+type ValidatorFunc func(ctx context.Context, tree *trillian.Tree, leaf *trillian.LogLeaf) error
+
+// Validate calls f.
+func (f ValidatorFunc) Validate(ctx context.Context, tree *trillian.Tree, leaf *trillian.LogLeaf) error {
+	return f(ctx, tree, leaf)
+}
+
+// Status returns the status to report for a leaf rejected with err.
+func Status(err error) *status.Status {
+	if s, ok := status.FromError(err); ok {
+		return s
+	}
+	return status.New(codes.InvalidArgument, err.Error())
+}
+
+// All is a Validator which accepts the leaves which all of the validators
+// accept, and rejects the others with the error of the first validator which
+// rejects them.
+type All []Validator
+
+// Validate runs the validators in order.
+func (a All) Validate(ctx context.Context, tree *trillian.Tree, leaf *trillian.LogLeaf) error {
+	for _, v := range a {
+		if err := v.Validate(ctx, tree, leaf); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// PerTree is a Validator which checks the leaves of each tree with the
+// validator configured for it.
+type PerTree struct {
+	// Trees holds the validators of trees by tree ID.
+	Trees map[int64]Validator
+	// Default, if not nil, validates the leaves of the trees not in Trees.
+	Default Validator
+}
+
+// Validate runs the validator of the tree, if any.
+func (p PerTree) Validate(ctx context.Context, tree *trillian.Tree, leaf *trillian.LogLeaf) error {
+	v, ok := p.Trees[tree.TreeId]
+	if !ok {
+		v = p.Default
+	}
+	if v == nil {
+		return nil
+	}
+	return v.Validate(ctx, tree, leaf)
+}
+
+// MaxSize is a Validator which rejects leaves with too much data. A zero
+// limit means no limit.
+type MaxSize struct {
+	// LeafValue is the max size of LeafValue.
+	LeafValue int
+	// ExtraData is the max size of ExtraData.
+	ExtraData int
+}
+
+// Validate checks the sizes of the leaf data.
+func (m MaxSize) Validate(ctx context.Context, tree *trillian.Tree, leaf *trillian.LogLeaf) error {
+	if got := len(leaf.LeafValue); m.LeafValue > 0 && got > m.LeafValue {
+		return status.Errorf(codes.InvalidArgument, "leaf_value has %d bytes, more than the limit of %d", got, m.LeafValue)
+	}
+	if got := len(leaf.ExtraData); m.ExtraData > 0 && got > m.ExtraData {
+		return status.Errorf(codes.InvalidArgument, "extra_data has %d bytes, more than the limit of %d", got, m.ExtraData)
+	}
+	return nil
+}
+
+// Signature is a Validator which only accepts leaves whose LeafValue is
+// signed by one of a set of keys.
+type Signature struct {
+	// Keys are the public keys which may sign leaves.
+	Keys []crypto.PublicKey
+	// Hash is the hash function used when signing.
+	Hash crypto.Hash
+	// SignatureOf returns the signature of a leaf. If nil, the signature is
+	// the ExtraData of the leaf.
+	SignatureOf func(leaf *trillian.LogLeaf) []byte
+}
+
+// Validate checks the signature of the leaf against each of the keys.
+func (s Signature) Validate(ctx context.Context, tree *trillian.Tree, leaf *trillian.LogLeaf) error {
+	sig := leaf.ExtraData
+	if s.SignatureOf != nil {
+		sig = s.SignatureOf(leaf)
+	}
+	if len(sig) == 0 {
+		return status.Error(codes.PermissionDenied, "leaf is not signed")
+	}
+	for _, key := range s.Keys {
+		if tcrypto.Verify(key, s.Hash, leaf.LeafValue, sig) == nil {
+			return nil
+		}
+	}
+	return status.Error(codes.PermissionDenied, "leaf signature doesn't match any of the allowed keys")
+}
@@ -0,0 +1,102 @@
+// Copyright 2020 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package admission
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"errors"
+	"testing"
+
+	"github.com/google/trillian"
+	"github.com/google/trillian/crypto/keys/pem"
+	"github.com/google/trillian/testonly"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+var tree = &trillian.Tree{TreeId: 1}
+
+func sign(t *testing.T, signer crypto.Signer, data []byte) []byte {
+	t.Helper()
+	digest := sha256.Sum256(data)
+	sig, err := signer.Sign(rand.Reader, digest[:], crypto.SHA256)
+	if err != nil {
+		t.Fatalf("Sign(): %v", err)
+	}
+	return sig
+}
+
+func TestValidators(t *testing.T) {
+	ctx := context.Background()
+	key, err := pem.UnmarshalPrivateKey(testonly.DemoPrivateKey, testonly.DemoPrivateKeyPass)
+	if err != nil {
+		t.Fatalf("UnmarshalPrivateKey(): %v", err)
+	}
+	otherKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("GenerateKey(): %v", err)
+	}
+	sigValidator := Signature{Keys: []crypto.PublicKey{otherKey.Public(), key.Public()}, Hash: crypto.SHA256}
+	value := []byte("value")
+
+	for _, tc := range []struct {
+		desc     string
+		v        Validator
+		leaf     *trillian.LogLeaf
+		wantCode codes.Code
+	}{
+		{desc: "size-ok", v: MaxSize{LeafValue: 5, ExtraData: 1}, leaf: &trillian.LogLeaf{LeafValue: value, ExtraData: []byte("x")}},
+		{desc: "size-no-limit", v: MaxSize{}, leaf: &trillian.LogLeaf{LeafValue: value}},
+		{desc: "size-value", v: MaxSize{LeafValue: 4}, leaf: &trillian.LogLeaf{LeafValue: value}, wantCode: codes.InvalidArgument},
+		{desc: "size-extra", v: MaxSize{ExtraData: 1}, leaf: &trillian.LogLeaf{LeafValue: value, ExtraData: []byte("xx")}, wantCode: codes.InvalidArgument},
+		{desc: "sig-ok", v: sigValidator, leaf: &trillian.LogLeaf{LeafValue: value, ExtraData: sign(t, key, value)}},
+		{desc: "sig-missing", v: sigValidator, leaf: &trillian.LogLeaf{LeafValue: value}, wantCode: codes.PermissionDenied},
+		{desc: "sig-other-data", v: sigValidator, leaf: &trillian.LogLeaf{LeafValue: []byte("other"), ExtraData: sign(t, key, value)}, wantCode: codes.PermissionDenied},
+		{desc: "sig-unknown-key", v: Signature{Keys: []crypto.PublicKey{otherKey.Public()}, Hash: crypto.SHA256}, leaf: &trillian.LogLeaf{LeafValue: value, ExtraData: sign(t, key, value)}, wantCode: codes.PermissionDenied},
+		{
+			desc: "sig-custom-location",
+			v: Signature{Keys: []crypto.PublicKey{key.Public()}, Hash: crypto.SHA256, SignatureOf: func(l *trillian.LogLeaf) []byte {
+				return l.LeafIdentityHash
+			}},
+			leaf: &trillian.LogLeaf{LeafValue: value, LeafIdentityHash: sign(t, key, value)},
+		},
+		{desc: "all-ok", v: All{MaxSize{LeafValue: 10}, MaxSize{LeafValue: 5}}, leaf: &trillian.LogLeaf{LeafValue: value}},
+		{desc: "all-rejected", v: All{MaxSize{LeafValue: 10}, sigValidator}, leaf: &trillian.LogLeaf{LeafValue: value}, wantCode: codes.PermissionDenied},
+		{desc: "per-tree", v: PerTree{Trees: map[int64]Validator{1: MaxSize{LeafValue: 1}}}, leaf: &trillian.LogLeaf{LeafValue: value}, wantCode: codes.InvalidArgument},
+		{desc: "per-tree-default", v: PerTree{Trees: map[int64]Validator{2: MaxSize{}}, Default: MaxSize{LeafValue: 1}}, leaf: &trillian.LogLeaf{LeafValue: value}, wantCode: codes.InvalidArgument},
+		{desc: "per-tree-none", v: PerTree{Trees: map[int64]Validator{2: MaxSize{LeafValue: 1}}}, leaf: &trillian.LogLeaf{LeafValue: value}},
+	} {
+		t.Run(tc.desc, func(t *testing.T) {
+			err := tc.v.Validate(ctx, tree, tc.leaf)
+			if got := status.Code(err); got != tc.wantCode {
+				t.Errorf("Validate(): %v, want code %v", err, tc.wantCode)
+			}
+		})
+	}
+}
+
+func TestStatus(t *testing.T) {
+	if got, want := Status(errors.New("bad")).Code(), codes.InvalidArgument; got != want {
+		t.Errorf("Status(plain error).Code() = %v, want %v", got, want)
+	}
+	if got, want := Status(status.Error(codes.PermissionDenied, "no")).Code(), codes.PermissionDenied; got != want {
+		t.Errorf("Status(status error).Code() = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,129 @@
+// Copyright 2020 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package log
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/golang/glog"
+	"github.com/google/trillian/monitoring"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// BatchSizeConfig configures adaptive sizing of sequencer batches.
+type BatchSizeConfig struct {
+	// MinBatchSize and MaxBatchSize bound the batch size of each log. Adaptive
+	// sizing is disabled if MaxBatchSize is zero.
+	MinBatchSize int
+	MaxBatchSize int
+	// TargetLatency is the sequencing latency above which batches are made
+	// smaller. If zero, latency is not taken into account.
+	TargetLatency time.Duration
+}
+
+// batchSizer adapts the number of leaves that the sequencer dequeues from each
+// log. Batches grow while the queue has more leaves than a batch and they are
+// integrated within the target latency, and shrink when they are slower than
+// that or fail because of load, e.g. with transaction timeouts. Other errors,
+// such as a log needing initialization, leave the size unchanged.
+type batchSizer struct {
+	cfg BatchSizeConfig
+
+	mu    sync.Mutex
+	sizes map[int64]int
+}
+
+func newBatchSizer(cfg BatchSizeConfig, mf monitoring.MetricFactory) *batchSizer {
+	sequencerOnce.Do(func() {
+		createSequencerMetrics(mf)
+	})
+	if cfg.MinBatchSize < 1 {
+		cfg.MinBatchSize = 1
+	}
+	if cfg.MaxBatchSize < cfg.MinBatchSize {
+		cfg.MaxBatchSize = cfg.MinBatchSize
+	}
+	return &batchSizer{cfg: cfg, sizes: make(map[int64]int)}
+}
+
+// clamp returns the size bounded by the configured min and max values.
+func (b *batchSizer) clamp(size int) int {
+	if size < b.cfg.MinBatchSize {
+		return b.cfg.MinBatchSize
+	}
+	if size > b.cfg.MaxBatchSize {
+		return b.cfg.MaxBatchSize
+	}
+	return size
+}
+
+// size returns the batch size to use for the next batch of the log. Logs start
+// with the passed in initial size.
+func (b *batchSizer) size(logID int64, initial int) int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	size, ok := b.sizes[logID]
+	if !ok {
+		size = b.clamp(initial)
+		b.sizes[logID] = size
+		seqBatchSize.Set(float64(size), strconv.FormatInt(logID, 10))
+	}
+	return size
+}
+
+// update adjusts the batch size of the log, given the outcome of a batch of
+// the passed in size, which integrated count leaves. The depth is the number of
+// leaves in the queue of the log as counted by storage, or negative if unknown,
+// in which case a full batch is taken to mean that the queue is deep.
+func (b *batchSizer) update(ctx context.Context, logID int64, size, count int, depth int64, latency time.Duration, err error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	newSize := size
+	switch {
+	case err != nil:
+		if isLoadError(ctx, err) {
+			newSize = size / 2
+		}
+	case b.cfg.TargetLatency > 0 && latency > b.cfg.TargetLatency:
+		newSize = size * 3 / 4
+	case depth > int64(size), depth < 0 && count >= size:
+		// The queue is deeper than the batch, so grow it.
+		newSize = size + size/4 + 1
+	}
+	newSize = b.clamp(newSize)
+	if newSize != size {
+		glog.V(1).Infof("%v: batch size %d -> %d (count %d, depth %d, latency %v, err %v)", logID, size, newSize, count, depth, latency, err)
+	}
+	b.sizes[logID] = newSize
+	seqBatchSize.Set(float64(newSize), strconv.FormatInt(logID, 10))
+}
+
+// isLoadError returns whether a batch failed because storage couldn't keep
+// up, so that a smaller batch may succeed.
+func isLoadError(ctx context.Context, err error) bool {
+	if ctx.Err() != nil || errors.Is(err, context.DeadlineExceeded) {
+		return true
+	}
+	switch status.Code(err) {
+	case codes.DeadlineExceeded, codes.ResourceExhausted, codes.Unavailable, codes.Aborted:
+		return true
+	}
+	return false
+}
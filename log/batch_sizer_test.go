@@ -0,0 +1,79 @@
+// Copyright 2020 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package log
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/trillian/storage"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestBatchSizer(t *testing.T) {
+	cfg := BatchSizeConfig{MinBatchSize: 10, MaxBatchSize: 100, TargetLatency: time.Second}
+	for _, tc := range []struct {
+		desc    string
+		initial int
+		count   int
+		depth   int64
+		latency time.Duration
+		err     error
+		want    int
+	}{
+		{desc: "deep-queue", initial: 40, count: 40, depth: 500, latency: time.Millisecond, want: 51},
+		{desc: "deep-queue-at-max", initial: 100, count: 100, depth: 500, latency: time.Millisecond, want: 100},
+		{desc: "drained-queue", initial: 40, count: 40, depth: 0, latency: time.Millisecond, want: 40},
+		{desc: "shallow-queue", initial: 40, count: 3, depth: 3, latency: time.Millisecond, want: 40},
+		{desc: "unknown-depth-full-batch", initial: 40, count: 40, depth: -1, latency: time.Millisecond, want: 51},
+		{desc: "unknown-depth-shallow-queue", initial: 40, count: 3, depth: -1, latency: time.Millisecond, want: 40},
+		{desc: "slow", initial: 40, count: 40, depth: 500, latency: 2 * time.Second, want: 30},
+		{desc: "slow-shallow-queue", initial: 40, count: 3, depth: 3, latency: 2 * time.Second, want: 30},
+		{desc: "timeout", initial: 40, depth: 500, err: context.DeadlineExceeded, want: 20},
+		{desc: "unavailable", initial: 40, depth: 500, err: status.Error(codes.Unavailable, "overloaded"), want: 20},
+		{desc: "timeout-at-min", initial: 10, depth: 500, err: context.DeadlineExceeded, want: 10},
+		{desc: "other-error", initial: 40, depth: 500, err: storage.ErrTreeNeedsInit, want: 40},
+		{desc: "initial-below-min", initial: 1, count: 0, latency: time.Millisecond, want: 10},
+		{desc: "initial-above-max", initial: 1000, count: 0, latency: time.Millisecond, want: 100},
+	} {
+		t.Run(tc.desc, func(t *testing.T) {
+			b := newBatchSizer(cfg, nil)
+			size := b.size(1, tc.initial)
+			b.update(context.Background(), 1, size, tc.count, tc.depth, tc.latency, tc.err)
+			if got := b.size(1, tc.initial); got != tc.want {
+				t.Errorf("size()=%d, want %d", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestBatchSizerConverges(t *testing.T) {
+	b := newBatchSizer(BatchSizeConfig{MinBatchSize: 1, MaxBatchSize: 10000, TargetLatency: time.Second}, nil)
+	// Simulate a deep queue and a database which takes 1ms per leaf, so the
+	// batch size should settle around 1000 leaves.
+	for i := 0; i < 100; i++ {
+		size := b.size(1, 50)
+		b.update(context.Background(), 1, size, size, 100000, time.Duration(size)*time.Millisecond, nil)
+	}
+	if got := b.size(1, 50); got < 500 || got > 1300 {
+		t.Errorf("size()=%d, want around 1000", got)
+	}
+	// Logs are sized independently.
+	if got, want := b.size(2, 50), 50; got != want {
+		t.Errorf("size()=%d for another log, want %d", got, want)
+	}
+}
@@ -0,0 +1,147 @@
+// Copyright 2020 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package export
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/golang/glog"
+	"github.com/google/trillian"
+)
+
+// maxPendingLeaves is the max number of leaves which Async holds for a log
+// while its hook is busy. Beyond that, the leaves are dropped, and the hook
+// has to read them from storage.
+const maxPendingLeaves = 10000
+
+// Async is a Hook which calls another hook in the background, so that a slow
+// export doesn't hold up the log signer. Each log has its own goroutine, so a
+// slow log doesn't hold up the others either, and each call to the hook is
+// bounded by a timeout.
+//
+// If roots arrive faster than the hook handles them, Async skips to the
+// latest root of the log, and passes on the leaves of the skipped roots with
+// it. Hooks must therefore cope with being called for some roots only, as the
+// Exporter does by catching up from storage.
+type Async struct {
+	hook    Hook
+	timeout time.Duration
+
+	mu     sync.Mutex
+	logs   map[int64]*asyncLog
+	closed bool
+	done   chan struct{}
+	wg     sync.WaitGroup
+}
+
+// asyncLog holds the root of a log waiting to be passed to the hook.
+type asyncLog struct {
+	wake chan struct{}
+
+	tree   *trillian.Tree
+	root   *trillian.SignedLogRoot
+	leaves []*trillian.LogLeaf
+}
+
+// NewAsync returns an Async which calls hook with the given timeout. Close
+// must be called to stop its goroutines.
+func NewAsync(hook Hook, timeout time.Duration) *Async {
+	return &Async{
+		hook:    hook,
+		timeout: timeout,
+		logs:    make(map[int64]*asyncLog),
+		done:    make(chan struct{}),
+	}
+}
+
+// Integrated queues the root and leaves for the hook, and returns straight
+// away. It never fails: errors of the hook are only logged.
+func (a *Async) Integrated(ctx context.Context, tree *trillian.Tree, root *trillian.SignedLogRoot, leaves []*trillian.LogLeaf) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if a.closed {
+		return nil
+	}
+	l, ok := a.logs[tree.TreeId]
+	if !ok {
+		l = &asyncLog{wake: make(chan struct{}, 1)}
+		a.logs[tree.TreeId] = l
+		a.wg.Add(1)
+		go a.run(l)
+	}
+	l.tree, l.root, l.leaves = tree, root, appendLeaves(l.leaves, leaves)
+	select {
+	case l.wake <- struct{}{}:
+	default:
+	}
+	return nil
+}
+
+// appendLeaves returns the pending leaves followed by the new ones, as long as
+// they are contiguous and not too many. Otherwise only the new leaves are
+// kept, and the hook catches up with the others by itself.
+func appendLeaves(pending, leaves []*trillian.LogLeaf) []*trillian.LogLeaf {
+	switch {
+	case len(pending) == 0:
+		return leaves
+	case len(leaves) == 0:
+		return pending
+	case pending[len(pending)-1].LeafIndex+1 != leaves[0].LeafIndex,
+		len(pending)+len(leaves) > maxPendingLeaves:
+		return leaves
+	}
+	// Don't append to pending in place, as it may share the array of the
+	// leaves passed to an earlier call.
+	ret := make([]*trillian.LogLeaf, 0, len(pending)+len(leaves))
+	return append(append(ret, pending...), leaves...)
+}
+
+// run passes the roots of a log to the hook until Close is called.
+func (a *Async) run(l *asyncLog) {
+	defer a.wg.Done()
+	for {
+		select {
+		case <-l.wake:
+		case <-a.done:
+			return
+		}
+		a.mu.Lock()
+		tree, root, leaves := l.tree, l.root, l.leaves
+		l.leaves = nil
+		a.mu.Unlock()
+
+		ctx, cancel := context.WithTimeout(context.Background(), a.timeout)
+		if err := a.hook.Integrated(ctx, tree, root, leaves); err != nil {
+			glog.Warningf("%v: failed to export leaves: %v", tree.TreeId, err)
+		}
+		cancel()
+	}
+}
+
+// Close stops passing roots to the hook, and waits for the calls in progress
+// to return.
+func (a *Async) Close() {
+	a.mu.Lock()
+	if a.closed {
+		a.mu.Unlock()
+		return
+	}
+	a.closed = true
+	close(a.done)
+	a.mu.Unlock()
+	a.wg.Wait()
+}
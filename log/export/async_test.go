@@ -0,0 +1,91 @@
+// Copyright 2020 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package export
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/trillian"
+)
+
+func TestAsync(t *testing.T) {
+	ctx := context.Background()
+	entered, block := make(chan struct{}, 10), make(chan struct{})
+	calls := make(chan []*trillian.LogLeaf, 10)
+	hook := hookFunc(func(ctx context.Context, tree *trillian.Tree, root *trillian.SignedLogRoot, leaves []*trillian.LogLeaf) error {
+		if tree.TreeId == treeID {
+			entered <- struct{}{}
+			select {
+			case <-block:
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		}
+		calls <- leaves
+		return nil
+	})
+	a := NewAsync(hook, time.Minute)
+	defer a.Close()
+
+	// The calls for one log return straight away, even while the hook is
+	// blocked, and don't hold up other logs.
+	leaves := testLeaves(9)
+	for i := 0; i < 3; i++ {
+		if err := a.Integrated(ctx, tree, rootOfSize(t, 3*i+3), leaves[3*i:3*i+3]); err != nil {
+			t.Fatalf("Integrated(): %v", err)
+		}
+		if i == 0 {
+			<-entered
+		}
+	}
+	other := &trillian.Tree{TreeId: treeID + 1}
+	if err := a.Integrated(ctx, other, rootOfSize(t, 1), leaves[:1]); err != nil {
+		t.Fatalf("Integrated(): %v", err)
+	}
+	checkIndices(t, indices(<-calls), wantIndices(0, 1))
+
+	// The hook gets the first root, and then the last one with the leaves of
+	// the skipped root.
+	close(block)
+	checkIndices(t, indices(<-calls), wantIndices(0, 3))
+	checkIndices(t, indices(<-calls), wantIndices(3, 9))
+}
+
+func TestAsyncTimeout(t *testing.T) {
+	errs := make(chan error, 1)
+	hook := hookFunc(func(ctx context.Context, tree *trillian.Tree, root *trillian.SignedLogRoot, leaves []*trillian.LogLeaf) error {
+		<-ctx.Done()
+		errs <- ctx.Err()
+		return ctx.Err()
+	})
+	a := NewAsync(hook, 10*time.Millisecond)
+	defer a.Close()
+	if err := a.Integrated(context.Background(), tree, rootOfSize(t, 0), nil); err != nil {
+		t.Fatalf("Integrated(): %v", err)
+	}
+	if err := <-errs; err != context.DeadlineExceeded {
+		t.Errorf("hook called with context ending in %v, want %v", err, context.DeadlineExceeded)
+	}
+}
+
+func indices(leaves []*trillian.LogLeaf) []int64 {
+	var ret []int64
+	for _, l := range leaves {
+		ret = append(ret, l.LeafIndex)
+	}
+	return ret
+}
@@ -0,0 +1,233 @@
+// Copyright 2020 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package export passes the leaves integrated by the log signer on to
+// external sinks, such as files or downstream indexers.
+package export
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"sync"
+
+	"github.com/golang/glog"
+	"github.com/google/trillian"
+	"github.com/google/trillian/monitoring"
+	"github.com/google/trillian/storage"
+	"github.com/google/trillian/types"
+)
+
+// Hook is called by the log signer after it commits a new root.
+type Hook interface {
+	// Integrated is called with the root and the leaves integrated under it,
+	// in index order. The leaves are empty if the root was signed without
+	// new leaves. It is called for one root of a log at a time, but for
+	// different logs concurrently.
+	//
+	// The root is committed by the time Integrated is called, so an error
+	// doesn't undo it, and is only logged. The signer waits for Integrated to
+	// return, so hooks which may be slow should be wrapped in an Async.
+	Integrated(ctx context.Context, tree *trillian.Tree, root *trillian.SignedLogRoot, leaves []*trillian.LogLeaf) error
+}
+
+// Hooks is a Hook which calls each of its hooks in turn. All of them are
+// called even if one fails, and the first error is returned.
+type Hooks []Hook
+
+// Integrated calls Integrated on each of the hooks.
+func (h Hooks) Integrated(ctx context.Context, tree *trillian.Tree, root *trillian.SignedLogRoot, leaves []*trillian.LogLeaf) error {
+	var firstErr error
+	for _, hook := range h {
+		if err := hook.Integrated(ctx, tree, root, leaves); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return firstErr
+}
+
+// Sink receives the exported leaves of logs. It must be safe for concurrent
+// use by different logs.
+type Sink interface {
+	// Write stores leaves of the log, which have contiguous indices and are
+	// covered by the root. Once it returns nil, the leaves won't be written
+	// again, unless they are lost by the CursorStore.
+	Write(ctx context.Context, treeID int64, root *trillian.SignedLogRoot, leaves []*trillian.LogLeaf) error
+}
+
+// CursorStore persists the index of the next leaf to export from each log.
+type CursorStore interface {
+	// Cursor returns the index of the next leaf to export from the log, which
+	// is 0 if the log hasn't been exported before.
+	Cursor(ctx context.Context, treeID int64) (int64, error)
+	// SetCursor records the index of the next leaf to export from the log.
+	SetCursor(ctx context.Context, treeID int64, next int64) error
+}
+
+var (
+	once           sync.Once
+	exportedLeaves monitoring.Counter
+	exportErrors   monitoring.Counter
+	exportLag      monitoring.Gauge
+)
+
+func createMetrics(mf monitoring.MetricFactory) {
+	if mf == nil {
+		mf = monitoring.InertMetricFactory{}
+	}
+	exportedLeaves = mf.NewCounter("exported_leaves", "Number of leaves written to the export sink", "logid")
+	exportErrors = mf.NewCounter("export_errors", "Number of failed exports of integrated leaves", "logid")
+	exportLag = mf.NewGauge("export_lag_leaves", "Number of integrated leaves not yet exported", "logid")
+}
+
+// LogLocks serializes the calls of a Hook for each log, while letting calls
+// for different logs run concurrently. The zero value is ready to use.
+type LogLocks struct {
+	mu    sync.Mutex
+	locks map[int64]*sync.Mutex
+}
+
+// Lock locks the log, and returns the function to unlock it.
+func (l *LogLocks) Lock(treeID int64) func() {
+	l.mu.Lock()
+	if l.locks == nil {
+		l.locks = make(map[int64]*sync.Mutex)
+	}
+	m, ok := l.locks[treeID]
+	if !ok {
+		m = &sync.Mutex{}
+		l.locks[treeID] = m
+	}
+	l.mu.Unlock()
+	m.Lock()
+	return m.Unlock
+}
+
+// Exporter is a Hook which writes the leaves of logs to a Sink in index
+// order, delivering every leaf at least once.
+//
+// It records the next leaf to export in a CursorStore after each write to the
+// sink. If the leaves passed to Integrated don't follow on from the cursor,
+// e.g. because an earlier export failed, the process restarted, or another
+// signer integrated some leaves, the Exporter reads the missing leaves from
+// storage first. It reads at most MaxCatchUp leaves per call, so it can fall
+// behind a busy log for a while, and catches up with later roots.
+type Exporter struct {
+	sink       Sink
+	cursors    CursorStore
+	logStorage storage.LogStorage
+
+	// BatchSize is the max number of leaves read from storage at once.
+	BatchSize int64
+	// MaxCatchUp is the max number of leaves read from storage per root.
+	MaxCatchUp int64
+
+	locks LogLocks
+}
+
+// NewExporter returns an Exporter which writes leaves to the sink, and reads
+// missing leaves from logStorage.
+func NewExporter(sink Sink, cursors CursorStore, logStorage storage.LogStorage, mf monitoring.MetricFactory) *Exporter {
+	once.Do(func() { createMetrics(mf) })
+	return &Exporter{
+		sink:       sink,
+		cursors:    cursors,
+		logStorage: logStorage,
+		BatchSize:  1000,
+		MaxCatchUp: 10000,
+	}
+}
+
+// Integrated writes the leaves from the cursor of the log up to the size of
+// the root to the sink.
+func (e *Exporter) Integrated(ctx context.Context, tree *trillian.Tree, slr *trillian.SignedLogRoot, leaves []*trillian.LogLeaf) error {
+	defer e.locks.Lock(tree.TreeId)()
+	label := strconv.FormatInt(tree.TreeId, 10)
+	err := e.export(ctx, tree, slr, leaves, label)
+	if err != nil {
+		exportErrors.Inc(label)
+	}
+	return err
+}
+
+func (e *Exporter) export(ctx context.Context, tree *trillian.Tree, slr *trillian.SignedLogRoot, leaves []*trillian.LogLeaf, label string) error {
+	var root types.LogRootV1
+	if err := root.UnmarshalBinary(slr.GetLogRoot()); err != nil {
+		return fmt.Errorf("failed to parse log root: %v", err)
+	}
+	size := int64(root.TreeSize)
+	next, err := e.cursors.Cursor(ctx, tree.TreeId)
+	if err != nil {
+		return fmt.Errorf("failed to read export cursor: %v", err)
+	}
+	defer func() { exportLag.Set(float64(size-next), label) }()
+
+	var first int64
+	if len(leaves) > 0 {
+		first = leaves[0].LeafIndex
+	}
+	for catchUp := int64(0); next < size; {
+		var batch []*trillian.LogLeaf
+		if end := first + int64(len(leaves)); first <= next && next < end {
+			batch = leaves[next-first:]
+		} else {
+			if catchUp >= e.MaxCatchUp {
+				glog.Infof("%v: export is %d leaves behind, catching up with the next root", tree.TreeId, size-next)
+				return nil
+			}
+			end := size
+			if len(leaves) > 0 && next < first {
+				end = first
+			}
+			count := end - next
+			if count > e.BatchSize {
+				count = e.BatchSize
+			}
+			if batch, err = e.read(ctx, tree, next, count); err != nil {
+				return err
+			}
+			catchUp += int64(len(batch))
+		}
+
+		if err := e.sink.Write(ctx, tree.TreeId, slr, batch); err != nil {
+			return fmt.Errorf("failed to export leaves [%d, %d): %v", next, next+int64(len(batch)), err)
+		}
+		exportedLeaves.Add(float64(len(batch)), label)
+		next += int64(len(batch))
+		if err := e.cursors.SetCursor(ctx, tree.TreeId, next); err != nil {
+			return fmt.Errorf("failed to store export cursor: %v", err)
+		}
+	}
+	return nil
+}
+
+// read returns the leaves [start, start+count) of the log from storage.
+func (e *Exporter) read(ctx context.Context, tree *trillian.Tree, start, count int64) ([]*trillian.LogLeaf, error) {
+	tx, err := e.logStorage.SnapshotForTree(ctx, tree)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Close()
+	leaves, err := tx.GetLeavesByRange(ctx, start, count)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read leaves [%d, %d): %v", start, start+count, err)
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+	if len(leaves) == 0 || leaves[0].LeafIndex != start {
+		return nil, fmt.Errorf("storage has no leaf %d", start)
+	}
+	return leaves, nil
+}
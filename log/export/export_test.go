@@ -0,0 +1,284 @@
+// Copyright 2020 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package export
+
+import (
+	"bufio"
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/golang/protobuf/proto" //nolint:staticcheck
+	"github.com/google/trillian"
+	"github.com/google/trillian/storage"
+	"github.com/google/trillian/types"
+)
+
+const treeID = 7
+
+var tree = &trillian.Tree{TreeId: treeID, TreeType: trillian.TreeType_LOG}
+
+// fakeStorage is a LogStorage which only serves GetLeavesByRange.
+type fakeStorage struct {
+	storage.LogStorage
+	leaves []*trillian.LogLeaf
+	reads  int
+}
+
+type fakeTX struct {
+	storage.ReadOnlyLogTreeTX
+	s *fakeStorage
+}
+
+func (s *fakeStorage) SnapshotForTree(ctx context.Context, tree *trillian.Tree) (storage.ReadOnlyLogTreeTX, error) {
+	return fakeTX{s: s}, nil
+}
+
+func (t fakeTX) GetLeavesByRange(ctx context.Context, start, count int64) ([]*trillian.LogLeaf, error) {
+	t.s.reads++
+	end := start + count
+	if end > int64(len(t.s.leaves)) {
+		end = int64(len(t.s.leaves))
+	}
+	return t.s.leaves[start:end], nil
+}
+
+func (t fakeTX) Commit(context.Context) error { return nil }
+func (t fakeTX) Close() error                 { return nil }
+
+// memSink is a Sink which records the leaves written to it.
+type memSink struct {
+	leaves []*trillian.LogLeaf
+	fail   bool
+}
+
+func (m *memSink) Write(ctx context.Context, treeID int64, root *trillian.SignedLogRoot, leaves []*trillian.LogLeaf) error {
+	if m.fail {
+		return errors.New("sink failed")
+	}
+	m.leaves = append(m.leaves, leaves...)
+	return nil
+}
+
+// indices returns the indices of the leaves written to the sink.
+func (m *memSink) indices() []int64 {
+	var ret []int64
+	for _, l := range m.leaves {
+		ret = append(ret, l.LeafIndex)
+	}
+	return ret
+}
+
+type memCursors map[int64]int64
+
+func (m memCursors) Cursor(ctx context.Context, treeID int64) (int64, error) {
+	return m[treeID], nil
+}
+
+func (m memCursors) SetCursor(ctx context.Context, treeID int64, next int64) error {
+	m[treeID] = next
+	return nil
+}
+
+func testLeaves(n int) []*trillian.LogLeaf {
+	leaves := make([]*trillian.LogLeaf, n)
+	for i := range leaves {
+		leaves[i] = &trillian.LogLeaf{LeafIndex: int64(i), LeafValue: []byte(fmt.Sprint(i))}
+	}
+	return leaves
+}
+
+func rootOfSize(t *testing.T, size int) *trillian.SignedLogRoot {
+	t.Helper()
+	data, err := (&types.LogRootV1{TreeSize: uint64(size)}).MarshalBinary()
+	if err != nil {
+		t.Fatalf("MarshalBinary(): %v", err)
+	}
+	return &trillian.SignedLogRoot{LogRoot: data}
+}
+
+func wantIndices(start, end int64) []int64 {
+	var ret []int64
+	for i := start; i < end; i++ {
+		ret = append(ret, i)
+	}
+	return ret
+}
+
+func checkIndices(t *testing.T, got, want []int64) {
+	t.Helper()
+	if len(got) != len(want) {
+		t.Fatalf("exported leaves %v, want %v", got, want)
+	}
+	for i := range got {
+		if got[i] != want[i] {
+			t.Fatalf("exported leaves %v, want %v", got, want)
+		}
+	}
+}
+
+func TestExporter(t *testing.T) {
+	ctx := context.Background()
+	leaves := testLeaves(30)
+	s := &fakeStorage{leaves: leaves}
+	sink := &memSink{}
+	cursors := memCursors{}
+	e := NewExporter(sink, cursors, s, nil)
+	e.BatchSize = 4
+
+	// Leaves which follow on from the cursor aren't read from storage.
+	for _, end := range []int{3, 3, 10} {
+		begin := int(cursors[treeID])
+		if err := e.Integrated(ctx, tree, rootOfSize(t, end), leaves[begin:end]); err != nil {
+			t.Fatalf("Integrated(%d): %v", end, err)
+		}
+	}
+	checkIndices(t, sink.indices(), wantIndices(0, 10))
+	if s.reads != 0 {
+		t.Errorf("Exporter read from storage %d times, want 0", s.reads)
+	}
+
+	// A failed export is retried with the next root.
+	sink.fail = true
+	if err := e.Integrated(ctx, tree, rootOfSize(t, 15), leaves[10:15]); err == nil {
+		t.Fatal("Integrated() with failing sink succeeded")
+	}
+	sink.fail = false
+	if err := e.Integrated(ctx, tree, rootOfSize(t, 20), leaves[15:20]); err != nil {
+		t.Fatalf("Integrated(): %v", err)
+	}
+	checkIndices(t, sink.indices(), wantIndices(0, 20))
+	if got, want := s.reads, 2; got != want {
+		t.Errorf("Exporter read from storage %d times, want %d", got, want)
+	}
+	if got, want := cursors[treeID], int64(20); got != want {
+		t.Errorf("cursor = %d, want %d", got, want)
+	}
+
+	// A root without leaves exports the leaves missing up to its size.
+	if err := e.Integrated(ctx, tree, rootOfSize(t, 30), nil); err != nil {
+		t.Fatalf("Integrated(): %v", err)
+	}
+	checkIndices(t, sink.indices(), wantIndices(0, 30))
+}
+
+func TestExporterCatchUpLimit(t *testing.T) {
+	ctx := context.Background()
+	leaves := testLeaves(30)
+	sink := &memSink{}
+	e := NewExporter(sink, memCursors{}, &fakeStorage{leaves: leaves}, nil)
+	e.BatchSize, e.MaxCatchUp = 4, 8
+
+	if err := e.Integrated(ctx, tree, rootOfSize(t, 30), leaves[25:]); err != nil {
+		t.Fatalf("Integrated(): %v", err)
+	}
+	checkIndices(t, sink.indices(), wantIndices(0, 8))
+	for i := 0; i < 3; i++ {
+		if err := e.Integrated(ctx, tree, rootOfSize(t, 30), nil); err != nil {
+			t.Fatalf("Integrated(): %v", err)
+		}
+	}
+	checkIndices(t, sink.indices(), wantIndices(0, 30))
+}
+
+// hookFunc is a Hook which calls itself.
+type hookFunc func(ctx context.Context, tree *trillian.Tree, root *trillian.SignedLogRoot, leaves []*trillian.LogLeaf) error
+
+func (f hookFunc) Integrated(ctx context.Context, tree *trillian.Tree, root *trillian.SignedLogRoot, leaves []*trillian.LogLeaf) error {
+	return f(ctx, tree, root, leaves)
+}
+
+func TestHooks(t *testing.T) {
+	var calls []int
+	hook := func(i int, err error) Hook {
+		return hookFunc(func(context.Context, *trillian.Tree, *trillian.SignedLogRoot, []*trillian.LogLeaf) error {
+			calls = append(calls, i)
+			return err
+		})
+	}
+	errFirst, errSecond := errors.New("first"), errors.New("second")
+	h := Hooks{hook(0, nil), hook(1, errFirst), hook(2, errSecond)}
+	if err := h.Integrated(context.Background(), tree, rootOfSize(t, 1), nil); err != errFirst {
+		t.Errorf("Integrated(): %v, want %v", err, errFirst)
+	}
+	if got, want := fmt.Sprint(calls), "[0 1 2]"; got != want {
+		t.Errorf("hooks called: %s, want %s", got, want)
+	}
+}
+
+func TestFileCursors(t *testing.T) {
+	ctx := context.Background()
+	c := FileCursors{Dir: t.TempDir()}
+	if got, err := c.Cursor(ctx, treeID); err != nil || got != 0 {
+		t.Fatalf("Cursor() = %d, %v; want 0, nil", got, err)
+	}
+	for _, next := range []int64{5, 1000} {
+		if err := c.SetCursor(ctx, treeID, next); err != nil {
+			t.Fatalf("SetCursor(%d): %v", next, err)
+		}
+		if got, err := c.Cursor(ctx, treeID); err != nil || got != next {
+			t.Errorf("Cursor() = %d, %v; want %d, nil", got, err, next)
+		}
+	}
+}
+
+func TestFileSink(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	leaves := testLeaves(5)
+	sink := NewFileSink(dir)
+	for _, batch := range [][]*trillian.LogLeaf{leaves[:2], leaves[2:]} {
+		if err := sink.Write(ctx, treeID, rootOfSize(t, 5), batch); err != nil {
+			t.Fatalf("Write(): %v", err)
+		}
+	}
+	if err := sink.Close(); err != nil {
+		t.Fatalf("Close(): %v", err)
+	}
+
+	f, err := os.Open(filepath.Join(dir, fmt.Sprintf("%d.leaves", treeID)))
+	if err != nil {
+		t.Fatalf("Open(): %v", err)
+	}
+	defer f.Close()
+	r := bufio.NewReader(f)
+	for i := 0; ; i++ {
+		size, err := binary.ReadUvarint(r)
+		if err == io.EOF {
+			if i != len(leaves) {
+				t.Errorf("read %d leaves, want %d", i, len(leaves))
+			}
+			break
+		} else if err != nil {
+			t.Fatalf("ReadUvarint(): %v", err)
+		}
+		data := make([]byte, size)
+		if _, err := io.ReadFull(r, data); err != nil {
+			t.Fatalf("ReadFull(): %v", err)
+		}
+		var leaf trillian.LogLeaf
+		if err := proto.Unmarshal(data, &leaf); err != nil {
+			t.Fatalf("Unmarshal(): %v", err)
+		}
+		if !proto.Equal(&leaf, leaves[i]) {
+			t.Errorf("leaf %d = %v, want %v", i, &leaf, leaves[i])
+		}
+	}
+}
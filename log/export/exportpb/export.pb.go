@@ -0,0 +1,403 @@
+// Copyright 2020 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.24.0
+// 	protoc        v3.12.3
+// source: log/export/exportpb/export.proto
+
+package exportpb
+
+import (
+	context "context"
+	proto "github.com/golang/protobuf/proto"
+	trillian "github.com/google/trillian"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+// This is a compile-time assertion that a sufficiently up-to-date version
+// of the legacy proto package is being used.
+const _ = proto.ProtoPackageIsVersion4
+
+type ExportRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// ID of the log which the leaves belong to.
+	TreeId int64 `protobuf:"varint,1,opt,name=tree_id,json=treeId,proto3" json:"tree_id,omitempty"`
+	// The root which the leaves have been integrated under.
+	SignedLogRoot *trillian.SignedLogRoot `protobuf:"bytes,2,opt,name=signed_log_root,json=signedLogRoot,proto3" json:"signed_log_root,omitempty"`
+	// The leaves, with contiguous indices.
+	Leaves []*trillian.LogLeaf `protobuf:"bytes,3,rep,name=leaves,proto3" json:"leaves,omitempty"`
+}
+
+func (x *ExportRequest) Reset() {
+	*x = ExportRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_log_export_exportpb_export_proto_msgTypes[0]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ExportRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ExportRequest) ProtoMessage() {}
+
+func (x *ExportRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_log_export_exportpb_export_proto_msgTypes[0]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ExportRequest.ProtoReflect.Descriptor instead.
+func (*ExportRequest) Descriptor() ([]byte, []int) {
+	return file_log_export_exportpb_export_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *ExportRequest) GetTreeId() int64 {
+	if x != nil {
+		return x.TreeId
+	}
+	return 0
+}
+
+func (x *ExportRequest) GetSignedLogRoot() *trillian.SignedLogRoot {
+	if x != nil {
+		return x.SignedLogRoot
+	}
+	return nil
+}
+
+func (x *ExportRequest) GetLeaves() []*trillian.LogLeaf {
+	if x != nil {
+		return x.Leaves
+	}
+	return nil
+}
+
+type ExportResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// ID of the log which the acknowledged leaves belong to.
+	TreeId int64 `protobuf:"varint,1,opt,name=tree_id,json=treeId,proto3" json:"tree_id,omitempty"`
+	// The index following the last acknowledged leaf.
+	NextIndex int64 `protobuf:"varint,2,opt,name=next_index,json=nextIndex,proto3" json:"next_index,omitempty"`
+}
+
+func (x *ExportResponse) Reset() {
+	*x = ExportResponse{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_log_export_exportpb_export_proto_msgTypes[1]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ExportResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ExportResponse) ProtoMessage() {}
+
+func (x *ExportResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_log_export_exportpb_export_proto_msgTypes[1]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ExportResponse.ProtoReflect.Descriptor instead.
+func (*ExportResponse) Descriptor() ([]byte, []int) {
+	return file_log_export_exportpb_export_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *ExportResponse) GetTreeId() int64 {
+	if x != nil {
+		return x.TreeId
+	}
+	return 0
+}
+
+func (x *ExportResponse) GetNextIndex() int64 {
+	if x != nil {
+		return x.NextIndex
+	}
+	return 0
+}
+
+var File_log_export_exportpb_export_proto protoreflect.FileDescriptor
+
+var file_log_export_exportpb_export_proto_rawDesc = []byte{
+	0x0a, 0x20, 0x6c, 0x6f, 0x67, 0x2f, 0x65, 0x78, 0x70, 0x6f, 0x72, 0x74, 0x2f, 0x65, 0x78, 0x70,
+	0x6f, 0x72, 0x74, 0x70, 0x62, 0x2f, 0x65, 0x78, 0x70, 0x6f, 0x72, 0x74, 0x2e, 0x70, 0x72, 0x6f,
+	0x74, 0x6f, 0x12, 0x08, 0x65, 0x78, 0x70, 0x6f, 0x72, 0x74, 0x70, 0x62, 0x1a, 0x0e, 0x74, 0x72,
+	0x69, 0x6c, 0x6c, 0x69, 0x61, 0x6e, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x1a, 0x16, 0x74, 0x72,
+	0x69, 0x6c, 0x6c, 0x69, 0x61, 0x6e, 0x5f, 0x6c, 0x6f, 0x67, 0x5f, 0x61, 0x70, 0x69, 0x2e, 0x70,
+	0x72, 0x6f, 0x74, 0x6f, 0x22, 0x94, 0x01, 0x0a, 0x0d, 0x45, 0x78, 0x70, 0x6f, 0x72, 0x74, 0x52,
+	0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x17, 0x0a, 0x07, 0x74, 0x72, 0x65, 0x65, 0x5f, 0x69,
+	0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x03, 0x52, 0x06, 0x74, 0x72, 0x65, 0x65, 0x49, 0x64, 0x12,
+	0x3f, 0x0a, 0x0f, 0x73, 0x69, 0x67, 0x6e, 0x65, 0x64, 0x5f, 0x6c, 0x6f, 0x67, 0x5f, 0x72, 0x6f,
+	0x6f, 0x74, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x17, 0x2e, 0x74, 0x72, 0x69, 0x6c, 0x6c,
+	0x69, 0x61, 0x6e, 0x2e, 0x53, 0x69, 0x67, 0x6e, 0x65, 0x64, 0x4c, 0x6f, 0x67, 0x52, 0x6f, 0x6f,
+	0x74, 0x52, 0x0d, 0x73, 0x69, 0x67, 0x6e, 0x65, 0x64, 0x4c, 0x6f, 0x67, 0x52, 0x6f, 0x6f, 0x74,
+	0x12, 0x29, 0x0a, 0x06, 0x6c, 0x65, 0x61, 0x76, 0x65, 0x73, 0x18, 0x03, 0x20, 0x03, 0x28, 0x0b,
+	0x32, 0x11, 0x2e, 0x74, 0x72, 0x69, 0x6c, 0x6c, 0x69, 0x61, 0x6e, 0x2e, 0x4c, 0x6f, 0x67, 0x4c,
+	0x65, 0x61, 0x66, 0x52, 0x06, 0x6c, 0x65, 0x61, 0x76, 0x65, 0x73, 0x22, 0x48, 0x0a, 0x0e, 0x45,
+	0x78, 0x70, 0x6f, 0x72, 0x74, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x17, 0x0a,
+	0x07, 0x74, 0x72, 0x65, 0x65, 0x5f, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x03, 0x52, 0x06,
+	0x74, 0x72, 0x65, 0x65, 0x49, 0x64, 0x12, 0x1d, 0x0a, 0x0a, 0x6e, 0x65, 0x78, 0x74, 0x5f, 0x69,
+	0x6e, 0x64, 0x65, 0x78, 0x18, 0x02, 0x20, 0x01, 0x28, 0x03, 0x52, 0x09, 0x6e, 0x65, 0x78, 0x74,
+	0x49, 0x6e, 0x64, 0x65, 0x78, 0x32, 0x4f, 0x0a, 0x0a, 0x4c, 0x65, 0x61, 0x66, 0x45, 0x78, 0x70,
+	0x6f, 0x72, 0x74, 0x12, 0x41, 0x0a, 0x06, 0x45, 0x78, 0x70, 0x6f, 0x72, 0x74, 0x12, 0x17, 0x2e,
+	0x65, 0x78, 0x70, 0x6f, 0x72, 0x74, 0x70, 0x62, 0x2e, 0x45, 0x78, 0x70, 0x6f, 0x72, 0x74, 0x52,
+	0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x18, 0x2e, 0x65, 0x78, 0x70, 0x6f, 0x72, 0x74, 0x70,
+	0x62, 0x2e, 0x45, 0x78, 0x70, 0x6f, 0x72, 0x74, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65,
+	0x22, 0x00, 0x28, 0x01, 0x30, 0x01, 0x42, 0x30, 0x5a, 0x2e, 0x67, 0x69, 0x74, 0x68, 0x75, 0x62,
+	0x2e, 0x63, 0x6f, 0x6d, 0x2f, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2f, 0x74, 0x72, 0x69, 0x6c,
+	0x6c, 0x69, 0x61, 0x6e, 0x2f, 0x6c, 0x6f, 0x67, 0x2f, 0x65, 0x78, 0x70, 0x6f, 0x72, 0x74, 0x2f,
+	0x65, 0x78, 0x70, 0x6f, 0x72, 0x74, 0x70, 0x62, 0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
+}
+
+var (
+	file_log_export_exportpb_export_proto_rawDescOnce sync.Once
+	file_log_export_exportpb_export_proto_rawDescData = file_log_export_exportpb_export_proto_rawDesc
+)
+
+func file_log_export_exportpb_export_proto_rawDescGZIP() []byte {
+	file_log_export_exportpb_export_proto_rawDescOnce.Do(func() {
+		file_log_export_exportpb_export_proto_rawDescData = protoimpl.X.CompressGZIP(file_log_export_exportpb_export_proto_rawDescData)
+	})
+	return file_log_export_exportpb_export_proto_rawDescData
+}
+
+var file_log_export_exportpb_export_proto_msgTypes = make([]protoimpl.MessageInfo, 2)
+var file_log_export_exportpb_export_proto_goTypes = []interface{}{
+	(*ExportRequest)(nil),          // 0: exportpb.ExportRequest
+	(*ExportResponse)(nil),         // 1: exportpb.ExportResponse
+	(*trillian.SignedLogRoot)(nil), // 2: trillian.SignedLogRoot
+	(*trillian.LogLeaf)(nil),       // 3: trillian.LogLeaf
+}
+var file_log_export_exportpb_export_proto_depIdxs = []int32{
+	2, // 0: exportpb.ExportRequest.signed_log_root:type_name -> trillian.SignedLogRoot
+	3, // 1: exportpb.ExportRequest.leaves:type_name -> trillian.LogLeaf
+	0, // 2: exportpb.LeafExport.Export:input_type -> exportpb.ExportRequest
+	1, // 3: exportpb.LeafExport.Export:output_type -> exportpb.ExportResponse
+	3, // [3:4] is the sub-list for method output_type
+	2, // [2:3] is the sub-list for method input_type
+	2, // [2:2] is the sub-list for extension type_name
+	2, // [2:2] is the sub-list for extension extendee
+	0, // [0:2] is the sub-list for field type_name
+}
+
+func init() { file_log_export_exportpb_export_proto_init() }
+func file_log_export_exportpb_export_proto_init() {
+	if File_log_export_exportpb_export_proto != nil {
+		return
+	}
+	if !protoimpl.UnsafeEnabled {
+		file_log_export_exportpb_export_proto_msgTypes[0].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ExportRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_log_export_exportpb_export_proto_msgTypes[1].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ExportResponse); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: file_log_export_exportpb_export_proto_rawDesc,
+			NumEnums:      0,
+			NumMessages:   2,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_log_export_exportpb_export_proto_goTypes,
+		DependencyIndexes: file_log_export_exportpb_export_proto_depIdxs,
+		MessageInfos:      file_log_export_exportpb_export_proto_msgTypes,
+	}.Build()
+	File_log_export_exportpb_export_proto = out.File
+	file_log_export_exportpb_export_proto_rawDesc = nil
+	file_log_export_exportpb_export_proto_goTypes = nil
+	file_log_export_exportpb_export_proto_depIdxs = nil
+}
+
+// Reference imports to suppress errors if they are not otherwise used.
+var _ context.Context
+var _ grpc.ClientConnInterface
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+const _ = grpc.SupportPackageIsVersion6
+
+// LeafExportClient is the client API for LeafExport service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://godoc.org/google.golang.org/grpc#ClientConn.NewStream.
+type LeafExportClient interface {
+	// Export receives batches of leaves from a log signer, and acknowledges each
+	// of them with an ExportResponse once it has stored them. The batches of a
+	// log are sent in leaf index order, but may repeat leaves which were sent
+	// before.
+	Export(ctx context.Context, opts ...grpc.CallOption) (LeafExport_ExportClient, error)
+}
+
+type leafExportClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewLeafExportClient(cc grpc.ClientConnInterface) LeafExportClient {
+	return &leafExportClient{cc}
+}
+
+func (c *leafExportClient) Export(ctx context.Context, opts ...grpc.CallOption) (LeafExport_ExportClient, error) {
+	stream, err := c.cc.NewStream(ctx, &_LeafExport_serviceDesc.Streams[0], "/exportpb.LeafExport/Export", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &leafExportExportClient{stream}
+	return x, nil
+}
+
+type LeafExport_ExportClient interface {
+	Send(*ExportRequest) error
+	Recv() (*ExportResponse, error)
+	grpc.ClientStream
+}
+
+type leafExportExportClient struct {
+	grpc.ClientStream
+}
+
+func (x *leafExportExportClient) Send(m *ExportRequest) error {
+	return x.ClientStream.SendMsg(m)
+}
+
+func (x *leafExportExportClient) Recv() (*ExportResponse, error) {
+	m := new(ExportResponse)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// LeafExportServer is the server API for LeafExport service.
+type LeafExportServer interface {
+	// Export receives batches of leaves from a log signer, and acknowledges each
+	// of them with an ExportResponse once it has stored them. The batches of a
+	// log are sent in leaf index order, but may repeat leaves which were sent
+	// before.
+	Export(LeafExport_ExportServer) error
+}
+
+// UnimplementedLeafExportServer can be embedded to have forward compatible implementations.
+type UnimplementedLeafExportServer struct {
+}
+
+func (*UnimplementedLeafExportServer) Export(LeafExport_ExportServer) error {
+	return status.Errorf(codes.Unimplemented, "method Export not implemented")
+}
+
+func RegisterLeafExportServer(s *grpc.Server, srv LeafExportServer) {
+	s.RegisterService(&_LeafExport_serviceDesc, srv)
+}
+
+func _LeafExport_Export_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(LeafExportServer).Export(&leafExportExportServer{stream})
+}
+
+type LeafExport_ExportServer interface {
+	Send(*ExportResponse) error
+	Recv() (*ExportRequest, error)
+	grpc.ServerStream
+}
+
+type leafExportExportServer struct {
+	grpc.ServerStream
+}
+
+func (x *leafExportExportServer) Send(m *ExportResponse) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func (x *leafExportExportServer) Recv() (*ExportRequest, error) {
+	m := new(ExportRequest)
+	if err := x.ServerStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+var _LeafExport_serviceDesc = grpc.ServiceDesc{
+	ServiceName: "exportpb.LeafExport",
+	HandlerType: (*LeafExportServer)(nil),
+	Methods:     []grpc.MethodDesc{},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "Export",
+			Handler:       _LeafExport_Export_Handler,
+			ServerStreams: true,
+			ClientStreams: true,
+		},
+	},
+	Metadata: "log/export/exportpb/export.proto",
+}
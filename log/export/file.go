@@ -0,0 +1,133 @@
+// Copyright 2020 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package export
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/golang/protobuf/proto" //nolint:staticcheck
+	"github.com/google/trillian"
+)
+
+// FileSink is a Sink which appends the leaves of each log to the file
+// <tree ID>.leaves in a directory. Each leaf is stored as a LogLeaf proto
+// preceded by its size as a uvarint, which is the proto format read by the
+// importleaves command.
+//
+// Leaves are delivered at least once, so readers should skip leaves whose
+// index they have seen before. A crash while writing can leave a truncated
+// leaf at the end of a file, which readers should also skip.
+type FileSink struct {
+	dir string
+
+	mu    sync.Mutex
+	files map[int64]*os.File
+}
+
+// NewFileSink returns a FileSink which writes to files in dir.
+func NewFileSink(dir string) *FileSink {
+	return &FileSink{dir: dir, files: make(map[int64]*os.File)}
+}
+
+func (f *FileSink) file(treeID int64) (*os.File, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if file, ok := f.files[treeID]; ok {
+		return file, nil
+	}
+	name := filepath.Join(f.dir, fmt.Sprintf("%d.leaves", treeID))
+	file, err := os.OpenFile(name, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0644)
+	if err != nil {
+		return nil, err
+	}
+	f.files[treeID] = file
+	return file, nil
+}
+
+// Write appends the leaves to the file of the log, and syncs it.
+func (f *FileSink) Write(ctx context.Context, treeID int64, root *trillian.SignedLogRoot, leaves []*trillian.LogLeaf) error {
+	var buf bytes.Buffer
+	for _, leaf := range leaves {
+		data, err := proto.Marshal(leaf)
+		if err != nil {
+			return err
+		}
+		var size [binary.MaxVarintLen64]byte
+		buf.Write(size[:binary.PutUvarint(size[:], uint64(len(data)))])
+		buf.Write(data)
+	}
+	file, err := f.file(treeID)
+	if err != nil {
+		return err
+	}
+	if _, err := file.Write(buf.Bytes()); err != nil {
+		return err
+	}
+	return file.Sync()
+}
+
+// Close closes the files of the sink.
+func (f *FileSink) Close() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	var firstErr error
+	for id, file := range f.files {
+		if err := file.Close(); err != nil && firstErr == nil {
+			firstErr = err
+		}
+		delete(f.files, id)
+	}
+	return firstErr
+}
+
+// FileCursors is a CursorStore which keeps the cursor of each log in the file
+// <tree ID>.cursor in a directory.
+type FileCursors struct {
+	Dir string
+}
+
+func (f FileCursors) path(treeID int64) string {
+	return filepath.Join(f.Dir, fmt.Sprintf("%d.cursor", treeID))
+}
+
+// Cursor reads the cursor of the log from its file.
+func (f FileCursors) Cursor(ctx context.Context, treeID int64) (int64, error) {
+	data, err := ioutil.ReadFile(f.path(treeID))
+	if os.IsNotExist(err) {
+		return 0, nil
+	} else if err != nil {
+		return 0, err
+	}
+	return strconv.ParseInt(strings.TrimSpace(string(data)), 10, 64)
+}
+
+// SetCursor replaces the file of the log with one holding the new cursor.
+func (f FileCursors) SetCursor(ctx context.Context, treeID int64, next int64) error {
+	path := f.path(treeID)
+	tmp := path + ".tmp"
+	if err := ioutil.WriteFile(tmp, []byte(strconv.FormatInt(next, 10)+"\n"), 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
@@ -0,0 +1,139 @@
+// Copyright 2020 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package export
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/google/trillian"
+	"github.com/google/trillian/log/export/exportpb"
+)
+
+// GRPCSink is a Sink which sends the leaves to a LeafExport server, and waits
+// for the server to acknowledge each batch. Each log has its own stream, so
+// that logs don't wait for each other's acknowledgements. A stream is opened
+// on the first write of its log, and reopened after any error.
+type GRPCSink struct {
+	client exportpb.LeafExportClient
+
+	mu      sync.Mutex
+	streams map[int64]*grpcStream
+}
+
+// grpcStream is the stream of one log.
+type grpcStream struct {
+	mu     sync.Mutex
+	stream exportpb.LeafExport_ExportClient
+	cancel context.CancelFunc
+}
+
+// NewGRPCSink returns a GRPCSink which sends the leaves through client.
+func NewGRPCSink(client exportpb.LeafExportClient) *GRPCSink {
+	return &GRPCSink{client: client, streams: make(map[int64]*grpcStream)}
+}
+
+// Write sends the leaves to the server, and returns once it has acknowledged
+// them.
+func (g *GRPCSink) Write(ctx context.Context, treeID int64, root *trillian.SignedLogRoot, leaves []*trillian.LogLeaf) error {
+	if len(leaves) == 0 {
+		return nil
+	}
+	g.mu.Lock()
+	s, ok := g.streams[treeID]
+	if !ok {
+		s = &grpcStream{}
+		g.streams[treeID] = s
+	}
+	g.mu.Unlock()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.write(ctx, g.client, &exportpb.ExportRequest{TreeId: treeID, SignedLogRoot: root, Leaves: leaves}); err != nil {
+		s.reset()
+		return err
+	}
+	return nil
+}
+
+func (s *grpcStream) write(ctx context.Context, client exportpb.LeafExportClient, req *exportpb.ExportRequest) error {
+	if s.stream == nil {
+		// The stream outlives the calls to Write, so it doesn't use their ctx.
+		sctx, cancel := context.WithCancel(context.Background())
+		stream, err := client.Export(sctx)
+		if err != nil {
+			cancel()
+			return fmt.Errorf("failed to open export stream: %v", err)
+		}
+		s.stream, s.cancel = stream, cancel
+	}
+	stream := s.stream
+	if err := stream.Send(req); err != nil {
+		return err
+	}
+
+	type result struct {
+		resp *exportpb.ExportResponse
+		err  error
+	}
+	results := make(chan result, 1)
+	go func() {
+		resp, err := stream.Recv()
+		results <- result{resp, err}
+	}()
+	var r result
+	select {
+	case r = <-results:
+	case <-ctx.Done():
+		// Resetting the stream ends the Recv.
+		return ctx.Err()
+	}
+	if r.err != nil {
+		return r.err
+	}
+	last := req.Leaves[len(req.Leaves)-1].LeafIndex
+	if r.resp.TreeId != req.TreeId || r.resp.NextIndex != last+1 {
+		return fmt.Errorf("got ack for log %d up to index %d, want log %d up to index %d", r.resp.TreeId, r.resp.NextIndex, req.TreeId, last+1)
+	}
+	return nil
+}
+
+// reset drops the stream, so that the next write opens a new one. Must be
+// called with s.mu held.
+func (s *grpcStream) reset() {
+	if s.stream != nil {
+		s.cancel()
+		s.stream, s.cancel = nil, nil
+	}
+}
+
+// Close closes the streams to the server.
+func (g *GRPCSink) Close() error {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	var firstErr error
+	for _, s := range g.streams {
+		s.mu.Lock()
+		if s.stream != nil {
+			if err := s.stream.CloseSend(); err != nil && firstErr == nil {
+				firstErr = err
+			}
+		}
+		s.reset()
+		s.mu.Unlock()
+	}
+	return firstErr
+}
@@ -0,0 +1,166 @@
+// Copyright 2020 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package export
+
+import (
+	"context"
+	"io"
+	"net"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/trillian/log/export/exportpb"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// fakeReceiver is a LeafExport server which records the indices of the leaves
+// it receives. It fails the stream on receiving the leaf with index failAt,
+// and doesn't acknowledge the leaf with index hangAt.
+type fakeReceiver struct {
+	exportpb.UnimplementedLeafExportServer
+
+	mu      sync.Mutex
+	indices []int64
+	streams int
+	failAt  int64
+	hangAt  int64
+}
+
+func (f *fakeReceiver) Export(stream exportpb.LeafExport_ExportServer) error {
+	f.mu.Lock()
+	f.streams++
+	f.mu.Unlock()
+	for {
+		req, err := stream.Recv()
+		if err == io.EOF {
+			return nil
+		} else if err != nil {
+			return err
+		}
+		f.mu.Lock()
+		var fail, hang bool
+		for _, l := range req.Leaves {
+			switch l.LeafIndex {
+			case f.failAt:
+				f.failAt, fail = -1, true
+			case f.hangAt:
+				f.hangAt, hang = -1, true
+			}
+			f.indices = append(f.indices, l.LeafIndex)
+		}
+		f.mu.Unlock()
+		switch {
+		case fail:
+			return status.Error(codes.Unavailable, "receiver failed")
+		case hang:
+			<-stream.Context().Done()
+			return stream.Context().Err()
+		}
+		next := req.Leaves[len(req.Leaves)-1].LeafIndex + 1
+		if err := stream.Send(&exportpb.ExportResponse{TreeId: req.TreeId, NextIndex: next}); err != nil {
+			return err
+		}
+	}
+}
+
+func TestGRPCSink(t *testing.T) {
+	ctx := context.Background()
+	lis, err := net.Listen("tcp", "localhost:0")
+	if err != nil {
+		t.Fatalf("Listen(): %v", err)
+	}
+	server := grpc.NewServer()
+	receiver := &fakeReceiver{failAt: 3, hangAt: 5}
+	exportpb.RegisterLeafExportServer(server, receiver)
+	go server.Serve(lis)
+	defer server.Stop()
+	conn, err := grpc.Dial(lis.Addr().String(), grpc.WithInsecure())
+	if err != nil {
+		t.Fatalf("Dial(): %v", err)
+	}
+	defer conn.Close()
+
+	sink := NewGRPCSink(exportpb.NewLeafExportClient(conn))
+	defer sink.Close()
+	leaves := testLeaves(8)
+	root := rootOfSize(t, 8)
+	write := func(begin, end int) error {
+		ctx, cancel := context.WithTimeout(ctx, time.Second)
+		defer cancel()
+		return sink.Write(ctx, treeID, root, leaves[begin:end])
+	}
+
+	if err := write(0, 3); err != nil {
+		t.Fatalf("Write(0, 3): %v", err)
+	}
+	if err := write(3, 5); err == nil {
+		t.Fatal("Write(3, 5) to failing receiver succeeded")
+	}
+	if err := write(3, 5); err != nil {
+		t.Fatalf("Write(3, 5) retry: %v", err)
+	}
+	if err := write(5, 8); err == nil {
+		t.Fatal("Write(5, 8) to hanging receiver succeeded")
+	}
+	if err := write(5, 8); err != nil {
+		t.Fatalf("Write(5, 8) retry: %v", err)
+	}
+
+	receiver.mu.Lock()
+	defer receiver.mu.Unlock()
+	checkIndices(t, receiver.indices, []int64{0, 1, 2, 3, 4, 3, 4, 5, 6, 7, 5, 6, 7})
+	if got, want := receiver.streams, 3; got != want {
+		t.Errorf("sink opened %d streams, want %d", got, want)
+	}
+}
+
+func TestGRPCSinkStreamPerLog(t *testing.T) {
+	lis, err := net.Listen("tcp", "localhost:0")
+	if err != nil {
+		t.Fatalf("Listen(): %v", err)
+	}
+	server := grpc.NewServer()
+	receiver := &fakeReceiver{failAt: -1, hangAt: 5}
+	exportpb.RegisterLeafExportServer(server, receiver)
+	go server.Serve(lis)
+	defer server.Stop()
+	conn, err := grpc.Dial(lis.Addr().String(), grpc.WithInsecure())
+	if err != nil {
+		t.Fatalf("Dial(): %v", err)
+	}
+	defer conn.Close()
+
+	sink := NewGRPCSink(exportpb.NewLeafExportClient(conn))
+	defer sink.Close()
+	leaves := testLeaves(8)
+	root := rootOfSize(t, 8)
+
+	// A log whose acknowledgement hangs doesn't hold up other logs.
+	hangCtx, cancel := context.WithCancel(context.Background())
+	hung := make(chan error, 1)
+	go func() { hung <- sink.Write(hangCtx, treeID, root, leaves[5:8]) }()
+	ctx, cancelOther := context.WithTimeout(context.Background(), time.Second)
+	defer cancelOther()
+	if err := sink.Write(ctx, treeID+1, root, leaves[0:3]); err != nil {
+		t.Errorf("Write() to other log: %v", err)
+	}
+	cancel()
+	if err := <-hung; err == nil {
+		t.Error("Write() to hanging receiver succeeded")
+	}
+}
@@ -32,6 +32,7 @@ import (
 	"github.com/google/trillian/storage"
 	"github.com/google/trillian/util/clock"
 	"github.com/google/trillian/util/election"
+	"github.com/google/trillian/util/election2"
 )
 
 var (
@@ -46,6 +47,8 @@ var (
 	failedSigningRuns monitoring.Counter
 	entriesAdded      monitoring.Counter
 	batchesAdded      monitoring.Counter
+	ringMembers       monitoring.Gauge
+	assignedLogs      monitoring.Gauge
 )
 
 func createMetrics(mf monitoring.MetricFactory) {
@@ -68,6 +71,8 @@ func createMetrics(mf monitoring.MetricFactory) {
 	// entriesAdded / batchesAdded is average batch size. These can be used for
 	// tuning sequencing or evaluating performance.
 	batchesAdded = mf.NewCounter("batches_added", "Number of times a non zero number of entries was added", logIDLabel)
+	ringMembers = mf.NewGauge("ring_members", "Number of instances that active logs are assigned to")
+	assignedLogs = mf.NewGauge("ring_assigned_logs", "Number of active logs assigned to each instance", "member")
 }
 
 // Operation defines a task that operates on a log. Examples are scheduling, signing,
@@ -78,6 +83,13 @@ type Operation interface {
 	ExecutePass(ctx context.Context, logID int64, info *OperationInfo) (int, error)
 }
 
+// heldLogsObserver is implemented by Operations which keep state for each log,
+// to learn which logs the instance is master for before each pass, and drop the
+// state of the others.
+type heldLogsObserver interface {
+	heldLogs(logIDs []int64)
+}
+
 // OperationInfo bundles up information needed for running a set of Operations.
 type OperationInfo struct {
 	// Registry provides access to Trillian storage.
@@ -87,14 +99,28 @@ type OperationInfo struct {
 
 	// BatchSize is the processing batch size to be passed to tasks run by this manager
 	BatchSize int
+	// BatchSizing makes the sequencer adapt the batch size of each log, starting
+	// from BatchSize, if MaxBatchSize is set.
+	BatchSizing BatchSizeConfig
 	// TimeSource should be used by the Operation to allow mocking for tests.
 	TimeSource clock.TimeSource
+	// PipelinedSequencing makes the sequencer keep state for each log between
+	// passes, and overlap the stages of consecutive batches.
+	PipelinedSequencing bool
 
 	// The following parameters govern the overall scheduling of Operations
 	// by a OperationManager.
 
 	// Election-related configuration.
 	ElectionConfig election.RunnerConfig
+	// Membership, if set, makes the instances share out the logs between them
+	// using consistent hashing, so that each instance only takes part in the
+	// elections for the logs assigned to it.
+	Membership election2.Membership
+	// RebalanceInterval is the minimum time between releasing mastership of two
+	// logs that have been assigned to other instances, so that the logs move
+	// gradually when instances join or leave.
+	RebalanceInterval time.Duration
 
 	// RunInterval is the time between starting batches of processing.  If a
 	// batch takes longer than this interval to complete, the next batch
@@ -120,9 +146,28 @@ type OperationManager struct {
 	runnerWG            sync.WaitGroup
 	tracker             *election.MasterTracker
 	lastHeld            []int64
+	// ring assigns logs to instances if Membership is set, and lastRelease is
+	// when mastership was last released for a log assigned elsewhere.
+	ring        *election.HashRing
+	lastRelease time.Time
 	// Cache of logID => name; assumed not to change during runtime
 	logNamesMutex sync.Mutex
 	logNames      map[int64]string
+	// drain is closed to make OperationLoop stop after the current pass and
+	// resign all mastership, and done is closed when OperationLoop returns.
+	drain     chan struct{}
+	drainOnce sync.Once
+	done      chan struct{}
+	// statusMu guards the state reported by Status: the active logs seen by
+	// the latest pass, the outcome of the passes for each log, and the
+	// creation of tracker.
+	statusMu   sync.Mutex
+	lastActive []int64
+	passes     map[int64]*passStatus
+	// queue caches the queue depths reported by Status, which is served to
+	// unauthenticated clients, so that they can't make storage count them on
+	// every request.
+	queue *queueCounter
 }
 
 // NewOperationManager creates a new OperationManager instance.
@@ -139,6 +184,10 @@ func NewOperationManager(info OperationInfo, logOperation Operation) *OperationM
 		electionRunner:      make(map[string]*election.Runner),
 		pendingResignations: make(chan election.Resignation, 100),
 		logNames:            make(map[int64]string),
+		drain:               make(chan struct{}),
+		done:                make(chan struct{}),
+		passes:              make(map[int64]*passStatus),
+		queue:               newQueueCounter(info.Registry.LogStorage, info.TimeSource, queueCountInterval),
 	}
 }
 
@@ -213,19 +262,27 @@ func (o *OperationManager) masterFor(ctx context.Context, allIDs []int64) ([]int
 	}
 	if o.tracker == nil {
 		glog.Infof("creating mastership tracker for %v", allIDs)
-		o.tracker = election.NewMasterTracker(allStringIDs, func(id string, v bool) {
+		tracker := election.NewMasterTracker(allStringIDs, func(id string, v bool) {
 			val := 0.0
 			if v {
 				val = 1.0
 			}
 			isMaster.Set(val, id)
 		})
+		o.statusMu.Lock()
+		o.tracker = tracker
+		o.statusMu.Unlock()
+	}
+
+	if o.info.Membership != nil {
+		o.updateRing(ctx, allStringIDs)
+		o.releaseUnassigned(allStringIDs)
 	}
 
 	// Synchronize the set of log IDs with those we are tracking mastership for.
 	for _, logID := range allStringIDs {
 		knownLogs.Set(1, logID)
-		if o.electionRunner[logID] != nil {
+		if o.electionRunner[logID] != nil || !o.assigned(logID) {
 			continue
 		}
 		glog.Infof("create master election goroutine for %v", logID)
@@ -251,6 +308,10 @@ func (o *OperationManager) masterFor(ctx context.Context, allIDs []int64) ([]int
 		if i := sort.SearchStrings(allStringIDs, s); i >= len(allStringIDs) || allStringIDs[i] != s {
 			continue
 		}
+		// Skip the log if its election is being stopped.
+		if o.electionRunner[s] == nil {
+			continue
+		}
 		id, err := strconv.ParseInt(s, 10, 64)
 		if err != nil {
 			return nil, fmt.Errorf("failed to parse logID %v as int64", s)
@@ -261,11 +322,82 @@ func (o *OperationManager) masterFor(ctx context.Context, allIDs []int64) ([]int
 	return heldIDs, nil
 }
 
+// updateRing rebuilds the consistent-hash ring if the set of instances has
+// changed. The previous ring is kept if the instances can't be listed.
+func (o *OperationManager) updateRing(ctx context.Context, allIDs []string) {
+	members, err := o.info.Membership.Members(ctx)
+	if err != nil {
+		glog.Errorf("failed to list members, keeping the previous assignment: %v", err)
+	} else if o.ring == nil || !reflect.DeepEqual(members, o.ring.Members()) {
+		glog.Infof("assigning logs to members: %v", members)
+		if o.ring != nil {
+			for _, m := range o.ring.Members() {
+				assignedLogs.Set(0, m)
+			}
+		}
+		o.ring = election.NewHashRing(members, election.DefaultRingReplicas)
+		ringMembers.Set(float64(len(members)))
+	}
+	if o.ring == nil {
+		return
+	}
+	counts := make(map[string]int)
+	for _, logID := range allIDs {
+		counts[o.ring.Owner(logID)]++
+	}
+	for _, m := range o.ring.Members() {
+		assignedLogs.Set(float64(counts[m]), m)
+	}
+}
+
+// assigned returns whether this instance should take part in the election for
+// the given log. All logs are assigned to the instance until the ring is known.
+func (o *OperationManager) assigned(logID string) bool {
+	if o.info.Membership == nil || o.ring == nil {
+		return true
+	}
+	return o.ring.Owner(logID) == o.info.Membership.ID()
+}
+
+// releaseUnassigned stops the elections for the logs that are not assigned to
+// this instance any more. Elections for logs that the instance is not master
+// for are stopped straight away, whereas mastership is released for at most
+// one log per RebalanceInterval.
+func (o *OperationManager) releaseUnassigned(allIDs []string) {
+	held := make(map[string]bool)
+	for _, logID := range o.tracker.Held() {
+		held[logID] = true
+	}
+	for _, logID := range allIDs {
+		runner := o.electionRunner[logID]
+		if runner == nil || o.assigned(logID) {
+			continue
+		}
+		if held[logID] {
+			now := o.info.TimeSource.Now()
+			if now.Sub(o.lastRelease) < o.info.RebalanceInterval {
+				continue
+			}
+			o.lastRelease = now
+			glog.Infof("%s: releasing mastership, the log is assigned to %s", logID, o.ring.Owner(logID))
+		} else {
+			glog.V(1).Infof("%s: leaving election, the log is assigned to %s", logID, o.ring.Owner(logID))
+		}
+		// Canceling the runner makes it close the election, which resigns the
+		// mastership if held.
+		runner.Cancel()
+		delete(o.electionRunner, logID)
+	}
+}
+
 // updateHeldIDs updates the process status with the number/list of logs that
 // the instance holds mastership for.
 func (o *OperationManager) updateHeldIDs(ctx context.Context, logIDs, activeIDs []int64) {
 	heldInfo := o.heldInfo(ctx, logIDs)
 	msg := fmt.Sprintf("Acting as master for %d / %d active logs: %s", len(logIDs), len(activeIDs), heldInfo)
+	o.statusMu.Lock()
+	o.lastActive = append(o.lastActive[:0], activeIDs...)
+	o.statusMu.Unlock()
 	if !reflect.DeepEqual(logIDs, o.lastHeld) {
 		o.lastHeld = make([]int64, len(logIDs))
 		copy(o.lastHeld, logIDs)
@@ -294,10 +426,15 @@ func (o *OperationManager) getLogsAndExecutePass(ctx context.Context) error {
 		return fmt.Errorf("failed to determine log IDs we're master for: %v", err)
 	}
 	o.updateHeldIDs(ctx, logIDs, activeIDs)
+	if obs, ok := o.logOperation.(heldLogsObserver); ok {
+		obs.heldLogs(logIDs)
+	}
 
 	// TODO(pavelkalinnikov): Run executor once instead of doing it on each pass.
 	// This will be also needed when factoring out per-log operation loop.
 	ex := newExecutor(o.logOperation, &o.info, len(logIDs))
+	ex.tracker = o.tracker
+	ex.recordPass = o.recordPass
 	// Put logIDs that need to be processed to the executor's channel.
 	for _, logID := range logIDs {
 		ex.jobs <- logID
@@ -318,6 +455,18 @@ func (o *OperationManager) OperationSingle(ctx context.Context) {
 // TODO(Martin2112): No mechanism for error reporting etc., this is OK for v1 but needs work
 func (o *OperationManager) OperationLoop(ctx context.Context) {
 	glog.Infof("Log operation manager starting")
+	defer close(o.done)
+
+	// The waits between passes are cut short by draining.
+	sleepCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	go func() {
+		select {
+		case <-o.drain:
+			cancel()
+		case <-sleepCtx.Done():
+		}
+	}()
 
 	// Outer loop, runs until terminated
 loop:
@@ -349,6 +498,10 @@ loop:
 		case <-ctx.Done():
 			glog.Infof("Log operation manager shutting down")
 			break loop
+		case <-o.drain:
+			glog.Infof("Log operation manager draining")
+			o.resignAll(ctx)
+			break loop
 		default:
 		}
 
@@ -357,8 +510,13 @@ loop:
 		wait := o.info.RunInterval - duration
 		if wait > 0 {
 			glog.V(1).Infof("Processing started at %v for %v; wait %v before next run", start, duration, wait)
-			if err := clock.SleepContext(ctx, wait); err != nil {
-				glog.Infof("Log operation manager shutting down")
+			if err := clock.SleepContext(sleepCtx, wait); err != nil {
+				if ctx.Err() != nil {
+					glog.Infof("Log operation manager shutting down")
+					break loop
+				}
+				glog.Infof("Log operation manager draining")
+				o.resignAll(ctx)
 				break loop
 			}
 		} else {
@@ -388,6 +546,49 @@ loop:
 	glog.Infof("wait for termination of election runners...done")
 }
 
+// Drain makes OperationLoop finish the pass in progress without starting new
+// ones, and resign mastership of all logs so that other instances can take
+// over straight away. It blocks until OperationLoop returns, or the passed in
+// context is done.
+func (o *OperationManager) Drain(ctx context.Context) error {
+	o.drainOnce.Do(func() { close(o.drain) })
+	select {
+	case <-o.done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// resignAll stops all elections, explicitly resigning mastership of the held
+// logs rather than letting it expire.
+func (o *OperationManager) resignAll(ctx context.Context) {
+	held := make(map[string]bool)
+	if o.tracker != nil {
+		for _, logID := range o.tracker.Held() {
+			held[logID] = true
+		}
+	}
+	// Stop the other elections first, so that they don't capture mastership
+	// while the held logs are being resigned.
+	for logID, runner := range o.electionRunner {
+		if !held[logID] {
+			runner.Cancel()
+		}
+	}
+	for logID := range held {
+		runner := o.electionRunner[logID]
+		if runner == nil {
+			continue
+		}
+		glog.Infof("%s: resigning mastership for drain", logID)
+		resignations.Inc(logID)
+		if err := runner.Resign(ctx); err != nil {
+			glog.Errorf("%s: failed to resign mastership: %v", logID, err)
+		}
+	}
+}
+
 // logOperationExecutor runs the specified Operation on the submitted logs
 // in a set of parallel workers.
 type logOperationExecutor struct {
@@ -399,6 +600,10 @@ type logOperationExecutor struct {
 	// auto-cancelable when mastership is lost.
 	// TODO(pavelkalinnikov): Report job completion status back.
 	jobs chan int64
+	// tracker, if set, provides the fencing tokens passed to the jobs.
+	tracker *election.MasterTracker
+	// recordPass, if set, is called with the outcome of each job.
+	recordPass func(logID int64, count int, err error)
 }
 
 func newExecutor(op Operation, info *OperationInfo, jobs int) *logOperationExecutor {
@@ -434,8 +639,25 @@ func (e *logOperationExecutor) run(ctx context.Context) {
 				}
 
 				label := strconv.FormatInt(logID, 10)
+				jobCtx := ctx
+				if e.tracker != nil {
+					token, ok := e.tracker.FencingToken(label)
+					if !ok {
+						// Mastership was lost since the job was scheduled.
+						glog.Warningf("%v: skipping pass, no longer master", logID)
+						continue
+					}
+					// Make storage reject the writes if a newer master has
+					// written. Elections which don't fence give zero tokens.
+					if token != 0 {
+						jobCtx = storage.WithFencingToken(ctx, token)
+					}
+				}
 				start := e.info.TimeSource.Now()
-				count, err := e.op.ExecutePass(ctx, logID, e.info)
+				count, err := e.op.ExecutePass(jobCtx, logID, e.info)
+				if e.recordPass != nil {
+					e.recordPass(logID, count, err)
+				}
 				if err != nil {
 					glog.Errorf("ExecutePass(%v) failed: %v", logID, err)
 					failedSigningRuns.Inc(label)
@@ -20,6 +20,7 @@ import (
 	"fmt"
 	"reflect"
 	"strconv"
+	"sync"
 	"sync/atomic"
 	"testing"
 	"time"
@@ -242,6 +243,45 @@ func TestOperationManagerExecutePassError(t *testing.T) {
 	}
 }
 
+func TestExecutorFencing(t *testing.T) {
+	ctx := context.Background()
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	tracker := election.NewMasterTracker([]string{"1", "2", "3"}, nil)
+	tracker.SetFencingToken("1", 10)
+	tracker.Set("1", true)
+	tracker.SetFencingToken("2", 0)
+	tracker.Set("2", true)
+	// Mastership of log 3 was lost after the pass was scheduled.
+	tracker.SetFencingToken("3", 20)
+	tracker.Set("3", true)
+	tracker.Set("3", false)
+
+	tokens := make(map[int64]int64)
+	mockLogOp := NewMockOperation(ctrl)
+	for _, logID := range []int64{1, 2} {
+		mockLogOp.EXPECT().ExecutePass(gomock.Any(), logID, gomock.Any()).DoAndReturn(
+			func(ctx context.Context, logID int64, _ *OperationInfo) (int, error) {
+				tokens[logID] = storage.FencingTokenFromContext(ctx)
+				return 0, nil
+			})
+	}
+
+	info := defaultOperationInfo(extension.Registry{})
+	ex := newExecutor(mockLogOp, &info, 3)
+	ex.tracker = tracker
+	for _, logID := range []int64{1, 2, 3} {
+		ex.jobs <- logID
+	}
+	close(ex.jobs)
+	ex.run(ctx)
+
+	if want := map[int64]int64{1: 10, 2: 0}; !reflect.DeepEqual(tokens, want) {
+		t.Errorf("passes ran with fencing tokens %v, want %v", tokens, want)
+	}
+}
+
 func TestOperationManagerOperationLoopPassesIDs(t *testing.T) {
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
@@ -394,6 +434,50 @@ func TestOperationManagerOperationLoopExecutePassError(t *testing.T) {
 	}
 }
 
+func TestOperationManagerDrain(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	logID1 := int64(451)
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	fakeStorage, mockAdmin := setupLogIDs(ctrl, map[int64]string{451: "LogID1"})
+	fact := &resignRecordingFactory{}
+	registry := extension.Registry{
+		LogStorage:      fakeStorage,
+		AdminStorage:    mockAdmin,
+		ElectionFactory: fact,
+	}
+
+	info := defaultOperationInfo(registry)
+	info.RunInterval = 10 * time.Millisecond
+	info.TimeSource = clock.System
+	lom := NewOperationManager(info, nil)
+
+	drained := make(chan error, 1)
+	mockLogOp := NewMockOperation(ctrl)
+	// Only the pass in progress when draining starts is run, and it is not
+	// canceled.
+	mockLogOp.EXPECT().ExecutePass(gomock.Any(), logID1, gomock.Any()).Do(func(ctx context.Context, _ int64, _ *OperationInfo) {
+		go func() { drained <- lom.Drain(context.Background()) }()
+		<-lom.drain
+		if err := ctx.Err(); err != nil {
+			t.Errorf("ExecutePass(): context done while draining: %v", err)
+		}
+	}).Return(1, nil)
+	lom.logOperation = mockLogOp
+
+	go lom.OperationLoop(ctx)
+	if err := <-drained; err != nil {
+		t.Fatalf("Drain(): %v", err)
+	}
+	if got := atomic.LoadInt32(&fact.el.resigned); got != 1 {
+		t.Errorf("Resign() called %d times while draining, want 1", got)
+	}
+}
+
 func TestHeldInfo(t *testing.T) {
 	ctx := context.Background()
 	ctrl := gomock.NewController(t)
@@ -473,6 +557,82 @@ func TestMasterFor(t *testing.T) {
 	}
 }
 
+// fakeMembership is an election2.Membership whose members can be changed.
+type fakeMembership struct {
+	election2.StaticMembership
+	mu sync.Mutex
+}
+
+func (m *fakeMembership) set(members ...string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.All = members
+}
+
+func (m *fakeMembership) Members(ctx context.Context) ([]string, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.StaticMembership.Members(ctx)
+}
+
+func TestMasterForMembership(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	allIDs := []int64{11, 12, 13, 14, 15, 16, 17, 18, 19, 20}
+	ring := election.NewHashRing([]string{"a", "b"}, election.DefaultRingReplicas)
+	var assignedIDs []int64
+	for _, id := range allIDs {
+		if ring.Owner(strconv.FormatInt(id, 10)) == "a" {
+			assignedIDs = append(assignedIDs, id)
+		}
+	}
+	if len(assignedIDs) == 0 || len(assignedIDs) == len(allIDs) {
+		t.Fatalf("test needs logs assigned to both members, got %v", assignedIDs)
+	}
+
+	membership := &fakeMembership{StaticMembership: election2.StaticMembership{Self: "a"}}
+	fakeTime := clock.NewFake(time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC))
+	info := OperationInfo{
+		Registry:          extension.Registry{ElectionFactory: alwaysMasterFactory{}},
+		TimeSource:        fakeTime,
+		Membership:        membership,
+		RebalanceInterval: time.Minute,
+	}
+	lom := NewOperationManager(info, nil)
+	masterFor := func(want []int64) {
+		t.Helper()
+		// Check mastership twice, to give the election threads a chance to get started and report.
+		lom.masterFor(ctx, allIDs)
+		time.Sleep(100 * time.Millisecond)
+		got, err := lom.masterFor(ctx, allIDs)
+		if err != nil {
+			t.Fatalf("masterFor(): %v", err)
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Fatalf("masterFor()=%v, want %v", got, want)
+		}
+	}
+
+	membership.set("a", "b")
+	masterFor(assignedIDs)
+	// The instance is alone, so it takes over all the logs.
+	membership.set("a")
+	masterFor(allIDs)
+	// Another instance joins, and the logs are released one at a time.
+	membership.set("a", "b")
+	for held := len(allIDs) - 1; held >= len(assignedIDs); held-- {
+		got, err := lom.masterFor(ctx, allIDs)
+		if err != nil {
+			t.Fatalf("masterFor(): %v", err)
+		}
+		if len(got) != held {
+			t.Fatalf("masterFor()=%v, want %d logs", got, held)
+		}
+		fakeTime.Set(fakeTime.Now().Add(time.Minute))
+	}
+	masterFor(assignedIDs)
+}
+
 type alwaysMasterFactory struct{}
 
 func (m alwaysMasterFactory) NewElection(ctx context.Context, treeID string) (election2.Election, error) {
@@ -493,6 +653,29 @@ func (m masterForEvenFactory) NewElection(ctx context.Context, treeID string) (e
 	return d, nil
 }
 
+// resignRecordingFactory creates a single election which is always master and
+// counts explicit resignations with a live context.
+type resignRecordingFactory struct {
+	el *resignRecordingElection
+}
+
+func (f *resignRecordingFactory) NewElection(ctx context.Context, treeID string) (election2.Election, error) {
+	f.el = &resignRecordingElection{Election: eto.NewElection()}
+	return f.el, nil
+}
+
+type resignRecordingElection struct {
+	*eto.Election
+	resigned int32
+}
+
+func (e *resignRecordingElection) Resign(ctx context.Context) error {
+	if ctx.Err() == nil {
+		atomic.AddInt32(&e.resigned, 1)
+	}
+	return e.Election.Resign(ctx)
+}
+
 type failureFactory struct{}
 
 func (ff failureFactory) NewElection(ctx context.Context, treeID string) (election2.Election, error) {
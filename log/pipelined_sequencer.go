@@ -0,0 +1,407 @@
+// Copyright 2020 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package log
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/golang/glog"
+	"github.com/google/trillian"
+	"github.com/google/trillian/merkle/compact"
+	"github.com/google/trillian/storage"
+	"github.com/google/trillian/types"
+	"github.com/google/trillian/util/clock"
+)
+
+// PipelinedSequencer integrates batches of leaves into a single log, keeping
+// state between consecutive batches so that less work is done against storage
+// for each of them, and overlapping the stages of each batch.
+//
+// Storage requires a batch to be dequeued and committed in the same
+// transaction, and fixes the write revision when the transaction begins, so a
+// batch is only sequenced after the previous one has committed. This is also
+// what makes the roots commit in order. Within that constraint, the pipeline:
+//   - keeps the compact.Range of the last committed root in memory, instead of
+//     reading it from storage for every batch;
+//   - hashes a batch while its sequence numbers are being written;
+//   - prefetches the next batch while the current one commits: it is dequeued
+//     in its own transaction, which doesn't write anything, and hashed onto the
+//     compact range of the new root.
+//
+// The prefetch transaction may see the storage before or after the current
+// batch commits. In the former case, the leaves of the current batch are
+// dequeued too, checked, and skipped. The next batch still dequeues its leaves
+// in its own transaction, as storage only sequences leaves dequeued in the same
+// transaction, and uses the prefetched hashes only if the latest root is the
+// one the prefetch followed, and the same leaves were dequeued.
+//
+// The in-memory state is checked against the latest root in storage at the
+// start of each batch, and dropped on any error, so a failure or a crash at any
+// stage costs at most a re-read of the compact range from storage.
+//
+// A PipelinedSequencer is not safe for concurrent use. It is meant to be kept
+// for as long as the signer is master for the log.
+type PipelinedSequencer struct {
+	s     *Sequencer
+	tree  *trillian.Tree
+	label string
+
+	// root is the last root known to be committed, and cr is the compact range
+	// matching it. Both are nil if there is no such root.
+	root *types.LogRootV1
+	cr   *compact.Range
+	// next is the batch prefetched after root, if any.
+	next *prefetchedBatch
+}
+
+// NewPipelinedSequencer creates a PipelinedSequencer for the given log, which
+// uses the passed in Sequencer for storage, signing and quota.
+func NewPipelinedSequencer(s *Sequencer, tree *trillian.Tree) *PipelinedSequencer {
+	return &PipelinedSequencer{
+		s:     s,
+		tree:  tree,
+		label: strconv.FormatInt(tree.TreeId, 10),
+	}
+}
+
+// hashedBatch is the result of appending a batch of leaf hashes to a compact
+// range, which is computed concurrently with other work.
+type hashedBatch struct {
+	hashes [][]byte // The Merkle leaf hashes of the batch.
+	done   chan struct{}
+
+	// The fields below are only valid once done is closed.
+	cr       *compact.Range
+	nodeMap  map[compact.NodeID][]byte
+	rootHash []byte
+	err      error
+}
+
+// hashBatch starts appending the hashes to the compact range in the
+// background. The range is owned by the returned hashedBatch.
+func hashBatch(cr *compact.Range, hashes [][]byte) *hashedBatch {
+	h := &hashedBatch{hashes: hashes, cr: cr, done: make(chan struct{})}
+	go func() {
+		defer close(h.done)
+		h.nodeMap, h.rootHash, h.err = appendLeafHashes(h.cr, h.hashes)
+	}()
+	return h
+}
+
+// wait blocks until the hashing is done, and returns its error if any.
+func (h *hashedBatch) wait() error {
+	<-h.done
+	return h.err
+}
+
+// matches returns whether the batch was hashed for the passed in leaves.
+func (h *hashedBatch) matches(leaves []*trillian.LogLeaf) bool {
+	if len(h.hashes) != len(leaves) {
+		return false
+	}
+	for i, leaf := range leaves {
+		if !bytes.Equal(h.hashes[i], leaf.MerkleLeafHash) {
+			return false
+		}
+	}
+	return true
+}
+
+// prefetchedBatch is the batch following a root, which is dequeued in its own
+// transaction while the root commits, and then hashed.
+type prefetchedBatch struct {
+	after  *types.LogRootV1 // The root the batch follows.
+	cancel context.CancelFunc
+	done   chan struct{}
+
+	// The fields below are only valid once done is closed. hashed is nil if
+	// there was an error, or no leaves to hash.
+	hashed *hashedBatch
+	err    error
+}
+
+// pipelinedBatch holds the outcome of a single IntegrateBatch transaction.
+type pipelinedBatch struct {
+	numLeaves int
+	leaves    []*trillian.LogLeaf
+	parent    *types.LogRootV1 // The root the batch was integrated onto.
+	root      *types.LogRootV1
+	slr       *trillian.SignedLogRoot
+	cr        *compact.Range
+	stored    bool
+	next      *prefetchedBatch
+}
+
+// sameRoot returns whether two roots are the same root of the log.
+func sameRoot(a, b *types.LogRootV1) bool {
+	return a.Revision == b.Revision && a.TreeSize == b.TreeSize && bytes.Equal(a.RootHash, b.RootHash)
+}
+
+// cloneRange returns a copy of the compact range that can be appended to
+// without affecting the original.
+func cloneRange(fact *compact.RangeFactory, cr *compact.Range) (*compact.Range, error) {
+	hashes := make([][]byte, len(cr.Hashes()))
+	copy(hashes, cr.Hashes())
+	return fact.NewRange(cr.Begin(), cr.End(), hashes)
+}
+
+// IntegrateBatch integrates the next batch of up to limit leaves into the log,
+// with the same semantics as Sequencer.IntegrateBatch.
+func (p *PipelinedSequencer) IntegrateBatch(ctx context.Context, limit int, guardWindow, maxRootDurationInterval time.Duration) (int, error) {
+	start := p.s.timeSource.Now()
+	cutoff := start.Add(-guardWindow)
+	next := p.next
+	p.next = nil
+	if next != nil {
+		defer next.cancel()
+		// Storage may run the transactions of a log one at a time, so the
+		// prefetch has to be done before the next transaction begins.
+		select {
+		case <-next.done:
+		case <-ctx.Done():
+			return 0, ctx.Err()
+		}
+	}
+
+	var b *pipelinedBatch
+	err := p.s.logStorage.ReadWriteTransaction(ctx, p.tree, func(txCtx context.Context, tx storage.LogTreeTX) error {
+		defer seqBatches.Inc(p.label)
+		defer func() { seqLatency.Observe(clock.SecondsSince(p.s.timeSource, start), p.label) }()
+		// The transaction may be retried, so don't keep anything from the
+		// previous attempts.
+		if b != nil && b.next != nil {
+			b.next.cancel()
+		}
+		b = &pipelinedBatch{}
+		if err := p.integrate(txCtx, tx, b, next, limit, cutoff, maxRootDurationInterval); err != nil {
+			return err
+		}
+		if b.stored {
+			// Dequeue and hash the next batch while this one commits.
+			b.next = p.prefetch(ctx, b, limit, cutoff)
+		}
+		return nil
+	})
+	if err != nil {
+		// We don't know whether the transaction has committed, so the state has
+		// to be re-read from storage by the next batch.
+		if b != nil && b.next != nil {
+			b.next.cancel()
+		}
+		p.root, p.cr = nil, nil
+		return 0, err
+	}
+	p.root, p.cr, p.next = b.root, b.cr, b.next
+
+	// Let quota.Manager know about newly-sequenced entries.
+	p.s.replenishQuota(ctx, b.numLeaves, p.tree.TreeId)
+
+	seqCounter.Add(float64(b.numLeaves), p.label)
+	if b.stored {
+		glog.Infof("%v: sequenced %v leaves, size %v, tree-revision %v", p.tree.TreeId, b.numLeaves, b.root.TreeSize, b.root.Revision)
+		p.s.exportLeaves(ctx, p.tree, b.slr, b.leaves)
+	}
+	return b.numLeaves, nil
+}
+
+// compactRange returns a compact range for the current root, which the caller
+// is free to modify. It reuses the range kept in memory if it matches the root,
+// and otherwise builds one from storage.
+func (p *PipelinedSequencer) compactRange(ctx context.Context, tx storage.LogTreeTX, fact *compact.RangeFactory, currentRoot *types.LogRootV1) (*compact.Range, error) {
+	if p.root != nil && sameRoot(p.root, currentRoot) {
+		return cloneRange(fact, p.cr)
+	}
+	if p.root != nil {
+		glog.Warningf("%v: latest root doesn't match the pipelined one, rebuilding compact range", p.tree.TreeId)
+		seqPipelineReloads.Inc(p.label)
+	}
+	stageStart := p.s.timeSource.Now()
+	cr, err := p.s.initCompactRangeFromStorage(ctx, currentRoot, tx)
+	if err != nil {
+		return nil, fmt.Errorf("%v: compact range init failed: %v", p.tree.TreeId, err)
+	}
+	seqInitTreeLatency.Observe(clock.SecondsSince(p.s.timeSource, stageStart), p.label)
+	return cr, nil
+}
+
+// prefetch starts dequeuing the batch following b in its own transaction, and
+// then hashing it onto the compact range of b.
+func (p *PipelinedSequencer) prefetch(ctx context.Context, b *pipelinedBatch, limit int, cutoff time.Time) *prefetchedBatch {
+	ctx, cancel := context.WithCancel(ctx)
+	f := &prefetchedBatch{after: b.root, cancel: cancel, done: make(chan struct{})}
+	fact := &compact.RangeFactory{Hash: p.s.hasher.HashChildren}
+	cr, err := cloneRange(fact, b.cr)
+	if err != nil {
+		f.err = err
+		close(f.done)
+		return f
+	}
+	go func() {
+		defer close(f.done)
+		leaves, err := p.dequeueNext(ctx, b.parent, b.root, b.leaves, limit, cutoff)
+		if err != nil {
+			glog.V(1).Infof("%v: prefetch failed: %v", p.tree.TreeId, err)
+			f.err = err
+			return
+		}
+		if len(leaves) == 0 {
+			return
+		}
+		hashes := make([][]byte, len(leaves))
+		for i, leaf := range leaves {
+			hashes[i] = leaf.MerkleLeafHash
+		}
+		f.hashed = hashBatch(cr, hashes)
+	}()
+	return f
+}
+
+// dequeueNext dequeues the batch of up to limit leaves following the root
+// after, in a transaction which writes nothing. The transaction sees either
+// after, or the root before it is committed, parent, followed by the leaves
+// prev which after integrates. In the latter case, prev is dequeued too,
+// checked, and skipped.
+func (p *PipelinedSequencer) dequeueNext(ctx context.Context, parent, after *types.LogRootV1, prev []*trillian.LogLeaf, limit int, cutoff time.Time) ([]*trillian.LogLeaf, error) {
+	var leaves []*trillian.LogLeaf
+	err := p.s.logStorage.ReadWriteTransaction(ctx, p.tree, func(ctx context.Context, tx storage.LogTreeTX) error {
+		leaves = nil
+		slr, err := tx.LatestSignedLogRoot(ctx)
+		if err != nil {
+			return err
+		}
+		var root types.LogRootV1
+		if err := root.UnmarshalBinary(slr.LogRoot); err != nil {
+			return err
+		}
+		skip := 0
+		switch {
+		case sameRoot(&root, after):
+		case sameRoot(&root, parent):
+			skip = len(prev)
+		default:
+			return fmt.Errorf("latest root of revision %d doesn't precede the batch", root.Revision)
+		}
+		st, err := newSequencingTask(p.tree, &sequencingTaskData{
+			label:      p.label,
+			treeSize:   root.TreeSize,
+			timeSource: p.s.timeSource,
+			tx:         tx,
+		})
+		if err != nil {
+			return err
+		}
+		dequeued, err := st.fetch(ctx, skip+limit, cutoff)
+		if err != nil {
+			return err
+		}
+		if len(dequeued) < skip {
+			return fmt.Errorf("dequeued %d leaves, want at least the %d of the committing batch", len(dequeued), skip)
+		}
+		for i, leaf := range prev[:skip] {
+			if got := dequeued[i]; !bytes.Equal(got.MerkleLeafHash, leaf.MerkleLeafHash) || !bytes.Equal(got.LeafIdentityHash, leaf.LeafIdentityHash) {
+				return fmt.Errorf("dequeued leaf %d differs from the committing batch", got.LeafIndex)
+			}
+		}
+		leaves = dequeued[skip:]
+		return nil
+	})
+	return leaves, err
+}
+
+// integrate runs a single batch through the pipeline within the transaction,
+// and records the outcome in b. The hashes of next are used if it was
+// prefetched after the latest root, for the same leaves.
+func (p *PipelinedSequencer) integrate(ctx context.Context, tx storage.LogTreeTX, b *pipelinedBatch, next *prefetchedBatch, limit int, cutoff time.Time, maxRootDurationInterval time.Duration) error {
+	treeID := p.tree.TreeId
+	currentRoot, err := p.s.latestRoot(ctx, tx, treeID, p.label)
+	if err != nil {
+		return err
+	}
+	if final, err := checkFinalRoot(p.tree, currentRoot); final {
+		return err
+	}
+	fact := &compact.RangeFactory{Hash: p.s.hasher.HashChildren}
+	cr, err := p.compactRange(ctx, tx, fact, currentRoot)
+	if err != nil {
+		return err
+	}
+	b.parent, b.root, b.cr = currentRoot, currentRoot, cr
+
+	taskData := &sequencingTaskData{
+		label:      p.label,
+		treeSize:   currentRoot.TreeSize,
+		timeSource: p.s.timeSource,
+		tx:         tx,
+	}
+	st, err := newSequencingTask(p.tree, taskData)
+	if err != nil {
+		return err
+	}
+	leaves, err := st.fetch(ctx, limit, cutoff)
+	if err != nil {
+		return fmt.Errorf("%v: Sequencer failed to load sequenced batch: %v", treeID, err)
+	}
+	b.numLeaves = len(leaves)
+	if b.numLeaves == 0 && p.s.rootIsFresh(currentRoot, treeID, maxRootDurationInterval) {
+		glog.V(1).Infof("%v: No leaves sequenced in this signing operation", treeID)
+		return nil
+	}
+
+	newVersion, err := tx.WriteRevision(ctx)
+	if err != nil {
+		return err
+	}
+	if got, want := newVersion, int64(currentRoot.Revision)+1; got != want {
+		return fmt.Errorf("%v: got writeRevision of %v, but expected %v", treeID, got, want)
+	}
+	if err := p.s.prepareLeaves(leaves, cr.End(), p.label); err != nil {
+		return err
+	}
+
+	// Hash the batch while its sequence numbers are being written, unless it
+	// has already been hashed after being prefetched.
+	var hashed *hashedBatch
+	if next != nil && next.hashed != nil && sameRoot(next.after, currentRoot) && next.hashed.matches(leaves) {
+		seqPrefetchHits.Inc(p.label)
+		hashed = next.hashed
+	} else {
+		hashes := make([][]byte, len(leaves))
+		for i, leaf := range leaves {
+			hashes[i] = leaf.MerkleLeafHash
+		}
+		hashed = hashBatch(cr, hashes)
+	}
+	if err := st.update(ctx, leaves); err != nil {
+		return err
+	}
+
+	stageStart := p.s.timeSource.Now()
+	if err := hashed.wait(); err != nil {
+		return err
+	}
+	seqWriteTreeLatency.Observe(clock.SecondsSince(p.s.timeSource, stageStart), p.label)
+
+	newLogRoot, newSLR, err := p.s.storeBatch(ctx, tx, treeID, p.label, currentRoot, hashed.cr, hashed.nodeMap, hashed.rootHash, newVersion, nil)
+	if err != nil {
+		return err
+	}
+	b.root, b.slr, b.cr, b.stored = newLogRoot, newSLR, hashed.cr, true
+	b.leaves = leaves
+	return nil
+}
@@ -0,0 +1,695 @@
+// Copyright 2020 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package log
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/google/trillian"
+	"github.com/google/trillian/merkle/compact"
+	"github.com/google/trillian/merkle/rfc6962"
+	"github.com/google/trillian/quota"
+	"github.com/google/trillian/storage"
+	"github.com/google/trillian/storage/memory"
+	"github.com/google/trillian/storage/tree"
+	"github.com/google/trillian/types"
+	"github.com/google/trillian/util/clock"
+
+	stestonly "github.com/google/trillian/storage/testonly"
+)
+
+var errCrash = errors.New("crash")
+
+// fakeLogState is the content of a txLogStorage. Each transaction works on its
+// own copy of it, which replaces the original on commit.
+type fakeLogState struct {
+	roots  []*trillian.SignedLogRoot
+	queue  []*trillian.LogLeaf
+	leaves map[int64]*trillian.LogLeaf
+	nodes  map[string][]tree.Node // Keyed by NodeID.AsKey(), in revision order.
+}
+
+func (s *fakeLogState) clone() *fakeLogState {
+	ret := &fakeLogState{
+		roots:  append([]*trillian.SignedLogRoot(nil), s.roots...),
+		queue:  append([]*trillian.LogLeaf(nil), s.queue...),
+		leaves: make(map[int64]*trillian.LogLeaf),
+		nodes:  make(map[string][]tree.Node),
+	}
+	for k, v := range s.leaves {
+		ret.leaves[k] = v
+	}
+	for k, v := range s.nodes {
+		ret.nodes[k] = append([]tree.Node(nil), v...)
+	}
+	return ret
+}
+
+// txLogStorage is a transactional in-memory LogStorage, which supports just
+// enough for sequencing a single LOG tree. It can be set to fail at a given
+// point of a transaction, which is then rolled back.
+type txLogStorage struct {
+	storage.LogStorage
+
+	mu    sync.Mutex
+	state *fakeLogState
+	// failAt is the name of the LogTreeTX method to fail, or "Commit", or
+	// "CommitApplied". The failure happens once, after skipping the first
+	// failSkip calls.
+	failAt   string
+	failSkip int
+}
+
+func newTXLogStorage(t *testing.T, numLeaves int) *txLogStorage {
+	t.Helper()
+	root, err := fixedSigner.SignLogRoot(&types.LogRootV1{
+		RootHash:       rfc6962.DefaultHasher.EmptyRoot(),
+		TimestampNanos: uint64(time.Now().Add(-time.Hour).UnixNano()),
+	})
+	if err != nil {
+		t.Fatalf("SignLogRoot(): %v", err)
+	}
+	state := &fakeLogState{
+		roots:  []*trillian.SignedLogRoot{root},
+		leaves: make(map[int64]*trillian.LogLeaf),
+		nodes:  make(map[string][]tree.Node),
+	}
+	for i := 0; i < numLeaves; i++ {
+		data := []byte(fmt.Sprintf("leaf %d", i))
+		state.queue = append(state.queue, &trillian.LogLeaf{
+			LeafIdentityHash: rfc6962.DefaultHasher.HashLeaf(append([]byte("id "), data...)),
+			MerkleLeafHash:   rfc6962.DefaultHasher.HashLeaf(data),
+		})
+	}
+	return &txLogStorage{state: state}
+}
+
+func (s *txLogStorage) setFailure(method string, skip int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.failAt, s.failSkip = method, skip
+}
+
+// maybeFail returns errCrash if the method is the one set up to fail. Must be
+// called with s.mu held.
+func (s *txLogStorage) maybeFail(method string) error {
+	if s.failAt != method {
+		return nil
+	}
+	if s.failSkip > 0 {
+		s.failSkip--
+		return nil
+	}
+	s.failAt = ""
+	return errCrash
+}
+
+func (s *txLogStorage) ReadWriteTransaction(ctx context.Context, _ *trillian.Tree, f storage.LogTXFunc) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	tx := &txLogTreeTX{s: s, state: s.state.clone()}
+	if err := f(ctx, tx); err != nil {
+		return err
+	}
+	if err := s.maybeFail("Commit"); err != nil {
+		return err
+	}
+	s.state = tx.state
+	// Report a failure even though the transaction has committed, like when
+	// the connection breaks while committing.
+	return s.maybeFail("CommitApplied")
+}
+
+type txLogTreeTX struct {
+	storage.LogTreeTX
+	s     *txLogStorage
+	state *fakeLogState
+}
+
+func (t *txLogTreeTX) root() (*types.LogRootV1, error) {
+	var root types.LogRootV1
+	if err := root.UnmarshalBinary(t.state.roots[len(t.state.roots)-1].LogRoot); err != nil {
+		return nil, err
+	}
+	return &root, nil
+}
+
+func (t *txLogTreeTX) LatestSignedLogRoot(ctx context.Context) (*trillian.SignedLogRoot, error) {
+	if err := t.s.maybeFail("LatestSignedLogRoot"); err != nil {
+		return nil, err
+	}
+	return t.state.roots[len(t.state.roots)-1], nil
+}
+
+func (t *txLogTreeTX) WriteRevision(ctx context.Context) (int64, error) {
+	root, err := t.root()
+	if err != nil {
+		return 0, err
+	}
+	return int64(root.Revision) + 1, nil
+}
+
+func (t *txLogTreeTX) DequeueLeaves(ctx context.Context, limit int, cutoff time.Time) ([]*trillian.LogLeaf, error) {
+	if err := t.s.maybeFail("DequeueLeaves"); err != nil {
+		return nil, err
+	}
+	var ret []*trillian.LogLeaf
+	for i := 0; i < limit && i < len(t.state.queue); i++ {
+		ret = append(ret, proto.Clone(t.state.queue[i]).(*trillian.LogLeaf))
+	}
+	return ret, nil
+}
+
+func (t *txLogTreeTX) UpdateSequencedLeaves(ctx context.Context, leaves []*trillian.LogLeaf) error {
+	if err := t.s.maybeFail("UpdateSequencedLeaves"); err != nil {
+		return err
+	}
+	for _, leaf := range leaves {
+		if _, ok := t.state.leaves[leaf.LeafIndex]; ok {
+			return fmt.Errorf("leaf index %d already sequenced", leaf.LeafIndex)
+		}
+		t.state.leaves[leaf.LeafIndex] = proto.Clone(leaf).(*trillian.LogLeaf)
+		for i, queued := range t.state.queue {
+			if bytes.Equal(queued.LeafIdentityHash, leaf.LeafIdentityHash) {
+				t.state.queue = append(t.state.queue[:i:i], t.state.queue[i+1:]...)
+				break
+			}
+		}
+	}
+	return nil
+}
+
+func (t *txLogTreeTX) GetMerkleNodes(ctx context.Context, rev int64, ids []tree.NodeID) ([]tree.Node, error) {
+	if err := t.s.maybeFail("GetMerkleNodes"); err != nil {
+		return nil, err
+	}
+	ret := make([]tree.Node, 0, len(ids))
+	for _, id := range ids {
+		var found *tree.Node
+		for i, node := range t.state.nodes[id.AsKey()] {
+			if node.NodeRevision <= rev {
+				found = &t.state.nodes[id.AsKey()][i]
+			}
+		}
+		if found == nil {
+			return nil, fmt.Errorf("node %v not found at revision %d", id, rev)
+		}
+		ret = append(ret, *found)
+	}
+	return ret, nil
+}
+
+func (t *txLogTreeTX) SetMerkleNodes(ctx context.Context, nodes []tree.Node) error {
+	if err := t.s.maybeFail("SetMerkleNodes"); err != nil {
+		return err
+	}
+	for _, node := range nodes {
+		key := node.NodeID.AsKey()
+		t.state.nodes[key] = append(t.state.nodes[key], node)
+	}
+	return nil
+}
+
+func (t *txLogTreeTX) StoreSignedLogRoot(ctx context.Context, root *trillian.SignedLogRoot) error {
+	if err := t.s.maybeFail("StoreSignedLogRoot"); err != nil {
+		return err
+	}
+	t.state.roots = append(t.state.roots, root)
+	return nil
+}
+
+// checkLog verifies that the state of the storage is as if all the initially
+// queued leaves have been integrated one batch at a time, in queue order.
+func checkLog(t *testing.T, s *txLogStorage, want []*trillian.LogLeaf) {
+	t.Helper()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if got := len(s.state.queue); got != 0 {
+		t.Errorf("%d leaves left in the queue", got)
+	}
+	fact := compact.RangeFactory{Hash: rfc6962.DefaultHasher.HashChildren}
+	hashes := make(map[uint64][]byte)
+	cr := fact.NewEmptyRange(0)
+	hashes[0] = rfc6962.DefaultHasher.EmptyRoot()
+	for i, leaf := range want {
+		got, ok := s.state.leaves[int64(i)]
+		if !ok {
+			t.Fatalf("leaf %d not sequenced", i)
+		}
+		if !bytes.Equal(got.MerkleLeafHash, leaf.MerkleLeafHash) {
+			t.Errorf("leaf %d: MerkleLeafHash=%x, want %x", i, got.MerkleLeafHash, leaf.MerkleLeafHash)
+		}
+		if err := cr.Append(leaf.MerkleLeafHash, nil); err != nil {
+			t.Fatalf("Append(): %v", err)
+		}
+		hash, err := cr.GetRootHash(nil)
+		if err != nil {
+			t.Fatalf("GetRootHash(): %v", err)
+		}
+		hashes[cr.End()] = hash
+	}
+	if got, want := len(s.state.leaves), len(want); got != want {
+		t.Errorf("%d leaves sequenced, want %d", got, want)
+	}
+
+	// Every root must be consistent with the leaves, and the roots must have
+	// been committed in order.
+	for i, slr := range s.state.roots {
+		var root types.LogRootV1
+		if err := root.UnmarshalBinary(slr.LogRoot); err != nil {
+			t.Fatalf("UnmarshalBinary(): %v", err)
+		}
+		if got, want := root.Revision, uint64(i); got != want {
+			t.Errorf("root %d: Revision=%d, want %d", i, got, want)
+		}
+		if got, want := root.RootHash, hashes[root.TreeSize]; !bytes.Equal(got, want) {
+			t.Errorf("root %d: RootHash=%x, want %x", i, got, want)
+		}
+		if i == len(s.state.roots)-1 && root.TreeSize != uint64(len(want)) {
+			t.Errorf("latest root: TreeSize=%d, want %d", root.TreeSize, len(want))
+		}
+	}
+}
+
+// waitPrefetch waits until the prefetch of the next batch, if any, has finished
+// its transaction, so that failures set up afterwards hit the next batch.
+func waitPrefetch(p *PipelinedSequencer) {
+	if p.next != nil {
+		<-p.next.done
+	}
+}
+
+func newPipelineForTest(s *txLogStorage) *PipelinedSequencer {
+	seq := NewSequencer(rfc6962.DefaultHasher, clock.System, s, fixedSigner, nil, quota.Noop())
+	return NewPipelinedSequencer(seq, &trillian.Tree{TreeId: 1, TreeType: trillian.TreeType_LOG})
+}
+
+// drain runs the pipeline until the queue is empty.
+func drain(ctx context.Context, t *testing.T, p *PipelinedSequencer, limit int) {
+	t.Helper()
+	for i := 0; i < 100; i++ {
+		n, err := p.IntegrateBatch(ctx, limit, 0, 0)
+		if err != nil {
+			t.Fatalf("IntegrateBatch(): %v", err)
+		}
+		if n == 0 {
+			return
+		}
+	}
+	t.Fatal("IntegrateBatch() didn't drain the queue")
+}
+
+func TestPipelinedSequencer(t *testing.T) {
+	ctx := context.Background()
+	const numLeaves, limit = 20, 3
+	s := newTXLogStorage(t, numLeaves)
+	want := s.state.clone().queue
+
+	p := newPipelineForTest(s)
+	if _, err := p.IntegrateBatch(ctx, limit, 0, 0); err != nil {
+		t.Fatalf("IntegrateBatch(): %v", err)
+	}
+	if p.cr == nil || p.cr.End() != limit {
+		t.Fatalf("IntegrateBatch() kept compact range %v, want one of size %d", p.cr, limit)
+	}
+	if p.next == nil {
+		t.Fatal("IntegrateBatch() didn't prefetch the next batch")
+	}
+	waitPrefetch(p)
+	if err := p.next.err; err != nil {
+		t.Fatalf("prefetch failed: %v", err)
+	}
+	if p.next.hashed == nil || !p.next.hashed.matches(want[limit:2*limit]) {
+		t.Error("prefetched batch doesn't match the next queued leaves")
+	}
+	drain(ctx, t, p, limit)
+	checkLog(t, s, want)
+}
+
+func TestPipelinedSequencerPrefetchStale(t *testing.T) {
+	ctx := context.Background()
+	const limit = 3
+	s := newTXLogStorage(t, 2*limit+1)
+	want := s.state.clone().queue
+	// Only the first limit+1 leaves are queued before the first batch.
+	s.state.queue = s.state.queue[:limit+1]
+
+	p := newPipelineForTest(s)
+	if _, err := p.IntegrateBatch(ctx, limit, 0, 0); err != nil {
+		t.Fatalf("IntegrateBatch(): %v", err)
+	}
+	waitPrefetch(p)
+	if p.next.hashed == nil || !p.next.hashed.matches(want[limit:limit+1]) {
+		t.Fatal("prefetched batch doesn't match the next queued leaf")
+	}
+	// More leaves are queued after the prefetch, so the next batch dequeues
+	// more leaves than were prefetched.
+	s.mu.Lock()
+	s.state.queue = append(s.state.queue, want[limit+1:]...)
+	s.mu.Unlock()
+	drain(ctx, t, p, limit)
+	checkLog(t, s, want)
+}
+
+func TestPipelinedSequencerPrefetchBeforeCommit(t *testing.T) {
+	ctx := context.Background()
+	const numLeaves, limit = 20, 3
+	s := newTXLogStorage(t, numLeaves)
+	want := s.state.clone().queue
+	p := newPipelineForTest(s)
+	tx := &txLogTreeTX{s: s, state: s.state}
+	parent, err := tx.root()
+	if err != nil {
+		t.Fatalf("root(): %v", err)
+	}
+	// The batch being committed integrates the first limit leaves.
+	after := &types.LogRootV1{TreeSize: limit, RootHash: []byte("after"), Revision: parent.Revision + 1}
+
+	for _, tc := range []struct {
+		desc    string
+		prev    []*trillian.LogLeaf
+		wantErr bool
+	}{
+		{desc: "ok", prev: want[:limit]},
+		{desc: "other-leaves", prev: want[1 : limit+1], wantErr: true},
+		{desc: "more-leaves", prev: append(append([]*trillian.LogLeaf(nil), want...), want[0]), wantErr: true},
+	} {
+		t.Run(tc.desc, func(t *testing.T) {
+			// The prefetch sees the parent root, and the leaves of the batch
+			// still queued.
+			leaves, err := p.dequeueNext(ctx, parent, after, tc.prev, limit, time.Now())
+			if gotErr := err != nil; gotErr != tc.wantErr {
+				t.Fatalf("dequeueNext(): %v, wantErr %v", err, tc.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if got := len(leaves); got != limit {
+				t.Fatalf("dequeueNext() returned %d leaves, want %d", got, limit)
+			}
+			for i, leaf := range leaves {
+				if got, want := leaf.LeafIndex, int64(limit+i); got != want {
+					t.Errorf("leaf %d: LeafIndex=%d, want %d", i, got, want)
+				}
+				if !bytes.Equal(leaf.MerkleLeafHash, want[limit+i].MerkleLeafHash) {
+					t.Errorf("leaf %d: MerkleLeafHash=%x, want %x", i, leaf.MerkleLeafHash, want[limit+i].MerkleLeafHash)
+				}
+			}
+		})
+	}
+
+	// A root which is neither the committing one nor its parent fails the
+	// prefetch.
+	other := &types.LogRootV1{TreeSize: parent.TreeSize, RootHash: parent.RootHash, Revision: parent.Revision + 5}
+	if _, err := p.dequeueNext(ctx, other, after, want[:limit], limit, time.Now()); err == nil {
+		t.Error("dequeueNext() after an unknown root succeeded, want error")
+	}
+}
+
+func TestPipelinedSequencerCrash(t *testing.T) {
+	ctx := context.Background()
+	const numLeaves, limit = 20, 3
+	for _, tc := range []struct {
+		method  string
+		skip    int
+		wantErr bool
+	}{
+		{method: "LatestSignedLogRoot", wantErr: true},
+		{method: "DequeueLeaves", wantErr: true},
+		{method: "UpdateSequencedLeaves", wantErr: true},
+		{method: "SetMerkleNodes", wantErr: true},
+		{method: "StoreSignedLogRoot", wantErr: true},
+		// The batch fails to commit after prefetching the next one.
+		{method: "Commit", wantErr: true},
+		{method: "CommitApplied", wantErr: true},
+		// The prefetch of the next batch fails while the batch commits.
+		{method: "LatestSignedLogRoot", skip: 1},
+		{method: "DequeueLeaves", skip: 1},
+		{method: "Commit", skip: 1},
+	} {
+		for _, restart := range []bool{false, true} {
+			t.Run(fmt.Sprintf("%s:%d:restart=%v", tc.method, tc.skip, restart), func(t *testing.T) {
+				s := newTXLogStorage(t, numLeaves)
+				want := s.state.clone().queue
+
+				p := newPipelineForTest(s)
+				for i := 0; i < 2; i++ {
+					if _, err := p.IntegrateBatch(ctx, limit, 0, 0); err != nil {
+						t.Fatalf("IntegrateBatch(): %v", err)
+					}
+				}
+				waitPrefetch(p)
+				s.setFailure(tc.method, tc.skip)
+				if _, err := p.IntegrateBatch(ctx, limit, 0, 0); (err != nil) != tc.wantErr {
+					t.Fatalf("IntegrateBatch(): %v, wantErr %v", err, tc.wantErr)
+				}
+				if !tc.wantErr {
+					waitPrefetch(p)
+					if p.next.err == nil {
+						t.Fatal("prefetch succeeded, want error")
+					}
+				}
+				if restart {
+					p = newPipelineForTest(s)
+				}
+				drain(ctx, t, p, limit)
+				checkLog(t, s, want)
+			})
+		}
+	}
+}
+
+func TestPipelinedSequencerInterleaved(t *testing.T) {
+	ctx := context.Background()
+	const numLeaves, limit = 20, 3
+	s := newTXLogStorage(t, numLeaves)
+	want := s.state.clone().queue
+
+	p := newPipelineForTest(s)
+	seq := NewSequencer(rfc6962.DefaultHasher, clock.System, s, fixedSigner, nil, quota.Noop())
+	tree := &trillian.Tree{TreeId: 1, TreeType: trillian.TreeType_LOG}
+	for i := 0; i < 3; i++ {
+		if _, err := p.IntegrateBatch(ctx, limit, 0, 0); err != nil {
+			t.Fatalf("IntegrateBatch(): %v", err)
+		}
+		// Another sequencer integrates a batch, which makes both the compact
+		// range and the prefetched batch of the pipeline stale.
+		waitPrefetch(p)
+		if _, err := seq.IntegrateBatch(ctx, tree, limit-1, 0, 0); err != nil {
+			t.Fatalf("Sequencer.IntegrateBatch(): %v", err)
+		}
+	}
+	drain(ctx, t, p, limit)
+	checkLog(t, s, want)
+}
+
+// failingLogStorage wraps a LogStorage, and fails the next transaction either
+// before it has written anything or after it has committed. Memory storage
+// doesn't roll back all the writes of a failed transaction, so failures in
+// between are only covered by txLogStorage.
+type failingLogStorage struct {
+	storage.LogStorage
+	mu sync.Mutex
+	// failAt is "LatestSignedLogRoot", "DequeueLeaves" or "CommitApplied".
+	failAt string
+}
+
+func (s *failingLogStorage) setFailure(method string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.failAt = method
+}
+
+func (s *failingLogStorage) ReadWriteTransaction(ctx context.Context, tree *trillian.Tree, f storage.LogTXFunc) error {
+	s.mu.Lock()
+	failAt := s.failAt
+	s.failAt = ""
+	s.mu.Unlock()
+	err := s.LogStorage.ReadWriteTransaction(ctx, tree, func(ctx context.Context, tx storage.LogTreeTX) error {
+		return f(ctx, &failingLogTreeTX{LogTreeTX: tx, failAt: failAt})
+	})
+	if err == nil && failAt == "CommitApplied" {
+		return errCrash
+	}
+	return err
+}
+
+type failingLogTreeTX struct {
+	storage.LogTreeTX
+	failAt string
+}
+
+func (t *failingLogTreeTX) LatestSignedLogRoot(ctx context.Context) (*trillian.SignedLogRoot, error) {
+	if t.failAt == "LatestSignedLogRoot" {
+		return nil, errCrash
+	}
+	return t.LogTreeTX.LatestSignedLogRoot(ctx)
+}
+
+func (t *failingLogTreeTX) DequeueLeaves(ctx context.Context, limit int, cutoff time.Time) ([]*trillian.LogLeaf, error) {
+	if t.failAt == "DequeueLeaves" {
+		return nil, errCrash
+	}
+	return t.LogTreeTX.DequeueLeaves(ctx, limit, cutoff)
+}
+
+func TestPipelinedSequencerMemoryStorageCrash(t *testing.T) {
+	ctx := context.Background()
+	const numLeaves, limit = 20, 3
+	for _, method := range []string{"LatestSignedLogRoot", "DequeueLeaves", "CommitApplied"} {
+		for _, restart := range []bool{false, true} {
+			t.Run(fmt.Sprintf("%s:restart=%v", method, restart), func(t *testing.T) {
+				ts := memory.NewTreeStorage()
+				tree, err := storage.CreateTree(ctx, memory.NewAdminStorage(ts), stestonly.LogTree)
+				if err != nil {
+					t.Fatalf("CreateTree(): %v", err)
+				}
+				s := &failingLogStorage{LogStorage: memory.NewLogStorage(ts, nil)}
+				root, err := fixedSigner.SignLogRoot(&types.LogRootV1{
+					RootHash:       rfc6962.DefaultHasher.EmptyRoot(),
+					TimestampNanos: uint64(time.Now().Add(-time.Hour).UnixNano()),
+				})
+				if err != nil {
+					t.Fatalf("SignLogRoot(): %v", err)
+				}
+				if err := s.ReadWriteTransaction(ctx, tree, func(ctx context.Context, tx storage.LogTreeTX) error {
+					return tx.StoreSignedLogRoot(ctx, root)
+				}); err != nil {
+					t.Fatalf("StoreSignedLogRoot(): %v", err)
+				}
+				want := newTXLogStorage(t, numLeaves).state.queue
+				if _, err := s.QueueLeaves(ctx, tree, want, time.Now()); err != nil {
+					t.Fatalf("QueueLeaves(): %v", err)
+				}
+
+				seq := NewSequencer(rfc6962.DefaultHasher, clock.System, s, fixedSigner, nil, quota.Noop())
+				p := NewPipelinedSequencer(seq, tree)
+				for i := 0; i < 2; i++ {
+					if _, err := p.IntegrateBatch(ctx, limit, 0, 0); err != nil {
+						t.Fatalf("IntegrateBatch(): %v", err)
+					}
+				}
+				waitPrefetch(p)
+				s.setFailure(method)
+				if _, err := p.IntegrateBatch(ctx, limit, 0, 0); err == nil {
+					t.Fatal("IntegrateBatch() succeeded, want error")
+				}
+				if restart {
+					p = NewPipelinedSequencer(seq, tree)
+				}
+				drain(ctx, t, p, limit)
+
+				tx, err := s.SnapshotForTree(ctx, tree)
+				if err != nil {
+					t.Fatalf("SnapshotForTree(): %v", err)
+				}
+				defer tx.Close()
+				slr, err := tx.LatestSignedLogRoot(ctx)
+				if err != nil {
+					t.Fatalf("LatestSignedLogRoot(): %v", err)
+				}
+				var latest types.LogRootV1
+				if err := latest.UnmarshalBinary(slr.LogRoot); err != nil {
+					t.Fatalf("UnmarshalBinary(): %v", err)
+				}
+				leaves, err := tx.GetLeavesByRange(ctx, 0, numLeaves)
+				if err != nil {
+					t.Fatalf("GetLeavesByRange(): %v", err)
+				}
+				if got := len(leaves); got != numLeaves {
+					t.Fatalf("GetLeavesByRange() returned %d leaves, want %d", got, numLeaves)
+				}
+				cr := (&compact.RangeFactory{Hash: rfc6962.DefaultHasher.HashChildren}).NewEmptyRange(0)
+				for i, leaf := range leaves {
+					if !bytes.Equal(leaf.MerkleLeafHash, want[i].MerkleLeafHash) {
+						t.Errorf("leaf %d: MerkleLeafHash=%x, want %x", i, leaf.MerkleLeafHash, want[i].MerkleLeafHash)
+					}
+					if err := cr.Append(want[i].MerkleLeafHash, nil); err != nil {
+						t.Fatalf("Append(): %v", err)
+					}
+				}
+				wantHash, err := cr.GetRootHash(nil)
+				if err != nil {
+					t.Fatalf("GetRootHash(): %v", err)
+				}
+				if latest.TreeSize != numLeaves || !bytes.Equal(latest.RootHash, wantHash) {
+					t.Errorf("latest root: size %d, hash %x, want size %d, hash %x", latest.TreeSize, latest.RootHash, numLeaves, wantHash)
+				}
+			})
+		}
+	}
+}
+
+// recordingHook is an export.Hook which records the sizes of the roots and the
+// indices of the leaves passed to it.
+type recordingHook struct {
+	sizes   []uint64
+	indices []int64
+}
+
+func (r *recordingHook) Integrated(ctx context.Context, tree *trillian.Tree, slr *trillian.SignedLogRoot, leaves []*trillian.LogLeaf) error {
+	var root types.LogRootV1
+	if err := root.UnmarshalBinary(slr.LogRoot); err != nil {
+		return err
+	}
+	r.sizes = append(r.sizes, root.TreeSize)
+	for _, leaf := range leaves {
+		r.indices = append(r.indices, leaf.LeafIndex)
+	}
+	return nil
+}
+
+func TestExportHook(t *testing.T) {
+	ctx := context.Background()
+	const numLeaves, limit = 10, 3
+	s := newTXLogStorage(t, numLeaves)
+	hook := &recordingHook{}
+	p := newPipelineForTest(s)
+	p.s.exportHook = hook
+	seq := NewSequencer(rfc6962.DefaultHasher, clock.System, s, fixedSigner, nil, quota.Noop())
+	seq.exportHook = hook
+	tree := &trillian.Tree{TreeId: 1, TreeType: trillian.TreeType_LOG}
+
+	if _, err := seq.IntegrateBatch(ctx, tree, limit, 0, 0); err != nil {
+		t.Fatalf("Sequencer.IntegrateBatch(): %v", err)
+	}
+	// A failed batch isn't passed to the hook.
+	s.setFailure("Commit", 0)
+	if _, err := p.IntegrateBatch(ctx, limit, 0, 0); err == nil {
+		t.Fatal("IntegrateBatch() succeeded, want error")
+	}
+	drain(ctx, t, p, limit)
+
+	if got, want := hook.sizes, []uint64{3, 6, 9, 10}; !reflect.DeepEqual(got, want) {
+		t.Errorf("hook got roots of sizes %v, want %v", got, want)
+	}
+	for i, index := range hook.indices {
+		if index != int64(i) {
+			t.Fatalf("hook got leaves %v, want 0 to %d in order", hook.indices, numLeaves-1)
+		}
+	}
+	if got := len(hook.indices); got != numLeaves {
+		t.Errorf("hook got %d leaves, want %d", got, numLeaves)
+	}
+}
@@ -0,0 +1,91 @@
+// Copyright 2020 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package log
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/golang/glog"
+	"github.com/google/trillian/storage"
+	"github.com/google/trillian/util/clock"
+)
+
+// queueCountInterval is how long the counts of queued leaves are reused for
+// before being read from storage again.
+const queueCountInterval = 10 * time.Second
+
+// queueCounter caches the number of leaves queued in each log. Storage may have
+// to scan the whole queue to count them, so they are counted at most once per
+// interval, however many callers need them.
+type queueCounter struct {
+	ls       storage.LogStorage
+	ts       clock.TimeSource
+	interval time.Duration
+
+	mu      sync.Mutex
+	counts  storage.CountByLogID
+	fetched time.Time
+}
+
+func newQueueCounter(ls storage.LogStorage, ts clock.TimeSource, interval time.Duration) *queueCounter {
+	if ts == nil {
+		ts = clock.System
+	}
+	return &queueCounter{ls: ls, ts: ts, interval: interval}
+}
+
+// get returns the number of queued leaves per log, as counted within the last
+// interval, or nil if the storage can't count them.
+func (q *queueCounter) get(ctx context.Context) (storage.CountByLogID, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	now := q.ts.Now()
+	if !q.fetched.IsZero() && now.Sub(q.fetched) < q.interval {
+		return q.counts, nil
+	}
+	counts, err := countUnsequenced(ctx, q.ls)
+	if err != nil {
+		return nil, err
+	}
+	q.counts, q.fetched = counts, now
+	return counts, nil
+}
+
+// countUnsequenced returns the number of queued leaves per log, or nil if the
+// storage can't count them.
+func countUnsequenced(ctx context.Context, ls storage.LogStorage) (storage.CountByLogID, error) {
+	tx, err := ls.Snapshot(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create transaction: %v", err)
+	}
+	defer tx.Close()
+
+	counter, ok := tx.(storage.UnsequencedCounter)
+	if !ok {
+		glog.V(1).Info("storage can't count unsequenced leaves")
+		return nil, nil
+	}
+	counts, err := counter.GetUnsequencedCounts(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count unsequenced leaves: %v", err)
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("failed to commit: %v", err)
+	}
+	return counts, nil
+}
@@ -17,6 +17,7 @@ package log
 import (
 	"bytes"
 	"context"
+	"errors"
 	"fmt"
 	"strconv"
 	"sync"
@@ -25,6 +26,7 @@ import (
 	"github.com/golang/glog"
 	"github.com/golang/protobuf/ptypes"
 	"github.com/google/trillian"
+	"github.com/google/trillian/log/export"
 	"github.com/google/trillian/merkle/compact"
 	"github.com/google/trillian/merkle/hashers"
 	"github.com/google/trillian/monitoring"
@@ -54,6 +56,10 @@ var (
 	seqCounter             monitoring.Counter
 	seqMergeDelay          monitoring.Histogram
 	seqTimestamp           monitoring.Gauge
+	seqPipelineReloads     monitoring.Counter
+	seqPrefetchHits        monitoring.Counter
+	seqBatchSize           monitoring.Gauge
+	treeStateTransitions   monitoring.Counter
 
 	// QuotaIncreaseFactor is the multiplier used for the number of tokens added back to
 	// sequencing-based quotas. The resulting PutTokens call is equivalent to
@@ -93,8 +99,39 @@ func createSequencerMetrics(mf monitoring.MetricFactory) {
 	seqStoreRootLatency = mf.NewHistogram("sequencer_latency_store_root", "Latency of store-root part of sequencer batch operation in seconds", logIDLabel)
 	seqCounter = mf.NewCounter("sequencer_sequenced", "Number of leaves sequenced", logIDLabel)
 	seqMergeDelay = mf.NewHistogram("sequencer_merge_delay", "Delay between queuing and integration of leaves", logIDLabel)
+	seqPipelineReloads = mf.NewCounter("sequencer_pipeline_reloads", "Number of times a pipelined sequencer rebuilt its compact range from storage", logIDLabel)
+	seqPrefetchHits = mf.NewCounter("sequencer_prefetch_hits", "Number of batches hashed by a pipelined sequencer after being prefetched", logIDLabel)
+	seqBatchSize = mf.NewGauge("sequencer_batch_size", "Number of leaves the sequencer dequeues per batch, as chosen by adaptive sizing", logIDLabel)
+	treeStateTransitions = mf.NewCounter("tree_state_transitions", "Number of times the signer changed the state of a tree, by new state", logIDLabel, "state")
 }
 
+// FinalRootMetadata is the metadata of the root that the signer publishes for a
+// DRAINING log once all its leaves are integrated, before freezing the log.
+const FinalRootMetadata = "final"
+
+// IsFinalRoot returns whether the root is the final root of a frozen log.
+func IsFinalRoot(root *types.LogRootV1) bool {
+	return string(root.Metadata) == FinalRootMetadata
+}
+
+// checkFinalRoot returns true if the root is the final root of the log, after
+// which no more roots may be signed. A DRAINING log with a final root is only
+// waiting to be frozen, while for other logs it is an error.
+func checkFinalRoot(tree *trillian.Tree, root *types.LogRootV1) (bool, error) {
+	if !IsFinalRoot(root) {
+		return false, nil
+	}
+	if tree.TreeState != trillian.TreeState_DRAINING {
+		return true, fmt.Errorf("%v: log in state %v has a final root", tree.TreeId, tree.TreeState)
+	}
+	glog.V(1).Infof("%v: final root published, not signing", tree.TreeId)
+	return true, nil
+}
+
+// errNotDrained aborts publishing the final root of a log with leaves left to
+// integrate.
+var errNotDrained = errors.New("log has leaves to integrate")
+
 // Sequencer instances are responsible for integrating new leaves into a single log.
 // Leaves will be assigned unique sequence numbers when they are processed.
 // There is no strong ordering guarantee but in general entries will be processed
@@ -105,6 +142,7 @@ type Sequencer struct {
 	logStorage storage.LogStorage
 	signer     *tcrypto.Signer
 	qm         quota.Manager
+	exportHook export.Hook
 }
 
 // maxTreeDepth sets an upper limit on the size of Log trees.
@@ -226,19 +264,28 @@ func (s Sequencer) prepareLeaves(leaves []*trillian.LogLeaf, begin uint64, label
 // updateCompactRange adds the passed in leaves to the compact range. Returns a
 // map of all updated tree nodes, and the new root hash.
 func (s Sequencer) updateCompactRange(cr *compact.Range, leaves []*trillian.LogLeaf, label string) (map[compact.NodeID][]byte, []byte, error) {
+	hashes := make([][]byte, len(leaves))
+	for i, leaf := range leaves {
+		if idx, want := leaf.LeafIndex, cr.End()+uint64(i); idx < 0 || idx != int64(want) {
+			return nil, nil, fmt.Errorf("leaf index mismatch: got %d, want %d", idx, want)
+		}
+		hashes[i] = leaf.MerkleLeafHash
+	}
+	return appendLeafHashes(cr, hashes)
+}
+
+// appendLeafHashes appends the passed in Merkle leaf hashes to the compact
+// range. Returns a map of all updated tree nodes, and the new root hash.
+func appendLeafHashes(cr *compact.Range, hashes [][]byte) (map[compact.NodeID][]byte, []byte, error) {
 	nodeMap := make(map[compact.NodeID][]byte)
 	store := func(id compact.NodeID, hash []byte) { nodeMap[id] = hash }
 
 	// Update the tree state by integrating the leaves one by one.
-	for _, leaf := range leaves {
-		idx := leaf.LeafIndex
-		if size := cr.End(); idx < 0 || idx != int64(size) {
-			return nil, nil, fmt.Errorf("leaf index mismatch: got %d, want %d", idx, size)
-		}
+	for _, hash := range hashes {
 		// Store the leaf hash in the Merkle tree.
-		store(compact.NewNodeID(0, uint64(idx)), leaf.MerkleLeafHash)
+		store(compact.NewNodeID(0, cr.End()), hash)
 		// Store all the new internal nodes.
-		if err := cr.Append(leaf.MerkleLeafHash, store); err != nil {
+		if err := cr.Append(hash, store); err != nil {
 			return nil, nil, err
 		}
 	}
@@ -323,6 +370,112 @@ func (s *preorderedLogSequencingTask) update(ctx context.Context, leaves []*tril
 	return nil
 }
 
+// newSequencingTask returns the sequencingTask implementation for the type of
+// the given tree.
+func newSequencingTask(tree *trillian.Tree, data *sequencingTaskData) (sequencingTask, error) {
+	switch tree.TreeType {
+	case trillian.TreeType_LOG:
+		return (*logSequencingTask)(data), nil
+	case trillian.TreeType_PREORDERED_LOG:
+		return (*preorderedLogSequencingTask)(data), nil
+	default:
+		return nil, fmt.Errorf("IntegrateBatch not supported for TreeType %v", tree.TreeType)
+	}
+}
+
+// latestRoot returns the latest log root from storage.
+func (s Sequencer) latestRoot(ctx context.Context, tx storage.LogTreeTX, treeID int64, label string) (*types.LogRootV1, error) {
+	start := s.timeSource.Now()
+	// Get the latest known root from storage
+	sth, err := tx.LatestSignedLogRoot(ctx)
+	if err != nil || sth == nil {
+		return nil, fmt.Errorf("%v: Sequencer failed to get latest root: %v", treeID, err)
+	}
+	// There is no trust boundary between the signer and the
+	// database, so we skip signature verification.
+	// TODO(gbelvin): Add signature checking as a santity check.
+	var currentRoot types.LogRootV1
+	if err := currentRoot.UnmarshalBinary(sth.LogRoot); err != nil {
+		return nil, fmt.Errorf("%v: Sequencer failed to unmarshal latest root: %v", treeID, err)
+	}
+	seqGetRootLatency.Observe(clock.SecondsSince(s.timeSource, start), label)
+	seqTreeSize.Set(float64(currentRoot.TreeSize), label)
+
+	if currentRoot.RootHash == nil {
+		glog.Warningf("%v: Fresh log - no previous TreeHeads exist.", treeID)
+		return nil, storage.ErrTreeNeedsInit
+	}
+	return &currentRoot, nil
+}
+
+// rootIsFresh returns whether the current root is recent enough that no new
+// root is needed when there are no leaves to integrate.
+func (s Sequencer) rootIsFresh(currentRoot *types.LogRootV1, treeID int64, maxRootDurationInterval time.Duration) bool {
+	nowNanos := s.timeSource.Now().UnixNano()
+	interval := time.Duration(nowNanos - int64(currentRoot.TimestampNanos))
+	if maxRootDurationInterval == 0 || interval < maxRootDurationInterval {
+		return true
+	}
+	glog.Infof("%v: Force new root generation as %v since last root", treeID, interval)
+	return false
+}
+
+// storeBatch writes the tree nodes updated by a batch at newVersion, then
+// signs and stores the resulting log root. The compact range must already
+// include the batch, and newRoot must be its root hash. The root carries the
+// given metadata.
+func (s Sequencer) storeBatch(ctx context.Context, tx storage.LogTreeTX, treeID int64, label string, currentRoot *types.LogRootV1, cr *compact.Range, nodeMap map[compact.NodeID][]byte, newRoot []byte, newVersion int64, metadata []byte) (*types.LogRootV1, *trillian.SignedLogRoot, error) {
+	stageStart := s.timeSource.Now()
+
+	// Build objects for the nodes to be updated. Because we deduped via the map
+	// each node can only be created / updated once in each tree revision and
+	// they cannot conflict when we do the storage update.
+	targetNodes, err := s.buildNodesFromNodeMap(nodeMap, newVersion)
+	if err != nil {
+		// Probably an internal error with map building, unexpected.
+		return nil, nil, fmt.Errorf("%v: Failed to build target nodes in sequencer: %v", treeID, err)
+	}
+
+	// Now insert or update the nodes affected by the above, at the new tree
+	// version.
+	if err := tx.SetMerkleNodes(ctx, targetNodes); err != nil {
+		return nil, nil, fmt.Errorf("%v: Sequencer failed to set Merkle nodes: %v", treeID, err)
+	}
+	seqSetNodesLatency.Observe(clock.SecondsSince(s.timeSource, stageStart), label)
+	stageStart = s.timeSource.Now()
+
+	// Create the log root ready for signing.
+	if cr.End() == 0 {
+		// Override the nil root hash returned by the compact range.
+		newRoot = s.hasher.EmptyRoot()
+	}
+	newLogRoot := &types.LogRootV1{
+		RootHash:       newRoot,
+		TimestampNanos: uint64(s.timeSource.Now().UnixNano()),
+		TreeSize:       cr.End(),
+		Revision:       uint64(newVersion),
+		Metadata:       metadata,
+	}
+	seqTreeSize.Set(float64(newLogRoot.TreeSize), label)
+	seqTimestamp.Set(float64(time.Duration(newLogRoot.TimestampNanos)*time.Nanosecond/
+		time.Millisecond), label)
+
+	if newLogRoot.TimestampNanos <= currentRoot.TimestampNanos {
+		return nil, nil, fmt.Errorf("%v: refusing to sign root with timestamp earlier than previous root (%d <= %d)", treeID, newLogRoot.TimestampNanos, currentRoot.TimestampNanos)
+	}
+
+	newSLR, err := s.signer.SignLogRoot(newLogRoot)
+	if err != nil {
+		return nil, nil, fmt.Errorf("%v: signer failed to sign root: %v", treeID, err)
+	}
+
+	if err := tx.StoreSignedLogRoot(ctx, newSLR); err != nil {
+		return nil, nil, fmt.Errorf("%v: failed to write updated tree root: %v", treeID, err)
+	}
+	seqStoreRootLatency.Observe(clock.SecondsSince(s.timeSource, stageStart), label)
+	return newLogRoot, newSLR, nil
+}
+
 // IntegrateBatch wraps up all the operations needed to take a batch of queued
 // or sequenced leaves and integrate them into the tree.
 func (s Sequencer) IntegrateBatch(ctx context.Context, tree *trillian.Tree, limit int, guardWindow, maxRootDurationInterval time.Duration) (int, error) {
@@ -332,29 +485,17 @@ func (s Sequencer) IntegrateBatch(ctx context.Context, tree *trillian.Tree, limi
 	numLeaves := 0
 	var newLogRoot *types.LogRootV1
 	var newSLR *trillian.SignedLogRoot
+	var integrated []*trillian.LogLeaf
 	err := s.logStorage.ReadWriteTransaction(ctx, tree, func(ctx context.Context, tx storage.LogTreeTX) error {
-		stageStart := s.timeSource.Now()
 		defer seqBatches.Inc(label)
 		defer func() { seqLatency.Observe(clock.SecondsSince(s.timeSource, start), label) }()
 
-		// Get the latest known root from storage
-		sth, err := tx.LatestSignedLogRoot(ctx)
-		if err != nil || sth == nil {
-			return fmt.Errorf("%v: Sequencer failed to get latest root: %v", tree.TreeId, err)
-		}
-		// There is no trust boundary between the signer and the
-		// database, so we skip signature verification.
-		// TODO(gbelvin): Add signature checking as a santity check.
-		var currentRoot types.LogRootV1
-		if err := currentRoot.UnmarshalBinary(sth.LogRoot); err != nil {
-			return fmt.Errorf("%v: Sequencer failed to unmarshal latest root: %v", tree.TreeId, err)
+		currentRoot, err := s.latestRoot(ctx, tx, tree.TreeId, label)
+		if err != nil {
+			return err
 		}
-		seqGetRootLatency.Observe(clock.SecondsSince(s.timeSource, stageStart), label)
-		seqTreeSize.Set(float64(currentRoot.TreeSize), label)
-
-		if currentRoot.RootHash == nil {
-			glog.Warningf("%v: Fresh log - no previous TreeHeads exist.", tree.TreeId)
-			return storage.ErrTreeNeedsInit
+		if final, err := checkFinalRoot(tree, currentRoot); final {
+			return err
 		}
 
 		taskData := &sequencingTaskData{
@@ -363,14 +504,9 @@ func (s Sequencer) IntegrateBatch(ctx context.Context, tree *trillian.Tree, limi
 			timeSource: s.timeSource,
 			tx:         tx,
 		}
-		var st sequencingTask
-		switch tree.TreeType {
-		case trillian.TreeType_LOG:
-			st = (*logSequencingTask)(taskData)
-		case trillian.TreeType_PREORDERED_LOG:
-			st = (*preorderedLogSequencingTask)(taskData)
-		default:
-			return fmt.Errorf("IntegrateBatch not supported for TreeType %v", tree.TreeType)
+		st, err := newSequencingTask(tree, taskData)
+		if err != nil {
+			return err
 		}
 
 		sequencedLeaves, err := st.fetch(ctx, limit, start.Add(-guardWindow))
@@ -381,19 +517,14 @@ func (s Sequencer) IntegrateBatch(ctx context.Context, tree *trillian.Tree, limi
 
 		// We need to create a signed root if entries were added or the latest root
 		// is too old.
-		if numLeaves == 0 {
-			nowNanos := s.timeSource.Now().UnixNano()
-			interval := time.Duration(nowNanos - int64(currentRoot.TimestampNanos))
-			if maxRootDurationInterval == 0 || interval < maxRootDurationInterval {
-				// We have nothing to integrate into the tree.
-				glog.V(1).Infof("%v: No leaves sequenced in this signing operation", tree.TreeId)
-				return nil
-			}
-			glog.Infof("%v: Force new root generation as %v since last root", tree.TreeId, interval)
+		if numLeaves == 0 && s.rootIsFresh(currentRoot, tree.TreeId, maxRootDurationInterval) {
+			// We have nothing to integrate into the tree.
+			glog.V(1).Infof("%v: No leaves sequenced in this signing operation", tree.TreeId)
+			return nil
 		}
 
-		stageStart = s.timeSource.Now()
-		cr, err := s.initCompactRangeFromStorage(ctx, &currentRoot, tx)
+		stageStart := s.timeSource.Now()
+		cr, err := s.initCompactRangeFromStorage(ctx, currentRoot, tx)
 		if err != nil {
 			return fmt.Errorf("%v: compact range init failed: %v", tree.TreeId, err)
 		}
@@ -426,67 +557,92 @@ func (s Sequencer) IntegrateBatch(ctx context.Context, tree *trillian.Tree, limi
 		if err := st.update(ctx, sequencedLeaves); err != nil {
 			return err
 		}
-		stageStart = s.timeSource.Now()
+		integrated = sequencedLeaves
+
+		newLogRoot, newSLR, err = s.storeBatch(ctx, tx, tree.TreeId, label, currentRoot, cr, nodeMap, newRoot, newVersion, nil)
+		return err
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	// Let quota.Manager know about newly-sequenced entries.
+	s.replenishQuota(ctx, numLeaves, tree.TreeId)
+
+	seqCounter.Add(float64(numLeaves), label)
+	if newSLR != nil {
+		glog.Infof("%v: sequenced %v leaves, size %v, tree-revision %v", tree.TreeId, numLeaves, newLogRoot.TreeSize, newLogRoot.Revision)
+		s.exportLeaves(ctx, tree, newSLR, integrated)
+	}
+	return numLeaves, nil
+}
 
-		// Build objects for the nodes to be updated. Because we deduped via the map
-		// each node can only be created / updated once in each tree revision and
-		// they cannot conflict when we do the storage update.
-		targetNodes, err := s.buildNodesFromNodeMap(nodeMap, newVersion)
+// PublishFinalRoot signs and stores the final root of a log, which covers the
+// same leaves as the latest root and carries FinalRootMetadata. It returns
+// false without storing a root if there are leaves to integrate, including
+// those queued within the guard window, and true if the final root is stored
+// or had been stored before.
+func (s Sequencer) PublishFinalRoot(ctx context.Context, tree *trillian.Tree) (bool, error) {
+	label := strconv.FormatInt(tree.TreeId, 10)
+	err := s.logStorage.ReadWriteTransaction(ctx, tree, func(ctx context.Context, tx storage.LogTreeTX) error {
+		currentRoot, err := s.latestRoot(ctx, tx, tree.TreeId, label)
 		if err != nil {
-			// Probably an internal error with map building, unexpected.
-			return fmt.Errorf("%v: Failed to build target nodes in sequencer: %v", tree.TreeId, err)
+			return err
 		}
-
-		// Now insert or update the nodes affected by the above, at the new tree
-		// version.
-		if err := tx.SetMerkleNodes(ctx, targetNodes); err != nil {
-			return fmt.Errorf("%v: Sequencer failed to set Merkle nodes: %v", tree.TreeId, err)
+		if IsFinalRoot(currentRoot) {
+			return nil
 		}
-		seqSetNodesLatency.Observe(clock.SecondsSince(s.timeSource, stageStart), label)
-		stageStart = s.timeSource.Now()
 
-		// Create the log root ready for signing.
-		if cr.End() == 0 {
-			// Override the nil root hash returned by the compact range.
-			newRoot = s.hasher.EmptyRoot()
+		st, err := newSequencingTask(tree, &sequencingTaskData{
+			label:      label,
+			treeSize:   currentRoot.TreeSize,
+			timeSource: s.timeSource,
+			tx:         tx,
+		})
+		if err != nil {
+			return err
 		}
-		newLogRoot = &types.LogRootV1{
-			RootHash:       newRoot,
-			TimestampNanos: uint64(s.timeSource.Now().UnixNano()),
-			TreeSize:       cr.End(),
-			Revision:       uint64(newVersion),
+		leaves, err := st.fetch(ctx, 1, s.timeSource.Now())
+		if err != nil {
+			return fmt.Errorf("%v: Sequencer failed to check for leaves: %v", tree.TreeId, err)
 		}
-		seqTreeSize.Set(float64(newLogRoot.TreeSize), label)
-		seqTimestamp.Set(float64(time.Duration(newLogRoot.TimestampNanos)*time.Nanosecond/
-			time.Millisecond), label)
-
-		if newLogRoot.TimestampNanos <= currentRoot.TimestampNanos {
-			return fmt.Errorf("%v: refusing to sign root with timestamp earlier than previous root (%d <= %d)", tree.TreeId, newLogRoot.TimestampNanos, currentRoot.TimestampNanos)
+		if len(leaves) > 0 {
+			return errNotDrained
 		}
 
-		newSLR, err = s.signer.SignLogRoot(newLogRoot)
+		cr, err := s.initCompactRangeFromStorage(ctx, currentRoot, tx)
 		if err != nil {
-			return fmt.Errorf("%v: signer failed to sign root: %v", tree.TreeId, err)
+			return fmt.Errorf("%v: compact range init failed: %v", tree.TreeId, err)
 		}
-
-		if err := tx.StoreSignedLogRoot(ctx, newSLR); err != nil {
-			return fmt.Errorf("%v: failed to write updated tree root: %v", tree.TreeId, err)
+		newVersion, err := tx.WriteRevision(ctx)
+		if err != nil {
+			return err
 		}
-		seqStoreRootLatency.Observe(clock.SecondsSince(s.timeSource, stageStart), label)
-		return nil
+		if got, want := newVersion, int64(currentRoot.Revision)+1; got != want {
+			return fmt.Errorf("%v: got writeRevision of %v, but expected %v", tree.TreeId, got, want)
+		}
+		_, _, err = s.storeBatch(ctx, tx, tree.TreeId, label, currentRoot, cr, nil, currentRoot.RootHash, newVersion, []byte(FinalRootMetadata))
+		return err
 	})
-	if err != nil {
-		return 0, err
+	switch {
+	case err == errNotDrained:
+		return false, nil
+	case err != nil:
+		return false, err
 	}
+	return true, nil
+}
 
-	// Let quota.Manager know about newly-sequenced entries.
-	s.replenishQuota(ctx, numLeaves, tree.TreeId)
-
-	seqCounter.Add(float64(numLeaves), label)
-	if newSLR != nil {
-		glog.Infof("%v: sequenced %v leaves, size %v, tree-revision %v", tree.TreeId, numLeaves, newLogRoot.TreeSize, newLogRoot.Revision)
+// exportLeaves passes a newly committed root and the leaves integrated under it to
+// the export hook, if any. Failures are only logged, as the hook is expected
+// to catch up with the leaves it missed.
+func (s Sequencer) exportLeaves(ctx context.Context, tree *trillian.Tree, root *trillian.SignedLogRoot, leaves []*trillian.LogLeaf) {
+	if s.exportHook == nil {
+		return
+	}
+	if err := s.exportHook.Integrated(ctx, tree, root, leaves); err != nil {
+		glog.Warningf("%v: failed to export leaves: %v", tree.TreeId, err)
 	}
-	return numLeaves, nil
 }
 
 // replenishQuota replenishes all quotas, such as {Tree/Global, Read/Write},
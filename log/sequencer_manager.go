@@ -17,6 +17,7 @@ package log
 import (
 	"context"
 	"fmt"
+	"strconv"
 	"sync"
 	"time"
 
@@ -25,6 +26,7 @@ import (
 	"github.com/google/trillian"
 	"github.com/google/trillian/extension"
 	"github.com/google/trillian/merkle/hashers"
+	"github.com/google/trillian/storage"
 	"github.com/google/trillian/trees"
 
 	tcrypto "github.com/google/trillian/crypto"
@@ -36,6 +38,15 @@ type SequencerManager struct {
 	registry     extension.Registry
 	signers      map[int64]*tcrypto.Signer
 	signersMutex sync.Mutex
+	// pipelines holds the pipelined sequencers used when
+	// OperationInfo.PipelinedSequencing is set.
+	pipelines      map[int64]*PipelinedSequencer
+	pipelinesMutex sync.Mutex
+	// sizer adapts batch sizes when OperationInfo.BatchSizing is enabled, using
+	// the queue depths from queue. Both are created on the first pass.
+	sizer     *batchSizer
+	queue     *queueCounter
+	sizerOnce sync.Once
 }
 
 var seqOpts = trees.NewGetOpts(trees.SequenceLog, trillian.TreeType_LOG, trillian.TreeType_PREORDERED_LOG)
@@ -47,6 +58,7 @@ func NewSequencerManager(registry extension.Registry, gw time.Duration) *Sequenc
 		guardWindow: gw,
 		registry:    registry,
 		signers:     make(map[int64]*tcrypto.Signer),
+		pipelines:   make(map[int64]*PipelinedSequencer),
 	}
 }
 
@@ -72,19 +84,78 @@ func (s *SequencerManager) ExecutePass(ctx context.Context, logID int64, info *O
 	}
 
 	sequencer := NewSequencer(hasher, info.TimeSource, s.registry.LogStorage, signer, s.registry.MetricFactory, s.registry.QuotaManager)
+	sequencer.exportHook = s.registry.ExportHook
 
 	maxRootDuration, err := ptypes.Duration(tree.MaxRootDuration)
 	if err != nil {
 		glog.Warning("failed to parse tree.MaxRootDuration, using zero")
 		maxRootDuration = 0
 	}
-	leaves, err := sequencer.IntegrateBatch(ctx, tree, info.BatchSize, s.guardWindow, maxRootDuration)
+	batchSize := info.BatchSize
+	var sizer *batchSizer
+	if info.BatchSizing.MaxBatchSize > 0 {
+		s.sizerOnce.Do(func() {
+			s.sizer = newBatchSizer(info.BatchSizing, s.registry.MetricFactory)
+			s.queue = newQueueCounter(s.registry.LogStorage, info.TimeSource, queueCountInterval)
+		})
+		sizer = s.sizer
+		batchSize = sizer.size(logID, info.BatchSize)
+	}
+	start := info.TimeSource.Now()
+	var leaves int
+	if info.PipelinedSequencing {
+		leaves, err = s.getPipeline(sequencer, tree).IntegrateBatch(ctx, batchSize, s.guardWindow, maxRootDuration)
+	} else {
+		leaves, err = sequencer.IntegrateBatch(ctx, tree, batchSize, s.guardWindow, maxRootDuration)
+	}
+	if sizer != nil {
+		latency := info.TimeSource.Now().Sub(start)
+		sizer.update(ctx, logID, batchSize, leaves, s.queueDepth(ctx, logID), latency, err)
+	}
 	if err != nil {
 		return 0, fmt.Errorf("failed to integrate batch for %v: %v", logID, err)
 	}
+	if tree.TreeState == trillian.TreeState_DRAINING && leaves == 0 {
+		if err := s.freeze(ctx, sequencer, tree); err != nil {
+			return 0, fmt.Errorf("failed to freeze log %v: %v", logID, err)
+		}
+	}
 	return leaves, nil
 }
 
+// freeze publishes the final root of a DRAINING log once all its leaves have
+// been integrated, then makes the log FROZEN.
+func (s *SequencerManager) freeze(ctx context.Context, sequencer *Sequencer, tree *trillian.Tree) error {
+	final, err := sequencer.PublishFinalRoot(ctx, tree)
+	if err != nil || !final {
+		return err
+	}
+	// The final root may have been stored by an earlier pass that failed to
+	// update the tree, in which case this completes the transition.
+	if _, err := storage.UpdateTree(ctx, s.registry.AdminStorage, tree.TreeId, func(t *trillian.Tree) {
+		t.TreeState = trillian.TreeState_FROZEN
+	}); err != nil {
+		return err
+	}
+	glog.Infof("%v: published final root and froze the drained log", tree.TreeId)
+	treeStateTransitions.Inc(strconv.FormatInt(tree.TreeId, 10), trillian.TreeState_FROZEN.String())
+	return nil
+}
+
+// queueDepth returns the number of leaves queued in the log, or -1 if storage
+// can't count them.
+func (s *SequencerManager) queueDepth(ctx context.Context, logID int64) int64 {
+	counts, err := s.queue.get(ctx)
+	if err != nil {
+		glog.Warningf("%v: failed to get queue depth: %v", logID, err)
+		return -1
+	}
+	if counts == nil {
+		return -1
+	}
+	return counts[logID]
+}
+
 // getSigner returns a signer for the given tree.
 // Signers are cached, so only one will be created per tree.
 func (s *SequencerManager) getSigner(ctx context.Context, tree *trillian.Tree) (*tcrypto.Signer, error) {
@@ -103,3 +174,36 @@ func (s *SequencerManager) getSigner(ctx context.Context, tree *trillian.Tree) (
 	s.signers[tree.GetTreeId()] = signer
 	return signer, nil
 }
+
+// heldLogs drops the pipelined sequencers of the logs that this instance is no
+// longer master for, including the deleted and frozen ones.
+func (s *SequencerManager) heldLogs(logIDs []int64) {
+	held := make(map[int64]bool, len(logIDs))
+	for _, id := range logIDs {
+		held[id] = true
+	}
+	s.pipelinesMutex.Lock()
+	defer s.pipelinesMutex.Unlock()
+	for id := range s.pipelines {
+		if !held[id] {
+			delete(s.pipelines, id)
+		}
+	}
+}
+
+// getPipeline returns the pipelined sequencer for the given tree, creating it
+// if there isn't one yet, and makes it use the passed in Sequencer and tree.
+// The state it keeps is checked against storage on each batch, so it is still
+// correct if another signer has sequenced the log in the meantime.
+func (s *SequencerManager) getPipeline(sequencer *Sequencer, tree *trillian.Tree) *PipelinedSequencer {
+	s.pipelinesMutex.Lock()
+	defer s.pipelinesMutex.Unlock()
+
+	p, ok := s.pipelines[tree.TreeId]
+	if !ok {
+		p = NewPipelinedSequencer(sequencer, tree)
+		s.pipelines[tree.TreeId] = p
+	}
+	p.s, p.tree = sequencer, tree
+	return p
+}
@@ -328,3 +328,87 @@ func fakeKeyProtoHandler(wantKeyProto proto.Message, signer crypto.Signer, err e
 		return nil, fmt.Errorf("fakeKeyProtoHandler: got %s, want %s", gotKeyProto, wantKeyProto)
 	}
 }
+
+func TestSequencerManagerFreezesDrainedLog(t *testing.T) {
+	ctx := context.Background()
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	tree := proto.Clone(stestonly.LogTree).(*trillian.Tree)
+	tree.TreeState = trillian.TreeState_DRAINING
+	logID := tree.GetTreeId()
+
+	var keyProto ptypes.DynamicAny
+	if err := ptypes.UnmarshalAny(tree.PrivateKey, &keyProto); err != nil {
+		t.Fatalf("Failed to unmarshal tree.PrivateKey: %v", err)
+	}
+	keys.RegisterHandler(fakeKeyProtoHandler(keyProto.Message, fixedGoSigner, nil))
+	defer keys.UnregisterHandler(keyProto.Message)
+
+	for _, tc := range []struct {
+		desc       string
+		numLeaves  int
+		wantFrozen bool
+	}{
+		{desc: "queued-leaves", numLeaves: 1},
+		{desc: "drained", wantFrozen: true},
+	} {
+		t.Run(tc.desc, func(t *testing.T) {
+			mockAdminTx := storage.NewMockReadOnlyAdminTX(mockCtrl)
+			mockAdminTx.EXPECT().GetTree(gomock.Any(), logID).Return(tree, nil)
+			mockAdminTx.EXPECT().Commit().Return(nil)
+			mockAdminTx.EXPECT().Close().Return(nil)
+			mockAdmin := &stestonly.FakeAdminStorage{ReadOnlyTX: []storage.ReadOnlyAdminTX{mockAdminTx}}
+
+			var frozen bool
+			if tc.wantFrozen {
+				mockAdminRWTx := storage.NewMockAdminTX(mockCtrl)
+				mockAdminRWTx.EXPECT().UpdateTree(gomock.Any(), logID, gomock.Any()).DoAndReturn(
+					func(_ context.Context, _ int64, fn func(*trillian.Tree)) (*trillian.Tree, error) {
+						updated := proto.Clone(tree).(*trillian.Tree)
+						fn(updated)
+						frozen = updated.TreeState == trillian.TreeState_FROZEN
+						return updated, nil
+					})
+				mockAdminRWTx.EXPECT().Commit().Return(nil)
+				mockAdminRWTx.EXPECT().Close().Return(nil)
+				mockAdmin.TX = []storage.AdminTX{mockAdminRWTx}
+			}
+
+			s := newTXLogStorage(t, tc.numLeaves)
+			registry := extension.Registry{
+				AdminStorage: mockAdmin,
+				LogStorage:   s,
+				QuotaManager: quota.Noop(),
+			}
+			info := createTestInfo(registry)
+			info.TimeSource = clock.System
+
+			sm := NewSequencerManager(registry, zeroDuration)
+			if _, err := sm.ExecutePass(ctx, logID, info); err != nil {
+				t.Fatalf("ExecutePass(): %v", err)
+			}
+			if frozen != tc.wantFrozen {
+				t.Errorf("ExecutePass() froze the log: %v, want %v", frozen, tc.wantFrozen)
+			}
+			var root types.LogRootV1
+			if err := root.UnmarshalBinary(s.state.roots[len(s.state.roots)-1].LogRoot); err != nil {
+				t.Fatalf("UnmarshalBinary(): %v", err)
+			}
+			if got := IsFinalRoot(&root); got != tc.wantFrozen {
+				t.Errorf("IsFinalRoot(latest root): %v, want %v", got, tc.wantFrozen)
+			}
+		})
+	}
+}
+
+func TestSequencerManagerDropsPipelines(t *testing.T) {
+	sm := NewSequencerManager(extension.Registry{}, 0)
+	for _, id := range []int64{1, 2, 3} {
+		sm.getPipeline(nil, &trillian.Tree{TreeId: id})
+	}
+	sm.heldLogs([]int64{2, 4})
+	if got := len(sm.pipelines); got != 1 || sm.pipelines[2] == nil {
+		t.Errorf("heldLogs() kept pipelines %v, want only log 2", sm.pipelines)
+	}
+}
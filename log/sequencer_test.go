@@ -15,6 +15,7 @@
 package log
 
 import (
+	"bytes"
 	"context"
 	"crypto"
 	"errors"
@@ -802,3 +803,68 @@ func TestIntegrateBatch_PutTokens(t *testing.T) {
 		}()
 	}
 }
+
+func TestPublishFinalRoot(t *testing.T) {
+	ctx := context.Background()
+	const numLeaves, limit = 7, 3
+	s := newTXLogStorage(t, numLeaves)
+	tree := &trillian.Tree{TreeId: 1, TreeType: trillian.TreeType_LOG, TreeState: trillian.TreeState_DRAINING}
+	seq := NewSequencer(rfc6962.DefaultHasher, clock.System, s, fixedSigner, nil, quota.Noop())
+
+	if final, err := seq.PublishFinalRoot(ctx, tree); err != nil || final {
+		t.Fatalf("PublishFinalRoot() with queued leaves: %v, %v; want false, nil", final, err)
+	}
+	if got, want := len(s.state.roots), 1; got != want {
+		t.Fatalf("PublishFinalRoot() with queued leaves stored a root: got %d roots, want %d", got, want)
+	}
+
+	for n := -1; n != 0; {
+		var err error
+		if n, err = seq.IntegrateBatch(ctx, tree, limit, 0, 0); err != nil {
+			t.Fatalf("IntegrateBatch(): %v", err)
+		}
+	}
+	want := s.state.clone()
+	for i := 0; i < 2; i++ {
+		if final, err := seq.PublishFinalRoot(ctx, tree); err != nil || !final {
+			t.Fatalf("PublishFinalRoot() #%d: %v, %v; want true, nil", i, final, err)
+		}
+	}
+	if got, want := len(s.state.roots), len(want.roots)+1; got != want {
+		t.Fatalf("got %d roots, want %d", got, want)
+	}
+	var last, root types.LogRootV1
+	if err := last.UnmarshalBinary(want.roots[len(want.roots)-1].LogRoot); err != nil {
+		t.Fatalf("UnmarshalBinary(): %v", err)
+	}
+	if err := root.UnmarshalBinary(s.state.roots[len(s.state.roots)-1].LogRoot); err != nil {
+		t.Fatalf("UnmarshalBinary(): %v", err)
+	}
+	if !IsFinalRoot(&root) {
+		t.Errorf("final root has metadata %q, want %q", root.Metadata, FinalRootMetadata)
+	}
+	if root.TreeSize != numLeaves || !bytes.Equal(root.RootHash, last.RootHash) {
+		t.Errorf("final root covers %d leaves with hash %x, want %d leaves with hash %x", root.TreeSize, root.RootHash, numLeaves, last.RootHash)
+	}
+	if got, want := root.Revision, last.Revision+1; got != want {
+		t.Errorf("final root revision: got %d, want %d", got, want)
+	}
+
+	// No root is signed on top of the final one, even once it is older than
+	// the maximum root duration.
+	roots := len(s.state.roots)
+	if n, err := seq.IntegrateBatch(ctx, tree, limit, 0, time.Nanosecond); err != nil || n != 0 {
+		t.Errorf("IntegrateBatch() after final root: %v, %v; want 0, nil", n, err)
+	}
+	p := NewPipelinedSequencer(seq, tree)
+	if n, err := p.IntegrateBatch(ctx, limit, 0, time.Nanosecond); err != nil || n != 0 {
+		t.Errorf("PipelinedSequencer.IntegrateBatch() after final root: %v, %v; want 0, nil", n, err)
+	}
+	active := &trillian.Tree{TreeId: 1, TreeType: trillian.TreeType_LOG, TreeState: trillian.TreeState_ACTIVE}
+	if _, err := seq.IntegrateBatch(ctx, active, limit, 0, time.Nanosecond); err == nil {
+		t.Error("IntegrateBatch() of an ACTIVE log with a final root succeeded, want error")
+	}
+	if got := len(s.state.roots); got != roots {
+		t.Errorf("got %d roots after the final one, want none", got-roots)
+	}
+}
@@ -0,0 +1,122 @@
+// Copyright 2020 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package log
+
+import (
+	"context"
+	"sort"
+	"strconv"
+	"time"
+)
+
+// LogStatus describes the sequencing of a log as seen by an OperationManager.
+type LogStatus struct {
+	LogID       int64
+	DisplayName string
+	// IsMaster is whether the instance holds mastership of the log. It is false
+	// if mastership is unknown because no master election is used.
+	IsMaster bool
+	// LastIntegration is when a pass last integrated leaves into the log, and
+	// LastBatchSize is the number of leaves it integrated.
+	LastIntegration time.Time
+	LastBatchSize   int
+	// QueueDepth is the number of leaves queued for the log, as counted within
+	// the last queueCountInterval, or -1 if the storage can't count them.
+	QueueDepth int64
+	// LastError is the error of the last failed pass, which happened at
+	// LastErrorTime, and ConsecutiveFailures is the number of passes that have
+	// failed since the last successful one.
+	LastError           error
+	LastErrorTime       time.Time
+	ConsecutiveFailures int
+}
+
+// passStatus holds the outcome of the recent passes for a log.
+type passStatus struct {
+	lastIntegration     time.Time
+	lastBatchSize       int
+	lastError           error
+	lastErrorTime       time.Time
+	consecutiveFailures int
+}
+
+// recordPass updates the status of a log with the outcome of a pass.
+func (o *OperationManager) recordPass(logID int64, count int, err error) {
+	now := o.info.TimeSource.Now()
+	o.statusMu.Lock()
+	defer o.statusMu.Unlock()
+	ps, ok := o.passes[logID]
+	if !ok {
+		ps = &passStatus{}
+		o.passes[logID] = ps
+	}
+	switch {
+	case err != nil:
+		ps.lastError, ps.lastErrorTime = err, now
+		ps.consecutiveFailures++
+	case count > 0:
+		ps.lastIntegration, ps.lastBatchSize = now, count
+		fallthrough
+	default:
+		ps.consecutiveFailures = 0
+	}
+}
+
+// Status returns the sequencing status of the active logs seen by the latest
+// pass, ordered by log ID.
+func (o *OperationManager) Status(ctx context.Context) ([]LogStatus, error) {
+	o.statusMu.Lock()
+	logIDs := append([]int64(nil), o.lastActive...)
+	tracker := o.tracker
+	passes := make(map[int64]passStatus, len(o.passes))
+	for id, ps := range o.passes {
+		passes[id] = *ps
+	}
+	o.statusMu.Unlock()
+
+	var held map[string]bool
+	if tracker != nil {
+		held = make(map[string]bool)
+		for _, id := range tracker.Held() {
+			held[id] = true
+		}
+	}
+	counts, err := o.queue.get(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Slice(logIDs, func(i, j int) bool { return logIDs[i] < logIDs[j] })
+	ret := make([]LogStatus, 0, len(logIDs))
+	for _, logID := range logIDs {
+		ps := passes[logID]
+		st := LogStatus{
+			LogID:               logID,
+			DisplayName:         o.logName(ctx, logID),
+			IsMaster:            held[strconv.FormatInt(logID, 10)],
+			LastIntegration:     ps.lastIntegration,
+			LastBatchSize:       ps.lastBatchSize,
+			QueueDepth:          -1,
+			LastError:           ps.lastError,
+			LastErrorTime:       ps.lastErrorTime,
+			ConsecutiveFailures: ps.consecutiveFailures,
+		}
+		if counts != nil {
+			st.QueueDepth = counts[logID]
+		}
+		ret = append(ret, st)
+	}
+	return ret, nil
+}
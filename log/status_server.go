@@ -0,0 +1,126 @@
+// Copyright 2020 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package log
+
+import (
+	"context"
+	"html/template"
+	"net/http"
+	"time"
+
+	"github.com/golang/glog"
+	"github.com/golang/protobuf/ptypes"
+	"github.com/google/trillian"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	tspb "github.com/golang/protobuf/ptypes/timestamp"
+)
+
+var statusPage = template.Must(template.New("status").Funcs(template.FuncMap{
+	"time": func(t time.Time) string {
+		if t.IsZero() {
+			return "-"
+		}
+		return t.UTC().Format(time.RFC3339)
+	},
+}).Parse(`<!DOCTYPE html>
+<html>
+<head><title>Log Signer Status</title></head>
+<body>
+<h1>Log Signer Status</h1>
+<p>Master for {{.Held}} / {{len .Logs}} active logs.</p>
+<table border="1">
+<tr><th>Log ID</th><th>Name</th><th>Master</th><th>Last integration</th><th>Last batch size</th><th>Queue depth</th><th>Consecutive failures</th><th>Last error</th><th>Last error time</th></tr>
+{{range .Logs}}<tr>
+<td>{{.LogID}}</td><td>{{.DisplayName}}</td><td>{{.IsMaster}}</td><td>{{time .LastIntegration}}</td><td>{{.LastBatchSize}}</td><td>{{if ge .QueueDepth 0}}{{.QueueDepth}}{{else}}-{{end}}</td><td>{{.ConsecutiveFailures}}</td><td>{{if .LastError}}{{.LastError}}{{else}}-{{end}}</td><td>{{time .LastErrorTime}}</td>
+</tr>
+{{end}}</table>
+</body>
+</html>
+`))
+
+// StatusServer reports the sequencing status of the logs handled by an
+// OperationManager, both through the TrillianLogSequencer gRPC service and as
+// an HTTP status page.
+type StatusServer struct {
+	om *OperationManager
+}
+
+// NewStatusServer creates a StatusServer for the given OperationManager.
+func NewStatusServer(om *OperationManager) *StatusServer {
+	return &StatusServer{om: om}
+}
+
+// GetSignerStatus returns the sequencing status of the logs known to the
+// signer.
+func (s *StatusServer) GetSignerStatus(ctx context.Context, req *trillian.GetSignerStatusRequest) (*trillian.GetSignerStatusResponse, error) {
+	logs, err := s.om.Status(ctx)
+	if err != nil {
+		return nil, status.Errorf(codes.Unavailable, "failed to get status: %v", err)
+	}
+	resp := &trillian.GetSignerStatusResponse{Logs: make([]*trillian.LogSequencingStatus, 0, len(logs))}
+	for _, l := range logs {
+		st := &trillian.LogSequencingStatus{
+			LogId:               l.LogID,
+			DisplayName:         l.DisplayName,
+			IsMaster:            l.IsMaster,
+			LastIntegrationTime: timestampProto(l.LastIntegration),
+			LastBatchSize:       int64(l.LastBatchSize),
+			QueueDepth:          l.QueueDepth,
+			LastErrorTime:       timestampProto(l.LastErrorTime),
+			ConsecutiveFailures: int64(l.ConsecutiveFailures),
+		}
+		if l.LastError != nil {
+			st.LastError = l.LastError.Error()
+		}
+		resp.Logs = append(resp.Logs, st)
+	}
+	return resp, nil
+}
+
+// ServeHTTP renders the status page.
+func (s *StatusServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	logs, err := s.om.Status(r.Context())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusServiceUnavailable)
+		return
+	}
+	held := 0
+	for _, l := range logs {
+		if l.IsMaster {
+			held++
+		}
+	}
+	data := struct {
+		Held int
+		Logs []LogStatus
+	}{Held: held, Logs: logs}
+	if err := statusPage.Execute(w, data); err != nil {
+		glog.Errorf("failed to render status page: %v", err)
+	}
+}
+
+// timestampProto converts a time to a proto, leaving it unset for zero times.
+func timestampProto(t time.Time) *tspb.Timestamp {
+	if t.IsZero() {
+		return nil
+	}
+	ts, err := ptypes.TimestampProto(t)
+	if err != nil {
+		return nil
+	}
+	return ts
+}
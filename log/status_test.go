@@ -0,0 +1,143 @@
+// Copyright 2020 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package log
+
+import (
+	"context"
+	"errors"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/google/trillian"
+	"github.com/google/trillian/extension"
+	"github.com/google/trillian/storage"
+	"github.com/google/trillian/util/clock"
+)
+
+// countingLogTX is a ReadOnlyLogTX which can count unsequenced leaves.
+type countingLogTX struct {
+	*storage.MockReadOnlyLogTX
+	counts storage.CountByLogID
+}
+
+func (tx countingLogTX) GetUnsequencedCounts(ctx context.Context) (storage.CountByLogID, error) {
+	ret := make(storage.CountByLogID)
+	for id, count := range tx.counts {
+		ret[id] = count
+	}
+	return ret, nil
+}
+
+func TestStatus(t *testing.T) {
+	ctx := context.Background()
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	for _, tc := range []struct {
+		desc      string
+		counts    storage.CountByLogID
+		wantDepth [2]int64
+	}{
+		{desc: "no-counter", wantDepth: [2]int64{-1, -1}},
+		{desc: "counter", counts: storage.CountByLogID{11: 7}, wantDepth: [2]int64{7, 0}},
+	} {
+		t.Run(tc.desc, func(t *testing.T) {
+			fakeStorage, mockAdmin := setupLogIDs(ctrl, map[int64]string{11: "Log11", 12: "Log12"})
+			if tc.counts != nil {
+				mockTx := storage.NewMockReadOnlyLogTX(ctrl)
+				mockTx.EXPECT().GetActiveLogIDs(gomock.Any()).AnyTimes().Return([]int64{12, 11}, nil)
+				mockTx.EXPECT().Commit(gomock.Any()).AnyTimes().Return(nil)
+				mockTx.EXPECT().Close().AnyTimes().Return(nil)
+				fakeStorage = storage.NewMockLogStorage(ctrl)
+				fakeStorage.EXPECT().Snapshot(gomock.Any()).AnyTimes().Return(countingLogTX{mockTx, tc.counts}, nil)
+			}
+			registry := extension.Registry{LogStorage: fakeStorage, AdminStorage: mockAdmin}
+
+			fakeTime := clock.NewFake(time.Unix(1500000000, 0))
+			info := defaultOperationInfo(registry)
+			info.TimeSource = fakeTime
+			mockLogOp := NewMockOperation(ctrl)
+			mockLogOp.EXPECT().ExecutePass(gomock.Any(), int64(11), gomock.Any()).Return(5, nil)
+			mockLogOp.EXPECT().ExecutePass(gomock.Any(), int64(12), gomock.Any()).Return(0, errors.New("test error")).Times(2)
+			mockLogOp.EXPECT().ExecutePass(gomock.Any(), int64(11), gomock.Any()).Return(0, nil)
+
+			lom := NewOperationManager(info, mockLogOp)
+			lom.OperationSingle(ctx)
+			integrated := fakeTime.Now()
+			fakeTime.Set(integrated.Add(time.Minute))
+			lom.OperationSingle(ctx)
+
+			got, err := lom.Status(ctx)
+			if err != nil {
+				t.Fatalf("Status(): %v", err)
+			}
+			if len(got) != 2 {
+				t.Fatalf("Status(): %d logs, want 2", len(got))
+			}
+			l11, l12 := got[0], got[1]
+			// Mastership is unknown without master election.
+			if l11.LogID != 11 || l11.DisplayName != "Log11" || l11.IsMaster {
+				t.Errorf("Status()[0]: %+v, want log 11 not known to be held", l11)
+			}
+			if !l11.LastIntegration.Equal(integrated) || l11.LastBatchSize != 5 {
+				t.Errorf("Status()[0]: integrated %d at %v, want 5 at %v", l11.LastBatchSize, l11.LastIntegration, integrated)
+			}
+			if l11.LastError != nil || l11.ConsecutiveFailures != 0 {
+				t.Errorf("Status()[0]: error %v, %d failures, want none", l11.LastError, l11.ConsecutiveFailures)
+			}
+			if l12.LogID != 12 || l12.LastError == nil || l12.ConsecutiveFailures != 2 || !l12.LastIntegration.IsZero() {
+				t.Errorf("Status()[1]: %+v, want 2 failures for log 12", l12)
+			}
+			if got, want := [2]int64{l11.QueueDepth, l12.QueueDepth}, tc.wantDepth; got != want {
+				t.Errorf("Status(): queue depths %v, want %v", got, want)
+			}
+			if tc.counts != nil {
+				// The counts are cached for a while.
+				tc.counts[11] = 9
+				if got, err := lom.Status(ctx); err != nil || got[0].QueueDepth != 7 {
+					t.Errorf("Status(): %+v, %v; want cached queue depth 7", got, err)
+				}
+				fakeTime.Set(fakeTime.Now().Add(queueCountInterval))
+				if got, err := lom.Status(ctx); err != nil || got[0].QueueDepth != 9 {
+					t.Errorf("Status(): %+v, %v; want queue depth 9 after %v", got, err, queueCountInterval)
+				}
+			}
+
+			s := NewStatusServer(lom)
+			resp, err := s.GetSignerStatus(ctx, &trillian.GetSignerStatusRequest{})
+			if err != nil {
+				t.Fatalf("GetSignerStatus(): %v", err)
+			}
+			if len(resp.Logs) != 2 {
+				t.Fatalf("GetSignerStatus(): %d logs, want 2", len(resp.Logs))
+			}
+			if got := resp.Logs[0]; got.LogId != 11 || got.LastBatchSize != 5 || got.LastIntegrationTime.GetSeconds() != integrated.Unix() || got.LastErrorTime != nil {
+				t.Errorf("GetSignerStatus(): %v, want log 11 integrated", got)
+			}
+			if got := resp.Logs[1]; got.LogId != 12 || got.LastError != "test error" || got.ConsecutiveFailures != 2 {
+				t.Errorf("GetSignerStatus(): %v, want log 12 failing", got)
+			}
+
+			w := httptest.NewRecorder()
+			s.ServeHTTP(w, httptest.NewRequest("GET", "/status", nil))
+			if body := w.Body.String(); !strings.Contains(body, "Log11") || !strings.Contains(body, "test error") {
+				t.Errorf("ServeHTTP(): page does not list the logs:\n%s", body)
+			}
+		})
+	}
+}
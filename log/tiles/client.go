@@ -0,0 +1,352 @@
+// Copyright 2020 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tiles
+
+import (
+	"container/list"
+	"context"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/golang/protobuf/proto" //nolint:staticcheck
+	"github.com/google/trillian"
+	"github.com/google/trillian/client"
+	"github.com/google/trillian/merkle"
+	"github.com/google/trillian/merkle/compact"
+	"github.com/google/trillian/types"
+)
+
+// Fetcher returns the file at a path relative to the tiles of a log, which
+// uses forward slashes.
+type Fetcher func(ctx context.Context, path string) ([]byte, error)
+
+// DirFetcher returns a Fetcher which reads the files of a log from dir.
+func DirFetcher(dir string) Fetcher {
+	return func(ctx context.Context, path string) ([]byte, error) {
+		return ioutil.ReadFile(filepath.Join(dir, filepath.FromSlash(path)))
+	}
+}
+
+// HTTPFetcher returns a Fetcher which gets the files of a log from baseURL
+// with c.
+func HTTPFetcher(c *http.Client, baseURL string) Fetcher {
+	baseURL = strings.TrimSuffix(baseURL, "/")
+	return func(ctx context.Context, path string) ([]byte, error) {
+		req, err := http.NewRequest(http.MethodGet, baseURL+"/"+path, nil)
+		if err != nil {
+			return nil, err
+		}
+		resp, err := c.Do(req.WithContext(ctx))
+		if err != nil {
+			return nil, err
+		}
+		defer resp.Body.Close()
+		if resp.StatusCode != http.StatusOK {
+			return nil, fmt.Errorf("GET %s: %s", req.URL, resp.Status)
+		}
+		return ioutil.ReadAll(resp.Body)
+	}
+}
+
+// Client reads a log from its tiles. It verifies the checkpoints of the log
+// against a trusted root, and computes proofs from the tiles of the trusted
+// root. UpdateRoot, VerifyInclusion and GetLeaf verify the proofs they use.
+// InclusionProof and ConsistencyProof don't, as the Client doesn't know the
+// leaf or the roots they are for, so callers must verify them like the proofs
+// of a log server.
+//
+// Tiles never change, so the Client keeps the last MaxTiles tiles it used in
+// memory. The tiles of a proof which fails verification are dropped, so that
+// a tile which was tampered with, or corrupted in transit, is fetched again.
+type Client struct {
+	verifier *client.LogVerifier
+	fetch    Fetcher
+	v        merkle.LogVerifier
+	rf       *compact.RangeFactory
+
+	// MaxTiles is the max number of tiles kept in memory.
+	MaxTiles int
+
+	mu    sync.Mutex
+	root  types.LogRootV1
+	tiles map[string]*list.Element
+	lru   *list.List
+}
+
+// cachedTile is a tile kept in memory by a Client.
+type cachedTile struct {
+	path   string
+	hashes [][]byte
+}
+
+// NewClient returns a Client for the log verified by verifier, reading its
+// tiles with fetch. The first checkpoint is trusted if trusted is empty.
+func NewClient(verifier *client.LogVerifier, fetch Fetcher, trusted types.LogRootV1) *Client {
+	return &Client{
+		verifier: verifier,
+		fetch:    fetch,
+		v:        merkle.NewLogVerifier(verifier.Hasher),
+		rf:       &compact.RangeFactory{Hash: verifier.Hasher.HashChildren},
+		MaxTiles: 1000,
+		root:     trusted,
+		tiles:    make(map[string]*list.Element),
+		lru:      list.New(),
+	}
+}
+
+// Root returns the trusted root of the log.
+func (c *Client) Root() types.LogRootV1 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.root
+}
+
+// UpdateRoot fetches the checkpoint of the log and, if it is consistent with
+// the trusted root, trusts it instead. It returns the trusted root.
+func (c *Client) UpdateRoot(ctx context.Context) (*types.LogRootV1, error) {
+	data, err := c.fetch(ctx, CheckpointPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch checkpoint: %v", err)
+	}
+	var slr trillian.SignedLogRoot
+	if err := proto.Unmarshal(data, &slr); err != nil {
+		return nil, fmt.Errorf("failed to parse checkpoint: %v", err)
+	}
+	// Only the signature is checked here, as the consistency proof is
+	// computed from the tiles of the new root.
+	root, err := c.verifier.VerifyRoot(&types.LogRootV1{}, &slr, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if trusted := c.root; trusted.TreeSize > 0 {
+		if root.TreeSize < trusted.TreeSize {
+			return nil, &client.RollbackError{Trusted: trusted, Root: *root, Err: errors.New("tree size decreased")}
+		}
+		proof, paths, err := c.consistencyProof(ctx, int64(trusted.TreeSize), int64(root.TreeSize), root)
+		if err != nil {
+			return nil, err
+		}
+		if err := c.v.VerifyConsistencyProof(int64(trusted.TreeSize), int64(root.TreeSize), trusted.RootHash, root.RootHash, proof); err != nil {
+			c.evict(paths)
+			return nil, &client.RollbackError{Trusted: trusted, Root: *root, Err: fmt.Errorf("failed to verify consistency proof: %v", err)}
+		}
+		if root.TreeSize == trusted.TreeSize && root.TimestampNanos < trusted.TimestampNanos {
+			return &trusted, nil
+		}
+	}
+	c.root = *root
+	return root, nil
+}
+
+// InclusionProof returns the inclusion proof for the leaf at index in the
+// tree of the given size, which is at most the size of the trusted root. The
+// proof is not verified.
+func (c *Client) InclusionProof(ctx context.Context, index, size int64) ([][]byte, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	proof, _, err := c.inclusionProof(ctx, index, size)
+	return proof, err
+}
+
+// ConsistencyProof returns the consistency proof between the trees of the
+// given sizes, which are at most the size of the trusted root. The proof is
+// not verified.
+func (c *Client) ConsistencyProof(ctx context.Context, first, second int64) ([][]byte, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if uint64(second) > c.root.TreeSize {
+		return nil, fmt.Errorf("tree size %d is beyond the trusted root size %d", second, c.root.TreeSize)
+	}
+	proof, _, err := c.consistencyProof(ctx, first, second, &c.root)
+	return proof, err
+}
+
+// VerifyInclusion verifies that data is the leaf at index in the tree of the
+// trusted root.
+func (c *Client) VerifyInclusion(ctx context.Context, data []byte, index int64) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	proof, paths, err := c.inclusionProof(ctx, index, int64(c.root.TreeSize))
+	if err != nil {
+		return err
+	}
+	if err := c.verifier.VerifyInclusionAtIndex(&c.root, data, index, proof); err != nil {
+		c.evict(paths)
+		return err
+	}
+	return nil
+}
+
+// GetLeaf returns the value of the leaf at index from its data tile, once it
+// has verified that the value is in the tree of the trusted root.
+func (c *Client) GetLeaf(ctx context.Context, index int64) ([]byte, error) {
+	root := c.Root()
+	if index < 0 || uint64(index) >= root.TreeSize {
+		return nil, fmt.Errorf("leaf %d is beyond the trusted root size %d", index, root.TreeSize)
+	}
+	tile := index / TileWidth
+	path := DataPath(tile, tileWidth(tile, root.TreeSize))
+	data, err := c.fetch(ctx, path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch %s: %v", path, err)
+	}
+	values, err := decodeData(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %v", path, err)
+	}
+	if i := int(index % TileWidth); i < len(values) {
+		value := values[i]
+		if err := c.VerifyInclusion(ctx, value, index); err != nil {
+			return nil, err
+		}
+		return value, nil
+	}
+	return nil, fmt.Errorf("%s has %d leaves, want %d", path, len(values), tileWidth(tile, root.TreeSize))
+}
+
+// inclusionProof returns the inclusion proof for the leaf at index in the
+// tree of the given size, computed from the tiles of the trusted root, and
+// the paths of these tiles.
+func (c *Client) inclusionProof(ctx context.Context, index, size int64) ([][]byte, []string, error) {
+	if uint64(size) > c.root.TreeSize {
+		return nil, nil, fmt.Errorf("tree size %d is beyond the trusted root size %d", size, c.root.TreeSize)
+	}
+	fetches, err := merkle.CalcInclusionProofNodeAddresses(size, index, size+1)
+	if err != nil {
+		return nil, nil, err
+	}
+	return c.proof(ctx, fetches, &c.root)
+}
+
+// consistencyProof returns the consistency proof between the trees of the
+// given sizes, computed from the tiles of root, and the paths of these tiles.
+func (c *Client) consistencyProof(ctx context.Context, first, second int64, root *types.LogRootV1) ([][]byte, []string, error) {
+	if first == 0 {
+		return nil, nil, nil
+	}
+	fetches, err := merkle.CalcConsistencyProofNodeAddresses(first, second, second+1)
+	if err != nil {
+		return nil, nil, err
+	}
+	return c.proof(ctx, fetches, root)
+}
+
+// proof returns the proof made of the given nodes, computed from the tiles of
+// root, and the paths of these tiles. The nodes must be perfect subtrees
+// within root. Runs of nodes to rehash are folded into their common ancestor,
+// as storage does.
+func (c *Client) proof(ctx context.Context, fetches []merkle.NodeFetch, root *types.LogRootV1) ([][]byte, []string, error) {
+	var proof [][]byte
+	var paths []string
+	var rehashed []byte
+	for _, f := range fetches {
+		hash, path, err := c.nodeHash(ctx, f.ID, root)
+		if err != nil {
+			return nil, nil, err
+		}
+		paths = append(paths, path)
+		switch {
+		case f.Rehash && rehashed == nil:
+			rehashed = hash
+		case f.Rehash:
+			rehashed = c.verifier.Hasher.HashChildren(hash, rehashed)
+		default:
+			if rehashed != nil {
+				proof, rehashed = append(proof, rehashed), nil
+			}
+			proof = append(proof, hash)
+		}
+	}
+	if rehashed != nil {
+		proof = append(proof, rehashed)
+	}
+	return proof, paths, nil
+}
+
+// nodeHash returns the hash of a perfect subtree within root, and the path of
+// the tile it is computed from. Nodes between the tile levels are hashed from
+// the nodes of the tile below them.
+func (c *Client) nodeHash(ctx context.Context, id compact.NodeID, root *types.LogRootV1) ([]byte, string, error) {
+	level := int(id.Level / TileHeight)
+	shift := id.Level % TileHeight
+	begin := id.Index << shift
+	end := (id.Index + 1) << shift
+	index := int64(begin / TileWidth)
+	hashes, path, err := c.tile(ctx, level, index, root)
+	if err != nil {
+		return nil, "", err
+	}
+	offset := begin % TileWidth
+	if n := uint64(len(hashes)); offset+(end-begin) > n {
+		return nil, "", fmt.Errorf("tile %d/%d has %d hashes, want node %+v", level, index, n, id)
+	}
+	if shift == 0 {
+		return hashes[offset], path, nil
+	}
+	r := c.rf.NewEmptyRange(0)
+	for _, h := range hashes[offset : offset+(end-begin)] {
+		if err := r.Append(h, nil); err != nil {
+			return nil, "", err
+		}
+	}
+	hash, err := r.GetRootHash(nil)
+	return hash, path, err
+}
+
+// tile returns the hashes of a hash tile of root, and its path.
+func (c *Client) tile(ctx context.Context, level int, index int64, root *types.LogRootV1) ([][]byte, string, error) {
+	width := tileWidth(index, root.TreeSize>>(uint(level)*TileHeight))
+	path := TilePath(level, index, width)
+	if e, ok := c.tiles[path]; ok {
+		c.lru.MoveToFront(e)
+		return e.Value.(*cachedTile).hashes, path, nil
+	}
+	data, err := c.fetch(ctx, path)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to fetch %s: %v", path, err)
+	}
+	hashes, err := splitHashes(data, c.verifier.Hasher.Size())
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to parse %s: %v", path, err)
+	}
+	if len(hashes) != width {
+		return nil, "", fmt.Errorf("%s has %d hashes, want %d", path, len(hashes), width)
+	}
+	c.tiles[path] = c.lru.PushFront(&cachedTile{path: path, hashes: hashes})
+	for c.lru.Len() > c.MaxTiles {
+		e := c.lru.Back()
+		c.lru.Remove(e)
+		delete(c.tiles, e.Value.(*cachedTile).path)
+	}
+	return hashes, path, nil
+}
+
+// evict drops the tiles at the given paths from memory.
+func (c *Client) evict(paths []string) {
+	for _, path := range paths {
+		if e, ok := c.tiles[path]; ok {
+			c.lru.Remove(e)
+			delete(c.tiles, path)
+		}
+	}
+}
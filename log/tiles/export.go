@@ -0,0 +1,228 @@
+// Copyright 2020 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tiles
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+
+	"github.com/golang/glog"
+	"github.com/golang/protobuf/proto" //nolint:staticcheck
+	"github.com/google/trillian"
+	"github.com/google/trillian/log/export"
+	"github.com/google/trillian/storage"
+	"github.com/google/trillian/storage/tree"
+	"github.com/google/trillian/types"
+)
+
+// maxTreeDepth is the depth of log trees, used for tree.NodeID creation.
+const maxTreeDepth = 64
+
+// Exporter is an export.Hook which writes the tiles of logs to the directory
+// <tree ID> in a directory, followed by the checkpoint of each root.
+//
+// The hashes of the tiles are the subtree nodes stored by the log signer,
+// read at the revision of the root. As full tiles never change, the Exporter
+// only writes the full tiles after the last one written before. It writes at
+// most MaxTiles tiles per root. If more are missing, e.g. the first time a
+// large log is exported, it doesn't write the checkpoint of the root, and
+// carries on with later roots.
+type Exporter struct {
+	dir        string
+	logStorage storage.LogStorage
+
+	// MaxTiles is the max number of tiles written per root.
+	MaxTiles int
+
+	locks export.LogLocks
+}
+
+// NewExporter returns an Exporter which writes tiles to dir, and reads them
+// from logStorage.
+func NewExporter(dir string, logStorage storage.LogStorage) *Exporter {
+	return &Exporter{
+		dir:        dir,
+		logStorage: logStorage,
+		MaxTiles:   1000,
+	}
+}
+
+// Integrated writes the tiles of the log which are missing for the root,
+// followed by its checkpoint.
+func (e *Exporter) Integrated(ctx context.Context, tree *trillian.Tree, slr *trillian.SignedLogRoot, leaves []*trillian.LogLeaf) error {
+	defer e.locks.Lock(tree.TreeId)()
+	var root types.LogRootV1
+	if err := root.UnmarshalBinary(slr.GetLogRoot()); err != nil {
+		return fmt.Errorf("failed to parse log root: %v", err)
+	}
+	dir := filepath.Join(e.dir, strconv.FormatInt(tree.TreeId, 10))
+
+	tx, err := e.logStorage.SnapshotForTree(ctx, tree)
+	if err != nil {
+		return err
+	}
+	defer tx.Close()
+	w := &tileWriter{tx: tx, dir: dir, rev: int64(root.Revision), budget: e.MaxTiles}
+	if err := w.writeAll(ctx, root.TreeSize); err != nil {
+		return err
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return err
+	}
+	if w.budget < 0 {
+		glog.Infof("%v: tiles are behind root %d, catching up with the next root", tree.TreeId, root.TreeSize)
+		return nil
+	}
+
+	data, err := proto.Marshal(slr)
+	if err != nil {
+		return err
+	}
+	return writeFile(filepath.Join(dir, CheckpointPath), data)
+}
+
+// tileWriter writes the tiles of a log from a storage transaction.
+type tileWriter struct {
+	tx     storage.ReadOnlyLogTreeTX
+	dir    string
+	rev    int64
+	budget int
+}
+
+// writeAll writes the missing tiles of a tree of the given size, as long as
+// the budget lasts.
+func (w *tileWriter) writeAll(ctx context.Context, size uint64) error {
+	// The data tiles are written as level -1.
+	if err := w.writeLevel(ctx, -1, size); err != nil {
+		return err
+	}
+	for level := 0; size>>(uint(level)*TileHeight) > 0; level++ {
+		if err := w.writeLevel(ctx, level, size>>(uint(level)*TileHeight)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// path returns the path of a tile, which is a data tile for level -1.
+func (w *tileWriter) path(level int, index int64, width int) string {
+	if level < 0 {
+		return filepath.Join(w.dir, filepath.FromSlash(DataPath(index, width)))
+	}
+	return filepath.Join(w.dir, filepath.FromSlash(TilePath(level, index, width)))
+}
+
+// writeLevel writes the missing tiles of a level which has count nodes.
+func (w *tileWriter) writeLevel(ctx context.Context, level int, count uint64) error {
+	// Full tiles are written in index order, so the ones before the last
+	// one written are there already.
+	full := int64(count / TileWidth)
+	next := full
+	for ; next > 0; next-- {
+		if _, err := os.Stat(w.path(level, next-1, TileWidth)); err == nil {
+			break
+		}
+	}
+	end := full
+	if count%TileWidth != 0 {
+		end++
+	}
+	for index := next; index < end; index++ {
+		width := tileWidth(index, count)
+		path := w.path(level, index, width)
+		if _, err := os.Stat(path); err == nil {
+			continue
+		}
+		if w.budget--; w.budget < 0 {
+			return nil
+		}
+		var data []byte
+		var err error
+		if level < 0 {
+			data, err = w.readData(ctx, index, width)
+		} else {
+			data, err = w.readHashes(ctx, level, index, width)
+		}
+		if err != nil {
+			return err
+		}
+		if err := writeFile(path, data); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// readHashes returns the hash tile with the given level, index and width.
+func (w *tileWriter) readHashes(ctx context.Context, level int, index int64, width int) ([]byte, error) {
+	ids := make([]tree.NodeID, 0, width)
+	for i := 0; i < width; i++ {
+		id, err := tree.NewNodeIDForTreeCoords(int64(level*TileHeight), index*TileWidth+int64(i), maxTreeDepth)
+		if err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	nodes, err := w.tx.GetMerkleNodes(ctx, w.rev, ids)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read nodes of tile %d/%d: %v", level, index, err)
+	}
+	if len(nodes) != width {
+		return nil, fmt.Errorf("got %d nodes of tile %d/%d, want %d", len(nodes), level, index, width)
+	}
+	var buf bytes.Buffer
+	for _, n := range nodes {
+		buf.Write(n.Hash)
+	}
+	return buf.Bytes(), nil
+}
+
+// readData returns the data tile with the given index and width.
+func (w *tileWriter) readData(ctx context.Context, index int64, width int) ([]byte, error) {
+	start := index * TileWidth
+	leaves, err := w.tx.GetLeavesByRange(ctx, start, int64(width))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read leaves of data tile %d: %v", index, err)
+	}
+	if len(leaves) != width {
+		return nil, fmt.Errorf("got %d leaves of data tile %d, want %d", len(leaves), index, width)
+	}
+	values := make([][]byte, 0, width)
+	for i, l := range leaves {
+		if want := start + int64(i); l.LeafIndex != want {
+			return nil, fmt.Errorf("got leaf %d in data tile %d, want %d", l.LeafIndex, index, want)
+		}
+		values = append(values, l.LeafValue)
+	}
+	return encodeData(values), nil
+}
+
+// writeFile writes a file through a temporary file, so that readers never see
+// it partially written.
+func writeFile(path string, data []byte) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	tmp := path + ".tmp"
+	if err := ioutil.WriteFile(tmp, data, 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
@@ -0,0 +1,118 @@
+// Copyright 2020 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package tiles stores the Merkle trees of logs as static files, which can be
+// served by any file server or CDN, and verifies the proofs computed from
+// them.
+//
+// The layout follows the tiles of Go's checksum database. A hash tile at level
+// L holds up to 256 consecutive hashes of the tree nodes at level 8*L, which
+// are the roots of perfect subtrees of 256^L leaves. A data tile holds the
+// values of the leaves whose hashes are in the level 0 tile with the same
+// index. Full tiles never change once written. A tile with fewer hashes is
+// stored at a separate path which includes its width, so it doesn't change
+// either. The latest SignedLogRoot of the log, which tells clients the widths
+// to fetch, is stored as a binary proto in the file named by CheckpointPath.
+package tiles
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+)
+
+const (
+	// TileHeight is the number of tree levels covered by a tile. It matches
+	// the strata of the log subtrees in storage.
+	TileHeight = 8
+	// TileWidth is the number of hashes or leaves in a full tile.
+	TileWidth = 1 << TileHeight
+
+	// CheckpointPath is the path of the latest SignedLogRoot of a log.
+	CheckpointPath = "checkpoint"
+)
+
+// TilePath returns the path of the hash tile at the given level and index,
+// holding width hashes.
+func TilePath(level int, index int64, width int) string {
+	return tilePath(fmt.Sprint(level), index, width)
+}
+
+// DataPath returns the path of the data tile with the given index, holding
+// width leaves.
+func DataPath(index int64, width int) string {
+	return tilePath("data", index, width)
+}
+
+// tilePath formats the index in groups of 3 digits, all but the last one
+// prefixed with "x", so that directories hold at most 1000 entries. For
+// example, the full tile 1234067 of level 1 is at tile/8/1/x001/x234/067.
+func tilePath(level string, index int64, width int) string {
+	n := fmt.Sprintf("%03d", index%1000)
+	for index /= 1000; index > 0; index /= 1000 {
+		n = fmt.Sprintf("x%03d/%s", index%1000, n)
+	}
+	p := fmt.Sprintf("tile/%d/%s/%s", TileHeight, level, n)
+	if width < TileWidth {
+		p += fmt.Sprintf(".p/%d", width)
+	}
+	return p
+}
+
+// tileWidth returns the width of the tile with the given index, in a tile
+// level of count nodes.
+func tileWidth(index int64, count uint64) int {
+	if rest := count - uint64(index)*TileWidth; rest < TileWidth {
+		return int(rest)
+	}
+	return TileWidth
+}
+
+// encodeData encodes leaf values as a data tile, each preceded by its size
+// as a uvarint.
+func encodeData(values [][]byte) []byte {
+	var data []byte
+	for _, v := range values {
+		var size [binary.MaxVarintLen64]byte
+		data = append(data, size[:binary.PutUvarint(size[:], uint64(len(v)))]...)
+		data = append(data, v...)
+	}
+	return data
+}
+
+// decodeData returns the leaf values in a data tile.
+func decodeData(data []byte) ([][]byte, error) {
+	var values [][]byte
+	for len(data) > 0 {
+		size, n := binary.Uvarint(data)
+		if n <= 0 || uint64(len(data)-n) < size {
+			return nil, errors.New("truncated data tile")
+		}
+		values = append(values, data[n:n+int(size)])
+		data = data[n+int(size):]
+	}
+	return values, nil
+}
+
+// splitHashes returns the hashes in a hash tile.
+func splitHashes(data []byte, size int) ([][]byte, error) {
+	if size <= 0 || len(data)%size != 0 {
+		return nil, fmt.Errorf("hash tile size %d is not a multiple of %d", len(data), size)
+	}
+	hashes := make([][]byte, 0, len(data)/size)
+	for ; len(data) > 0; data = data[size:] {
+		hashes = append(hashes, data[:size])
+	}
+	return hashes, nil
+}
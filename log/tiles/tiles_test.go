@@ -0,0 +1,371 @@
+// Copyright 2020 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tiles
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/golang/protobuf/ptypes"
+	"github.com/google/trillian"
+	"github.com/google/trillian/client"
+	"github.com/google/trillian/crypto/keys/der"
+	"github.com/google/trillian/crypto/keys/pem"
+	"github.com/google/trillian/crypto/keyspb"
+	"github.com/google/trillian/crypto/sigpb"
+	"github.com/google/trillian/log"
+	"github.com/google/trillian/merkle/rfc6962"
+	"github.com/google/trillian/monitoring"
+	"github.com/google/trillian/quota"
+	"github.com/google/trillian/storage"
+	"github.com/google/trillian/storage/memory"
+	"github.com/google/trillian/testonly"
+	"github.com/google/trillian/trees"
+	"github.com/google/trillian/types"
+	"github.com/google/trillian/util/clock"
+
+	ktestonly "github.com/google/trillian/crypto/keys/testonly"
+
+	_ "github.com/google/trillian/crypto/keys/der/proto" // Register PrivateKey ProtoHandler
+)
+
+func TestTilePath(t *testing.T) {
+	for _, tc := range []struct {
+		level int
+		index int64
+		width int
+		want  string
+	}{
+		{level: 0, index: 0, width: 256, want: "tile/8/0/000"},
+		{level: 0, index: 5, width: 3, want: "tile/8/0/005.p/3"},
+		{level: 2, index: 1000, width: 256, want: "tile/8/2/x001/000"},
+		{level: 1, index: 1234067, width: 256, want: "tile/8/1/x001/x234/067"},
+	} {
+		if got := TilePath(tc.level, tc.index, tc.width); got != tc.want {
+			t.Errorf("TilePath(%d, %d, %d) = %q, want %q", tc.level, tc.index, tc.width, got, tc.want)
+		}
+	}
+	if got, want := DataPath(1, 17), "tile/8/data/001.p/17"; got != want {
+		t.Errorf("DataPath(1, 17) = %q, want %q", got, want)
+	}
+}
+
+func TestData(t *testing.T) {
+	values := [][]byte{[]byte("a"), {}, bytes.Repeat([]byte("b"), 300)}
+	got, err := decodeData(encodeData(values))
+	if err != nil {
+		t.Fatalf("decodeData(): %v", err)
+	}
+	if len(got) != len(values) {
+		t.Fatalf("decodeData() returned %d values, want %d", len(got), len(values))
+	}
+	for i := range values {
+		if !bytes.Equal(got[i], values[i]) {
+			t.Errorf("decodeData() value %d = %x, want %x", i, got[i], values[i])
+		}
+	}
+	if _, err := decodeData(encodeData(values)[:10]); err == nil {
+		t.Error("decodeData() of a truncated tile succeeded")
+	}
+}
+
+// testLog is a log in memory storage whose roots are exported as tiles.
+type testLog struct {
+	t        *testing.T
+	ls       storage.LogStorage
+	tree     *trillian.Tree
+	seq      *log.Sequencer
+	verifier *client.LogVerifier
+	exporter *Exporter
+	dir      string
+	size     int
+}
+
+func newTestLog(ctx context.Context, t *testing.T) *testLog {
+	t.Helper()
+	ts := memory.NewTreeStorage()
+	ls := memory.NewLogStorage(ts, nil)
+	key, err := pem.UnmarshalPrivateKey(testonly.DemoPrivateKey, testonly.DemoPrivateKeyPass)
+	if err != nil {
+		t.Fatalf("UnmarshalPrivateKey(): %v", err)
+	}
+	keyDER, err := der.MarshalPrivateKey(key)
+	if err != nil {
+		t.Fatalf("MarshalPrivateKey(): %v", err)
+	}
+	privKey, err := ptypes.MarshalAny(&keyspb.PrivateKey{Der: keyDER})
+	if err != nil {
+		t.Fatalf("MarshalAny(): %v", err)
+	}
+	tree, err := storage.CreateTree(ctx, memory.NewAdminStorage(ts), &trillian.Tree{
+		TreeType:           trillian.TreeType_LOG,
+		TreeState:          trillian.TreeState_ACTIVE,
+		HashStrategy:       trillian.HashStrategy_RFC6962_SHA256,
+		HashAlgorithm:      sigpb.DigitallySigned_SHA256,
+		SignatureAlgorithm: sigpb.DigitallySigned_ECDSA,
+		PrivateKey:         privKey,
+		PublicKey:          &keyspb.PublicKey{Der: ktestonly.MustMarshalPublicPEMToDER(testonly.DemoPublicKey)},
+		MaxRootDuration:    ptypes.DurationProto(0),
+	})
+	if err != nil {
+		t.Fatalf("CreateTree(): %v", err)
+	}
+	signer, err := trees.Signer(ctx, tree)
+	if err != nil {
+		t.Fatalf("Signer(): %v", err)
+	}
+	slr, err := signer.SignLogRoot(&types.LogRootV1{RootHash: rfc6962.DefaultHasher.EmptyRoot()})
+	if err != nil {
+		t.Fatalf("SignLogRoot(): %v", err)
+	}
+	if err := ls.ReadWriteTransaction(ctx, tree, func(ctx context.Context, tx storage.LogTreeTX) error {
+		return tx.StoreSignedLogRoot(ctx, slr)
+	}); err != nil {
+		t.Fatalf("StoreSignedLogRoot(): %v", err)
+	}
+	verifier, err := client.NewLogVerifierFromTree(tree)
+	if err != nil {
+		t.Fatalf("NewLogVerifierFromTree(): %v", err)
+	}
+	dir, err := ioutil.TempDir("", "tiles")
+	if err != nil {
+		t.Fatalf("TempDir(): %v", err)
+	}
+	return &testLog{
+		t:        t,
+		ls:       ls,
+		tree:     tree,
+		seq:      log.NewSequencer(rfc6962.DefaultHasher, clock.System, ls, signer, monitoring.InertMetricFactory{}, quota.Noop()),
+		verifier: verifier,
+		exporter: NewExporter(dir, ls),
+		dir:      dir,
+	}
+}
+
+func (l *testLog) close() {
+	os.RemoveAll(l.dir)
+}
+
+// logDir returns the directory of the tiles of the log.
+func (l *testLog) logDir() string {
+	return filepath.Join(l.dir, strconv.FormatInt(l.tree.TreeId, 10))
+}
+
+func value(i int) []byte {
+	return []byte(fmt.Sprintf("leaf %d", i))
+}
+
+// grow integrates count more leaves, and exports the new root.
+func (l *testLog) grow(ctx context.Context, count int) {
+	l.t.Helper()
+	leaves := make([]*trillian.LogLeaf, 0, count)
+	for i := l.size; i < l.size+count; i++ {
+		leaf := l.verifier.BuildLeaf(value(i))
+		leaf.LeafIdentityHash = leaf.MerkleLeafHash
+		leaves = append(leaves, leaf)
+	}
+	if _, err := l.ls.QueueLeaves(ctx, l.tree, leaves, time.Now()); err != nil {
+		l.t.Fatalf("QueueLeaves(): %v", err)
+	}
+	if n, err := l.seq.IntegrateBatch(ctx, l.tree, count, 0, 24*time.Hour); err != nil || n != count {
+		l.t.Fatalf("IntegrateBatch() = %d, %v; want %d, nil", n, err, count)
+	}
+	l.size += count
+	if err := l.export(ctx); err != nil {
+		l.t.Fatalf("Integrated(): %v", err)
+	}
+}
+
+// export exports the latest root of the log.
+func (l *testLog) export(ctx context.Context) error {
+	tx, err := l.ls.SnapshotForTree(ctx, l.tree)
+	if err != nil {
+		return err
+	}
+	defer tx.Close()
+	slr, err := tx.LatestSignedLogRoot(ctx)
+	if err != nil {
+		return err
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return err
+	}
+	return l.exporter.Integrated(ctx, l.tree, slr, nil)
+}
+
+// check checks that c trusts the latest root of the log, and reads and
+// verifies the leaves and proofs of the log.
+func (l *testLog) check(ctx context.Context, c *Client) {
+	l.t.Helper()
+	root, err := c.UpdateRoot(ctx)
+	if err != nil {
+		l.t.Fatalf("UpdateRoot(): %v", err)
+	}
+	if got, want := root.TreeSize, uint64(l.size); got != want {
+		l.t.Fatalf("UpdateRoot() size = %d, want %d", got, want)
+	}
+	for _, i := range []int{0, 1, l.size / 2, l.size - 2, l.size - 1} {
+		if i < 0 || i >= l.size {
+			continue
+		}
+		got, err := c.GetLeaf(ctx, int64(i))
+		if err != nil {
+			l.t.Fatalf("GetLeaf(%d): %v", i, err)
+		}
+		if want := value(i); !bytes.Equal(got, want) {
+			l.t.Errorf("GetLeaf(%d) = %q, want %q", i, got, want)
+		}
+	}
+	// Proofs for earlier sizes are computed from the same tiles.
+	for _, size := range []int64{1, 2, 255, 256, 257, int64(l.size / 3)} {
+		if size < 1 || size > int64(l.size) {
+			continue
+		}
+		first := size / 2
+		proof, err := c.ConsistencyProof(ctx, first, size)
+		if err != nil {
+			l.t.Fatalf("ConsistencyProof(%d, %d): %v", first, size, err)
+		}
+		if err := c.v.VerifyConsistencyProof(first, size, l.rootHash(c, first), l.rootHash(c, size), proof); err != nil {
+			l.t.Errorf("VerifyConsistencyProof(%d, %d): %v", first, size, err)
+		}
+		index := size - 1
+		proof, err = c.InclusionProof(ctx, index, size)
+		if err != nil {
+			l.t.Fatalf("InclusionProof(%d, %d): %v", index, size, err)
+		}
+		leafHash := rfc6962.DefaultHasher.HashLeaf(value(int(index)))
+		if err := c.v.VerifyInclusionProof(index, size, proof, l.rootHash(c, size), leafHash); err != nil {
+			l.t.Errorf("VerifyInclusionProof(%d, %d): %v", index, size, err)
+		}
+	}
+}
+
+// rootHash returns the root hash of the log at size, computed from its
+// leaves.
+func (l *testLog) rootHash(c *Client, size int64) []byte {
+	r := c.rf.NewEmptyRange(0)
+	for i := 0; i < int(size); i++ {
+		if err := r.Append(rfc6962.DefaultHasher.HashLeaf(value(i)), nil); err != nil {
+			l.t.Fatalf("Append(): %v", err)
+		}
+	}
+	hash, err := r.GetRootHash(nil)
+	if err != nil {
+		l.t.Fatalf("GetRootHash(): %v", err)
+	}
+	if size == 0 {
+		return rfc6962.DefaultHasher.EmptyRoot()
+	}
+	return hash
+}
+
+func TestExportAndVerify(t *testing.T) {
+	ctx := context.Background()
+	l := newTestLog(ctx, t)
+	defer l.close()
+	c := NewClient(l.verifier, DirFetcher(l.logDir()), types.LogRootV1{})
+
+	for _, count := range []int{1, 4, 250, 1, 300, 1000} {
+		l.grow(ctx, count)
+		l.check(ctx, c)
+	}
+
+	// A client which trusts an earlier root catches up over HTTP.
+	s := httptest.NewServer(http.FileServer(http.Dir(l.logDir())))
+	defer s.Close()
+	trusted := types.LogRootV1{TreeSize: 5, RootHash: l.rootHash(c, 5)}
+	hc := NewClient(l.verifier, HTTPFetcher(s.Client(), s.URL), trusted)
+	hc.MaxTiles = 2
+	l.check(ctx, hc)
+	if got, want := hc.lru.Len(), hc.MaxTiles; got > want {
+		t.Errorf("Client kept %d tiles, want at most %d", got, want)
+	}
+}
+
+// swapHashes swaps the first two hashes of a hash tile of the log.
+func (l *testLog) swapHashes(path string) {
+	l.t.Helper()
+	path = filepath.Join(l.logDir(), filepath.FromSlash(path))
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		l.t.Fatalf("ReadFile(): %v", err)
+	}
+	size := rfc6962.DefaultHasher.Size()
+	tampered := append(append(append([]byte{}, data[size:2*size]...), data[:size]...), data[2*size:]...)
+	if err := ioutil.WriteFile(path, tampered, 0644); err != nil {
+		l.t.Fatalf("WriteFile(): %v", err)
+	}
+}
+
+func TestTamperedTile(t *testing.T) {
+	ctx := context.Background()
+	l := newTestLog(ctx, t)
+	defer l.close()
+	l.grow(ctx, 300)
+	c := NewClient(l.verifier, DirFetcher(l.logDir()), types.LogRootV1{})
+	if _, err := c.UpdateRoot(ctx); err != nil {
+		t.Fatalf("UpdateRoot(): %v", err)
+	}
+
+	l.swapHashes(TilePath(0, 0, TileWidth))
+	if _, err := c.GetLeaf(ctx, 0); err == nil {
+		t.Error("GetLeaf() with a tampered tile succeeded")
+	}
+	// The tampered tile isn't kept, so the client recovers once it is fixed.
+	l.swapHashes(TilePath(0, 0, TileWidth))
+	if _, err := c.GetLeaf(ctx, 0); err != nil {
+		t.Errorf("GetLeaf() with a fixed tile: %v", err)
+	}
+
+	// A client which trusts a root is not fooled by tampered tiles of a
+	// later one.
+	l.grow(ctx, 1)
+	l.swapHashes(TilePath(0, 1, 45))
+	var rbErr *client.RollbackError
+	if _, err := c.UpdateRoot(ctx); !errors.As(err, &rbErr) {
+		t.Errorf("UpdateRoot() with a tampered tile: %v, want RollbackError", err)
+	}
+}
+
+func TestCatchUp(t *testing.T) {
+	ctx := context.Background()
+	l := newTestLog(ctx, t)
+	defer l.close()
+	l.exporter.MaxTiles = 2
+	l.grow(ctx, 3*TileWidth)
+
+	// The tiles are behind the root, so it has no checkpoint yet.
+	c := NewClient(l.verifier, DirFetcher(l.logDir()), types.LogRootV1{})
+	if _, err := c.UpdateRoot(ctx); err == nil {
+		t.Fatal("UpdateRoot() before catching up succeeded")
+	}
+	for i := 0; i < 3; i++ {
+		if err := l.export(ctx); err != nil {
+			t.Fatalf("Integrated(): %v", err)
+		}
+	}
+	l.check(ctx, c)
+}
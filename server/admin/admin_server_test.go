@@ -639,9 +639,13 @@ func TestServer_UpdateTree(t *testing.T) {
 		StorageSettings: settings,
 		MaxRootDuration: ptypes.DurationProto(2 * time.Nanosecond),
 		PrivateKey:      ttestonly.MustMarshalAny(t, &empty.Empty{}),
+		ScheduledTransition: &trillian.ScheduledTreeTransition{
+			TreeState:      trillian.TreeState_DRAINING,
+			TransitionTime: nowPB,
+		},
 	}
 	successMask := &field_mask.FieldMask{
-		Paths: []string{"tree_state", "display_name", "description", "storage_settings", "max_root_duration", "private_key"},
+		Paths: []string{"tree_state", "display_name", "description", "storage_settings", "max_root_duration", "private_key", "scheduled_transition"},
 	}
 
 	successWant := proto.Clone(existingTree).(*trillian.Tree)
@@ -651,6 +655,7 @@ func TestServer_UpdateTree(t *testing.T) {
 	successWant.StorageSettings = successTree.StorageSettings
 	successWant.PrivateKey = nil // redacted on responses
 	successWant.MaxRootDuration = successTree.MaxRootDuration
+	successWant.ScheduledTransition = successTree.ScheduledTransition
 
 	tests := []struct {
 		desc                           string
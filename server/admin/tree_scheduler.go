@@ -0,0 +1,144 @@
+// Copyright 2020 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package admin
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"math/rand"
+	"sync"
+	"time"
+
+	"github.com/golang/glog"
+	"github.com/google/trillian"
+	"github.com/google/trillian/monitoring"
+	"github.com/google/trillian/storage"
+	"github.com/google/trillian/trees"
+)
+
+var (
+	scheduledTransitionCounter monitoring.Counter
+	schedulerMetricsOnce       sync.Once
+)
+
+func incScheduledTransitionCounter(treeID int64, state trillian.TreeState, success bool) {
+	scheduledTransitionCounter.Inc(fmt.Sprint(treeID), state.String(), fmt.Sprint(success))
+}
+
+// TreeStateScheduler applies scheduled tree state transitions.
+//
+// A tree with a due scheduled transition (see Tree.ScheduledTransition) is
+// already treated as being in the scheduled state when checking which
+// operations it allows. TreeStateScheduler makes the transition permanent by
+// updating the tree state in storage and clearing the scheduled transition.
+type TreeStateScheduler struct {
+	// admin is the storage.AdminStorage interface.
+	admin storage.AdminStorage
+
+	// minRunInterval defines how frequently sweeps for due transitions are
+	// performed. Actual runs happen randomly between [minInterval,2*minInterval).
+	minRunInterval time.Duration
+}
+
+// NewTreeStateScheduler returns a new TreeStateScheduler.
+func NewTreeStateScheduler(admin storage.AdminStorage, minRunInterval time.Duration, mf monitoring.MetricFactory) *TreeStateScheduler {
+	ts := &TreeStateScheduler{
+		admin:          admin,
+		minRunInterval: minRunInterval,
+	}
+	schedulerMetricsOnce.Do(func() {
+		if mf == nil {
+			mf = monitoring.InertMetricFactory{}
+		}
+		scheduledTransitionCounter = mf.NewCounter("tree_scheduled_transition_counter", "Counter of applied scheduled tree state transitions", monitoring.TreeIDLabel, "state", "success")
+	})
+	return ts
+}
+
+// Run starts applying scheduled transitions. It runs until ctx is cancelled.
+func (ts *TreeStateScheduler) Run(ctx context.Context) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		default:
+		}
+
+		count, err := ts.RunOnce(ctx)
+		if err != nil {
+			glog.Errorf("TreeStateScheduler.Run: %v", err)
+		}
+		if count > 0 {
+			glog.Infof("TreeStateScheduler.Run: successfully applied %v scheduled transitions", count)
+		}
+
+		d := ts.minRunInterval + time.Duration(rand.Int63n(ts.minRunInterval.Nanoseconds()))
+		timeSleep(d)
+	}
+}
+
+// RunOnce performs a single sweep for due scheduled transitions. Returns the
+// number of successfully applied transitions.
+//
+// It attempts to apply as many due transitions as possible, regardless of
+// failures. If it encounters any failures the resulting error is non-nil.
+func (ts *TreeStateScheduler) RunOnce(ctx context.Context) (int, error) {
+	now := timeNow()
+
+	// List and update trees in separate transactions. Each update checks again
+	// that the transition is due, in case the tree changed in the meantime.
+	allTrees, err := storage.ListTrees(ctx, ts.admin, false /* includeDeleted */)
+	if err != nil {
+		return 0, fmt.Errorf("error listing trees: %v", err)
+	}
+
+	count := 0
+	var errs []error
+	for _, tree := range allTrees {
+		st := trees.DueTransition(tree, now)
+		if st == nil {
+			continue
+		}
+
+		glog.Infof("TreeStateScheduler.RunOnce: Moving tree %v from %v to %v", tree.TreeId, tree.TreeState, st.TreeState)
+		if _, err := storage.UpdateTree(ctx, ts.admin, tree.TreeId, func(t *trillian.Tree) {
+			if st := trees.DueTransition(t, now); st != nil {
+				t.TreeState = st.TreeState
+				t.ScheduledTransition = nil
+			}
+		}); err != nil {
+			errs = append(errs, fmt.Errorf("error updating tree %v: %v", tree.TreeId, err))
+			incScheduledTransitionCounter(tree.TreeId, st.TreeState, false)
+			continue
+		}
+
+		count++
+		incScheduledTransitionCounter(tree.TreeId, st.TreeState, true)
+	}
+
+	if len(errs) == 0 {
+		return count, nil
+	}
+
+	buf := &bytes.Buffer{}
+	buf.WriteString("encountered errors applying scheduled transitions:")
+	for _, err := range errs {
+		buf.WriteString("\n\t")
+		buf.WriteString(err.Error())
+	}
+	return count, errors.New(buf.String())
+}
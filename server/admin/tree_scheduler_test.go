@@ -0,0 +1,130 @@
+// Copyright 2020 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package admin
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/golang/protobuf/proto" //nolint:staticcheck
+	"github.com/golang/protobuf/ptypes"
+	"github.com/google/trillian"
+	"github.com/google/trillian/storage"
+	"github.com/google/trillian/storage/testonly"
+)
+
+func TestTreeStateScheduler_RunOnce(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	scheduled := func(id int64, state trillian.TreeState, at time.Time) *trillian.Tree {
+		tree := proto.Clone(testonly.LogTree).(*trillian.Tree)
+		tree.TreeId = id
+		ts, _ := ptypes.TimestampProto(at)
+		tree.ScheduledTransition = &trillian.ScheduledTreeTransition{TreeState: state, TransitionTime: ts}
+		return tree
+	}
+	tree1 := proto.Clone(testonly.LogTree).(*trillian.Tree)
+	tree1.TreeId = 1
+	tree2 := scheduled(2, trillian.TreeState_DRAINING, time.Date(2020, 6, 1, 0, 0, 0, 0, time.UTC))
+	tree3 := scheduled(3, trillian.TreeState_FROZEN, time.Date(2020, 7, 1, 0, 0, 0, 0, time.UTC))
+	allTrees := []*trillian.Tree{tree1, tree2, tree3}
+
+	tests := []struct {
+		desc      string
+		now       time.Time
+		updateErr error
+		want      map[int64]trillian.TreeState
+		wantErr   bool
+	}{
+		{
+			desc: "noneDue",
+			now:  time.Date(2020, 5, 31, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			desc: "oneDue",
+			now:  time.Date(2020, 6, 1, 0, 0, 0, 0, time.UTC),
+			want: map[int64]trillian.TreeState{tree2.TreeId: trillian.TreeState_DRAINING},
+		},
+		{
+			desc: "twoDue",
+			now:  time.Date(2020, 7, 2, 0, 0, 0, 0, time.UTC),
+			want: map[int64]trillian.TreeState{
+				tree2.TreeId: trillian.TreeState_DRAINING,
+				tree3.TreeId: trillian.TreeState_FROZEN,
+			},
+		},
+		{
+			desc:      "updateErr",
+			now:       time.Date(2020, 6, 1, 0, 0, 0, 0, time.UTC),
+			updateErr: errors.New("update failed"),
+			want:      map[int64]trillian.TreeState{tree2.TreeId: trillian.TreeState_DRAINING},
+			wantErr:   true,
+		},
+	}
+
+	defer func(f func() time.Time) { timeNow = f }(timeNow)
+	ctx := context.Background()
+	for _, test := range tests {
+		t.Run(test.desc, func(t *testing.T) {
+			timeNow = func() time.Time { return test.now }
+
+			listTX := storage.NewMockReadOnlyAdminTX(ctrl)
+			as := &testonly.FakeAdminStorage{ReadOnlyTX: []storage.ReadOnlyAdminTX{listTX}}
+			listTX.EXPECT().ListTrees(gomock.Any(), false /* includeDeleted */).Return(allTrees, nil)
+			listTX.EXPECT().Close().Return(nil)
+			listTX.EXPECT().Commit().Return(nil)
+
+			for _, tree := range allTrees {
+				wantState, ok := test.want[tree.TreeId]
+				if !ok {
+					continue
+				}
+				tree := tree
+				updateTX := storage.NewMockAdminTX(ctrl)
+				updateTX.EXPECT().UpdateTree(gomock.Any(), tree.TreeId, gomock.Any()).DoAndReturn(
+					func(_ context.Context, _ int64, fn func(*trillian.Tree)) (*trillian.Tree, error) {
+						updated := proto.Clone(tree).(*trillian.Tree)
+						fn(updated)
+						if updated.TreeState != wantState || updated.ScheduledTransition != nil {
+							t.Errorf("UpdateTree(%v): got state %v and transition %v, want %v and none", tree.TreeId, updated.TreeState, updated.ScheduledTransition, wantState)
+						}
+						return updated, test.updateErr
+					})
+				updateTX.EXPECT().Close().Return(nil)
+				if test.updateErr == nil {
+					updateTX.EXPECT().Commit().Return(nil)
+				}
+				as.TX = append(as.TX, updateTX)
+			}
+
+			ts := NewTreeStateScheduler(as, 1*time.Second /* minRunInterval */, nil /* mf */)
+			count, err := ts.RunOnce(ctx)
+			if gotErr := err != nil; gotErr != test.wantErr {
+				t.Errorf("RunOnce() returned err = %v, wantErr %v", err, test.wantErr)
+			}
+			wantCount := len(test.want)
+			if test.wantErr {
+				wantCount = 0
+			}
+			if count != wantCount {
+				t.Errorf("RunOnce() = %v, want = %v", count, wantCount)
+			}
+		})
+	}
+}
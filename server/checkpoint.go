@@ -0,0 +1,161 @@
+// Copyright 2020 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package server
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"path"
+	"strconv"
+	"sync"
+
+	"github.com/golang/glog"
+	"github.com/google/trillian"
+	"github.com/google/trillian/server/interceptor"
+	"github.com/google/trillian/storage"
+	"github.com/google/trillian/trees"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	tcrypto "github.com/google/trillian/crypto"
+)
+
+// CheckpointHandler is an http.Handler which serves the latest root of a log
+// as a checkpoint, in the signed note format used by transparency tools
+// outside Trillian. The log ID is the last element of the request path, e.g.
+// GET /checkpoint/<log ID>.
+//
+// The origin of the checkpoint, which is also the name of its signer, is the
+// origin prefix followed by the log ID. The checkpoint is signed with the key
+// of the log, once the signature of the root has been verified. This is done
+// once per root: the checkpoint of the latest root of each log is kept, and
+// served until the log has a new root.
+//
+// Requests go through the TrillianInterceptor like GetLatestSignedLogRoot
+// RPCs, so they are checked against the tree state and charged to quota.
+type CheckpointHandler struct {
+	log          trillian.TrillianLogServer
+	admin        storage.AdminStorage
+	ti           *interceptor.TrillianInterceptor
+	originPrefix string
+
+	mu          sync.Mutex
+	checkpoints map[int64]*checkpoint
+}
+
+// checkpoint is the checkpoint of a signed log root.
+type checkpoint struct {
+	slr       *trillian.SignedLogRoot
+	timestamp uint64
+	note      []byte
+}
+
+// NewCheckpointHandler returns a CheckpointHandler which reads the latest
+// roots from log and the keys of logs from admin, intercepting requests with
+// ti.
+func NewCheckpointHandler(log trillian.TrillianLogServer, admin storage.AdminStorage, ti *interceptor.TrillianInterceptor, originPrefix string) *CheckpointHandler {
+	return &CheckpointHandler{
+		log:          log,
+		admin:        admin,
+		ti:           ti,
+		originPrefix: originPrefix,
+		checkpoints:  make(map[int64]*checkpoint),
+	}
+}
+
+// Origin returns the origin of the checkpoints of a log.
+func (h *CheckpointHandler) Origin(logID int64) string {
+	return h.originPrefix + strconv.FormatInt(logID, 10)
+}
+
+// ServeHTTP serves the latest checkpoint of the log named by the request path.
+func (h *CheckpointHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	logID, err := strconv.ParseInt(path.Base(r.URL.Path), 10, 64)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("invalid log ID %q", path.Base(r.URL.Path)), http.StatusBadRequest)
+		return
+	}
+	note, err := h.latest(r, logID)
+	if err != nil {
+		code := http.StatusInternalServerError
+		switch status.Code(err) {
+		case codes.NotFound:
+			code = http.StatusNotFound
+		case codes.InvalidArgument, codes.FailedPrecondition:
+			code = http.StatusBadRequest
+		case codes.ResourceExhausted, codes.Unavailable:
+			code = http.StatusServiceUnavailable
+		}
+		if code == http.StatusInternalServerError {
+			glog.Warningf("%v: failed to serve checkpoint: %v", logID, err)
+		}
+		http.Error(w, err.Error(), code)
+		return
+	}
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	w.Write(note)
+}
+
+// latest returns the latest checkpoint of a log, signed with its key.
+func (h *CheckpointHandler) latest(r *http.Request, logID int64) (note []byte, err error) {
+	ctx := r.Context()
+	req := &trillian.GetLatestSignedLogRootRequest{LogId: logID}
+	var resp *trillian.GetLatestSignedLogRootResponse
+	rp := h.ti.NewProcessor()
+	if ctx, err = rp.Before(ctx, req, getLatestSignedLogRootMethod); err != nil {
+		return nil, err
+	}
+	defer func() { rp.After(ctx, resp, getLatestSignedLogRootMethod, err) }()
+
+	if resp, err = h.log.GetLatestSignedLogRoot(ctx, req); err != nil {
+		return nil, err
+	}
+	slr := resp.SignedLogRoot
+	h.mu.Lock()
+	c, ok := h.checkpoints[logID]
+	h.mu.Unlock()
+	if ok && bytes.Equal(c.slr.LogRoot, slr.GetLogRoot()) && bytes.Equal(c.slr.LogRootSignature, slr.GetLogRootSignature()) {
+		return c.note, nil
+	}
+
+	tree, err := trees.GetTree(ctx, h.admin, logID, optsLogRead)
+	if err != nil {
+		return nil, err
+	}
+	signer, err := trees.Signer(ctx, tree)
+	if err != nil {
+		return nil, err
+	}
+	root, err := tcrypto.VerifySignedLogRoot(signer.Public(), signer.Hash, slr)
+	if err != nil {
+		return nil, fmt.Errorf("failed to verify log root: %v", err)
+	}
+	origin := h.Origin(logID)
+	if note, err = signer.SignCheckpoint(origin, root.Checkpoint(origin)); err != nil {
+		return nil, err
+	}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	// Don't replace the checkpoint of a later root signed concurrently.
+	if c, ok := h.checkpoints[logID]; !ok || c.timestamp <= root.TimestampNanos {
+		h.checkpoints[logID] = &checkpoint{slr: slr, timestamp: root.TimestampNanos, note: note}
+	}
+	return note, nil
+}
@@ -0,0 +1,156 @@
+// Copyright 2020 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package server
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/google/trillian"
+	"github.com/google/trillian/quota"
+	"github.com/google/trillian/server/interceptor"
+	"github.com/google/trillian/storage"
+	"github.com/google/trillian/storage/memory"
+	"github.com/google/trillian/trees"
+	"github.com/google/trillian/types"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	tcrypto "github.com/google/trillian/crypto"
+	stestonly "github.com/google/trillian/storage/testonly"
+)
+
+// fakeRootServer serves a fixed SignedLogRoot for a single log.
+type fakeRootServer struct {
+	trillian.UnimplementedTrillianLogServer
+	logID int64
+	slr   *trillian.SignedLogRoot
+}
+
+func (f *fakeRootServer) GetLatestSignedLogRoot(ctx context.Context, req *trillian.GetLatestSignedLogRootRequest) (*trillian.GetLatestSignedLogRootResponse, error) {
+	if req.LogId != f.logID {
+		return nil, status.Errorf(codes.NotFound, "log %d not found", req.LogId)
+	}
+	return &trillian.GetLatestSignedLogRootResponse{SignedLogRoot: f.slr}, nil
+}
+
+// notFoundAdmin reports missing trees with codes.NotFound, as the SQL storage
+// implementations do.
+type notFoundAdmin struct {
+	storage.AdminStorage
+}
+
+func (a notFoundAdmin) Snapshot(ctx context.Context) (storage.ReadOnlyAdminTX, error) {
+	tx, err := a.AdminStorage.Snapshot(ctx)
+	return notFoundAdminTX{tx}, err
+}
+
+type notFoundAdminTX struct {
+	storage.ReadOnlyAdminTX
+}
+
+func (tx notFoundAdminTX) GetTree(ctx context.Context, treeID int64) (*trillian.Tree, error) {
+	tree, err := tx.ReadOnlyAdminTX.GetTree(ctx, treeID)
+	if err != nil {
+		return nil, status.Error(codes.NotFound, err.Error())
+	}
+	return tree, nil
+}
+
+// fakeQuota is a quota.Manager which denies all tokens if deny is set.
+type fakeQuota struct {
+	quota.Manager
+	deny bool
+}
+
+func (f *fakeQuota) GetTokens(ctx context.Context, numTokens int, specs []quota.Spec) error {
+	if f.deny {
+		return errors.New("no tokens")
+	}
+	return nil
+}
+
+func TestCheckpointHandler(t *testing.T) {
+	ctx := context.Background()
+	admin := notFoundAdmin{memory.NewAdminStorage(memory.NewTreeStorage())}
+	tree, err := storage.CreateTree(ctx, admin, stestonly.LogTree)
+	if err != nil {
+		t.Fatalf("CreateTree(): %v", err)
+	}
+	signer, err := trees.Signer(ctx, tree)
+	if err != nil {
+		t.Fatalf("Signer(): %v", err)
+	}
+	root := &types.LogRootV1{TreeSize: 42, RootHash: bytes.Repeat([]byte{1}, 32), TimestampNanos: 1}
+	slr, err := signer.SignLogRoot(root)
+	if err != nil {
+		t.Fatalf("SignLogRoot(): %v", err)
+	}
+	log := &fakeRootServer{logID: tree.TreeId, slr: slr}
+	qm := &fakeQuota{Manager: quota.Noop()}
+	ti := interceptor.New(admin, qm, false /* quotaDryRun */, nil /* mf */)
+	h := NewCheckpointHandler(log, admin, ti, "example.com/log/")
+	origin := fmt.Sprintf("example.com/log/%d", tree.TreeId)
+	if got := h.Origin(tree.TreeId); got != origin {
+		t.Errorf("Origin() = %q, want %q", got, origin)
+	}
+
+	get := func(path string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, httptest.NewRequest(http.MethodGet, path, nil))
+		return w
+	}
+	w := get(fmt.Sprintf("/checkpoint/%d", tree.TreeId))
+	if w.Code != http.StatusOK {
+		t.Fatalf("GET checkpoint: %d %s", w.Code, w.Body)
+	}
+	c, err := tcrypto.VerifyCheckpoint(signer.Public(), origin, w.Body.Bytes())
+	if err != nil {
+		t.Fatalf("VerifyCheckpoint(): %v", err)
+	}
+	if c.Origin != origin || c.TreeSize != root.TreeSize || !bytes.Equal(c.RootHash, root.RootHash) {
+		t.Errorf("VerifyCheckpoint() = %+v, want root %+v", c, root)
+	}
+	// The checkpoint is only signed once per root. ECDSA signatures are
+	// randomized, so a new signature wouldn't match.
+	if w2 := get(fmt.Sprintf("/checkpoint/%d", tree.TreeId)); !bytes.Equal(w2.Body.Bytes(), w.Body.Bytes()) {
+		t.Errorf("GET checkpoint again = %s, want %s", w2.Body, w.Body)
+	}
+
+	if w := get("/checkpoint/abc"); w.Code != http.StatusBadRequest {
+		t.Errorf("GET invalid log ID: %d, want %d", w.Code, http.StatusBadRequest)
+	}
+	if w := get(fmt.Sprintf("/checkpoint/%d", tree.TreeId+1)); w.Code != http.StatusNotFound {
+		t.Errorf("GET unknown log: %d, want %d", w.Code, http.StatusNotFound)
+	}
+
+	// A root which doesn't verify with the key of the log isn't served.
+	log.slr = &trillian.SignedLogRoot{LogRoot: slr.LogRoot, LogRootSignature: []byte("bad")}
+	if w := get(fmt.Sprintf("/checkpoint/%d", tree.TreeId)); w.Code != http.StatusInternalServerError {
+		t.Errorf("GET with a bad root signature: %d, want %d", w.Code, http.StatusInternalServerError)
+	}
+
+	// Requests are charged to quota like GetLatestSignedLogRoot RPCs.
+	log.slr = slr
+	qm.deny = true
+	if w := get(fmt.Sprintf("/checkpoint/%d", tree.TreeId)); w.Code != http.StatusServiceUnavailable {
+		t.Errorf("GET without quota: %d, want %d", w.Code, http.StatusServiceUnavailable)
+	}
+}
@@ -30,6 +30,7 @@ import (
 	"github.com/google/trillian/server/errors"
 	"github.com/google/trillian/storage"
 	"github.com/google/trillian/trees"
+	grpc_middleware "github.com/grpc-ecosystem/go-grpc-middleware"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
@@ -144,6 +145,27 @@ func (i *TrillianInterceptor) UnaryInterceptor(ctx context.Context, req interfac
 	return resp, err
 }
 
+// StreamInterceptor makes the TrillianInterceptor available to the handlers
+// of streaming RPCs, which don't go through UnaryInterceptor. Handlers apply
+// its logic to each unit of work they do, using NewProcessorFromContext.
+func (i *TrillianInterceptor) StreamInterceptor(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	ws := grpc_middleware.WrapServerStream(ss)
+	ws.WrappedContext = context.WithValue(ss.Context(), interceptorKey{}, i)
+	return handler(srv, ws)
+}
+
+type interceptorKey struct{}
+
+// NewProcessorFromContext returns a RequestProcessor for the TrillianInterceptor
+// installed by StreamInterceptor in ctx, or nil if there is none.
+func NewProcessorFromContext(ctx context.Context) RequestProcessor {
+	i, ok := ctx.Value(interceptorKey{}).(*TrillianInterceptor)
+	if !ok {
+		return nil
+	}
+	return i.NewProcessor()
+}
+
 // NewProcessor returns a RequestProcessor for the TrillianInterceptor logic.
 func (i *TrillianInterceptor) NewProcessor() RequestProcessor {
 	return &trillianProcessor{parent: i}
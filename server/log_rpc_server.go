@@ -17,14 +17,17 @@ package server
 import (
 	"context"
 	"fmt"
+	"io"
 	"strconv"
 
 	"github.com/golang/glog"
 	"github.com/google/trillian"
 	"github.com/google/trillian/extension"
+	"github.com/google/trillian/log/admission"
 	"github.com/google/trillian/merkle"
 	"github.com/google/trillian/merkle/hashers"
 	"github.com/google/trillian/monitoring"
+	"github.com/google/trillian/server/interceptor"
 	"github.com/google/trillian/storage"
 	"github.com/google/trillian/trees"
 	"github.com/google/trillian/types"
@@ -36,7 +39,21 @@ import (
 // TODO: There is no access control in the server yet and clients could easily modify
 // any tree.
 
-const traceSpanRoot = "/trillian"
+const (
+	traceSpanRoot = "/trillian"
+
+	// sequencedLeavesChunkSize is the max number of leaves which
+	// AddSequencedLeavesStream stores in one transaction.
+	sequencedLeavesChunkSize = 1000
+
+	// addSequencedLeavesMethod is the method which AddSequencedLeavesStream
+	// charges its chunks to quota as.
+	addSequencedLeavesMethod = "/trillian.TrillianLog/AddSequencedLeaves"
+
+	// getLatestSignedLogRootMethod is the method which CheckpointHandler
+	// intercepts its requests as.
+	getLatestSignedLogRootMethod = "/trillian.TrillianLog/GetLatestSignedLogRoot"
+)
 
 var (
 	optsLogInit            = trees.NewGetOpts(trees.Admin, trillian.TreeType_LOG, trillian.TreeType_PREORDERED_LOG)
@@ -139,7 +156,7 @@ func (t *TrillianLogRPCServer) QueueLeaves(ctx context.Context, req *trillian.Qu
 
 	hashLeaves(req.Leaves, hasher)
 
-	ret, err := t.registry.LogStorage.QueueLeaves(ctx, tree, req.Leaves, t.timeSource.Now())
+	ret, err := t.admitLeaves(ctx, tree, req.Leaves)
 	if err != nil {
 		return nil, err
 	}
@@ -150,12 +167,69 @@ func (t *TrillianLogRPCServer) QueueLeaves(ctx context.Context, req *trillian.Qu
 			t.leafCounter.Inc(label, "queued")
 		} else if l.Status.Code == int32(codes.AlreadyExists) {
 			t.leafCounter.Inc(label, "duplicate")
+		} else {
+			t.leafCounter.Inc(label, "rejected")
 		}
 	}
 
 	return &trillian.QueueLeavesResponse{QueuedLeaves: ret}, nil
 }
 
+// admitLeaves checks the leaves with the leaf validator of the registry, if
+// any, and queues those which it accepts. It returns the results of all the
+// leaves in order, where the rejected leaves have the status which the
+// validator rejected them with.
+func (t *TrillianLogRPCServer) admitLeaves(ctx context.Context, tree *trillian.Tree, leaves []*trillian.LogLeaf) ([]*trillian.QueuedLogLeaf, error) {
+	v := t.registry.LeafValidator
+	if v == nil {
+		return t.registry.LogStorage.QueueLeaves(ctx, tree, leaves, t.timeSource.Now())
+	}
+	results := make([]*trillian.QueuedLogLeaf, len(leaves))
+	admitted := make([]*trillian.LogLeaf, 0, len(leaves))
+	for i, leaf := range leaves {
+		if err := v.Validate(ctx, tree, leaf); err != nil {
+			results[i] = &trillian.QueuedLogLeaf{Status: admission.Status(err).Proto()}
+			continue
+		}
+		admitted = append(admitted, leaf)
+	}
+	if len(admitted) == 0 {
+		return results, nil
+	}
+
+	stored, err := t.registry.LogStorage.QueueLeaves(ctx, tree, admitted, t.timeSource.Now())
+	if err != nil {
+		return nil, err
+	}
+	if got, want := len(stored), len(admitted); got != want {
+		return nil, status.Errorf(codes.Internal, "storage returned %d leaves, want: %d", got, want)
+	}
+	for i := range results {
+		if results[i] == nil {
+			results[i], stored = stored[0], stored[1:]
+		}
+	}
+	return results, nil
+}
+
+// validateSequencedLeaves checks the leaves with the leaf validator of the
+// registry, if any. Unlike QueueLeaves, which skips the rejected leaves, the
+// sequenced leaves would leave a gap in the log, so the first rejected leaf
+// fails the whole request with the status which the validator rejected it with.
+func (t *TrillianLogRPCServer) validateSequencedLeaves(ctx context.Context, tree *trillian.Tree, leaves []*trillian.LogLeaf) error {
+	v := t.registry.LeafValidator
+	if v == nil {
+		return nil
+	}
+	for _, leaf := range leaves {
+		if err := v.Validate(ctx, tree, leaf); err != nil {
+			s := admission.Status(err)
+			return status.Errorf(s.Code(), "leaf %d rejected: %s", leaf.LeafIndex, s.Message())
+		}
+	}
+	return nil
+}
+
 // AddSequencedLeaf submits one sequenced leaf to the storage.
 func (t *TrillianLogRPCServer) AddSequencedLeaf(ctx context.Context, req *trillian.AddSequencedLeafRequest) (*trillian.AddSequencedLeafResponse, error) {
 	ctx, spanEnd := spanFor(ctx, "AddSequencedLeaf")
@@ -198,6 +272,9 @@ func (t *TrillianLogRPCServer) AddSequencedLeaves(ctx context.Context, req *tril
 	hashLeaves(req.Leaves, hasher)
 
 	ctx = trees.NewContext(ctx, tree)
+	if err := t.validateSequencedLeaves(ctx, tree, req.Leaves); err != nil {
+		return nil, err
+	}
 	leaves, err := t.registry.LogStorage.AddSequencedLeaves(ctx, tree, req.Leaves, t.timeSource.Now())
 	if err != nil {
 		return nil, err
@@ -218,6 +295,114 @@ func (t *TrillianLogRPCServer) AddSequencedLeaves(ctx context.Context, req *tril
 	return &trillian.AddSequencedLeavesResponse{Results: leaves}, nil
 }
 
+// AddSequencedLeavesStream adds the leaves of a stream of requests to a
+// PREORDERED_LOG, in chunks of up to sequencedLeavesChunkSize leaves. Each
+// chunk is stored in its own transaction, and its result sent, before more
+// requests are read. A leaf rejected by the leaf validator fails the stream,
+// after the chunks before it.
+func (t *TrillianLogRPCServer) AddSequencedLeavesStream(stream trillian.TrillianLog_AddSequencedLeavesStreamServer) error {
+	ctx, spanEnd := spanFor(stream.Context(), "AddSequencedLeavesStream")
+	defer spanEnd()
+
+	var (
+		tree     *trillian.Tree
+		hasher   hashers.LogHasher
+		chargeTo *trillian.ChargeTo
+		next     int64
+		chunk    []*trillian.LogLeaf
+	)
+	for {
+		req, err := stream.Recv()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			return err
+		}
+		if err := validateAddSequencedLeavesRequest(req); err != nil {
+			return err
+		}
+
+		if tree == nil {
+			if tree, hasher, err = t.getTreeAndHasher(ctx, req.LogId, optsPreorderedLogWrite); err != nil {
+				return err
+			}
+			ctx = trees.NewContext(ctx, tree)
+			chargeTo = req.ChargeTo
+			next = req.Leaves[0].LeafIndex
+		} else if req.LogId != tree.TreeId {
+			return status.Errorf(codes.InvalidArgument, "AddSequencedLeavesRequest.LogId=%v, want %v as in the first request of the stream", req.LogId, tree.TreeId)
+		}
+		if got := req.Leaves[0].LeafIndex; got != next {
+			return status.Errorf(codes.FailedPrecondition, "AddSequencedLeavesRequest.Leaves[0].LeafIndex=%v, want %v", got, next)
+		}
+		next += int64(len(req.Leaves))
+
+		for _, leaf := range req.Leaves {
+			chunk = append(chunk, leaf)
+			if len(chunk) == sequencedLeavesChunkSize {
+				if err := t.addSequencedChunk(ctx, stream, tree, hasher, &trillian.AddSequencedLeavesRequest{LogId: tree.TreeId, Leaves: chunk, ChargeTo: chargeTo}); err != nil {
+					return err
+				}
+				chunk = nil
+			}
+		}
+	}
+	if tree == nil {
+		return status.Error(codes.InvalidArgument, "AddSequencedLeavesStream: no requests")
+	}
+	if len(chunk) > 0 {
+		return t.addSequencedChunk(ctx, stream, tree, hasher, &trillian.AddSequencedLeavesRequest{LogId: tree.TreeId, Leaves: chunk, ChargeTo: chargeTo})
+	}
+	return nil
+}
+
+// addSequencedChunk stores a chunk of leaves for AddSequencedLeavesStream, and
+// sends its result on the stream. The chunk is charged to quota as if it had
+// been added with AddSequencedLeaves, using the interceptor of the stream, if
+// any.
+func (t *TrillianLogRPCServer) addSequencedChunk(ctx context.Context, stream trillian.TrillianLog_AddSequencedLeavesStreamServer, tree *trillian.Tree, hasher hashers.LogHasher, req *trillian.AddSequencedLeavesRequest) (err error) {
+	var results []*trillian.QueuedLogLeaf
+	if rp := interceptor.NewProcessorFromContext(ctx); rp != nil {
+		if ctx, err = rp.Before(ctx, req, addSequencedLeavesMethod); err != nil {
+			return err
+		}
+		defer func() {
+			rp.After(ctx, &trillian.AddSequencedLeavesResponse{Results: results}, addSequencedLeavesMethod, err)
+		}()
+	}
+
+	leaves := req.Leaves
+	hashLeaves(leaves, hasher)
+	if err = t.validateSequencedLeaves(ctx, tree, leaves); err != nil {
+		return err
+	}
+	results, err = t.registry.LogStorage.AddSequencedLeaves(ctx, tree, leaves, t.timeSource.Now())
+	if err != nil {
+		return err
+	}
+	if got, want := len(results), len(leaves); got != want {
+		return status.Errorf(codes.Internal, "AddSequencedLeaves returned %d leaves, want: %d", got, want)
+	}
+
+	label := strconv.FormatInt(tree.TreeId, 10)
+	res := &trillian.SequencedLeavesChunk{StartIndex: leaves[0].LeafIndex, LeafCount: int64(len(leaves))}
+	for _, l := range results {
+		if l.Status == nil || l.Status.Code == int32(codes.OK) {
+			t.leafCounter.Inc(label, "inserted")
+			continue
+		}
+		t.leafCounter.Inc(label, "skipped")
+		if res.SkippedCount == 0 {
+			res.Status = l.Status
+		}
+		res.SkippedCount++
+	}
+	if res.Status == nil {
+		res.Status = status.New(codes.OK, "").Proto()
+	}
+	return stream.Send(&trillian.AddSequencedLeavesStreamResponse{Chunks: []*trillian.SequencedLeavesChunk{res}})
+}
+
 // GetInclusionProof obtains the proof of inclusion in the tree for a leaf that has been sequenced.
 // Similar to the get proof by hash handler but one less step as we don't need to look up the index
 func (t *TrillianLogRPCServer) GetInclusionProof(ctx context.Context, req *trillian.GetInclusionProofRequest) (*trillian.GetInclusionProofResponse, error) {
@@ -15,10 +15,12 @@
 package server
 
 import (
+	"bytes"
 	"context"
 	"crypto"
 	"errors"
 	"fmt"
+	"io"
 	"strings"
 	"testing"
 	"time"
@@ -30,12 +32,16 @@ import (
 	"github.com/google/trillian/crypto/keys/pem"
 	"github.com/google/trillian/crypto/sigpb"
 	"github.com/google/trillian/extension"
+	"github.com/google/trillian/log/admission"
 	"github.com/google/trillian/merkle/rfc6962"
+	"github.com/google/trillian/quota"
+	"github.com/google/trillian/server/interceptor"
 	"github.com/google/trillian/storage"
 	"github.com/google/trillian/testonly"
 	"github.com/google/trillian/types"
 	"github.com/google/trillian/util/clock"
 	"google.golang.org/genproto/googleapis/rpc/code"
+	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
 
@@ -527,6 +533,48 @@ func TestQueueLeaves(t *testing.T) {
 	}
 }
 
+func TestQueueLeavesRejected(t *testing.T) {
+	ctx := context.Background()
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockStorage := storage.NewMockLogStorage(ctrl)
+	mockStorage.EXPECT().QueueLeaves(gomock.Any(), cmpMatcher{tree1}, cmpMatcher{[]*trillian.LogLeaf{leaf1, leaf3}}, fakeTime).
+		Return([]*trillian.QueuedLogLeaf{okQueuedLeaf(leaf1), dupeQueuedLeaf(leaf3)}, nil)
+
+	rejectLeaf2 := admission.ValidatorFunc(func(_ context.Context, _ *trillian.Tree, leaf *trillian.LogLeaf) error {
+		if bytes.Equal(leaf.LeafValue, leaf2.LeafValue) {
+			return errors.New("rejected")
+		}
+		return nil
+	})
+	registry := extension.Registry{
+		AdminStorage:  fakeAdminStorage(ctrl, storageParams{treeID: logID1, numSnapshots: 2}),
+		LogStorage:    mockStorage,
+		LeafValidator: rejectLeaf2,
+	}
+	server := NewTrillianLogRPCServer(registry, fakeTimeSource)
+
+	req := &trillian.QueueLeavesRequest{LogId: logID1, Leaves: []*trillian.LogLeaf{leaf1, leaf2, leaf3, leaf2}}
+	rsp, err := server.QueueLeaves(ctx, req)
+	if err != nil {
+		t.Fatalf("QueueLeaves(): %v", err)
+	}
+	var got []codes.Code
+	for _, l := range rsp.QueuedLeaves {
+		got = append(got, codes.Code(l.Status.GetCode()))
+	}
+	if want := []codes.Code{codes.OK, codes.InvalidArgument, codes.AlreadyExists, codes.InvalidArgument}; !cmp.Equal(got, want) {
+		t.Errorf("QueueLeaves() returned statuses %v, want %v", got, want)
+	}
+
+	// No leaves are stored if all are rejected.
+	req = &trillian.QueueLeavesRequest{LogId: logID1, Leaves: []*trillian.LogLeaf{leaf2}}
+	if rsp, err := server.QueueLeaves(ctx, req); err != nil || len(rsp.QueuedLeaves) != 1 {
+		t.Errorf("QueueLeaves(all rejected) = %v, %v; want 1 leaf", rsp, err)
+	}
+}
+
 func TestAddSequencedLeavesStorageError(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
@@ -590,6 +638,261 @@ func TestAddSequencedLeaves(t *testing.T) {
 	}
 }
 
+// fakeAddSequencedLeavesStream is a TrillianLog_AddSequencedLeavesStreamServer
+// which receives a fixed list of requests, and records the responses sent.
+type fakeAddSequencedLeavesStream struct {
+	grpc.ServerStream
+	ctx   context.Context
+	reqs  []*trillian.AddSequencedLeavesRequest
+	resps []*trillian.AddSequencedLeavesStreamResponse
+}
+
+func (f *fakeAddSequencedLeavesStream) Context() context.Context {
+	if f.ctx != nil {
+		return f.ctx
+	}
+	return context.Background()
+}
+
+func (f *fakeAddSequencedLeavesStream) Recv() (*trillian.AddSequencedLeavesRequest, error) {
+	if len(f.reqs) == 0 {
+		return nil, io.EOF
+	}
+	req := f.reqs[0]
+	f.reqs = f.reqs[1:]
+	return req, nil
+}
+
+func (f *fakeAddSequencedLeavesStream) Send(resp *trillian.AddSequencedLeavesStreamResponse) error {
+	f.resps = append(f.resps, resp)
+	return nil
+}
+
+// chunks returns the chunks of the responses sent, and fails the test unless
+// each response holds one chunk.
+func (f *fakeAddSequencedLeavesStream) chunks(t *testing.T) []*trillian.SequencedLeavesChunk {
+	t.Helper()
+	var chunks []*trillian.SequencedLeavesChunk
+	for _, resp := range f.resps {
+		if len(resp.Chunks) != 1 {
+			t.Errorf("response has %d chunks, want 1", len(resp.Chunks))
+		}
+		chunks = append(chunks, resp.Chunks...)
+	}
+	return chunks
+}
+
+// addSeqRequests returns requests for the leaves [start, end) of logID, with
+// up to n leaves each.
+func addSeqRequests(logID, start, end int64, n int) []*trillian.AddSequencedLeavesRequest {
+	var reqs []*trillian.AddSequencedLeavesRequest
+	for i := start; i < end; i++ {
+		if (i-start)%int64(n) == 0 {
+			reqs = append(reqs, &trillian.AddSequencedLeavesRequest{LogId: logID})
+		}
+		req := reqs[len(reqs)-1]
+		req.Leaves = append(req.Leaves, &trillian.LogLeaf{LeafValue: []byte(fmt.Sprint(i)), LeafIndex: i})
+	}
+	return reqs
+}
+
+func TestAddSequencedLeavesStream(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	const size = sequencedLeavesChunkSize + 10
+	const dupIndex = sequencedLeavesChunkSize + 3
+	mockStorage := storage.NewMockLogStorage(ctrl)
+	var starts []int64
+	mockStorage.EXPECT().AddSequencedLeaves(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Times(2).DoAndReturn(
+		func(_ context.Context, _ *trillian.Tree, leaves []*trillian.LogLeaf, _ time.Time) ([]*trillian.QueuedLogLeaf, error) {
+			starts = append(starts, leaves[0].LeafIndex)
+			ret := make([]*trillian.QueuedLogLeaf, len(leaves))
+			for i, l := range leaves {
+				if l.MerkleLeafHash == nil {
+					return nil, fmt.Errorf("leaf %d has no MerkleLeafHash", l.LeafIndex)
+				}
+				ret[i] = &trillian.QueuedLogLeaf{Status: status.New(codes.OK, "OK").Proto()}
+				if l.LeafIndex == dupIndex {
+					ret[i].Status = status.New(codes.FailedPrecondition, "conflicting LeafIndex").Proto()
+				}
+			}
+			return ret, nil
+		})
+
+	registry := extension.Registry{
+		AdminStorage: fakeAdminStorage(ctrl, storageParams{logID3, true, 1, nil, nil, false}),
+		LogStorage:   mockStorage,
+	}
+	server := NewTrillianLogRPCServer(registry, fakeTimeSource)
+
+	stream := &fakeAddSequencedLeavesStream{reqs: addSeqRequests(logID3, 0, size, 300)}
+	if err := server.AddSequencedLeavesStream(stream); err != nil {
+		t.Fatalf("AddSequencedLeavesStream(): %v", err)
+	}
+	if got, want := starts, []int64{0, sequencedLeavesChunkSize}; !cmp.Equal(got, want) {
+		t.Errorf("AddSequencedLeaves() called for leaves from %v, want %v", got, want)
+	}
+	want := []*trillian.SequencedLeavesChunk{
+		{StartIndex: 0, LeafCount: sequencedLeavesChunkSize, Status: status.New(codes.OK, "").Proto()},
+		{StartIndex: sequencedLeavesChunkSize, LeafCount: 10, SkippedCount: 1, Status: status.New(codes.FailedPrecondition, "conflicting LeafIndex").Proto()},
+	}
+	if got := stream.chunks(t); !cmp.Equal(got, want, cmp.Comparer(proto.Equal)) {
+		t.Errorf("AddSequencedLeavesStream() sent %v, want %v", got, want)
+	}
+}
+
+func TestAddSequencedLeavesStreamQuota(t *testing.T) {
+	const size = sequencedLeavesChunkSize + 10
+	for _, tc := range []struct {
+		desc     string
+		denied   bool
+		wantCode codes.Code
+	}{
+		{desc: "charged"},
+		{desc: "denied", denied: true, wantCode: codes.ResourceExhausted},
+	} {
+		t.Run(tc.desc, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockStorage := storage.NewMockLogStorage(ctrl)
+			mockStorage.EXPECT().AddSequencedLeaves(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).AnyTimes().DoAndReturn(
+				func(_ context.Context, _ *trillian.Tree, leaves []*trillian.LogLeaf, _ time.Time) ([]*trillian.QueuedLogLeaf, error) {
+					ret := make([]*trillian.QueuedLogLeaf, len(leaves))
+					for i := range ret {
+						ret[i] = &trillian.QueuedLogLeaf{Status: status.New(codes.OK, "OK").Proto()}
+					}
+					return ret, nil
+				})
+			qm := quota.NewMockManager(ctrl)
+			var tokens []int
+			qm.EXPECT().GetTokens(gomock.Any(), gomock.Any(), gomock.Any()).AnyTimes().DoAndReturn(
+				func(_ context.Context, n int, _ []quota.Spec) error {
+					tokens = append(tokens, n)
+					if tc.denied {
+						return errors.New("no tokens")
+					}
+					return nil
+				})
+
+			admin := fakeAdminStorage(ctrl, storageParams{logID3, true, 3, nil, nil, false})
+			registry := extension.Registry{
+				AdminStorage: admin,
+				LogStorage:   mockStorage,
+				QuotaManager: qm,
+			}
+			server := NewTrillianLogRPCServer(registry, fakeTimeSource)
+
+			// Run the handler behind the stream interceptor, as a gRPC server does.
+			ti := interceptor.New(admin, qm, false /* quotaDryRun */, nil)
+			stream := &fakeAddSequencedLeavesStream{reqs: addSeqRequests(logID3, 0, size, 300)}
+			err := ti.StreamInterceptor(server, stream, &grpc.StreamServerInfo{}, func(_ interface{}, ss grpc.ServerStream) error {
+				stream.ctx = ss.Context()
+				return server.AddSequencedLeavesStream(stream)
+			})
+			if got := status.Code(err); got != tc.wantCode {
+				t.Fatalf("AddSequencedLeavesStream(): %v, want code %v", err, tc.wantCode)
+			}
+			want := []int{sequencedLeavesChunkSize, 10}
+			if tc.denied {
+				want = want[:1]
+			}
+			if !cmp.Equal(tokens, want) {
+				t.Errorf("GetTokens() called for %v tokens, want %v", tokens, want)
+			}
+		})
+	}
+}
+
+func TestAddSequencedLeavesRejected(t *testing.T) {
+	const rejectIndex = sequencedLeavesChunkSize + 3
+	rejectLeaf := admission.ValidatorFunc(func(_ context.Context, _ *trillian.Tree, leaf *trillian.LogLeaf) error {
+		if leaf.LeafIndex == rejectIndex {
+			return status.Error(codes.PermissionDenied, "rejected")
+		}
+		return nil
+	})
+	newServer := func(ctrl *gomock.Controller, wantStores int) *TrillianLogRPCServer {
+		mockStorage := storage.NewMockLogStorage(ctrl)
+		mockStorage.EXPECT().AddSequencedLeaves(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Times(wantStores).DoAndReturn(
+			func(_ context.Context, _ *trillian.Tree, leaves []*trillian.LogLeaf, _ time.Time) ([]*trillian.QueuedLogLeaf, error) {
+				ret := make([]*trillian.QueuedLogLeaf, len(leaves))
+				for i := range ret {
+					ret[i] = &trillian.QueuedLogLeaf{Status: status.New(codes.OK, "OK").Proto()}
+				}
+				return ret, nil
+			})
+		registry := extension.Registry{
+			AdminStorage:  fakeAdminStorage(ctrl, storageParams{logID3, true, 1, nil, nil, false}),
+			LogStorage:    mockStorage,
+			LeafValidator: rejectLeaf,
+		}
+		return NewTrillianLogRPCServer(registry, fakeTimeSource)
+	}
+
+	t.Run("unary", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		// None of the leaves are stored.
+		server := newServer(ctrl, 0)
+		req := addSeqRequests(logID3, rejectIndex-2, rejectIndex+2, 10)[0]
+		if _, err := server.AddSequencedLeaves(context.Background(), req); status.Code(err) != codes.PermissionDenied {
+			t.Errorf("AddSequencedLeaves(): %v, want code %v", err, codes.PermissionDenied)
+		}
+	})
+	t.Run("stream", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		// Only the chunk before the rejected leaf is stored, and its result
+		// is sent before the stream fails.
+		server := newServer(ctrl, 1)
+		stream := &fakeAddSequencedLeavesStream{reqs: addSeqRequests(logID3, 0, rejectIndex+10, 300)}
+		if err := server.AddSequencedLeavesStream(stream); status.Code(err) != codes.PermissionDenied {
+			t.Errorf("AddSequencedLeavesStream(): %v, want code %v", err, codes.PermissionDenied)
+		}
+		want := []*trillian.SequencedLeavesChunk{
+			{StartIndex: 0, LeafCount: sequencedLeavesChunkSize, Status: status.New(codes.OK, "").Proto()},
+		}
+		if got := stream.chunks(t); !cmp.Equal(got, want, cmp.Comparer(proto.Equal)) {
+			t.Errorf("AddSequencedLeavesStream() sent %v, want %v", got, want)
+		}
+	})
+}
+
+func TestAddSequencedLeavesStreamErrors(t *testing.T) {
+	gap := addSeqRequests(logID3, 0, 10, 5)
+	gap[1].Leaves[0].LeafIndex++
+	otherLog := addSeqRequests(logID3, 0, 10, 5)
+	otherLog[1].LogId = logID1
+
+	for _, tc := range []struct {
+		desc     string
+		reqs     []*trillian.AddSequencedLeavesRequest
+		wantCode codes.Code
+	}{
+		{desc: "empty", wantCode: codes.InvalidArgument},
+		{desc: "gap", reqs: gap, wantCode: codes.FailedPrecondition},
+		{desc: "other-log", reqs: otherLog, wantCode: codes.InvalidArgument},
+		{desc: "no-leaves", reqs: []*trillian.AddSequencedLeavesRequest{{LogId: logID3}}, wantCode: codes.InvalidArgument},
+	} {
+		t.Run(tc.desc, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			registry := extension.Registry{
+				AdminStorage: fakeAdminStorage(ctrl, storageParams{logID3, true, 1, nil, nil, false}),
+				LogStorage:   storage.NewMockLogStorage(ctrl),
+			}
+			server := NewTrillianLogRPCServer(registry, fakeTimeSource)
+
+			err := server.AddSequencedLeavesStream(&fakeAddSequencedLeavesStream{reqs: tc.reqs})
+			if got := status.Code(err); got != tc.wantCode {
+				t.Errorf("AddSequencedLeavesStream(): %v, want code %v", err, tc.wantCode)
+			}
+		})
+	}
+}
+
 type latestRootTest struct {
 	desc        string
 	req         *trillian.GetLatestSignedLogRootRequest
@@ -0,0 +1,367 @@
+// Copyright 2020 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sharding
+
+import (
+	"context"
+	"fmt"
+	"io"
+
+	"github.com/google/trillian"
+	"github.com/google/trillian/sharding/shardingpb"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// shardedLog is a ShardedLog with its shards ordered by time.
+type shardedLog struct {
+	id     int64
+	shards []*shard
+}
+
+// Router is a TrillianLog server which forwards requests for sharded logs to
+// the trees holding their shards.
+//
+// Leaves queued with the ID of a sharded log go to the shard covering their
+// timestamp. Leaves queued with the tree ID of a shard go to that shard, and
+// are rejected if their timestamp is outside of its time range. All other
+// requests must name a shard by its tree ID, as tree sizes and leaf indices
+// are per shard. Clients can find the shards with ShardAdmin.ListShards.
+type Router struct {
+	backend  trillian.TrillianLogClient
+	leafTime LeafTimeFunc
+	logs     map[int64]*shardedLog
+	shards   map[int64]*shard
+}
+
+// NewRouter returns a Router for the sharded logs in cfg, which forwards
+// requests to backend. The leafTime function picks the shard of queued leaves.
+func NewRouter(cfg *shardingpb.RouterConfig, backend trillian.TrillianLogClient, leafTime LeafTimeFunc) (*Router, error) {
+	r := &Router{
+		backend:  backend,
+		leafTime: leafTime,
+		logs:     make(map[int64]*shardedLog),
+		shards:   make(map[int64]*shard),
+	}
+	for _, l := range cfg.GetLogs() {
+		if _, ok := r.logs[l.LogId]; ok {
+			return nil, fmt.Errorf("duplicate log ID %d", l.LogId)
+		}
+		shards, err := parseShards(l.Shards)
+		if err != nil {
+			return nil, fmt.Errorf("log %d: %v", l.LogId, err)
+		}
+		for _, s := range shards {
+			if _, ok := r.shards[s.pb.TreeId]; ok {
+				return nil, fmt.Errorf("log %d: shard %d is in more than one log", l.LogId, s.pb.TreeId)
+			}
+			r.shards[s.pb.TreeId] = s
+		}
+		r.logs[l.LogId] = &shardedLog{id: l.LogId, shards: shards}
+	}
+	for id := range r.logs {
+		if _, ok := r.shards[id]; ok {
+			return nil, fmt.Errorf("log ID %d is also the tree ID of a shard", id)
+		}
+	}
+	return r, nil
+}
+
+// route returns the tree ID of the shard that the leaf queued to logID goes to.
+func (r *Router) route(logID int64, leaf *trillian.LogLeaf) (int64, error) {
+	l, isLog := r.logs[logID]
+	s, isShard := r.shards[logID]
+	if !isLog && !isShard {
+		return 0, status.Errorf(codes.NotFound, "log %d is not served by this router", logID)
+	}
+	t, err := r.leafTime(leaf)
+	if err != nil {
+		return 0, status.Errorf(codes.InvalidArgument, "no timestamp for leaf: %v", err)
+	}
+	if isShard {
+		if !s.contains(t) {
+			return 0, status.Errorf(codes.OutOfRange, "leaf timestamp %v is outside of shard %d", t, logID)
+		}
+		return logID, nil
+	}
+	for _, s := range l.shards {
+		if s.contains(t) {
+			return s.pb.TreeId, nil
+		}
+	}
+	return 0, status.Errorf(codes.OutOfRange, "log %d has no shard for leaf timestamp %v", logID, t)
+}
+
+// checkShard returns an error unless logID is the tree ID of a shard.
+func (r *Router) checkShard(logID int64) error {
+	if _, ok := r.shards[logID]; ok {
+		return nil
+	}
+	if _, ok := r.logs[logID]; ok {
+		return status.Errorf(codes.InvalidArgument, "log %d is sharded, requests other than queueing leaves must use the tree ID of a shard", logID)
+	}
+	return status.Errorf(codes.NotFound, "log %d is not served by this router", logID)
+}
+
+// checkLeaves returns an error unless logID is the tree ID of a shard which
+// covers the timestamps of all the leaves.
+func (r *Router) checkLeaves(logID int64, leaves []*trillian.LogLeaf) error {
+	if err := r.checkShard(logID); err != nil {
+		return err
+	}
+	for _, leaf := range leaves {
+		if _, err := r.route(logID, leaf); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// QueueLeaf forwards the request to the shard for the leaf.
+func (r *Router) QueueLeaf(ctx context.Context, req *trillian.QueueLeafRequest) (*trillian.QueueLeafResponse, error) {
+	treeID, err := r.route(req.LogId, req.Leaf)
+	if err != nil {
+		return nil, err
+	}
+	return r.backend.QueueLeaf(ctx, &trillian.QueueLeafRequest{LogId: treeID, Leaf: req.Leaf, ChargeTo: req.ChargeTo})
+}
+
+// QueueLeaves splits the leaves by shard, and forwards a request to each of
+// the shards. If a request fails, the leaves sent to other shards may have
+// been queued anyway.
+func (r *Router) QueueLeaves(ctx context.Context, req *trillian.QueueLeavesRequest) (*trillian.QueueLeavesResponse, error) {
+	if len(req.Leaves) == 0 {
+		return nil, status.Error(codes.InvalidArgument, "no leaves to queue")
+	}
+	// Group the indices of the leaves by shard, keeping the order of shards
+	// stable for the benefit of tests and logs.
+	var treeIDs []int64
+	indices := make(map[int64][]int)
+	for i, leaf := range req.Leaves {
+		treeID, err := r.route(req.LogId, leaf)
+		if err != nil {
+			return nil, err
+		}
+		if _, ok := indices[treeID]; !ok {
+			treeIDs = append(treeIDs, treeID)
+		}
+		indices[treeID] = append(indices[treeID], i)
+	}
+
+	queued := make([]*trillian.QueuedLogLeaf, len(req.Leaves))
+	for _, treeID := range treeIDs {
+		idx := indices[treeID]
+		leaves := make([]*trillian.LogLeaf, 0, len(idx))
+		for _, i := range idx {
+			leaves = append(leaves, req.Leaves[i])
+		}
+		rsp, err := r.backend.QueueLeaves(ctx, &trillian.QueueLeavesRequest{LogId: treeID, Leaves: leaves, ChargeTo: req.ChargeTo})
+		if err != nil {
+			return nil, err
+		}
+		if got, want := len(rsp.QueuedLeaves), len(idx); got != want {
+			return nil, status.Errorf(codes.Internal, "shard %d returned %d queued leaves, want %d", treeID, got, want)
+		}
+		for j, i := range idx {
+			queued[i] = rsp.QueuedLeaves[j]
+		}
+	}
+	return &trillian.QueueLeavesResponse{QueuedLeaves: queued}, nil
+}
+
+// AddSequencedLeaf forwards the request to the shard it names.
+func (r *Router) AddSequencedLeaf(ctx context.Context, req *trillian.AddSequencedLeafRequest) (*trillian.AddSequencedLeafResponse, error) {
+	if err := r.checkLeaves(req.LogId, []*trillian.LogLeaf{req.Leaf}); err != nil {
+		return nil, err
+	}
+	return r.backend.AddSequencedLeaf(ctx, req)
+}
+
+// AddSequencedLeaves forwards the request to the shard it names.
+func (r *Router) AddSequencedLeaves(ctx context.Context, req *trillian.AddSequencedLeavesRequest) (*trillian.AddSequencedLeavesResponse, error) {
+	if err := r.checkLeaves(req.LogId, req.Leaves); err != nil {
+		return nil, err
+	}
+	return r.backend.AddSequencedLeaves(ctx, req)
+}
+
+// AddSequencedLeavesStream forwards the stream to the shard named by its
+// requests, which must all name the same shard. The responses of the shard are
+// forwarded as it sends them.
+func (r *Router) AddSequencedLeavesStream(stream trillian.TrillianLog_AddSequencedLeavesStreamServer) error {
+	ctx, cancel := context.WithCancel(stream.Context())
+	defer cancel()
+	req, err := stream.Recv()
+	if err == io.EOF {
+		return status.Error(codes.InvalidArgument, "no leaves to add")
+	} else if err != nil {
+		return err
+	}
+	if err := r.checkLeaves(req.LogId, req.Leaves); err != nil {
+		return err
+	}
+	out, err := r.backend.AddSequencedLeavesStream(ctx)
+	if err != nil {
+		return err
+	}
+
+	reqErr := make(chan error, 1)
+	go func() {
+		err := r.forwardSequencedLeaves(stream, out, req)
+		reqErr <- err
+		if err != nil {
+			// Ends the stream to the shard, so that Recv returns.
+			cancel()
+		}
+	}()
+	for {
+		resp, err := out.Recv()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			select {
+			case err := <-reqErr:
+				if err != nil {
+					return err
+				}
+			default:
+			}
+			return err
+		}
+		if err := stream.Send(resp); err != nil {
+			return err
+		}
+	}
+	return <-reqErr
+}
+
+// forwardSequencedLeaves sends req and the following requests of stream to
+// out, checking that they name the same shard as req.
+func (r *Router) forwardSequencedLeaves(stream trillian.TrillianLog_AddSequencedLeavesStreamServer, out trillian.TrillianLog_AddSequencedLeavesStreamClient, req *trillian.AddSequencedLeavesRequest) error {
+	logID := req.LogId
+	for {
+		if err := out.Send(req); err == io.EOF {
+			// The shard has ended the stream, and Recv returns why.
+			return nil
+		} else if err != nil {
+			return err
+		}
+		var err error
+		if req, err = stream.Recv(); err == io.EOF {
+			return out.CloseSend()
+		} else if err != nil {
+			return err
+		}
+		if req.LogId != logID {
+			return status.Errorf(codes.InvalidArgument, "stream sends leaves to log %d and %d", logID, req.LogId)
+		}
+		if err := r.checkLeaves(req.LogId, req.Leaves); err != nil {
+			return err
+		}
+	}
+}
+
+// GetInclusionProof forwards the request to the shard it names.
+func (r *Router) GetInclusionProof(ctx context.Context, req *trillian.GetInclusionProofRequest) (*trillian.GetInclusionProofResponse, error) {
+	if err := r.checkShard(req.LogId); err != nil {
+		return nil, err
+	}
+	return r.backend.GetInclusionProof(ctx, req)
+}
+
+// GetInclusionProofByHash forwards the request to the shard it names.
+func (r *Router) GetInclusionProofByHash(ctx context.Context, req *trillian.GetInclusionProofByHashRequest) (*trillian.GetInclusionProofByHashResponse, error) {
+	if err := r.checkShard(req.LogId); err != nil {
+		return nil, err
+	}
+	return r.backend.GetInclusionProofByHash(ctx, req)
+}
+
+// GetConsistencyProof forwards the request to the shard it names.
+func (r *Router) GetConsistencyProof(ctx context.Context, req *trillian.GetConsistencyProofRequest) (*trillian.GetConsistencyProofResponse, error) {
+	if err := r.checkShard(req.LogId); err != nil {
+		return nil, err
+	}
+	return r.backend.GetConsistencyProof(ctx, req)
+}
+
+// GetLatestSignedLogRoot forwards the request to the shard it names.
+func (r *Router) GetLatestSignedLogRoot(ctx context.Context, req *trillian.GetLatestSignedLogRootRequest) (*trillian.GetLatestSignedLogRootResponse, error) {
+	if err := r.checkShard(req.LogId); err != nil {
+		return nil, err
+	}
+	return r.backend.GetLatestSignedLogRoot(ctx, req)
+}
+
+// GetSequencedLeafCount forwards the request to the shard it names.
+func (r *Router) GetSequencedLeafCount(ctx context.Context, req *trillian.GetSequencedLeafCountRequest) (*trillian.GetSequencedLeafCountResponse, error) {
+	if err := r.checkShard(req.LogId); err != nil {
+		return nil, err
+	}
+	return r.backend.GetSequencedLeafCount(ctx, req)
+}
+
+// GetEntryAndProof forwards the request to the shard it names.
+func (r *Router) GetEntryAndProof(ctx context.Context, req *trillian.GetEntryAndProofRequest) (*trillian.GetEntryAndProofResponse, error) {
+	if err := r.checkShard(req.LogId); err != nil {
+		return nil, err
+	}
+	return r.backend.GetEntryAndProof(ctx, req)
+}
+
+// InitLog forwards the request to the shard it names.
+func (r *Router) InitLog(ctx context.Context, req *trillian.InitLogRequest) (*trillian.InitLogResponse, error) {
+	if err := r.checkShard(req.LogId); err != nil {
+		return nil, err
+	}
+	return r.backend.InitLog(ctx, req)
+}
+
+// GetLeavesByIndex forwards the request to the shard it names.
+func (r *Router) GetLeavesByIndex(ctx context.Context, req *trillian.GetLeavesByIndexRequest) (*trillian.GetLeavesByIndexResponse, error) {
+	if err := r.checkShard(req.LogId); err != nil {
+		return nil, err
+	}
+	return r.backend.GetLeavesByIndex(ctx, req)
+}
+
+// GetLeavesByRange forwards the request to the shard it names.
+func (r *Router) GetLeavesByRange(ctx context.Context, req *trillian.GetLeavesByRangeRequest) (*trillian.GetLeavesByRangeResponse, error) {
+	if err := r.checkShard(req.LogId); err != nil {
+		return nil, err
+	}
+	return r.backend.GetLeavesByRange(ctx, req)
+}
+
+// GetLeavesByHash forwards the request to the shard it names.
+func (r *Router) GetLeavesByHash(ctx context.Context, req *trillian.GetLeavesByHashRequest) (*trillian.GetLeavesByHashResponse, error) {
+	if err := r.checkShard(req.LogId); err != nil {
+		return nil, err
+	}
+	return r.backend.GetLeavesByHash(ctx, req)
+}
+
+// ListShards implements shardingpb.ShardAdminServer.
+func (r *Router) ListShards(ctx context.Context, req *shardingpb.ListShardsRequest) (*shardingpb.ListShardsResponse, error) {
+	l, ok := r.logs[req.LogId]
+	if !ok {
+		return nil, status.Errorf(codes.NotFound, "log %d is not served by this router", req.LogId)
+	}
+	rsp := &shardingpb.ListShardsResponse{Shards: make([]*shardingpb.LogShard, 0, len(l.shards))}
+	for _, s := range l.shards {
+		rsp.Shards = append(rsp.Shards, s.pb)
+	}
+	return rsp, nil
+}
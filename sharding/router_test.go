@@ -0,0 +1,385 @@
+// Copyright 2020 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sharding
+
+import (
+	"context"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/golang/protobuf/proto" //nolint:staticcheck
+	"github.com/golang/protobuf/ptypes"
+	"github.com/golang/protobuf/ptypes/timestamp"
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/trillian"
+	"github.com/google/trillian/sharding/shardingpb"
+	"github.com/google/trillian/testonly"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	logID    = 100
+	shard19  = 2019
+	shard20  = 2020
+	unknown  = 1
+	otherLog = 200
+)
+
+func year(y int) time.Time {
+	return time.Date(y, 1, 1, 0, 0, 0, 0, time.UTC)
+}
+
+func ts(t time.Time) *timestamp.Timestamp {
+	pb, err := ptypes.TimestampProto(t)
+	if err != nil {
+		panic(err)
+	}
+	return pb
+}
+
+func leafAt(t time.Time, data string) *trillian.LogLeaf {
+	return &trillian.LogLeaf{LeafValue: []byte(data), QueueTimestamp: ts(t)}
+}
+
+// testConfig has a log with shards for 2019 and 2020, and 2020 being open ended.
+func testConfig() *shardingpb.RouterConfig {
+	return &shardingpb.RouterConfig{Logs: []*shardingpb.ShardedLog{{
+		LogId: logID,
+		Shards: []*shardingpb.LogShard{
+			{TreeId: shard20, NotBefore: ts(year(2020))},
+			{TreeId: shard19, NotBefore: ts(year(2019)), NotAfter: ts(year(2020))},
+		},
+	}}}
+}
+
+func TestNewRouter(t *testing.T) {
+	for _, tc := range []struct {
+		desc    string
+		logs    []*shardingpb.ShardedLog
+		wantErr bool
+	}{
+		{desc: "ok", logs: testConfig().Logs},
+		{desc: "empty"},
+		{
+			desc: "overlap",
+			logs: []*shardingpb.ShardedLog{{LogId: logID, Shards: []*shardingpb.LogShard{
+				{TreeId: shard19, NotBefore: ts(year(2019)), NotAfter: ts(year(2021))},
+				{TreeId: shard20, NotBefore: ts(year(2020))},
+			}}},
+			wantErr: true,
+		},
+		{
+			desc: "two-unbounded",
+			logs: []*shardingpb.ShardedLog{{LogId: logID, Shards: []*shardingpb.LogShard{
+				{TreeId: shard19, NotAfter: ts(year(2020))},
+				{TreeId: shard20},
+			}}},
+			wantErr: true,
+		},
+		{
+			desc: "empty-range",
+			logs: []*shardingpb.ShardedLog{{LogId: logID, Shards: []*shardingpb.LogShard{
+				{TreeId: shard19, NotBefore: ts(year(2020)), NotAfter: ts(year(2019))},
+			}}},
+			wantErr: true,
+		},
+		{
+			desc: "duplicate-log",
+			logs: []*shardingpb.ShardedLog{
+				{LogId: logID, Shards: []*shardingpb.LogShard{{TreeId: shard19}}},
+				{LogId: logID, Shards: []*shardingpb.LogShard{{TreeId: shard20}}},
+			},
+			wantErr: true,
+		},
+		{
+			desc: "shared-shard",
+			logs: []*shardingpb.ShardedLog{
+				{LogId: logID, Shards: []*shardingpb.LogShard{{TreeId: shard19}}},
+				{LogId: otherLog, Shards: []*shardingpb.LogShard{{TreeId: shard19}}},
+			},
+			wantErr: true,
+		},
+		{
+			desc: "log-is-shard",
+			logs: []*shardingpb.ShardedLog{
+				{LogId: logID, Shards: []*shardingpb.LogShard{{TreeId: shard19}}},
+				{LogId: shard19, Shards: []*shardingpb.LogShard{{TreeId: shard20}}},
+			},
+			wantErr: true,
+		},
+	} {
+		t.Run(tc.desc, func(t *testing.T) {
+			_, err := NewRouter(&shardingpb.RouterConfig{Logs: tc.logs}, nil, QueueTimestamp)
+			if gotErr := err != nil; gotErr != tc.wantErr {
+				t.Errorf("NewRouter(): %v, wantErr %v", err, tc.wantErr)
+			}
+		})
+	}
+}
+
+func TestShardFor(t *testing.T) {
+	shards := testConfig().Logs[0].Shards
+	for _, tc := range []struct {
+		t    time.Time
+		want int64
+	}{
+		{t: year(2018), want: 0},
+		{t: year(2019), want: shard19},
+		{t: year(2020).Add(-time.Nanosecond), want: shard19},
+		{t: year(2020), want: shard20},
+		{t: year(2050), want: shard20},
+	} {
+		got, err := ShardFor(shards, tc.t)
+		if err != nil {
+			t.Fatalf("ShardFor(%v): %v", tc.t, err)
+		}
+		if got.GetTreeId() != tc.want {
+			t.Errorf("ShardFor(%v) = %d, want %d", tc.t, got.GetTreeId(), tc.want)
+		}
+	}
+}
+
+// setupRouter returns a Router which forwards requests to a mock log server.
+func setupRouter(t *testing.T, ctrl *gomock.Controller) (*Router, *testonly.MockServer) {
+	t.Helper()
+	s, stop, err := testonly.NewMockServer(ctrl)
+	if err != nil {
+		t.Fatalf("NewMockServer(): %v", err)
+	}
+	t.Cleanup(stop)
+	r, err := NewRouter(testConfig(), s.LogClient, QueueTimestamp)
+	if err != nil {
+		t.Fatalf("NewRouter(): %v", err)
+	}
+	return r, s
+}
+
+func TestQueueLeaf(t *testing.T) {
+	ctx := context.Background()
+	for _, tc := range []struct {
+		desc     string
+		logID    int64
+		leaf     *trillian.LogLeaf
+		wantTree int64
+		wantCode codes.Code
+	}{
+		{desc: "route-2019", logID: logID, leaf: leafAt(year(2019), "a"), wantTree: shard19},
+		{desc: "route-2020", logID: logID, leaf: leafAt(year(2021), "a"), wantTree: shard20},
+		{desc: "shard", logID: shard19, leaf: leafAt(year(2019), "a"), wantTree: shard19},
+		{desc: "shard-out-of-range", logID: shard19, leaf: leafAt(year(2020), "a"), wantCode: codes.OutOfRange},
+		{desc: "no-shard", logID: logID, leaf: leafAt(year(2018), "a"), wantCode: codes.OutOfRange},
+		{desc: "no-timestamp", logID: logID, leaf: &trillian.LogLeaf{}, wantCode: codes.InvalidArgument},
+		{desc: "unknown-log", logID: unknown, leaf: leafAt(year(2019), "a"), wantCode: codes.NotFound},
+	} {
+		t.Run(tc.desc, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			r, s := setupRouter(t, ctrl)
+
+			if tc.wantCode == codes.OK {
+				s.Log.EXPECT().QueueLeaf(gomock.Any(), gomock.Any()).DoAndReturn(
+					func(_ context.Context, req *trillian.QueueLeafRequest) (*trillian.QueueLeafResponse, error) {
+						if req.LogId != tc.wantTree {
+							t.Errorf("QueueLeaf() forwarded to %d, want %d", req.LogId, tc.wantTree)
+						}
+						return &trillian.QueueLeafResponse{QueuedLeaf: &trillian.QueuedLogLeaf{Leaf: req.Leaf}}, nil
+					})
+			}
+			_, err := r.QueueLeaf(ctx, &trillian.QueueLeafRequest{LogId: tc.logID, Leaf: tc.leaf})
+			if got := status.Code(err); got != tc.wantCode {
+				t.Errorf("QueueLeaf(): %v, want code %v", err, tc.wantCode)
+			}
+		})
+	}
+}
+
+func TestQueueLeaves(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	r, s := setupRouter(t, ctrl)
+	ctx := context.Background()
+
+	leaves := []*trillian.LogLeaf{
+		leafAt(year(2020), "a"),
+		leafAt(year(2019), "b"),
+		leafAt(year(2021), "c"),
+	}
+	wantData := map[int64][]string{shard20: {"a", "c"}, shard19: {"b"}}
+	s.Log.EXPECT().QueueLeaves(gomock.Any(), gomock.Any()).Times(2).DoAndReturn(
+		func(_ context.Context, req *trillian.QueueLeavesRequest) (*trillian.QueueLeavesResponse, error) {
+			want := wantData[req.LogId]
+			if len(req.Leaves) != len(want) {
+				return nil, status.Errorf(codes.Internal, "QueueLeaves(%d) got %d leaves, want %d", req.LogId, len(req.Leaves), len(want))
+			}
+			rsp := &trillian.QueueLeavesResponse{}
+			for i, leaf := range req.Leaves {
+				if got := string(leaf.LeafValue); got != want[i] {
+					t.Errorf("QueueLeaves(%d) leaf %d = %q, want %q", req.LogId, i, got, want[i])
+				}
+				queued := proto.Clone(leaf).(*trillian.LogLeaf)
+				queued.MerkleLeafHash = []byte{byte(req.LogId)}
+				rsp.QueuedLeaves = append(rsp.QueuedLeaves, &trillian.QueuedLogLeaf{Leaf: queued})
+			}
+			return rsp, nil
+		})
+
+	rsp, err := r.QueueLeaves(ctx, &trillian.QueueLeavesRequest{LogId: logID, Leaves: leaves})
+	if err != nil {
+		t.Fatalf("QueueLeaves(): %v", err)
+	}
+	if got, want := len(rsp.QueuedLeaves), len(leaves); got != want {
+		t.Fatalf("QueueLeaves() returned %d leaves, want %d", got, want)
+	}
+	for i, want := range []string{"a", "b", "c"} {
+		if got := string(rsp.QueuedLeaves[i].Leaf.LeafValue); got != want {
+			t.Errorf("QueuedLeaves[%d] = %q, want %q", i, got, want)
+		}
+	}
+
+	if _, err := r.QueueLeaves(ctx, &trillian.QueueLeavesRequest{LogId: logID}); status.Code(err) != codes.InvalidArgument {
+		t.Errorf("QueueLeaves(no leaves): %v, want code %v", err, codes.InvalidArgument)
+	}
+	if _, err := r.QueueLeaves(ctx, &trillian.QueueLeavesRequest{LogId: logID, Leaves: []*trillian.LogLeaf{leafAt(year(2019), "a"), leafAt(year(2000), "b")}}); status.Code(err) != codes.OutOfRange {
+		t.Errorf("QueueLeaves(no shard): %v, want code %v", err, codes.OutOfRange)
+	}
+}
+
+func TestReadsNeedShard(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	r, s := setupRouter(t, ctrl)
+	ctx := context.Background()
+
+	s.Log.EXPECT().GetLatestSignedLogRoot(gomock.Any(), gomock.Any()).Return(&trillian.GetLatestSignedLogRootResponse{}, nil)
+	if _, err := r.GetLatestSignedLogRoot(ctx, &trillian.GetLatestSignedLogRootRequest{LogId: shard19}); err != nil {
+		t.Errorf("GetLatestSignedLogRoot(shard): %v", err)
+	}
+	if _, err := r.GetLatestSignedLogRoot(ctx, &trillian.GetLatestSignedLogRootRequest{LogId: logID}); status.Code(err) != codes.InvalidArgument {
+		t.Errorf("GetLatestSignedLogRoot(log): %v, want code %v", err, codes.InvalidArgument)
+	}
+	if _, err := r.GetLeavesByIndex(ctx, &trillian.GetLeavesByIndexRequest{LogId: unknown}); status.Code(err) != codes.NotFound {
+		t.Errorf("GetLeavesByIndex(unknown): %v, want code %v", err, codes.NotFound)
+	}
+	if _, err := r.AddSequencedLeaf(ctx, &trillian.AddSequencedLeafRequest{LogId: shard20, Leaf: leafAt(year(2019), "a")}); status.Code(err) != codes.OutOfRange {
+		t.Errorf("AddSequencedLeaf(out of range): %v, want code %v", err, codes.OutOfRange)
+	}
+}
+
+// fakeAddStream is a TrillianLog_AddSequencedLeavesStreamServer which receives
+// a fixed list of requests, and records the responses sent.
+type fakeAddStream struct {
+	grpc.ServerStream
+	reqs  []*trillian.AddSequencedLeavesRequest
+	resps []*trillian.AddSequencedLeavesStreamResponse
+}
+
+func (f *fakeAddStream) Context() context.Context { return context.Background() }
+
+func (f *fakeAddStream) Recv() (*trillian.AddSequencedLeavesRequest, error) {
+	if len(f.reqs) == 0 {
+		return nil, io.EOF
+	}
+	req := f.reqs[0]
+	f.reqs = f.reqs[1:]
+	return req, nil
+}
+
+func (f *fakeAddStream) Send(resp *trillian.AddSequencedLeavesStreamResponse) error {
+	f.resps = append(f.resps, resp)
+	return nil
+}
+
+func TestAddSequencedLeavesStream(t *testing.T) {
+	addReq := func(logID int64, t time.Time) *trillian.AddSequencedLeavesRequest {
+		return &trillian.AddSequencedLeavesRequest{LogId: logID, Leaves: []*trillian.LogLeaf{leafAt(t, "a")}}
+	}
+	for _, tc := range []struct {
+		desc     string
+		reqs     []*trillian.AddSequencedLeavesRequest
+		wantCode codes.Code
+	}{
+		{desc: "ok", reqs: []*trillian.AddSequencedLeavesRequest{addReq(shard19, year(2019)), addReq(shard19, year(2019))}},
+		{desc: "empty", wantCode: codes.InvalidArgument},
+		{desc: "sharded-log", reqs: []*trillian.AddSequencedLeavesRequest{addReq(logID, year(2019))}, wantCode: codes.InvalidArgument},
+		{desc: "out-of-range", reqs: []*trillian.AddSequencedLeavesRequest{addReq(shard19, year(2019)), addReq(shard19, year(2020))}, wantCode: codes.OutOfRange},
+		{desc: "two-shards", reqs: []*trillian.AddSequencedLeavesRequest{addReq(shard19, year(2019)), addReq(shard20, year(2020))}, wantCode: codes.InvalidArgument},
+	} {
+		t.Run(tc.desc, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			r, s := setupRouter(t, ctrl)
+
+			// The shard sends a response for each request, which the router
+			// forwards.
+			var want []*trillian.AddSequencedLeavesStreamResponse
+			s.Log.EXPECT().AddSequencedLeavesStream(gomock.Any()).MaxTimes(1).DoAndReturn(
+				func(stream trillian.TrillianLog_AddSequencedLeavesStreamServer) error {
+					for i := int64(0); ; i++ {
+						req, err := stream.Recv()
+						if err == io.EOF {
+							return nil
+						} else if err != nil {
+							return err
+						}
+						if req.LogId != shard19 {
+							return status.Errorf(codes.Internal, "forwarded to %d, want %d", req.LogId, shard19)
+						}
+						resp := &trillian.AddSequencedLeavesStreamResponse{Chunks: []*trillian.SequencedLeavesChunk{{StartIndex: i, LeafCount: 1}}}
+						want = append(want, resp)
+						if err := stream.Send(resp); err != nil {
+							return err
+						}
+					}
+				})
+
+			stream := &fakeAddStream{reqs: tc.reqs}
+			err := r.AddSequencedLeavesStream(stream)
+			if got := status.Code(err); got != tc.wantCode {
+				t.Fatalf("AddSequencedLeavesStream(): %v, want code %v", err, tc.wantCode)
+			}
+			if err == nil && !cmp.Equal(stream.resps, want, cmp.Comparer(proto.Equal)) {
+				t.Errorf("AddSequencedLeavesStream() sent %v, want %v", stream.resps, want)
+			}
+		})
+	}
+}
+
+func TestListShards(t *testing.T) {
+	r, err := NewRouter(testConfig(), nil, QueueTimestamp)
+	if err != nil {
+		t.Fatalf("NewRouter(): %v", err)
+	}
+	ctx := context.Background()
+
+	rsp, err := r.ListShards(ctx, &shardingpb.ListShardsRequest{LogId: logID})
+	if err != nil {
+		t.Fatalf("ListShards(): %v", err)
+	}
+	var got []int64
+	for _, s := range rsp.Shards {
+		got = append(got, s.TreeId)
+	}
+	if want := []int64{shard19, shard20}; len(got) != 2 || got[0] != want[0] || got[1] != want[1] {
+		t.Errorf("ListShards() = %v, want %v", got, want)
+	}
+
+	if _, err := r.ListShards(ctx, &shardingpb.ListShardsRequest{LogId: shard19}); status.Code(err) != codes.NotFound {
+		t.Errorf("ListShards(shard): %v, want code %v", err, codes.NotFound)
+	}
+}
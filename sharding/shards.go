@@ -0,0 +1,112 @@
+// Copyright 2020 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package sharding supports logs which are split into trees by leaf timestamp,
+// e.g. one tree per year. Each such tree is a shard of the log.
+package sharding
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/golang/protobuf/ptypes"
+	"github.com/google/trillian"
+	"github.com/google/trillian/sharding/shardingpb"
+)
+
+// LeafTimeFunc returns the timestamp which determines the shard of a leaf.
+type LeafTimeFunc func(*trillian.LogLeaf) (time.Time, error)
+
+// QueueTimestamp is a LeafTimeFunc which returns the queue_timestamp of the
+// leaf as set by the client. The log sets its own queue_timestamp when it
+// queues the leaf.
+func QueueTimestamp(leaf *trillian.LogLeaf) (time.Time, error) {
+	if leaf.GetQueueTimestamp() == nil {
+		return time.Time{}, errors.New("leaf has no queue_timestamp")
+	}
+	return ptypes.Timestamp(leaf.QueueTimestamp)
+}
+
+// shard is a LogShard with parsed time bounds. A zero bound means that the
+// shard is unbounded on that side.
+type shard struct {
+	pb                  *shardingpb.LogShard
+	notBefore, notAfter time.Time
+}
+
+func newShard(pb *shardingpb.LogShard) (*shard, error) {
+	s := &shard{pb: pb}
+	var err error
+	if pb.NotBefore != nil {
+		if s.notBefore, err = ptypes.Timestamp(pb.NotBefore); err != nil {
+			return nil, fmt.Errorf("shard %d: invalid not_before: %v", pb.TreeId, err)
+		}
+	}
+	if pb.NotAfter != nil {
+		if s.notAfter, err = ptypes.Timestamp(pb.NotAfter); err != nil {
+			return nil, fmt.Errorf("shard %d: invalid not_after: %v", pb.TreeId, err)
+		}
+	}
+	if !s.notBefore.IsZero() && !s.notAfter.IsZero() && !s.notBefore.Before(s.notAfter) {
+		return nil, fmt.Errorf("shard %d: empty time range [%v, %v)", pb.TreeId, s.notBefore, s.notAfter)
+	}
+	return s, nil
+}
+
+// contains returns whether the time range of the shard contains t.
+func (s *shard) contains(t time.Time) bool {
+	return (s.notBefore.IsZero() || !t.Before(s.notBefore)) &&
+		(s.notAfter.IsZero() || t.Before(s.notAfter))
+}
+
+// parseShards returns the shards ordered by time, and checks that their time
+// ranges don't overlap.
+func parseShards(pbs []*shardingpb.LogShard) ([]*shard, error) {
+	shards := make([]*shard, 0, len(pbs))
+	for _, pb := range pbs {
+		s, err := newShard(pb)
+		if err != nil {
+			return nil, err
+		}
+		shards = append(shards, s)
+	}
+	sort.Slice(shards, func(i, j int) bool {
+		// An unbounded lower bound is the zero time, which sorts first.
+		return shards[i].notBefore.Before(shards[j].notBefore)
+	})
+	for i := 1; i < len(shards); i++ {
+		prev, next := shards[i-1], shards[i]
+		if prev.notAfter.IsZero() || prev.notAfter.After(next.notBefore) || next.notBefore.IsZero() {
+			return nil, fmt.Errorf("shards %d and %d overlap", prev.pb.TreeId, next.pb.TreeId)
+		}
+	}
+	return shards, nil
+}
+
+// ShardFor returns the shard whose time range contains t, or nil if there is
+// none. It returns an error if the shards are invalid, e.g. overlap.
+func ShardFor(shards []*shardingpb.LogShard, t time.Time) (*shardingpb.LogShard, error) {
+	parsed, err := parseShards(shards)
+	if err != nil {
+		return nil, err
+	}
+	for _, s := range parsed {
+		if s.contains(t) {
+			return s.pb, nil
+		}
+	}
+	return nil, nil
+}
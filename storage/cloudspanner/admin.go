@@ -444,6 +444,9 @@ func newTreeInfo(tree *trillian.Tree, treeID int64, now time.Time) (*spannerpb.T
 		PublicKeyDer:          tree.GetPublicKey().GetDer(),
 		MaxRootDurationMillis: int64(maxRootDuration / time.Millisecond),
 	}
+	if err := setScheduledTransition(info, tree); err != nil {
+		return nil, err
+	}
 
 	switch tt := tree.TreeType; tt {
 	case trillian.TreeType_PREORDERED_LOG:
@@ -542,6 +545,9 @@ func (t *adminTX) UpdateTree(ctx context.Context, treeID int64, updateFunc func(
 	info.UpdateTimeNanos = now.UnixNano()
 	info.MaxRootDurationMillis = int64(maxRootDuration / time.Millisecond)
 	info.PrivateKey = tree.PrivateKey
+	if err := setScheduledTransition(info, tree); err != nil {
+		return nil, err
+	}
 
 	if err := t.updateTreeInfo(ctx, info); err != nil {
 		return nil, err
@@ -622,6 +628,7 @@ func (t *adminTX) HardDeleteTree(ctx context.Context, treeID int64) error {
 	return stx.BufferWrite([]*spanner.Mutation{
 		spanner.Delete("TreeRoots", spanner.Key{info.TreeId}),
 		spanner.Delete("TreeHeads", spanner.Key{info.TreeId}.AsPrefix()),
+		spanner.Delete("TreeFences", spanner.Key{info.TreeId}),
 		spanner.Delete("SubtreeData", spanner.Key{info.TreeId}.AsPrefix()),
 		spanner.Delete("LeafData", spanner.Key{info.TreeId}.AsPrefix()),
 		spanner.Delete("SequencedLeafData", spanner.Key{info.TreeId}.AsPrefix()),
@@ -727,9 +734,46 @@ func toTrillianTree(info *spannerpb.TreeInfo) (*trillian.Tree, error) {
 		}
 	}
 
+	if info.ScheduledTreeState != spannerpb.TreeState_UNKNOWN_TREE_STATE {
+		ts, ok := treeStateReverseMap[info.ScheduledTreeState]
+		if !ok {
+			return nil, status.Errorf(codes.Internal, "unexpected scheduled TreeState: %s", info.ScheduledTreeState)
+		}
+		transitionTime, err := ptypes.TimestampProto(time.Unix(0, info.ScheduledTimeNanos))
+		if err != nil {
+			return nil, status.Errorf(codes.Internal, "failed to convert scheduled transition time: %v", err)
+		}
+		tree.ScheduledTransition = &trillian.ScheduledTreeTransition{
+			TreeState:      ts,
+			TransitionTime: transitionTime,
+		}
+	}
+
 	return tree, nil
 }
 
+// setScheduledTransition copies the scheduled transition of tree, if any, to
+// info.
+func setScheduledTransition(info *spannerpb.TreeInfo, tree *trillian.Tree) error {
+	st := tree.ScheduledTransition
+	if st == nil {
+		info.ScheduledTreeState = spannerpb.TreeState_UNKNOWN_TREE_STATE
+		info.ScheduledTimeNanos = 0
+		return nil
+	}
+	ts, ok := treeStateMap[st.TreeState]
+	if !ok {
+		return status.Errorf(codes.InvalidArgument, "unexpected scheduled TreeState: %s", st.TreeState)
+	}
+	transitionTime, err := ptypes.Timestamp(st.TransitionTime)
+	if err != nil {
+		return status.Errorf(codes.InvalidArgument, "malformed scheduled transition time: %v", err)
+	}
+	info.ScheduledTreeState = ts
+	info.ScheduledTimeNanos = transitionTime.UnixNano()
+	return nil
+}
+
 // unmarshalSettings returns the message obtained from tree.StorageSettings.
 // If tree.StorageSettings is nil no unmarshaling will be attempted; instead the method will return
 // (nil, nil).
@@ -199,6 +199,7 @@ func (ls *logStorage) ReadWriteTransaction(ctx context.Context, tree *trillian.T
 		if err != nil && err != storage.ErrTreeNeedsInit {
 			return err
 		}
+		tx.fencingToken = storage.FencingTokenFromContext(ctx)
 		if err := f(ctx, tx); err != nil {
 			return err
 		}
@@ -436,6 +437,10 @@ type logTX struct {
 	// This is required to recover the primary key for the unsequenced entry in
 	// UpdateSequencedLeaves.
 	dequeued map[string]*QueuedEntry
+
+	// fencingToken is the token of the mastership the transaction is run
+	// under, or zero if it is not fenced.
+	fencingToken int64
 }
 
 func (tx *logTX) getLogStorageConfig() *spannerpb.LogStorageConfig {
@@ -525,9 +530,38 @@ func (tx *logTX) StoreSignedLogRoot(ctx context.Context, root *trillian.SignedLo
 	if !ok {
 		return ErrWrongTXType
 	}
+	if err := tx.updateFencingToken(ctx, stx); err != nil {
+		return err
+	}
 	return stx.BufferWrite([]*spanner.Mutation{m})
 }
 
+// updateFencingToken checks the fencing token of the transaction against the
+// latest one used to store a tree head, and records it if it is newer.
+func (tx *logTX) updateFencingToken(ctx context.Context, stx *spanner.ReadWriteTransaction) error {
+	var stored int64
+	row, err := stx.ReadRow(ctx, "TreeFences", spanner.Key{tx.treeID}, []string{"FencingToken"})
+	switch {
+	case spanner.ErrCode(err) == codes.NotFound:
+	case err != nil:
+		return err
+	default:
+		if err := row.Columns(&stored); err != nil {
+			return err
+		}
+	}
+	if err := storage.CheckFencingToken(tx.fencingToken, stored); err != nil {
+		return err
+	}
+	if tx.fencingToken > stored {
+		return stx.BufferWrite([]*spanner.Mutation{spanner.InsertOrUpdate(
+			"TreeFences",
+			[]string{"TreeID", "FencingToken"},
+			[]interface{}{tx.treeID, tx.fencingToken})})
+	}
+	return nil
+}
+
 func readLeaves(ctx context.Context, stx *spanner.ReadOnlyTransaction, logID int64, ids [][]byte, f func(*trillian.LogLeaf)) error {
 	leafTable := leafDataTbl
 	cols := []string{colLeafIdentityHash, colLeafValue, colExtraData, colQueueTimestampNanos}
@@ -27,31 +27,36 @@ emUgICAgICAgICAgICAgICAgSU5UNjQgTk9UIE5VTEwsCiAgUm9vdEhhc2ggICAgICAgICAgICAg
 ICAgQllURVMoMjU2KSBOT1QgTlVMTCwKICBSb290U2lnbmF0dXJlICAgICAgICAgICBCWVRFUygx
 MDI0KSBOT1QgTlVMTCwKICBUcmVlUmV2aXNpb24gICAgICAgICAgICBJTlQ2NCBOT1QgTlVMTCwK
 ICBUcmVlTWV0YWRhdGEgICAgICAgICAgICBCWVRFUygyMDk3MTUyKSwKKSBQUklNQVJZIEtFWShU
-cmVlSUQsIFRyZWVSZXZpc2lvbiBERVNDKTsKCkNSRUFURSBUQUJMRSBTdWJ0cmVlRGF0YSgKICBU
-cmVlSUQgICAgICBJTlQ2NCBOT1QgTlVMTCwKICBTdWJ0cmVlSUQgICBCWVRFUygyNTYpIE5PVCBO
-VUxMLAogIFJldmlzaW9uICAgIElOVDY0IE5PVCBOVUxMLAogIFN1YnRyZWUgICAgIEJZVEVTKE1B
-WCkgTk9UIE5VTEwKKSBQUklNQVJZIEtFWShUcmVlSUQsIFN1YnRyZWVJRCwgUmV2aXNpb24gREVT
-Qyk7CgpDUkVBVEUgVEFCTEUgTGVhZkRhdGEoCiAgVHJlZUlEICAgICAgICAgICAgICBJTlQ2NCBO
-T1QgTlVMTCwKICBMZWFmSWRlbnRpdHlIYXNoICAgIEJZVEVTKDI1NikgTk9UIE5VTEwsCiAgTGVh
-ZlZhbHVlICAgICAgICAgICBCWVRFUyhNQVgpIE5PVCBOVUxMLAogIEV4dHJhRGF0YSAgICAgICAg
-ICAgQllURVMoTUFYKSwKICBRdWV1ZVRpbWVzdGFtcE5hbm9zIElOVDY0IE5PVCBOVUxMLAopIFBS
-SU1BUlkgS0VZKFRyZWVJRCwgTGVhZklkZW50aXR5SGFzaCk7CgpDUkVBVEUgVEFCTEUgU2VxdWVu
-Y2VkTGVhZkRhdGEoCiAgVHJlZUlEICAgICAgICAgICAgICAgICAgSU5UNjQgTk9UIE5VTEwsCiAg
-U2VxdWVuY2VOdW1iZXIgICAgICAgICAgSU5UNjQgTk9UIE5VTEwsCiAgTGVhZklkZW50aXR5SGFz
-aCAgICAgICAgQllURVMoMjU2KSBOT1QgTlVMTCwKICBNZXJrbGVMZWFmSGFzaCAgICAgICAgICBC
-WVRFUygyNTYpIE5PVCBOVUxMLAogIEludGVncmF0ZVRpbWVzdGFtcE5hbm9zIElOVDY0IE5PVCBO
-VUxMLAopIFBSSU1BUlkgS0VZKFRyZWVJRCwgU2VxdWVuY2VOdW1iZXIpOwoKQ1JFQVRFIElOREVY
-IFNlcXVlbmNlQnlNZXJrbGVIYXNoCiAgT04gU2VxdWVuY2VkTGVhZkRhdGEoVHJlZUlELCBNZXJr
-bGVMZWFmSGFzaCkKICBTVE9SSU5HKExlYWZJZGVudGl0eUhhc2gpOwoKQ1JFQVRFIFRBQkxFIFVu
-c2VxdWVuY2VkKAogIFRyZWVJRCAgICAgICAgICAgICAgICAgSU5UNjQgTk9UIE5VTEwsCiAgQnVj
-a2V0ICAgICAgICAgICAgICAgICBJTlQ2NCBOT1QgTlVMTCwKICBRdWV1ZVRpbWVzdGFtcE5hbm9z
-ICAgIElOVDY0IE5PVCBOVUxMLAogIE1lcmtsZUxlYWZIYXNoICAgICAgICAgQllURVMoMjU2KSBO
-T1QgTlVMTCwKICBMZWFmSWRlbnRpdHlIYXNoICAgICAgIEJZVEVTKDI1NikgTk9UIE5VTEwsCikg
-UFJJTUFSWSBLRVkgKFRyZWVJRCwgQnVja2V0LCBRdWV1ZVRpbWVzdGFtcE5hbm9zLCBNZXJrbGVM
-ZWFmSGFzaCk7CgpDUkVBVEUgVEFCTEUgTWFwTGVhZkRhdGEoCiAgVHJlZUlEICAgICAgICAgICAg
-ICAgIElOVDY0IE5PVCBOVUxMLAogIExlYWZJbmRleCAgICAgICAgICAgICBCWVRFUygyNTYpIE5P
-VCBOVUxMLAogIE1hcFJldmlzaW9uICAgICAgICAgICBJTlQ2NCBOT1QgTlVMTCwKICBMZWFmSGFz
-aCAgICAgICAgICAgICAgQllURVMoMjU2KSwKICBMZWFmVmFsdWUgICAgICAgICAgICAgQllURVMo
-TUFYKSBOT1QgTlVMTCwKICBFeHRyYURhdGEgICAgICAgICAgICAgQllURVMoTUFYKSwKKSBQUklN
-QVJZIEtFWShUcmVlSUQsIExlYWZJbmRleCwgTWFwUmV2aXNpb24gREVTQyk7Cg==
+cmVlSUQsIFRyZWVSZXZpc2lvbiBERVNDKTsKCi0tIFRoZSBsYXRlc3QgZmVuY2luZyB0b2tlbiB1
+c2VkIHRvIHdyaXRlIGEgVHJlZUhlYWQsIHdoaWNoIHN0b3BzIGEgc2lnbmVyIHRoYXQKLS0gaGFz
+IGxvc3QgbWFzdGVyc2hpcCBmcm9tIHdyaXRpbmcgYSBUcmVlSGVhZCBhZnRlciBhIG5ld2VyIG1h
+c3RlciBkaWQuCkNSRUFURSBUQUJMRSBUcmVlRmVuY2VzKAogIFRyZWVJRCAgICAgICAgICAgICAg
+ICAgIElOVDY0IE5PVCBOVUxMLAogIEZlbmNpbmdUb2tlbiAgICAgICAgICAgIElOVDY0IE5PVCBO
+VUxMLAopIFBSSU1BUlkgS0VZKFRyZWVJRCk7CgpDUkVBVEUgVEFCTEUgU3VidHJlZURhdGEoCiAg
+VHJlZUlEICAgICAgSU5UNjQgTk9UIE5VTEwsCiAgU3VidHJlZUlEICAgQllURVMoMjU2KSBOT1Qg
+TlVMTCwKICBSZXZpc2lvbiAgICBJTlQ2NCBOT1QgTlVMTCwKICBTdWJ0cmVlICAgICBCWVRFUyhN
+QVgpIE5PVCBOVUxMCikgUFJJTUFSWSBLRVkoVHJlZUlELCBTdWJ0cmVlSUQsIFJldmlzaW9uIERF
+U0MpOwoKQ1JFQVRFIFRBQkxFIExlYWZEYXRhKAogIFRyZWVJRCAgICAgICAgICAgICAgSU5UNjQg
+Tk9UIE5VTEwsCiAgTGVhZklkZW50aXR5SGFzaCAgICBCWVRFUygyNTYpIE5PVCBOVUxMLAogIExl
+YWZWYWx1ZSAgICAgICAgICAgQllURVMoTUFYKSBOT1QgTlVMTCwKICBFeHRyYURhdGEgICAgICAg
+ICAgIEJZVEVTKE1BWCksCiAgUXVldWVUaW1lc3RhbXBOYW5vcyBJTlQ2NCBOT1QgTlVMTCwKKSBQ
+UklNQVJZIEtFWShUcmVlSUQsIExlYWZJZGVudGl0eUhhc2gpOwoKQ1JFQVRFIFRBQkxFIFNlcXVl
+bmNlZExlYWZEYXRhKAogIFRyZWVJRCAgICAgICAgICAgICAgICAgIElOVDY0IE5PVCBOVUxMLAog
+IFNlcXVlbmNlTnVtYmVyICAgICAgICAgIElOVDY0IE5PVCBOVUxMLAogIExlYWZJZGVudGl0eUhh
+c2ggICAgICAgIEJZVEVTKDI1NikgTk9UIE5VTEwsCiAgTWVya2xlTGVhZkhhc2ggICAgICAgICAg
+QllURVMoMjU2KSBOT1QgTlVMTCwKICBJbnRlZ3JhdGVUaW1lc3RhbXBOYW5vcyBJTlQ2NCBOT1Qg
+TlVMTCwKKSBQUklNQVJZIEtFWShUcmVlSUQsIFNlcXVlbmNlTnVtYmVyKTsKCkNSRUFURSBJTkRF
+WCBTZXF1ZW5jZUJ5TWVya2xlSGFzaAogIE9OIFNlcXVlbmNlZExlYWZEYXRhKFRyZWVJRCwgTWVy
+a2xlTGVhZkhhc2gpCiAgU1RPUklORyhMZWFmSWRlbnRpdHlIYXNoKTsKCkNSRUFURSBUQUJMRSBV
+bnNlcXVlbmNlZCgKICBUcmVlSUQgICAgICAgICAgICAgICAgIElOVDY0IE5PVCBOVUxMLAogIEJ1
+Y2tldCAgICAgICAgICAgICAgICAgSU5UNjQgTk9UIE5VTEwsCiAgUXVldWVUaW1lc3RhbXBOYW5v
+cyAgICBJTlQ2NCBOT1QgTlVMTCwKICBNZXJrbGVMZWFmSGFzaCAgICAgICAgIEJZVEVTKDI1Nikg
+Tk9UIE5VTEwsCiAgTGVhZklkZW50aXR5SGFzaCAgICAgICBCWVRFUygyNTYpIE5PVCBOVUxMLAop
+IFBSSU1BUlkgS0VZIChUcmVlSUQsIEJ1Y2tldCwgUXVldWVUaW1lc3RhbXBOYW5vcywgTWVya2xl
+TGVhZkhhc2gpOwoKQ1JFQVRFIFRBQkxFIE1hcExlYWZEYXRhKAogIFRyZWVJRCAgICAgICAgICAg
+ICAgICBJTlQ2NCBOT1QgTlVMTCwKICBMZWFmSW5kZXggICAgICAgICAgICAgQllURVMoMjU2KSBO
+T1QgTlVMTCwKICBNYXBSZXZpc2lvbiAgICAgICAgICAgSU5UNjQgTk9UIE5VTEwsCiAgTGVhZkhh
+c2ggICAgICAgICAgICAgIEJZVEVTKDI1NiksCiAgTGVhZlZhbHVlICAgICAgICAgICAgIEJZVEVT
+KE1BWCkgTk9UIE5VTEwsCiAgRXh0cmFEYXRhICAgICAgICAgICAgIEJZVEVTKE1BWCksCikgUFJJ
+TUFSWSBLRVkoVHJlZUlELCBMZWFmSW5kZXgsIE1hcFJldmlzaW9uIERFU0MpOwo=
 `
@@ -0,0 +1,58 @@
+// Copyright 2020 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package storage
+
+import (
+	"context"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// ErrStaleFencingToken is returned when a transaction tries to write a tree
+// head with a fencing token lower than that of a tree head written before.
+var ErrStaleFencingToken = status.Error(codes.FailedPrecondition, "tree head written with a newer fencing token")
+
+type fencingTokenKey struct{}
+
+// WithFencingToken returns a context carrying the fencing token of the
+// mastership that the caller holds for a tree, e.g. as issued by
+// election2.Election. LogStorage.ReadWriteTransaction passes the token to the
+// transaction, which then refuses to store a tree head if one has been stored
+// with a newer token, so that a writer which has lost mastership can't
+// overwrite the work of the new master. A zero token is only accepted for trees
+// which have never been written with a token.
+func WithFencingToken(ctx context.Context, token int64) context.Context {
+	return context.WithValue(ctx, fencingTokenKey{}, token)
+}
+
+// FencingTokenFromContext returns the fencing token carried by the context, or
+// zero if there is none.
+func FencingTokenFromContext(ctx context.Context) int64 {
+	token, _ := ctx.Value(fencingTokenKey{}).(int64)
+	return token
+}
+
+// CheckFencingToken returns ErrStaleFencingToken if a tree head has been stored
+// with a newer token than the passed in one, where stored is the latest token
+// which has been used for the tree, or zero if none. Once a token has been
+// stored, writes without a token are rejected too. It is a helper for storage
+// implementations.
+func CheckFencingToken(token, stored int64) error {
+	if token < stored {
+		return ErrStaleFencingToken
+	}
+	return nil
+}
@@ -136,6 +136,9 @@ type LogStorage interface {
 	// calls f with it.
 	// If f fails and returns an error, the storage implementation may optionally
 	// retry with a new transaction, and f MUST NOT keep state across calls.
+	// If ctx carries a fencing token (see WithFencingToken), the transaction
+	// fails with ErrStaleFencingToken on storing a tree head if a newer token
+	// has been used to store a tree head before.
 	ReadWriteTransaction(ctx context.Context, tree *trillian.Tree, f LogTXFunc) error
 
 	// QueueLeaves enqueues leaves for later integration into the tree.
@@ -179,6 +182,14 @@ type LogStorage interface {
 // CountByLogID is a map of total number of items keyed by log ID.
 type CountByLogID map[int64]int64
 
+// UnsequencedCounter is implemented by the ReadOnlyLogTX of storage systems
+// which can count the leaves queued for sequencing in each log. It is kept
+// out of LogMetadata because counting may need to scan the whole queue.
+type UnsequencedCounter interface {
+	// GetUnsequencedCounts returns the number of queued leaves per log.
+	GetUnsequencedCounts(ctx context.Context) (CountByLogID, error)
+}
+
 // LogMetadata provides access to information about the logs in storage
 type LogMetadata interface {
 	// GetActiveLogIDs returns a list of the IDs of all the logs that are
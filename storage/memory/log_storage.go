@@ -79,6 +79,13 @@ func sthKey(treeID int64, timestamp uint64) btree.Item {
 	return &kv{k: fmt.Sprintf("/%d/sth/%020d", treeID, timestamp)}
 }
 
+// fencingTokenKey formats a key for use in a tree's BTree store.
+// The associated Item value will be the latest fencing token used to store
+// an STH.
+func fencingTokenKey(treeID int64) btree.Item {
+	return &kv{k: fmt.Sprintf("/%d/fence", treeID)}
+}
+
 // getActiveLogIDs returns the IDs of all logs that are currently in a state
 // that requires sequencing (e.g. ACTIVE, DRAINING).
 func getActiveLogIDs(trees map[int64]*tree) []int64 {
@@ -163,8 +170,9 @@ func (m *memoryLogStorage) beginInternal(ctx context.Context, tree *trillian.Tre
 	}
 
 	ltx := &logTreeTX{
-		treeTX: ttx,
-		ls:     m,
+		treeTX:   ttx,
+		ls:       m,
+		treeType: tree.TreeType,
 	}
 
 	ltx.slr, err = ltx.fetchLatestRoot(ctx)
@@ -191,6 +199,7 @@ func (m *memoryLogStorage) ReadWriteTransaction(ctx context.Context, tree *trill
 		return err
 	}
 	defer tx.Close()
+	tx.fencingToken = storage.FencingTokenFromContext(ctx)
 	if err := f(ctx, tx); err != nil {
 		return err
 	}
@@ -198,7 +207,16 @@ func (m *memoryLogStorage) ReadWriteTransaction(ctx context.Context, tree *trill
 }
 
 func (m *memoryLogStorage) AddSequencedLeaves(ctx context.Context, tree *trillian.Tree, leaves []*trillian.LogLeaf, timestamp time.Time) ([]*trillian.QueuedLogLeaf, error) {
-	return nil, status.Errorf(codes.Unimplemented, "AddSequencedLeaves is not implemented")
+	var ret []*trillian.QueuedLogLeaf
+	err := m.ReadWriteTransaction(ctx, tree, func(ctx context.Context, tx storage.LogTreeTX) error {
+		var err error
+		ret, err = tx.AddSequencedLeaves(ctx, leaves, timestamp)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return ret, nil
 }
 
 func (m *memoryLogStorage) SnapshotForTree(ctx context.Context, tree *trillian.Tree) (storage.ReadOnlyLogTreeTX, error) {
@@ -245,9 +263,11 @@ func (m *memoryLogStorage) QueueLeaves(ctx context.Context, tree *trillian.Tree,
 
 type logTreeTX struct {
 	treeTX
-	ls   *memoryLogStorage
-	root types.LogRootV1
-	slr  *trillian.SignedLogRoot
+	ls           *memoryLogStorage
+	root         types.LogRootV1
+	slr          *trillian.SignedLogRoot
+	fencingToken int64
+	treeType     trillian.TreeType
 }
 
 func (t *logTreeTX) ReadRevision(ctx context.Context) (int64, error) {
@@ -264,6 +284,20 @@ func (t *logTreeTX) WriteRevision(ctx context.Context) (int64, error) {
 func (t *logTreeTX) DequeueLeaves(ctx context.Context, limit int, cutoffTime time.Time) ([]*trillian.LogLeaf, error) {
 	leaves := make([]*trillian.LogLeaf, 0, limit)
 
+	if t.treeType == trillian.TreeType_PREORDERED_LOG {
+		// The leaves following the current root were stored by
+		// AddSequencedLeaves, and are integrated up to the first gap.
+		for i := int64(0); i < int64(limit); i++ {
+			leaf := t.tx.Get(seqLeafKey(t.treeID, int64(t.root.TreeSize)+i))
+			if leaf == nil {
+				break
+			}
+			leaves = append(leaves, leaf.(*kv).v.(*trillian.LogLeaf))
+		}
+		dequeuedCounter.Add(float64(len(leaves)), labelForTX(t))
+		return leaves, nil
+	}
+
 	q := t.tx.Get(unseqKey(t.treeID)).(*kv).v.(*list.List)
 	e := q.Front()
 	for i := 0; i < limit && e != nil; i++ {
@@ -294,7 +328,29 @@ func (t *logTreeTX) QueueLeaves(ctx context.Context, leaves []*trillian.LogLeaf,
 }
 
 func (t *logTreeTX) AddSequencedLeaves(ctx context.Context, leaves []*trillian.LogLeaf, timestamp time.Time) ([]*trillian.QueuedLogLeaf, error) {
-	return nil, status.Errorf(codes.Unimplemented, "AddSequencedLeaves is not implemented")
+	res := make([]*trillian.QueuedLogLeaf, len(leaves))
+	ok := status.New(codes.OK, "OK").Proto()
+	for i, leaf := range leaves {
+		if got, want := len(leaf.LeafIdentityHash), t.hashSizeBytes; got != want {
+			return nil, status.Errorf(codes.FailedPrecondition, "leaves[%d] has incorrect hash size %d, want %d", i, got, want)
+		}
+		k := seqLeafKey(t.treeID, leaf.LeafIndex)
+		if existing := t.tx.Get(k); existing != nil {
+			res[i] = &trillian.QueuedLogLeaf{
+				Leaf:   existing.(*kv).v.(*trillian.LogLeaf),
+				Status: status.New(codes.FailedPrecondition, "conflicting LeafIndex").Proto(),
+			}
+			continue
+		}
+		// No deduping of LeafIdentityHash in this storage, as for QueueLeaves.
+		k.(*kv).v = leaf
+		t.tx.ReplaceOrInsert(k)
+		m := t.tx.Get(hashToSeqKey(t.treeID)).(*kv).v.(map[string][]int64)
+		m[string(leaf.MerkleLeafHash)] = append(m[string(leaf.MerkleLeafHash)], leaf.LeafIndex)
+		res[i] = &trillian.QueuedLogLeaf{Status: ok}
+	}
+	queuedCounter.Add(float64(len(leaves)), labelForTX(t))
+	return res, nil
 }
 
 func (t *logTreeTX) GetSequencedLeafCount(ctx context.Context) (int64, error) {
@@ -367,6 +423,9 @@ func (t *logTreeTX) StoreSignedLogRoot(ctx context.Context, slr *trillian.Signed
 	if err := root.UnmarshalBinary(slr.LogRoot); err != nil {
 		return err
 	}
+	if err := t.updateFencingToken(); err != nil {
+		return err
+	}
 	k := sthKey(t.treeID, root.TimestampNanos)
 	k.(*kv).v = slr
 	t.tx.ReplaceOrInsert(k)
@@ -378,6 +437,24 @@ func (t *logTreeTX) StoreSignedLogRoot(ctx context.Context, slr *trillian.Signed
 	return nil
 }
 
+// updateFencingToken checks the fencing token of the transaction against the
+// latest one used to store an STH, and records it if it is newer.
+func (t *logTreeTX) updateFencingToken() error {
+	var stored int64
+	if item := t.tx.Get(fencingTokenKey(t.treeID)); item != nil {
+		stored = item.(*kv).v.(int64)
+	}
+	if err := storage.CheckFencingToken(t.fencingToken, stored); err != nil {
+		return err
+	}
+	if t.fencingToken > stored {
+		k := fencingTokenKey(t.treeID)
+		k.(*kv).v = t.fencingToken
+		t.tx.ReplaceOrInsert(k)
+	}
+	return nil
+}
+
 func (t *logTreeTX) UpdateSequencedLeaves(ctx context.Context, leaves []*trillian.LogLeaf) error {
 	countByMerkleHash := make(map[string]int)
 	for _, leaf := range leaves {
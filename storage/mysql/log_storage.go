@@ -51,9 +51,12 @@ const (
 		  AND (Deleted IS NULL OR Deleted = 'false')`
 
 	selectSequencedLeafCountSQL  = "SELECT COUNT(*) FROM SequencedLeafData WHERE TreeId=?"
-	selectLatestSignedLogRootSQL = `SELECT TreeHeadTimestamp,TreeSize,RootHash,TreeRevision,RootSignature
+	selectUnsequencedCountsSQL   = "SELECT TreeId, COUNT(1) FROM Unsequenced GROUP BY TreeId"
+	selectLatestSignedLogRootSQL = `SELECT TreeHeadTimestamp,TreeSize,RootHash,TreeRevision,RootSignature,RootMetadata
 			FROM TreeHead WHERE TreeId=?
 			ORDER BY TreeHeadTimestamp DESC LIMIT 1`
+	selectFencingTokenSQL = "SELECT FencingToken FROM TreeFence WHERE TreeId=? FOR UPDATE"
+	upsertFencingTokenSQL = "INSERT INTO TreeFence(TreeId,FencingToken) VALUES(?,?) ON DUPLICATE KEY UPDATE FencingToken=GREATEST(FencingToken,VALUES(FencingToken))"
 
 	selectLeavesByRangeSQL = `SELECT s.MerkleLeafHash,l.LeafIdentityHash,l.LeafValue,s.SequenceNumber,l.ExtraData,l.QueueTimestampNanos,s.IntegrateTimestampNanos
 			FROM LeafData l,SequencedLeafData s
@@ -234,6 +237,26 @@ func (t *readOnlyLogTX) GetActiveLogIDs(ctx context.Context) ([]int64, error) {
 	return ids, rows.Err()
 }
 
+func (t *readOnlyLogTX) GetUnsequencedCounts(ctx context.Context) (storage.CountByLogID, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	rows, err := t.tx.QueryContext(ctx, selectUnsequencedCountsSQL)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	ret := make(storage.CountByLogID)
+	for rows.Next() {
+		var logID, count int64
+		if err := rows.Scan(&logID, &count); err != nil {
+			return nil, err
+		}
+		ret[logID] = count
+	}
+	return ret, rows.Err()
+}
+
 func (m *mySQLLogStorage) beginInternal(ctx context.Context, tree *trillian.Tree) (*logTreeTX, error) {
 	once.Do(func() {
 		createMetrics(m.metricFactory)
@@ -278,6 +301,7 @@ func (m *mySQLLogStorage) ReadWriteTransaction(ctx context.Context, tree *trilli
 		return err
 	}
 	defer tx.Close()
+	tx.fencingToken = storage.FencingTokenFromContext(ctx)
 	if err := f(ctx, tx); err != nil {
 		return err
 	}
@@ -353,6 +377,9 @@ type logTreeTX struct {
 	root     types.LogRootV1
 	slr      *trillian.SignedLogRoot
 	dequeued map[string]dequeuedLeaf
+	// fencingToken is the token of the mastership the transaction is run
+	// under, or zero if it is not fenced.
+	fencingToken int64
 }
 
 func (t *logTreeTX) ReadRevision(ctx context.Context) (int64, error) {
@@ -836,10 +863,10 @@ func (t *logTreeTX) LatestSignedLogRoot(ctx context.Context) (*trillian.SignedLo
 // fetchLatestRoot reads the latest SignedLogRoot from the DB and returns it.
 func (t *logTreeTX) fetchLatestRoot(ctx context.Context) (*trillian.SignedLogRoot, error) {
 	var timestamp, treeSize, treeRevision int64
-	var rootHash, rootSignatureBytes []byte
+	var rootHash, rootSignatureBytes, metadata []byte
 	if err := t.tx.QueryRowContext(
 		ctx, selectLatestSignedLogRootSQL, t.treeID).Scan(
-		&timestamp, &treeSize, &rootHash, &treeRevision, &rootSignatureBytes,
+		&timestamp, &treeSize, &rootHash, &treeRevision, &rootSignatureBytes, &metadata,
 	); err == sql.ErrNoRows {
 		// It's possible there are no roots for this tree yet
 		return nil, storage.ErrTreeNeedsInit
@@ -851,6 +878,7 @@ func (t *logTreeTX) fetchLatestRoot(ctx context.Context) (*trillian.SignedLogRoo
 		TimestampNanos: uint64(timestamp),
 		Revision:       uint64(treeRevision),
 		TreeSize:       uint64(treeSize),
+		Metadata:       metadata,
 	}).MarshalBinary()
 	if err != nil {
 		return nil, err
@@ -875,8 +903,8 @@ func (t *logTreeTX) StoreSignedLogRoot(ctx context.Context, root *trillian.Signe
 	if got, want := int64(logRoot.Revision), t.treeTX.writeRevision; got != want {
 		return status.Errorf(codes.Internal, "root.Revision: %v, want %v", got, want)
 	}
-	if len(logRoot.Metadata) != 0 {
-		return fmt.Errorf("unimplemented: mysql storage does not support log root metadata")
+	if err := t.updateFencingToken(ctx); err != nil {
+		return err
 	}
 
 	res, err := t.tx.ExecContext(
@@ -887,7 +915,8 @@ func (t *logTreeTX) StoreSignedLogRoot(ctx context.Context, root *trillian.Signe
 		logRoot.TreeSize,
 		logRoot.RootHash,
 		logRoot.Revision,
-		root.LogRootSignature)
+		root.LogRootSignature,
+		logRoot.Metadata)
 	if err != nil {
 		glog.Warningf("Failed to store signed root: %s", err)
 	}
@@ -895,6 +924,28 @@ func (t *logTreeTX) StoreSignedLogRoot(ctx context.Context, root *trillian.Signe
 	return checkResultOkAndRowCountIs(res, err, 1)
 }
 
+// updateFencingToken checks the fencing token of the transaction against the
+// latest one used to store a tree head, and records it if it is newer. The
+// row holding the token stays locked until the end of the transaction. As
+// there is no row to lock before the first token is stored, the upsert keeps
+// the greater of the stored and new tokens, so that a concurrent first writer
+// with a lower token can't replace it.
+func (t *logTreeTX) updateFencingToken(ctx context.Context) error {
+	var stored int64
+	if err := t.tx.QueryRowContext(ctx, selectFencingTokenSQL, t.treeID).Scan(&stored); err != nil && err != sql.ErrNoRows {
+		return err
+	}
+	if err := storage.CheckFencingToken(t.fencingToken, stored); err != nil {
+		return err
+	}
+	if t.fencingToken > stored {
+		if _, err := t.tx.ExecContext(ctx, upsertFencingTokenSQL, t.treeID, t.fencingToken); err != nil {
+			return fmt.Errorf("failed to store fencing token: %v", err)
+		}
+	}
+	return nil
+}
+
 func (t *logTreeTX) getLeavesByHashInternal(ctx context.Context, leafHashes [][]byte, tmpl *sql.Stmt, desc string) ([]*trillian.LogLeaf, error) {
 	stx := t.tx.StmtContext(ctx, tmpl)
 	defer stx.Close()
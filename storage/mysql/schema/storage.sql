@@ -22,6 +22,9 @@ CREATE TABLE IF NOT EXISTS Trees(
   PublicKey             MEDIUMBLOB NOT NULL,
   Deleted               BOOLEAN,
   DeleteTimeMillis      BIGINT,
+  -- State change scheduled for the tree, if any.
+  ScheduledState        ENUM('ACTIVE', 'FROZEN', 'DRAINING'),
+  ScheduledTimeMillis   BIGINT,
   PRIMARY KEY(TreeId)
 );
 
@@ -36,6 +39,17 @@ CREATE TABLE IF NOT EXISTS TreeControl(
   FOREIGN KEY(TreeId) REFERENCES Trees(TreeId) ON DELETE CASCADE
 );
 
+-- Mastership leases used by master election in the database. Each resource
+-- has the current master, the expiry time of its lease in microseconds of the
+-- database clock, and the fencing token incremented by each new master.
+CREATE TABLE IF NOT EXISTS MasterLease(
+  ResourceId           VARCHAR(255) NOT NULL,
+  MasterId             VARCHAR(255) NOT NULL,
+  ExpiryMicros         BIGINT NOT NULL,
+  FencingToken         BIGINT NOT NULL,
+  PRIMARY KEY(ResourceId)
+);
+
 CREATE TABLE IF NOT EXISTS Subtree(
   TreeId               BIGINT NOT NULL,
   SubtreeId            VARBINARY(255) NOT NULL,
@@ -56,6 +70,8 @@ CREATE TABLE IF NOT EXISTS TreeHead(
   RootHash             VARBINARY(255) NOT NULL,
   RootSignature        VARBINARY(1024) NOT NULL,
   TreeRevision         BIGINT,
+  -- The metadata of the log root, e.g. marking the final root of a log.
+  RootMetadata         BLOB,
   PRIMARY KEY(TreeId, TreeHeadTimestamp),
   FOREIGN KEY(TreeId) REFERENCES Trees(TreeId) ON DELETE CASCADE
 );
@@ -63,6 +79,15 @@ CREATE TABLE IF NOT EXISTS TreeHead(
 CREATE UNIQUE INDEX TreeHeadRevisionIdx
   ON TreeHead(TreeId, TreeRevision);
 
+-- The latest fencing token used to write a TreeHead, which stops a signer that
+-- has lost mastership from writing a TreeHead after a newer master did.
+CREATE TABLE IF NOT EXISTS TreeFence(
+  TreeId               BIGINT NOT NULL,
+  FencingToken         BIGINT NOT NULL,
+  PRIMARY KEY(TreeId),
+  FOREIGN KEY(TreeId) REFERENCES Trees(TreeId) ON DELETE CASCADE
+);
+
 -- ---------------------------------------------
 -- Log specific stuff here
 -- ---------------------------------------------
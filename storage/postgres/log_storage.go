@@ -51,6 +51,9 @@ const (
 	//selectLatestSignedLogRootSQL  = `SELECT tree_head_timestamp,tree_size,root_hash,tree_revision,root_signature
 	//              FROM tree_head WHERE tree_id=$1
 	//              ORDER BY tree_head_timestamp DESC LIMIT 1`
+	selectFencingTokenSQL = "SELECT fencing_token FROM tree_fence WHERE tree_id=$1 FOR UPDATE"
+	upsertFencingTokenSQL = `INSERT INTO tree_fence(tree_id,fencing_token) VALUES($1,$2)
+			ON CONFLICT (tree_id) DO UPDATE SET fencing_token=GREATEST(tree_fence.fencing_token,EXCLUDED.fencing_token)`
 
 	selectLeavesByRangeSQL = `SELECT s.merkle_leaf_hash,l.leaf_identity_hash,l.leaf_value,s.sequence_number,l.extra_data,l.queue_timestamp_nanos,s.integrate_timestamp_nanos
                         FROM leaf_data l,sequenced_leaf_data s
@@ -295,6 +298,7 @@ func (m *postgresLogStorage) ReadWriteTransaction(ctx context.Context, tree *tri
 		return err
 	}
 	defer tx.Close()
+	tx.fencingToken = storage.FencingTokenFromContext(ctx)
 	if err := f(ctx, tx); err != nil {
 		return err
 	}
@@ -357,6 +361,9 @@ type logTreeTX struct {
 	ls   *postgresLogStorage
 	root types.LogRootV1
 	slr  *trillian.SignedLogRoot
+	// fencingToken is the token of the mastership the transaction is run
+	// under, or zero if it is not fenced.
+	fencingToken int64
 }
 
 func (t *logTreeTX) ReadRevision(ctx context.Context) (int64, error) {
@@ -843,9 +850,8 @@ func (t *logTreeTX) StoreSignedLogRoot(ctx context.Context, root *trillian.Signe
 		glog.Warningf("Failed to parse log root: %x %v", root.LogRoot, err)
 		return err
 	}
-	if len(logRoot.Metadata) != 0 {
-		return fmt.Errorf("unimplemented: postgres storage does not support log root metadata")
-
+	if err := t.updateFencingToken(ctx); err != nil {
+		return err
 	}
 	//get a json copy of the tree_head
 	data, _ := json.Marshal(logRoot)
@@ -871,6 +877,28 @@ func (t *logTreeTX) StoreSignedLogRoot(ctx context.Context, root *trillian.Signe
 	return checkResultOkAndRowCountIs(res, err, 1)
 }
 
+// updateFencingToken checks the fencing token of the transaction against the
+// latest one used to store a tree head, and records it if it is newer. The
+// row holding the token stays locked until the end of the transaction. As
+// there is no row to lock before the first token is stored, the upsert keeps
+// the greater of the stored and new tokens, so that a concurrent first writer
+// with a lower token can't replace it.
+func (t *logTreeTX) updateFencingToken(ctx context.Context) error {
+	var stored int64
+	if err := t.tx.QueryRowContext(ctx, selectFencingTokenSQL, t.treeID).Scan(&stored); err != nil && err != sql.ErrNoRows {
+		return err
+	}
+	if err := storage.CheckFencingToken(t.fencingToken, stored); err != nil {
+		return err
+	}
+	if t.fencingToken > stored {
+		if _, err := t.tx.ExecContext(ctx, upsertFencingTokenSQL, t.treeID, t.fencingToken); err != nil {
+			return fmt.Errorf("failed to store fencing token: %v", err)
+		}
+	}
+	return nil
+}
+
 func (t *logTreeTX) getLeavesByHashInternal(ctx context.Context, leafHashes [][]byte, tmpl *sql.Stmt, desc string) ([]*trillian.LogLeaf, error) {
 	stx := t.tx.StmtContext(ctx, tmpl)
 	defer stx.Close()
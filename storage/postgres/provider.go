@@ -28,10 +28,10 @@ import (
 )
 
 var (
-	pgConnStr         = flag.String("pg_conn_str", "user=postgres dbname=test port=5432 sslmode=disable", "Connection string for Postgres database")
-	pgOnce            sync.Once
-	pgOnceErr         error
-	pgStorageInstance *pgProvider
+	pgConnStr = flag.String("pg_conn_str", "user=postgres dbname=test port=5432 sslmode=disable", "Connection string for Postgres database")
+	pgOnce    sync.Once
+	pgOnceErr error
+	pgDB      *sql.DB
 )
 
 func init() {
@@ -45,23 +45,21 @@ type pgProvider struct {
 	mf monitoring.MetricFactory
 }
 
-func newPGProvider(mf monitoring.MetricFactory) (storage.Provider, error) {
+// GetDatabase returns the instance of the PostgreSQL database used by the
+// storage provider, or creates one.
+func GetDatabase() (*sql.DB, error) {
 	pgOnce.Do(func() {
-		var db *sql.DB
-		db, pgOnceErr = OpenDB(*pgConnStr)
-		if pgOnceErr != nil {
-			return
-		}
-
-		pgStorageInstance = &pgProvider{
-			db: db,
-			mf: mf,
-		}
+		pgDB, pgOnceErr = OpenDB(*pgConnStr)
 	})
-	if pgOnceErr != nil {
-		return nil, pgOnceErr
+	return pgDB, pgOnceErr
+}
+
+func newPGProvider(mf monitoring.MetricFactory) (storage.Provider, error) {
+	db, err := GetDatabase()
+	if err != nil {
+		return nil, err
 	}
-	return pgStorageInstance, nil
+	return &pgProvider{db: db, mf: mf}, nil
 }
 
 func (s *pgProvider) LogStorage() storage.LogStorage {
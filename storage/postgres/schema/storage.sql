@@ -28,6 +28,8 @@ CREATE TABLE IF NOT EXISTS trees (
   public_key               BYTEA NOT NULL,
   deleted                  BOOLEAN NOT NULL DEFAULT FALSE,
   delete_time_millis       BIGINT,
+  scheduled_state          E_TREE_STATE,
+  scheduled_time_millis    BIGINT,
   current_tree_data	   json,
   root_signature	   BYTEA,
   PRIMARY KEY(tree_id)
@@ -44,6 +46,17 @@ CREATE TABLE IF NOT EXISTS tree_control(
   FOREIGN KEY(tree_id) REFERENCES trees(tree_id) ON DELETE CASCADE
 );--end
 
+-- Mastership leases used by master election in the database. Each resource
+-- has the current master, the expiry time of its lease in microseconds of the
+-- database clock, and the fencing token incremented by each new master.
+CREATE TABLE IF NOT EXISTS master_lease(
+  resource_id            VARCHAR(255) NOT NULL,
+  master_id              VARCHAR(255) NOT NULL,
+  expiry_micros          BIGINT NOT NULL,
+  fencing_token          BIGINT NOT NULL,
+  PRIMARY KEY(resource_id)
+);--end
+
 CREATE TABLE IF NOT EXISTS subtree(
   tree_id               BIGINT NOT NULL,
   subtree_id            BYTEA NOT NULL,
@@ -70,6 +83,15 @@ CREATE TABLE IF NOT EXISTS tree_head(
 -- having a DESC scan on the primary key
 CREATE UNIQUE INDEX TreeHeadRevisionIdx ON tree_head(tree_id, tree_revision DESC);--end
 
+-- The latest fencing token used to write a tree_head, which stops a signer
+-- that has lost mastership from writing a tree_head after a newer master did.
+CREATE TABLE IF NOT EXISTS tree_fence(
+  tree_id                BIGINT NOT NULL,
+  fencing_token          BIGINT NOT NULL,
+  PRIMARY KEY(tree_id),
+  FOREIGN KEY(tree_id) REFERENCES trees(tree_id) ON DELETE CASCADE
+);--end
+
 -- ---------------------------------------------
 -- Log specific stuff here
 -- ---------------------------------------------
@@ -67,6 +67,23 @@ CREATE TABLE IF NOT EXISTS tree_head(
 -- having a DESC scan on the primary key
 CREATE UNIQUE INDEX TreeHeadRevisionIdx ON tree_head(tree_id, tree_revision DESC);
 
+-- The latest fencing token used to write a tree_head, which stops a signer
+-- that has lost mastership from writing a tree_head after a newer master did.
+CREATE TABLE IF NOT EXISTS tree_fence(
+  tree_id                BIGINT NOT NULL,
+  fencing_token          BIGINT NOT NULL,
+  PRIMARY KEY(tree_id)
+);
+
+-- Mastership leases used by master election in the database.
+CREATE TABLE IF NOT EXISTS master_lease(
+  resource_id            VARCHAR(255) NOT NULL,
+  master_id              VARCHAR(255) NOT NULL,
+  expiry_micros          BIGINT NOT NULL,
+  fencing_token          BIGINT NOT NULL,
+  PRIMARY KEY(resource_id)
+);
+
 -- ---------------------------------------------
 -- Log specific stuff here
 -- ---------------------------------------------
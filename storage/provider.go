@@ -53,6 +53,11 @@ func NewProviderFromFlags(mf monitoring.MetricFactory) (Provider, error) {
 	return NewProvider(*storageSystem, mf)
 }
 
+// SystemFromFlags returns the name of the storage system specified by flag.
+func SystemFromFlags() string {
+	return *storageSystem
+}
+
 // NewProvider returns a new Provider instance of the type specified by name.
 func NewProvider(name string, mf monitoring.MetricFactory) (Provider, error) {
 	spMu.RLock()
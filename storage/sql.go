@@ -60,6 +60,8 @@ func ReadTree(row Row) (*trillian.Tree, error) {
 	var privateKey, publicKey []byte
 	var deleted sql.NullBool
 	var deleteMillis sql.NullInt64
+	var scheduledState sql.NullString
+	var scheduledMillis sql.NullInt64
 	err := row.Scan(
 		&tree.TreeId,
 		&treeState,
@@ -76,6 +78,8 @@ func ReadTree(row Row) (*trillian.Tree, error) {
 		&maxRootDurationMillis,
 		&deleted,
 		&deleteMillis,
+		&scheduledState,
+		&scheduledMillis,
 	)
 	if err != nil {
 		return nil, err
@@ -148,5 +152,35 @@ func ReadTree(row Row) (*trillian.Tree, error) {
 		}
 	}
 
+	if scheduledState.Valid && scheduledMillis.Valid {
+		ts, ok := trillian.TreeState_value[scheduledState.String]
+		if !ok {
+			return nil, fmt.Errorf("unknown scheduled TreeState: %v", scheduledState.String)
+		}
+		transitionTime, err := ptypes.TimestampProto(FromMillisSinceEpoch(scheduledMillis.Int64))
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse scheduled transition time: %v", err)
+		}
+		tree.ScheduledTransition = &trillian.ScheduledTreeTransition{
+			TreeState:      trillian.TreeState(ts),
+			TransitionTime: transitionTime,
+		}
+	}
+
 	return tree, nil
 }
+
+// ScheduledTransitionColumns returns the values of the scheduled state and
+// time columns for the tree, which are NULL if it has no scheduled transition.
+func ScheduledTransitionColumns(tree *trillian.Tree) (sql.NullString, sql.NullInt64, error) {
+	st := tree.ScheduledTransition
+	if st == nil {
+		return sql.NullString{}, sql.NullInt64{}, nil
+	}
+	transitionTime, err := ptypes.Timestamp(st.TransitionTime)
+	if err != nil {
+		return sql.NullString{}, sql.NullInt64{}, fmt.Errorf("could not parse scheduled transition time: %v", err)
+	}
+	return sql.NullString{String: st.TreeState.String(), Valid: true},
+		sql.NullInt64{Int64: ToMillisSinceEpoch(transitionTime), Valid: true}, nil
+}
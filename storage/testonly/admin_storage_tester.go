@@ -27,6 +27,7 @@ import (
 	"github.com/golang/protobuf/ptypes"
 	"github.com/golang/protobuf/ptypes/any"
 	"github.com/golang/protobuf/ptypes/empty"
+	"github.com/golang/protobuf/ptypes/timestamp"
 	"github.com/google/go-cmp/cmp"
 	"github.com/google/trillian"
 	"github.com/google/trillian/crypto/keys"
@@ -275,6 +276,16 @@ func (tester *AdminStorageTester) TestUpdateTree(t *testing.T) {
 		tree.TreeType = trillian.TreeType_MAP
 	}
 
+	// Use a time with millisecond precision, as that's what SQL storage keeps.
+	scheduledLog := proto.Clone(referenceLog).(*trillian.Tree)
+	scheduledLog.ScheduledTransition = &trillian.ScheduledTreeTransition{
+		TreeState:      trillian.TreeState_FROZEN,
+		TransitionTime: &timestamp.Timestamp{Seconds: 1600000000, Nanos: 123000000},
+	}
+	scheduledLogFunc := func(tree *trillian.Tree) {
+		tree.ScheduledTransition = scheduledLog.ScheduledTransition
+	}
+
 	referenceMap := proto.Clone(MapTree).(*trillian.Tree)
 	validMap := proto.Clone(referenceMap).(*trillian.Tree)
 	validMap.DisplayName = "Updated Map"
@@ -324,6 +335,12 @@ func (tester *AdminStorageTester) TestUpdateTree(t *testing.T) {
 			updateFunc: validLogWithoutOptionalsFunc,
 			want:       validLogWithoutOptionals,
 		},
+		{
+			desc:       "scheduledLog",
+			create:     referenceLog,
+			updateFunc: scheduledLogFunc,
+			want:       scheduledLog,
+		},
 		{
 			desc:       "invalidLog",
 			create:     referenceLog,
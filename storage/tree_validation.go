@@ -124,6 +124,16 @@ func validateMutableTreeFields(ctx context.Context, tree *trillian.Tree) error {
 	} else if duration < 0 {
 		return status.Errorf(codes.InvalidArgument, "max_root_duration negative: %v", tree.MaxRootDuration)
 	}
+	if st := tree.ScheduledTransition; st != nil {
+		switch st.TreeState {
+		case trillian.TreeState_ACTIVE, trillian.TreeState_DRAINING, trillian.TreeState_FROZEN:
+		default:
+			return status.Errorf(codes.InvalidArgument, "invalid scheduled_transition.tree_state: %v", st.TreeState)
+		}
+		if _, err := ptypes.Timestamp(st.TransitionTime); err != nil {
+			return status.Errorf(codes.InvalidArgument, "scheduled_transition.transition_time malformed: %v", st.TransitionTime)
+		}
+	}
 
 	// Implementations may vary, so let's assume storage_settings is mutable.
 	// Other than checking that it's a valid Any there isn't much to do at this layer, though.
@@ -112,6 +112,34 @@ func TestValidateTreeForCreation(t *testing.T) {
 	invalidRootDuration := newTree()
 	invalidRootDuration.MaxRootDuration = ptypes.DurationProto(-1 * time.Second)
 
+	validTransition := newTree()
+	validTransition.ScheduledTransition = &trillian.ScheduledTreeTransition{
+		TreeState:      trillian.TreeState_DRAINING,
+		TransitionTime: ptypes.TimestampNow(),
+	}
+
+	invalidTransitionState := newTree()
+	invalidTransitionState.ScheduledTransition = &trillian.ScheduledTreeTransition{
+		TransitionTime: ptypes.TimestampNow(),
+	}
+
+	deletedTransitionState := newTree()
+	deletedTransitionState.ScheduledTransition = &trillian.ScheduledTreeTransition{
+		TreeState:      trillian.TreeState_DEPRECATED_SOFT_DELETED,
+		TransitionTime: ptypes.TimestampNow(),
+	}
+
+	outOfRangeTransitionState := newTree()
+	outOfRangeTransitionState.ScheduledTransition = &trillian.ScheduledTreeTransition{
+		TreeState:      trillian.TreeState(42),
+		TransitionTime: ptypes.TimestampNow(),
+	}
+
+	nilTransitionTime := newTree()
+	nilTransitionTime.ScheduledTransition = &trillian.ScheduledTreeTransition{
+		TreeState: trillian.TreeState_FROZEN,
+	}
+
 	deletedTree := newTree()
 	deletedTree.Deleted = true
 
@@ -210,6 +238,30 @@ func TestValidateTreeForCreation(t *testing.T) {
 			tree:    invalidRootDuration,
 			wantErr: true,
 		},
+		{
+			desc: "validTransition",
+			tree: validTransition,
+		},
+		{
+			desc:    "invalidTransitionState",
+			tree:    invalidTransitionState,
+			wantErr: true,
+		},
+		{
+			desc:    "deletedTransitionState",
+			tree:    deletedTransitionState,
+			wantErr: true,
+		},
+		{
+			desc:    "outOfRangeTransitionState",
+			tree:    outOfRangeTransitionState,
+			wantErr: true,
+		},
+		{
+			desc:    "nilTransitionTime",
+			tree:    nilTransitionTime,
+			wantErr: true,
+		},
 		{
 			desc:    "deletedTree",
 			tree:    deletedTree,
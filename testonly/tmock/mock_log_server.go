@@ -64,6 +64,20 @@ func (mr *MockTrillianLogServerMockRecorder) AddSequencedLeaves(arg0, arg1 inter
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddSequencedLeaves", reflect.TypeOf((*MockTrillianLogServer)(nil).AddSequencedLeaves), arg0, arg1)
 }
 
+// AddSequencedLeavesStream mocks base method
+func (m *MockTrillianLogServer) AddSequencedLeavesStream(arg0 trillian.TrillianLog_AddSequencedLeavesStreamServer) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AddSequencedLeavesStream", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// AddSequencedLeavesStream indicates an expected call of AddSequencedLeavesStream
+func (mr *MockTrillianLogServerMockRecorder) AddSequencedLeavesStream(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddSequencedLeavesStream", reflect.TypeOf((*MockTrillianLogServer)(nil).AddSequencedLeavesStream), arg0)
+}
+
 // GetConsistencyProof mocks base method
 func (m *MockTrillianLogServer) GetConsistencyProof(arg0 context.Context, arg1 *trillian.GetConsistencyProofRequest) (*trillian.GetConsistencyProofResponse, error) {
 	m.ctrl.T.Helper()
@@ -20,6 +20,7 @@ import (
 	"context"
 	"crypto"
 	"fmt"
+	"time"
 
 	"github.com/golang/protobuf/ptypes"
 	"github.com/google/trillian"
@@ -37,6 +38,9 @@ const traceSpanRoot = "/trillian/trees"
 
 type treeKey struct{}
 
+// timeNow is the clock used to check for due scheduled transitions.
+var timeNow = time.Now
+
 type accessRule struct {
 	// Tree states are accepted if there is a 'true' value for them in this map.
 	okStates map[trillian.TreeState]bool
@@ -139,19 +143,45 @@ func validate(o GetOpts, tree *trillian.Tree) error {
 	}
 
 	// Apply the rule, ensure it allows the tree type and state that we have.
-	if !rule.okTypes[tree.TreeType] || !rule.okStates[tree.TreeState] {
+	// A due scheduled transition applies even if storage doesn't reflect it yet.
+	state := EffectiveState(tree, timeNow())
+	if !rule.okTypes[tree.TreeType] || !rule.okStates[state] {
 		// If we have a status code to use it takes precedence, otherwise it's
 		// a generic InvalidArgument code.
-		code, ok := rule.rejectCodes[tree.TreeState]
+		code, ok := rule.rejectCodes[state]
 		if !ok {
 			code = codes.InvalidArgument
 		}
-		return status.Errorf(code, "operation: %v not allowed for tree type: %v state: %v", o.Operation, tree.TreeType, tree.TreeState)
+		return status.Errorf(code, "operation: %v not allowed for tree type: %v state: %v", o.Operation, tree.TreeType, state)
 	}
 
 	return nil
 }
 
+// DueTransition returns the scheduled transition of the tree if its transition
+// time is not after now, or nil otherwise.
+func DueTransition(tree *trillian.Tree, now time.Time) *trillian.ScheduledTreeTransition {
+	st := tree.GetScheduledTransition()
+	if st == nil {
+		return nil
+	}
+	transitionTime, err := ptypes.Timestamp(st.TransitionTime)
+	if err != nil || transitionTime.After(now) {
+		return nil
+	}
+	return st
+}
+
+// EffectiveState returns the state of the tree at the given time, which is the
+// scheduled state if a scheduled transition is due, or its stored state
+// otherwise.
+func EffectiveState(tree *trillian.Tree, now time.Time) trillian.TreeState {
+	if st := DueTransition(tree, now); st != nil {
+		return st.TreeState
+	}
+	return tree.TreeState
+}
+
 // GetTree returns the specified tree, either from the ctx (if present) or read from storage.
 // The tree will be validated according to GetOpts before returned. Tree state is also considered
 // (for example, deleted tree will return NotFound errors).
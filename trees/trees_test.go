@@ -25,6 +25,7 @@ import (
 	"fmt"
 	"math/big"
 	"testing"
+	"time"
 
 	"github.com/golang/mock/gomock"
 	"github.com/golang/protobuf/proto" //nolint:staticcheck
@@ -79,6 +80,16 @@ func TestGetTree(t *testing.T) {
 	drainingTree.TreeId = 3
 	drainingTree.TreeState = trillian.TreeState_DRAINING
 
+	scheduledDrainingTree := proto.Clone(testonly.LogTree).(*trillian.Tree)
+	scheduledDrainingTree.TreeId = 4
+	scheduledDrainingTree.ScheduledTransition = &trillian.ScheduledTreeTransition{
+		TreeState:      trillian.TreeState_DRAINING,
+		TransitionTime: ptypes.TimestampNow(),
+	}
+
+	futureDrainingTree := proto.Clone(scheduledDrainingTree).(*trillian.Tree)
+	futureDrainingTree.ScheduledTransition.TransitionTime, _ = ptypes.TimestampProto(time.Now().Add(time.Hour))
+
 	softDeletedTree := proto.Clone(testonly.LogTree).(*trillian.Tree)
 	softDeletedTree.Deleted = true
 	softDeletedTree.DeleteTime = ptypes.TimestampNow()
@@ -239,6 +250,28 @@ func TestGetTree(t *testing.T) {
 			storageTree: drainingTree,
 			wantTree:    drainingTree,
 		},
+		{
+			desc:        "queueScheduledDraining",
+			treeID:      scheduledDrainingTree.TreeId,
+			opts:        NewGetOpts(QueueLog, trillian.TreeType_LOG),
+			storageTree: scheduledDrainingTree,
+			wantErr:     true,
+			code:        codes.PermissionDenied,
+		},
+		{
+			desc:        "queueFutureDraining",
+			treeID:      futureDrainingTree.TreeId,
+			opts:        NewGetOpts(QueueLog, trillian.TreeType_LOG),
+			storageTree: futureDrainingTree,
+			wantTree:    futureDrainingTree,
+		},
+		{
+			desc:        "sequenceScheduledDraining",
+			treeID:      scheduledDrainingTree.TreeId,
+			opts:        NewGetOpts(SequenceLog, trillian.TreeType_LOG),
+			storageTree: scheduledDrainingTree,
+			wantTree:    scheduledDrainingTree,
+		},
 		{
 			desc:        "queueDraining",
 			treeID:      drainingTree.TreeId,
@@ -23,10 +23,14 @@ package trillian
 import (
 	context "context"
 	proto "github.com/golang/protobuf/proto"
+	timestamp "github.com/golang/protobuf/ptypes/timestamp"
 	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
 	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
 	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
 	reflect "reflect"
+	sync "sync"
 )
 
 const (
@@ -40,29 +44,297 @@ const (
 // of the legacy proto package is being used.
 const _ = proto.ProtoPackageIsVersion4
 
+type GetSignerStatusRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+}
+
+func (x *GetSignerStatusRequest) Reset() {
+	*x = GetSignerStatusRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_trillian_log_sequencer_api_proto_msgTypes[0]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *GetSignerStatusRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetSignerStatusRequest) ProtoMessage() {}
+
+func (x *GetSignerStatusRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_trillian_log_sequencer_api_proto_msgTypes[0]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetSignerStatusRequest.ProtoReflect.Descriptor instead.
+func (*GetSignerStatusRequest) Descriptor() ([]byte, []int) {
+	return file_trillian_log_sequencer_api_proto_rawDescGZIP(), []int{0}
+}
+
+type GetSignerStatusResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// The logs known to the signer, ordered by log ID.
+	Logs []*LogSequencingStatus `protobuf:"bytes,1,rep,name=logs,proto3" json:"logs,omitempty"`
+}
+
+func (x *GetSignerStatusResponse) Reset() {
+	*x = GetSignerStatusResponse{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_trillian_log_sequencer_api_proto_msgTypes[1]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *GetSignerStatusResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetSignerStatusResponse) ProtoMessage() {}
+
+func (x *GetSignerStatusResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_trillian_log_sequencer_api_proto_msgTypes[1]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetSignerStatusResponse.ProtoReflect.Descriptor instead.
+func (*GetSignerStatusResponse) Descriptor() ([]byte, []int) {
+	return file_trillian_log_sequencer_api_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *GetSignerStatusResponse) GetLogs() []*LogSequencingStatus {
+	if x != nil {
+		return x.Logs
+	}
+	return nil
+}
+
+// LogSequencingStatus describes the sequencing of a log as seen by a signer.
+type LogSequencingStatus struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	LogId       int64  `protobuf:"varint,1,opt,name=log_id,json=logId,proto3" json:"log_id,omitempty"`
+	DisplayName string `protobuf:"bytes,2,opt,name=display_name,json=displayName,proto3" json:"display_name,omitempty"`
+	// Whether the signer is master for the log. False if the signer doesn't use
+	// master election, as mastership is then unknown.
+	IsMaster bool `protobuf:"varint,3,opt,name=is_master,json=isMaster,proto3" json:"is_master,omitempty"`
+	// When the signer last integrated leaves into the log, and how many.
+	LastIntegrationTime *timestamp.Timestamp `protobuf:"bytes,4,opt,name=last_integration_time,json=lastIntegrationTime,proto3" json:"last_integration_time,omitempty"`
+	LastBatchSize       int64                `protobuf:"varint,5,opt,name=last_batch_size,json=lastBatchSize,proto3" json:"last_batch_size,omitempty"`
+	// The number of leaves queued for the log, or -1 if the storage can't count
+	// them.
+	QueueDepth int64 `protobuf:"varint,6,opt,name=queue_depth,json=queueDepth,proto3" json:"queue_depth,omitempty"`
+	// The error of the last failed sequencing pass, when it happened, and how
+	// many passes have failed since the last successful one.
+	LastError           string               `protobuf:"bytes,7,opt,name=last_error,json=lastError,proto3" json:"last_error,omitempty"`
+	LastErrorTime       *timestamp.Timestamp `protobuf:"bytes,8,opt,name=last_error_time,json=lastErrorTime,proto3" json:"last_error_time,omitempty"`
+	ConsecutiveFailures int64                `protobuf:"varint,9,opt,name=consecutive_failures,json=consecutiveFailures,proto3" json:"consecutive_failures,omitempty"`
+}
+
+func (x *LogSequencingStatus) Reset() {
+	*x = LogSequencingStatus{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_trillian_log_sequencer_api_proto_msgTypes[2]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *LogSequencingStatus) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*LogSequencingStatus) ProtoMessage() {}
+
+func (x *LogSequencingStatus) ProtoReflect() protoreflect.Message {
+	mi := &file_trillian_log_sequencer_api_proto_msgTypes[2]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use LogSequencingStatus.ProtoReflect.Descriptor instead.
+func (*LogSequencingStatus) Descriptor() ([]byte, []int) {
+	return file_trillian_log_sequencer_api_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *LogSequencingStatus) GetLogId() int64 {
+	if x != nil {
+		return x.LogId
+	}
+	return 0
+}
+
+func (x *LogSequencingStatus) GetDisplayName() string {
+	if x != nil {
+		return x.DisplayName
+	}
+	return ""
+}
+
+func (x *LogSequencingStatus) GetIsMaster() bool {
+	if x != nil {
+		return x.IsMaster
+	}
+	return false
+}
+
+func (x *LogSequencingStatus) GetLastIntegrationTime() *timestamp.Timestamp {
+	if x != nil {
+		return x.LastIntegrationTime
+	}
+	return nil
+}
+
+func (x *LogSequencingStatus) GetLastBatchSize() int64 {
+	if x != nil {
+		return x.LastBatchSize
+	}
+	return 0
+}
+
+func (x *LogSequencingStatus) GetQueueDepth() int64 {
+	if x != nil {
+		return x.QueueDepth
+	}
+	return 0
+}
+
+func (x *LogSequencingStatus) GetLastError() string {
+	if x != nil {
+		return x.LastError
+	}
+	return ""
+}
+
+func (x *LogSequencingStatus) GetLastErrorTime() *timestamp.Timestamp {
+	if x != nil {
+		return x.LastErrorTime
+	}
+	return nil
+}
+
+func (x *LogSequencingStatus) GetConsecutiveFailures() int64 {
+	if x != nil {
+		return x.ConsecutiveFailures
+	}
+	return 0
+}
+
 var File_trillian_log_sequencer_api_proto protoreflect.FileDescriptor
 
 var file_trillian_log_sequencer_api_proto_rawDesc = []byte{
 	0x0a, 0x20, 0x74, 0x72, 0x69, 0x6c, 0x6c, 0x69, 0x61, 0x6e, 0x5f, 0x6c, 0x6f, 0x67, 0x5f, 0x73,
 	0x65, 0x71, 0x75, 0x65, 0x6e, 0x63, 0x65, 0x72, 0x5f, 0x61, 0x70, 0x69, 0x2e, 0x70, 0x72, 0x6f,
-	0x74, 0x6f, 0x12, 0x08, 0x74, 0x72, 0x69, 0x6c, 0x6c, 0x69, 0x61, 0x6e, 0x32, 0x16, 0x0a, 0x14,
-	0x54, 0x72, 0x69, 0x6c, 0x6c, 0x69, 0x61, 0x6e, 0x4c, 0x6f, 0x67, 0x53, 0x65, 0x71, 0x75, 0x65,
-	0x6e, 0x63, 0x65, 0x72, 0x42, 0x57, 0x0a, 0x19, 0x63, 0x6f, 0x6d, 0x2e, 0x67, 0x6f, 0x6f, 0x67,
-	0x6c, 0x65, 0x2e, 0x74, 0x72, 0x69, 0x6c, 0x6c, 0x69, 0x61, 0x6e, 0x2e, 0x70, 0x72, 0x6f, 0x74,
-	0x6f, 0x42, 0x1c, 0x54, 0x72, 0x69, 0x6c, 0x6c, 0x69, 0x61, 0x6e, 0x4c, 0x6f, 0x67, 0x53, 0x65,
-	0x71, 0x75, 0x65, 0x6e, 0x63, 0x65, 0x72, 0x41, 0x70, 0x69, 0x50, 0x72, 0x6f, 0x74, 0x6f, 0x50,
-	0x01, 0x5a, 0x1a, 0x67, 0x69, 0x74, 0x68, 0x75, 0x62, 0x2e, 0x63, 0x6f, 0x6d, 0x2f, 0x67, 0x6f,
-	0x6f, 0x67, 0x6c, 0x65, 0x2f, 0x74, 0x72, 0x69, 0x6c, 0x6c, 0x69, 0x61, 0x6e, 0x62, 0x06, 0x70,
-	0x72, 0x6f, 0x74, 0x6f, 0x33,
-}
-
-var file_trillian_log_sequencer_api_proto_goTypes = []interface{}{}
+	0x74, 0x6f, 0x12, 0x08, 0x74, 0x72, 0x69, 0x6c, 0x6c, 0x69, 0x61, 0x6e, 0x1a, 0x1f, 0x67, 0x6f,
+	0x6f, 0x67, 0x6c, 0x65, 0x2f, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2f, 0x74, 0x69,
+	0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x22, 0x18, 0x0a,
+	0x16, 0x47, 0x65, 0x74, 0x53, 0x69, 0x67, 0x6e, 0x65, 0x72, 0x53, 0x74, 0x61, 0x74, 0x75, 0x73,
+	0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x22, 0x4c, 0x0a, 0x17, 0x47, 0x65, 0x74, 0x53, 0x69,
+	0x67, 0x6e, 0x65, 0x72, 0x53, 0x74, 0x61, 0x74, 0x75, 0x73, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e,
+	0x73, 0x65, 0x12, 0x31, 0x0a, 0x04, 0x6c, 0x6f, 0x67, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x0b,
+	0x32, 0x1d, 0x2e, 0x74, 0x72, 0x69, 0x6c, 0x6c, 0x69, 0x61, 0x6e, 0x2e, 0x4c, 0x6f, 0x67, 0x53,
+	0x65, 0x71, 0x75, 0x65, 0x6e, 0x63, 0x69, 0x6e, 0x67, 0x53, 0x74, 0x61, 0x74, 0x75, 0x73, 0x52,
+	0x04, 0x6c, 0x6f, 0x67, 0x73, 0x22, 0x9b, 0x03, 0x0a, 0x13, 0x4c, 0x6f, 0x67, 0x53, 0x65, 0x71,
+	0x75, 0x65, 0x6e, 0x63, 0x69, 0x6e, 0x67, 0x53, 0x74, 0x61, 0x74, 0x75, 0x73, 0x12, 0x15, 0x0a,
+	0x06, 0x6c, 0x6f, 0x67, 0x5f, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x03, 0x52, 0x05, 0x6c,
+	0x6f, 0x67, 0x49, 0x64, 0x12, 0x21, 0x0a, 0x0c, 0x64, 0x69, 0x73, 0x70, 0x6c, 0x61, 0x79, 0x5f,
+	0x6e, 0x61, 0x6d, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0b, 0x64, 0x69, 0x73, 0x70,
+	0x6c, 0x61, 0x79, 0x4e, 0x61, 0x6d, 0x65, 0x12, 0x1b, 0x0a, 0x09, 0x69, 0x73, 0x5f, 0x6d, 0x61,
+	0x73, 0x74, 0x65, 0x72, 0x18, 0x03, 0x20, 0x01, 0x28, 0x08, 0x52, 0x08, 0x69, 0x73, 0x4d, 0x61,
+	0x73, 0x74, 0x65, 0x72, 0x12, 0x4e, 0x0a, 0x15, 0x6c, 0x61, 0x73, 0x74, 0x5f, 0x69, 0x6e, 0x74,
+	0x65, 0x67, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x5f, 0x74, 0x69, 0x6d, 0x65, 0x18, 0x04, 0x20,
+	0x01, 0x28, 0x0b, 0x32, 0x1a, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f,
+	0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x54, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x52,
+	0x13, 0x6c, 0x61, 0x73, 0x74, 0x49, 0x6e, 0x74, 0x65, 0x67, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e,
+	0x54, 0x69, 0x6d, 0x65, 0x12, 0x26, 0x0a, 0x0f, 0x6c, 0x61, 0x73, 0x74, 0x5f, 0x62, 0x61, 0x74,
+	0x63, 0x68, 0x5f, 0x73, 0x69, 0x7a, 0x65, 0x18, 0x05, 0x20, 0x01, 0x28, 0x03, 0x52, 0x0d, 0x6c,
+	0x61, 0x73, 0x74, 0x42, 0x61, 0x74, 0x63, 0x68, 0x53, 0x69, 0x7a, 0x65, 0x12, 0x1f, 0x0a, 0x0b,
+	0x71, 0x75, 0x65, 0x75, 0x65, 0x5f, 0x64, 0x65, 0x70, 0x74, 0x68, 0x18, 0x06, 0x20, 0x01, 0x28,
+	0x03, 0x52, 0x0a, 0x71, 0x75, 0x65, 0x75, 0x65, 0x44, 0x65, 0x70, 0x74, 0x68, 0x12, 0x1d, 0x0a,
+	0x0a, 0x6c, 0x61, 0x73, 0x74, 0x5f, 0x65, 0x72, 0x72, 0x6f, 0x72, 0x18, 0x07, 0x20, 0x01, 0x28,
+	0x09, 0x52, 0x09, 0x6c, 0x61, 0x73, 0x74, 0x45, 0x72, 0x72, 0x6f, 0x72, 0x12, 0x42, 0x0a, 0x0f,
+	0x6c, 0x61, 0x73, 0x74, 0x5f, 0x65, 0x72, 0x72, 0x6f, 0x72, 0x5f, 0x74, 0x69, 0x6d, 0x65, 0x18,
+	0x08, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1a, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70,
+	0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x54, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d,
+	0x70, 0x52, 0x0d, 0x6c, 0x61, 0x73, 0x74, 0x45, 0x72, 0x72, 0x6f, 0x72, 0x54, 0x69, 0x6d, 0x65,
+	0x12, 0x31, 0x0a, 0x14, 0x63, 0x6f, 0x6e, 0x73, 0x65, 0x63, 0x75, 0x74, 0x69, 0x76, 0x65, 0x5f,
+	0x66, 0x61, 0x69, 0x6c, 0x75, 0x72, 0x65, 0x73, 0x18, 0x09, 0x20, 0x01, 0x28, 0x03, 0x52, 0x13,
+	0x63, 0x6f, 0x6e, 0x73, 0x65, 0x63, 0x75, 0x74, 0x69, 0x76, 0x65, 0x46, 0x61, 0x69, 0x6c, 0x75,
+	0x72, 0x65, 0x73, 0x32, 0x70, 0x0a, 0x14, 0x54, 0x72, 0x69, 0x6c, 0x6c, 0x69, 0x61, 0x6e, 0x4c,
+	0x6f, 0x67, 0x53, 0x65, 0x71, 0x75, 0x65, 0x6e, 0x63, 0x65, 0x72, 0x12, 0x58, 0x0a, 0x0f, 0x47,
+	0x65, 0x74, 0x53, 0x69, 0x67, 0x6e, 0x65, 0x72, 0x53, 0x74, 0x61, 0x74, 0x75, 0x73, 0x12, 0x20,
+	0x2e, 0x74, 0x72, 0x69, 0x6c, 0x6c, 0x69, 0x61, 0x6e, 0x2e, 0x47, 0x65, 0x74, 0x53, 0x69, 0x67,
+	0x6e, 0x65, 0x72, 0x53, 0x74, 0x61, 0x74, 0x75, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74,
+	0x1a, 0x21, 0x2e, 0x74, 0x72, 0x69, 0x6c, 0x6c, 0x69, 0x61, 0x6e, 0x2e, 0x47, 0x65, 0x74, 0x53,
+	0x69, 0x67, 0x6e, 0x65, 0x72, 0x53, 0x74, 0x61, 0x74, 0x75, 0x73, 0x52, 0x65, 0x73, 0x70, 0x6f,
+	0x6e, 0x73, 0x65, 0x22, 0x00, 0x42, 0x57, 0x0a, 0x19, 0x63, 0x6f, 0x6d, 0x2e, 0x67, 0x6f, 0x6f,
+	0x67, 0x6c, 0x65, 0x2e, 0x74, 0x72, 0x69, 0x6c, 0x6c, 0x69, 0x61, 0x6e, 0x2e, 0x70, 0x72, 0x6f,
+	0x74, 0x6f, 0x42, 0x1c, 0x54, 0x72, 0x69, 0x6c, 0x6c, 0x69, 0x61, 0x6e, 0x4c, 0x6f, 0x67, 0x53,
+	0x65, 0x71, 0x75, 0x65, 0x6e, 0x63, 0x65, 0x72, 0x41, 0x70, 0x69, 0x50, 0x72, 0x6f, 0x74, 0x6f,
+	0x50, 0x01, 0x5a, 0x1a, 0x67, 0x69, 0x74, 0x68, 0x75, 0x62, 0x2e, 0x63, 0x6f, 0x6d, 0x2f, 0x67,
+	0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2f, 0x74, 0x72, 0x69, 0x6c, 0x6c, 0x69, 0x61, 0x6e, 0x62, 0x06,
+	0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
+}
+
+var (
+	file_trillian_log_sequencer_api_proto_rawDescOnce sync.Once
+	file_trillian_log_sequencer_api_proto_rawDescData = file_trillian_log_sequencer_api_proto_rawDesc
+)
+
+func file_trillian_log_sequencer_api_proto_rawDescGZIP() []byte {
+	file_trillian_log_sequencer_api_proto_rawDescOnce.Do(func() {
+		file_trillian_log_sequencer_api_proto_rawDescData = protoimpl.X.CompressGZIP(file_trillian_log_sequencer_api_proto_rawDescData)
+	})
+	return file_trillian_log_sequencer_api_proto_rawDescData
+}
+
+var file_trillian_log_sequencer_api_proto_msgTypes = make([]protoimpl.MessageInfo, 3)
+var file_trillian_log_sequencer_api_proto_goTypes = []interface{}{
+	(*GetSignerStatusRequest)(nil),  // 0: trillian.GetSignerStatusRequest
+	(*GetSignerStatusResponse)(nil), // 1: trillian.GetSignerStatusResponse
+	(*LogSequencingStatus)(nil),     // 2: trillian.LogSequencingStatus
+	(*timestamp.Timestamp)(nil),     // 3: google.protobuf.Timestamp
+}
 var file_trillian_log_sequencer_api_proto_depIdxs = []int32{
-	0, // [0:0] is the sub-list for method output_type
-	0, // [0:0] is the sub-list for method input_type
-	0, // [0:0] is the sub-list for extension type_name
-	0, // [0:0] is the sub-list for extension extendee
-	0, // [0:0] is the sub-list for field type_name
+	2, // 0: trillian.GetSignerStatusResponse.logs:type_name -> trillian.LogSequencingStatus
+	3, // 1: trillian.LogSequencingStatus.last_integration_time:type_name -> google.protobuf.Timestamp
+	3, // 2: trillian.LogSequencingStatus.last_error_time:type_name -> google.protobuf.Timestamp
+	0, // 3: trillian.TrillianLogSequencer.GetSignerStatus:input_type -> trillian.GetSignerStatusRequest
+	1, // 4: trillian.TrillianLogSequencer.GetSignerStatus:output_type -> trillian.GetSignerStatusResponse
+	4, // [4:5] is the sub-list for method output_type
+	3, // [3:4] is the sub-list for method input_type
+	3, // [3:3] is the sub-list for extension type_name
+	3, // [3:3] is the sub-list for extension extendee
+	0, // [0:3] is the sub-list for field type_name
 }
 
 func init() { file_trillian_log_sequencer_api_proto_init() }
@@ -70,18 +342,57 @@ func file_trillian_log_sequencer_api_proto_init() {
 	if File_trillian_log_sequencer_api_proto != nil {
 		return
 	}
+	if !protoimpl.UnsafeEnabled {
+		file_trillian_log_sequencer_api_proto_msgTypes[0].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*GetSignerStatusRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_trillian_log_sequencer_api_proto_msgTypes[1].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*GetSignerStatusResponse); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_trillian_log_sequencer_api_proto_msgTypes[2].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*LogSequencingStatus); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+	}
 	type x struct{}
 	out := protoimpl.TypeBuilder{
 		File: protoimpl.DescBuilder{
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: file_trillian_log_sequencer_api_proto_rawDesc,
 			NumEnums:      0,
-			NumMessages:   0,
+			NumMessages:   3,
 			NumExtensions: 0,
 			NumServices:   1,
 		},
 		GoTypes:           file_trillian_log_sequencer_api_proto_goTypes,
 		DependencyIndexes: file_trillian_log_sequencer_api_proto_depIdxs,
+		MessageInfos:      file_trillian_log_sequencer_api_proto_msgTypes,
 	}.Build()
 	File_trillian_log_sequencer_api_proto = out.File
 	file_trillian_log_sequencer_api_proto_rawDesc = nil
@@ -101,6 +412,9 @@ const _ = grpc.SupportPackageIsVersion6
 //
 // For semantics around ctx use and closing/ending streaming RPCs, please refer to https://godoc.org/google.golang.org/grpc#ClientConn.NewStream.
 type TrillianLogSequencerClient interface {
+	// GetSignerStatus returns the sequencing status of the logs known to the
+	// signer, including which of them it is master for.
+	GetSignerStatus(ctx context.Context, in *GetSignerStatusRequest, opts ...grpc.CallOption) (*GetSignerStatusResponse, error)
 }
 
 type trillianLogSequencerClient struct {
@@ -111,22 +425,61 @@ func NewTrillianLogSequencerClient(cc grpc.ClientConnInterface) TrillianLogSeque
 	return &trillianLogSequencerClient{cc}
 }
 
+func (c *trillianLogSequencerClient) GetSignerStatus(ctx context.Context, in *GetSignerStatusRequest, opts ...grpc.CallOption) (*GetSignerStatusResponse, error) {
+	out := new(GetSignerStatusResponse)
+	err := c.cc.Invoke(ctx, "/trillian.TrillianLogSequencer/GetSignerStatus", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // TrillianLogSequencerServer is the server API for TrillianLogSequencer service.
 type TrillianLogSequencerServer interface {
+	// GetSignerStatus returns the sequencing status of the logs known to the
+	// signer, including which of them it is master for.
+	GetSignerStatus(context.Context, *GetSignerStatusRequest) (*GetSignerStatusResponse, error)
 }
 
 // UnimplementedTrillianLogSequencerServer can be embedded to have forward compatible implementations.
 type UnimplementedTrillianLogSequencerServer struct {
 }
 
+func (*UnimplementedTrillianLogSequencerServer) GetSignerStatus(context.Context, *GetSignerStatusRequest) (*GetSignerStatusResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetSignerStatus not implemented")
+}
+
 func RegisterTrillianLogSequencerServer(s *grpc.Server, srv TrillianLogSequencerServer) {
 	s.RegisterService(&_TrillianLogSequencer_serviceDesc, srv)
 }
 
+func _TrillianLogSequencer_GetSignerStatus_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetSignerStatusRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TrillianLogSequencerServer).GetSignerStatus(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/trillian.TrillianLogSequencer/GetSignerStatus",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TrillianLogSequencerServer).GetSignerStatus(ctx, req.(*GetSignerStatusRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 var _TrillianLogSequencer_serviceDesc = grpc.ServiceDesc{
 	ServiceName: "trillian.TrillianLogSequencer",
 	HandlerType: (*TrillianLogSequencerServer)(nil),
-	Methods:     []grpc.MethodDesc{},
-	Streams:     []grpc.StreamDesc{},
-	Metadata:    "trillian_log_sequencer_api.proto",
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "GetSignerStatus",
+			Handler:    _TrillianLogSequencer_GetSignerStatus_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "trillian_log_sequencer_api.proto",
 }
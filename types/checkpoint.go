@@ -0,0 +1,104 @@
+// Copyright 2020 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package types
+
+import (
+	"bytes"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Checkpoint is the text form of a log root exchanged by transparency tools
+// outside Trillian, as the body of a signed note:
+//
+//	<origin>
+//	<tree size, in decimal>
+//	<root hash, in base64>
+//
+// The origin identifies the log. Any lines after the root hash are
+// extensions, which are kept but not interpreted.
+type Checkpoint struct {
+	Origin     string
+	TreeSize   uint64
+	RootHash   []byte
+	Extensions []string
+}
+
+// Checkpoint returns the checkpoint of the log root, for the log identified
+// by origin.
+func (l *LogRootV1) Checkpoint(origin string) *Checkpoint {
+	return &Checkpoint{Origin: origin, TreeSize: l.TreeSize, RootHash: l.RootHash}
+}
+
+// MarshalText returns the text of the checkpoint, which ends with a newline.
+func (c *Checkpoint) MarshalText() ([]byte, error) {
+	if err := checkLine(c.Origin); err != nil {
+		return nil, fmt.Errorf("invalid origin: %v", err)
+	}
+	var b bytes.Buffer
+	fmt.Fprintf(&b, "%s\n%d\n%s\n", c.Origin, c.TreeSize, base64.StdEncoding.EncodeToString(c.RootHash))
+	for _, e := range c.Extensions {
+		if err := checkLine(e); err != nil {
+			return nil, fmt.Errorf("invalid extension: %v", err)
+		}
+		fmt.Fprintf(&b, "%s\n", e)
+	}
+	return b.Bytes(), nil
+}
+
+// UnmarshalText parses the text of a checkpoint.
+func (c *Checkpoint) UnmarshalText(text []byte) error {
+	if !bytes.HasSuffix(text, []byte("\n")) {
+		return errors.New("checkpoint doesn't end with a newline")
+	}
+	lines := strings.Split(string(text[:len(text)-1]), "\n")
+	if len(lines) < 3 {
+		return fmt.Errorf("checkpoint has %d lines, want at least 3", len(lines))
+	}
+	if err := checkLine(lines[0]); err != nil {
+		return fmt.Errorf("invalid origin: %v", err)
+	}
+	size, err := strconv.ParseUint(lines[1], 10, 64)
+	if err != nil || strconv.FormatUint(size, 10) != lines[1] {
+		return fmt.Errorf("invalid tree size %q", lines[1])
+	}
+	hash, err := base64.StdEncoding.DecodeString(lines[2])
+	if err != nil {
+		return fmt.Errorf("invalid root hash: %v", err)
+	}
+	var extensions []string
+	for _, e := range lines[3:] {
+		if err := checkLine(e); err != nil {
+			return fmt.Errorf("invalid extension: %v", err)
+		}
+		extensions = append(extensions, e)
+	}
+	*c = Checkpoint{Origin: lines[0], TreeSize: size, RootHash: hash, Extensions: extensions}
+	return nil
+}
+
+// checkLine returns an error if s can't be a line of a checkpoint.
+func checkLine(s string) error {
+	if s == "" {
+		return errors.New("empty line")
+	}
+	if strings.Contains(s, "\n") {
+		return errors.New("contains a newline")
+	}
+	return nil
+}
@@ -0,0 +1,87 @@
+// Copyright 2020 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package types
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestCheckpointText(t *testing.T) {
+	for _, test := range []struct {
+		desc string
+		c    Checkpoint
+		text string
+	}{
+		{
+			desc: "empty",
+			c:    Checkpoint{Origin: "example.com/log", RootHash: []byte{}},
+			text: "example.com/log\n0\n\n",
+		},
+		{
+			desc: "root",
+			c:    Checkpoint{Origin: "log 1", TreeSize: 1234, RootHash: []byte("root hash")},
+			text: "log 1\n1234\ncm9vdCBoYXNo\n",
+		},
+		{
+			desc: "extensions",
+			c:    Checkpoint{Origin: "log", TreeSize: 1, RootHash: []byte{1}, Extensions: []string{"a", "b c"}},
+			text: "log\n1\nAQ==\na\nb c\n",
+		},
+	} {
+		t.Run(test.desc, func(t *testing.T) {
+			text, err := test.c.MarshalText()
+			if err != nil {
+				t.Fatalf("MarshalText(): %v", err)
+			}
+			if got := string(text); got != test.text {
+				t.Errorf("MarshalText() = %q, want %q", got, test.text)
+			}
+			var c Checkpoint
+			if err := c.UnmarshalText(text); err != nil {
+				t.Fatalf("UnmarshalText(): %v", err)
+			}
+			if !reflect.DeepEqual(c, test.c) {
+				t.Errorf("UnmarshalText() = %+v, want %+v", c, test.c)
+			}
+		})
+	}
+}
+
+func TestCheckpointInvalid(t *testing.T) {
+	for _, c := range []Checkpoint{
+		{Origin: ""},
+		{Origin: "two\nlines"},
+		{Origin: "log", Extensions: []string{""}},
+	} {
+		if _, err := c.MarshalText(); err == nil {
+			t.Errorf("MarshalText(%+v) succeeded, want error", c)
+		}
+	}
+	for _, text := range []string{
+		"log\n1\nAQ==",
+		"log\n1\n",
+		"\n1\nAQ==\n",
+		"log\n01\nAQ==\n",
+		"log\n-1\nAQ==\n",
+		"log\n1\nnot base64\n",
+		"log\n1\nAQ==\n\n",
+	} {
+		var c Checkpoint
+		if err := c.UnmarshalText([]byte(text)); err == nil {
+			t.Errorf("UnmarshalText(%q) succeeded, want error", text)
+		}
+	}
+}
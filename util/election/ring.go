@@ -0,0 +1,90 @@
+// Copyright 2020 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package election
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"sort"
+	"strconv"
+)
+
+// DefaultRingReplicas is the number of points that each member occupies on a
+// HashRing by default.
+const DefaultRingReplicas = 64
+
+// HashRing assigns resources to a set of members using consistent hashing, so
+// that when a member joins or leaves only the resources that it gains or loses
+// change their owner. Every member is placed at several points of the ring to
+// spread the resources evenly.
+type HashRing struct {
+	members []string
+	points  []ringPoint
+}
+
+type ringPoint struct {
+	hash   uint64
+	member string
+}
+
+// NewHashRing creates a HashRing for the given members, each of which is put
+// at the given number of points of the ring. Instances created with the same
+// members and replicas make the same assignments.
+func NewHashRing(members []string, replicas int) *HashRing {
+	if replicas < 1 {
+		replicas = 1
+	}
+	sorted := make([]string, len(members))
+	copy(sorted, members)
+	sort.Strings(sorted)
+
+	points := make([]ringPoint, 0, len(sorted)*replicas)
+	for _, m := range sorted {
+		for i := 0; i < replicas; i++ {
+			points = append(points, ringPoint{hash: ringHash(m + "#" + strconv.Itoa(i)), member: m})
+		}
+	}
+	sort.Slice(points, func(i, j int) bool {
+		if points[i].hash != points[j].hash {
+			return points[i].hash < points[j].hash
+		}
+		return points[i].member < points[j].member
+	})
+	return &HashRing{members: sorted, points: points}
+}
+
+// Members returns the sorted list of members of the ring.
+func (r *HashRing) Members() []string {
+	return r.members
+}
+
+// Owner returns the member that the resource with the given ID is assigned to,
+// or an empty string if the ring has no members.
+func (r *HashRing) Owner(id string) string {
+	if len(r.points) == 0 {
+		return ""
+	}
+	h := ringHash(id)
+	i := sort.Search(len(r.points), func(i int) bool { return r.points[i].hash >= h })
+	if i == len(r.points) {
+		i = 0 // Wrap around.
+	}
+	return r.points[i].member
+}
+
+func ringHash(s string) uint64 {
+	sum := sha256.Sum256([]byte(s))
+	return binary.BigEndian.Uint64(sum[:8])
+}
@@ -0,0 +1,94 @@
+// Copyright 2020 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package election
+
+import (
+	"strconv"
+	"testing"
+)
+
+func assign(r *HashRing, ids int) map[string]string {
+	owners := make(map[string]string)
+	for i := 0; i < ids; i++ {
+		id := strconv.Itoa(i)
+		owners[id] = r.Owner(id)
+	}
+	return owners
+}
+
+func TestHashRingEmpty(t *testing.T) {
+	r := NewHashRing(nil, DefaultRingReplicas)
+	if got := r.Owner("1"); got != "" {
+		t.Errorf("Owner()=%q, want empty", got)
+	}
+}
+
+func TestHashRingDeterministic(t *testing.T) {
+	r1 := NewHashRing([]string{"a", "b", "c"}, DefaultRingReplicas)
+	r2 := NewHashRing([]string{"c", "a", "b"}, DefaultRingReplicas)
+	for id, owner := range assign(r1, 1000) {
+		if got := r2.Owner(id); got != owner {
+			t.Errorf("Owner(%s)=%q, want %q", id, got, owner)
+		}
+	}
+}
+
+func TestHashRingBalance(t *testing.T) {
+	const ids = 10000
+	members := []string{"a", "b", "c", "d", "e"}
+	counts := make(map[string]int)
+	for _, owner := range assign(NewHashRing(members, DefaultRingReplicas), ids) {
+		counts[owner]++
+	}
+	for _, m := range members {
+		// Each member should get roughly 1/5 of the resources.
+		if got, avg := counts[m], ids/len(members); got < avg/2 || got > avg*3/2 {
+			t.Errorf("member %s owns %d resources, want around %d", m, got, avg)
+		}
+	}
+}
+
+func TestHashRingMinimalMovement(t *testing.T) {
+	const ids = 10000
+	members := []string{"a", "b", "c", "d"}
+	before := assign(NewHashRing(members, DefaultRingReplicas), ids)
+
+	for _, tc := range []struct {
+		desc    string
+		members []string
+		changed string
+	}{
+		{desc: "join", members: append([]string{"e"}, members...), changed: "e"},
+		{desc: "leave", members: members[1:], changed: "a"},
+	} {
+		t.Run(tc.desc, func(t *testing.T) {
+			after := assign(NewHashRing(tc.members, DefaultRingReplicas), ids)
+			moved := 0
+			for id, owner := range before {
+				if after[id] == owner {
+					continue
+				}
+				moved++
+				// Only the resources of the changed member may move.
+				if owner != tc.changed && after[id] != tc.changed {
+					t.Errorf("%s moved from %s to %s", id, owner, after[id])
+				}
+			}
+			if max := ids * 2 / 5; moved > max {
+				t.Errorf("%d resources moved, want at most %d", moved, max)
+			}
+		})
+	}
+}
@@ -16,8 +16,10 @@ package election
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"math/rand"
+	"sync"
 	"time"
 
 	"github.com/golang/glog"
@@ -72,6 +74,10 @@ func fixupRunnerConfig(cfg *RunnerConfig) {
 	}
 }
 
+// errResigned is returned by beMaster when mastership has been resigned by a
+// call to Resign, which stops the election process.
+var errResigned = errors.New("resigned on request")
+
 // Runner controls a continuous election process.
 type Runner struct {
 	// Allow the user to store a Cancel function with the runner for convenience.
@@ -80,6 +86,17 @@ type Runner struct {
 	cfg      *RunnerConfig
 	tracker  *MasterTracker
 	election election2.Election
+
+	// term holds the channels through which Resign reaches the Run goroutine
+	// while it is master, or nil otherwise.
+	mu   sync.Mutex
+	term *masterTerm
+}
+
+// masterTerm is a period of mastership of a Runner.
+type masterTerm struct {
+	resign chan chan error // Requests to resign, with a channel for the result.
+	done   chan struct{}   // Closed at the end of the term.
 }
 
 // NewRunner builds a new election Runner instance with the given configuration.  On calling
@@ -115,7 +132,10 @@ func (er *Runner) Run(ctx context.Context, pending chan<- Resignation) {
 	}()
 
 	for {
-		if err := er.beMaster(ctx, pending); err != nil {
+		if err := er.beMaster(ctx, pending); err == errResigned {
+			glog.Infof("%s: stopping election after resigning", er.id)
+			break
+		} else if err != nil {
 			glog.Errorf("%s: %v", er.id, err)
 			break
 		}
@@ -127,7 +147,12 @@ func (er *Runner) beMaster(ctx context.Context, pending chan<- Resignation) erro
 	if err := er.election.Await(ctx); err != nil {
 		return fmt.Errorf("election.Await() failed: %v", err)
 	}
-	glog.Infof("%s: Now, I am the master", er.id)
+	token, err := er.election.FencingToken(ctx)
+	if err != nil {
+		return fmt.Errorf("election.FencingToken() failed: %v", err)
+	}
+	glog.Infof("%s: Now, I am the master (fencing token %d)", er.id, token)
+	er.tracker.SetFencingToken(er.id, token)
 	er.tracker.Set(er.id, true)
 	defer er.tracker.Set(er.id, false)
 
@@ -139,18 +164,44 @@ func (er *Runner) beMaster(ctx context.Context, pending chan<- Resignation) erro
 	timer := er.cfg.TimeSource.NewTimer(er.cfg.ResignDelay())
 	defer timer.Stop()
 
+	term := &masterTerm{resign: make(chan chan error), done: make(chan struct{})}
+	er.mu.Lock()
+	er.term = term
+	er.mu.Unlock()
+	defer func() {
+		er.mu.Lock()
+		er.term = nil
+		er.mu.Unlock()
+		close(term.done)
+	}()
+
 	select {
 	case <-mctx.Done(): // Mastership context is canceled.
 		glog.Errorf("%s: no longer the master!", er.id)
 		return mctx.Err()
 
+	case errc := <-term.resign:
+		errc <- er.election.Resign(ctx)
+		return errResigned
+
 	case <-timer.Chan():
 		glog.Infof("%s: queue up resignation of mastership", er.id)
 		done := make(chan struct{})
 		r := Resignation{ID: er.id, er: er, done: done}
 		select {
 		case pending <- r:
-			<-done // Block until acted on.
+			// Block until acted on. Resign may be called by the goroutine
+			// which executes the resignation in the meantime, so serve it.
+			var err error
+			for {
+				select {
+				case <-done:
+					return err
+				case errc := <-term.resign:
+					errc <- er.election.Resign(ctx)
+					err = errResigned
+				}
+			}
 		default:
 			glog.Warning("Dropping resignation because operation manager seems to be exiting")
 		}
@@ -158,6 +209,28 @@ func (er *Runner) beMaster(ctx context.Context, pending chan<- Resignation) erro
 	return nil
 }
 
+// Resign releases mastership if the runner holds it, and then stops the
+// election process. The election is resigned by the Run goroutine, so that it
+// is never used concurrently, and Resign returns nil straight away if the
+// runner is not master.
+func (er *Runner) Resign(ctx context.Context) error {
+	er.mu.Lock()
+	term := er.term
+	er.mu.Unlock()
+	if term == nil {
+		return nil
+	}
+	errc := make(chan error, 1)
+	select {
+	case term.resign <- errc:
+		return <-errc
+	case <-term.done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
 // Resignation indicates that a master should explicitly resign mastership, by invoking
 // the Execute() method at a point where no master-related activity is ongoing.
 type Resignation struct {
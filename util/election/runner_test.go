@@ -157,3 +157,47 @@ func TestElectionRunnerRun(t *testing.T) {
 		})
 	}
 }
+
+func TestElectionRunnerResign(t *testing.T) {
+	const logID = "6962"
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	e := to.NewElection()
+	start := time.Now()
+	ts := clock.NewFake(start)
+	tracker := election.NewMasterTracker([]string{logID}, nil)
+	cfg := election.RunnerConfig{TimeSource: ts}
+	er := election.NewRunner(logID, &cfg, tracker, nil, e)
+
+	// Resigning does nothing while the runner is not master.
+	if err := er.Resign(ctx); err != nil {
+		t.Fatalf("Resign() before Run: %v", err)
+	}
+
+	stopped := make(chan struct{})
+	go func() {
+		defer close(stopped)
+		er.Run(ctx, make(chan election.Resignation, 1))
+	}()
+	time.Sleep(100 * time.Millisecond) // Let Run create its Timer.
+	ts.Set(start.Add(election.MinPreElectionPause))
+	for len(tracker.Held()) == 0 {
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	if err := er.Resign(ctx); err != nil {
+		t.Fatalf("Resign(): %v", err)
+	}
+	select {
+	case <-stopped:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Run() didn't return after Resign()")
+	}
+	if held := tracker.Held(); len(held) != 0 {
+		t.Errorf("Held()=%v after Resign(), want none", held)
+	}
+	if err := er.Resign(ctx); err != nil {
+		t.Errorf("Resign() after Run: %v", err)
+	}
+}
@@ -28,6 +28,7 @@ type MasterTracker struct {
 	mu          sync.RWMutex
 	masterFor   map[string]bool
 	masterCount int
+	tokens      map[string]int64
 	notify      func(id string, isMaster bool)
 }
 
@@ -38,7 +39,7 @@ func NewMasterTracker(ids []string, notify func(id string, isMaster bool)) *Mast
 	for _, id := range ids {
 		mf[id] = false
 	}
-	return &MasterTracker{masterFor: mf, notify: notify}
+	return &MasterTracker{masterFor: mf, tokens: make(map[string]int64), notify: notify}
 }
 
 // Set changes the tracked mastership status for the given id.  This method should
@@ -56,11 +57,31 @@ func (mt *MasterTracker) Set(id string, val bool) {
 	} else if !val && existing {
 		mt.masterCount--
 	}
+	if !val {
+		delete(mt.tokens, id)
+	}
 	if mt.notify != nil {
 		mt.notify(id, val)
 	}
 }
 
+// SetFencingToken records the fencing token of the mastership for the given
+// id. The token is forgotten when the id stops being tracked as mastered.
+func (mt *MasterTracker) SetFencingToken(id string, token int64) {
+	mt.mu.Lock()
+	defer mt.mu.Unlock()
+	mt.tokens[id] = token
+}
+
+// FencingToken returns the fencing token of the mastership for the given id.
+// The returned bool is false if mastership is not held for the id.
+func (mt *MasterTracker) FencingToken(id string) (int64, bool) {
+	mt.mu.RLock()
+	defer mt.mu.RUnlock()
+	token, ok := mt.tokens[id]
+	return token, ok
+}
+
 // Count returns the number of IDs for which we are currently master.
 func (mt *MasterTracker) Count() int {
 	mt.mu.RLock()
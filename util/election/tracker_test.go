@@ -107,3 +107,20 @@ func TestMasterTracker(t *testing.T) {
 		}
 	}
 }
+
+func TestMasterTrackerFencingToken(t *testing.T) {
+	mt := NewMasterTracker([]string{"1", "2"}, nil)
+	mt.SetFencingToken("1", 10)
+	mt.Set("1", true)
+	if got, ok := mt.FencingToken("1"); !ok || got != 10 {
+		t.Errorf("FencingToken(1)=%d, %v; want 10, true", got, ok)
+	}
+	if got, ok := mt.FencingToken("2"); ok {
+		t.Errorf("FencingToken(2)=%d, %v; want not held", got, ok)
+	}
+	// The token is forgotten once mastership is lost.
+	mt.Set("1", false)
+	if got, ok := mt.FencingToken("1"); ok {
+		t.Errorf("FencingToken(1)=%d, %v after losing mastership, want not held", got, ok)
+	}
+}
@@ -31,7 +31,13 @@
 // TODO(pavelkalinnikov): Merge this package with util/election.
 package election2
 
-import "context"
+import (
+	"context"
+	"errors"
+)
+
+// ErrNotMaster is returned by FencingToken if the instance is not the master.
+var ErrNotMaster = errors.New("not the master")
 
 // Election controls an instance's participation in master election process.
 // Note: Implementations are not intended to be thread-safe.
@@ -70,6 +76,18 @@ type Election interface {
 	// Resign to have best protection against double-master situations.
 	Resign(ctx context.Context) error
 
+	// FencingToken returns the fencing token of the current mastership, which
+	// is greater than the tokens of all the preceding masters of the resource.
+	// A master passes the token along with its writes, so that storage can
+	// reject writes from former masters once a newer one has written. Returns
+	// ErrNotMaster if the instance has not captured mastership, or has resigned.
+	// The token is not re-validated with the election backend, so it can be
+	// returned after mastership has been overtaken.
+	//
+	// Tokens are only ordered within one implementation: the tokens of
+	// different election backends can't be compared.
+	FencingToken(ctx context.Context) (int64, error)
+
 	// Close permanently stops participating in election, and releases the
 	// resources. It does best effort on resigning despite potential cancelation
 	// of the passed in context, so that other instances can overtake mastership
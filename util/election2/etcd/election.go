@@ -100,6 +100,16 @@ func (e *Election) WithMastership(ctx context.Context) (context.Context, error)
 	return cctx, nil
 }
 
+// FencingToken returns the etcd revision at which the instance became the
+// master. Each master's election key is created after those of all the
+// preceding masters, so the revisions grow with each change of mastership.
+func (e *Election) FencingToken(ctx context.Context) (int64, error) {
+	if e.election.Key() == "" {
+		return 0, election2.ErrNotMaster
+	}
+	return e.election.Rev(), nil
+}
+
 // Resign releases mastership for this instance. The instance can be elected
 // again using Await. Idempotent, might be useful to retry if fails.
 func (e *Election) Resign(ctx context.Context) error {
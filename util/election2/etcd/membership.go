@@ -0,0 +1,152 @@
+// Copyright 2020 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package etcd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang/glog"
+	"go.etcd.io/etcd/clientv3"
+	"go.etcd.io/etcd/clientv3/concurrency"
+)
+
+// retryInterval is how long Membership waits before retrying to register the
+// instance again after its session has expired.
+const retryInterval = time.Second
+
+// Membership is an implementation of election2.Membership based on etcd. Each
+// instance holds a key in a common directory, which is attached to the lease
+// of its session, so it disappears once the instance stops keeping it alive.
+// If the session expires, e.g. because etcd was unreachable for longer than
+// the lease TTL, the instance registers again with a new session.
+type Membership struct {
+	instanceID string
+	dir        string
+	client     *clientv3.Client
+
+	mu      sync.Mutex
+	session *concurrency.Session
+
+	cancel context.CancelFunc
+	done   chan struct{} // Closed when keepRegistered returns.
+}
+
+// NewMembership registers the instance as a member under the given etcd
+// directory. The passed in etcd client should remain valid for the lifetime
+// of the object.
+func NewMembership(ctx context.Context, instanceID string, client *clientv3.Client, dir string) (*Membership, error) {
+	m := &Membership{
+		instanceID: instanceID,
+		dir:        strings.TrimRight(dir, "/") + "/",
+		client:     client,
+		done:       make(chan struct{}),
+	}
+	session, err := m.register(ctx, concurrency.WithContext(ctx))
+	if err != nil {
+		return nil, err
+	}
+	m.session = session
+	var cctx context.Context
+	cctx, m.cancel = context.WithCancel(context.Background())
+	go m.keepRegistered(cctx, session)
+	return m, nil
+}
+
+// register creates a new session and puts the member key under its lease.
+func (m *Membership) register(ctx context.Context, opts ...concurrency.SessionOption) (*concurrency.Session, error) {
+	session, err := concurrency.NewSession(m.client, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create etcd session: %v", err)
+	}
+	if _, err := m.client.Put(ctx, m.dir+m.instanceID, m.instanceID, clientv3.WithLease(session.Lease())); err != nil {
+		session.Close()
+		return nil, fmt.Errorf("failed to register member %s: %v", m.instanceID, err)
+	}
+	glog.Infof("Registered member %s in %s", m.instanceID, m.dir)
+	return session, nil
+}
+
+// keepRegistered registers the instance again whenever its session expires,
+// until the context is canceled.
+func (m *Membership) keepRegistered(ctx context.Context, session *concurrency.Session) {
+	defer close(m.done)
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-session.Done():
+		}
+		glog.Warningf("etcd session of member %s expired, registering again", m.instanceID)
+		for {
+			var err error
+			if session, err = m.register(ctx, concurrency.WithContext(m.client.Ctx())); err == nil {
+				break
+			}
+			glog.Errorf("Failed to register member %s again: %v", m.instanceID, err)
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(retryInterval):
+			}
+		}
+		m.mu.Lock()
+		m.session = session
+		m.mu.Unlock()
+	}
+}
+
+// ID returns the ID of this instance.
+func (m *Membership) ID() string {
+	return m.instanceID
+}
+
+// Members returns the sorted IDs of all the currently registered instances.
+func (m *Membership) Members(ctx context.Context) ([]string, error) {
+	m.mu.Lock()
+	session := m.session
+	m.mu.Unlock()
+	select {
+	case <-session.Done():
+		return nil, errors.New("etcd session expired")
+	default:
+	}
+	rsp, err := m.client.Get(ctx, m.dir, clientv3.WithPrefix())
+	if err != nil {
+		return nil, err
+	}
+	ids := make([]string, 0, len(rsp.Kvs))
+	for _, kv := range rsp.Kvs {
+		ids = append(ids, string(kv.Value))
+	}
+	sort.Strings(ids)
+	return ids, nil
+}
+
+// Close deregisters the instance. No other method should be called after
+// Close.
+func (m *Membership) Close(ctx context.Context) error {
+	m.cancel()
+	<-m.done
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	// Revoking the session's lease removes the member key.
+	return m.session.Close()
+}
@@ -0,0 +1,93 @@
+// Copyright 2020 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package etcd
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/google/trillian/testonly/integration/etcd"
+)
+
+func TestMembership(t *testing.T) {
+	_, client, cleanup, err := etcd.StartEtcd()
+	if err != nil {
+		t.Fatalf("StartEtcd(): %v", err)
+	}
+	defer cleanup()
+
+	ctx := context.Background()
+	members := func(m *Membership, want ...string) {
+		t.Helper()
+		got, err := m.Members(ctx)
+		if err != nil {
+			t.Fatalf("Members(): %v", err)
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("Members()=%v, want %v", got, want)
+		}
+	}
+
+	m2, err := NewMembership(ctx, "serv2", client, "members")
+	if err != nil {
+		t.Fatalf("NewMembership(serv2): %v", err)
+	}
+	members(m2, "serv2")
+	m1, err := NewMembership(ctx, "serv1", client, "members/")
+	if err != nil {
+		t.Fatalf("NewMembership(serv1): %v", err)
+	}
+	members(m1, "serv1", "serv2")
+	members(m2, "serv1", "serv2")
+
+	// Members of another directory are not visible.
+	other, err := NewMembership(ctx, "serv3", client, "other")
+	if err != nil {
+		t.Fatalf("NewMembership(serv3): %v", err)
+	}
+	defer other.Close(ctx)
+	members(other, "serv3")
+
+	// A member whose session expires registers again with a new one.
+	m1.mu.Lock()
+	lease := m1.session.Lease()
+	m1.mu.Unlock()
+	if _, err := client.Revoke(ctx, lease); err != nil {
+		t.Fatalf("Revoke(): %v", err)
+	}
+	for i := 0; ; i++ {
+		got, err := m2.Members(ctx)
+		if err != nil {
+			t.Fatalf("Members(): %v", err)
+		}
+		if reflect.DeepEqual(got, []string{"serv1", "serv2"}) {
+			break
+		}
+		if i == 100 {
+			t.Fatalf("Members()=%v after session expiry, want serv1 to register again", got)
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+
+	if err := m2.Close(ctx); err != nil {
+		t.Fatalf("Close(serv2): %v", err)
+	}
+	members(m1, "serv1")
+	if err := m1.Close(ctx); err != nil {
+		t.Fatalf("Close(serv1): %v", err)
+	}
+}
@@ -0,0 +1,65 @@
+// Copyright 2020 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package election2
+
+import (
+	"context"
+	"sort"
+)
+
+// Membership tracks the set of instances that take part in elections for a
+// common set of resources, so that they can agree on how to share them out.
+// An instance is registered as a member for as long as its Membership object
+// is alive and not closed.
+type Membership interface {
+	// ID returns the ID of this instance.
+	ID() string
+
+	// Members returns the sorted IDs of all the currently registered instances.
+	// The list does not include this instance if it has lost its registration,
+	// e.g. due to a network partition.
+	Members(ctx context.Context) ([]string, error)
+
+	// Close deregisters this instance. No other method should be called after
+	// Close.
+	Close(ctx context.Context) error
+}
+
+// StaticMembership is a Membership with a fixed set of instances, for
+// deployments where the instances are known in advance.
+type StaticMembership struct {
+	// Self is the ID of this instance.
+	Self string
+	// All holds the IDs of all the instances, including Self.
+	All []string
+}
+
+// ID returns the ID of this instance.
+func (m StaticMembership) ID() string {
+	return m.Self
+}
+
+// Members returns the sorted IDs of all the instances.
+func (m StaticMembership) Members(ctx context.Context) ([]string, error) {
+	ids := make([]string, len(m.All))
+	copy(ids, m.All)
+	sort.Strings(ids)
+	return ids, nil
+}
+
+// Close does nothing because the set of instances is fixed.
+func (m StaticMembership) Close(ctx context.Context) error {
+	return nil
+}
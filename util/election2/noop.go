@@ -34,6 +34,12 @@ func (ne NoopElection) Resign(ctx context.Context) error {
 	return nil
 }
 
+// FencingToken returns zero, which means that writes are not fenced, because
+// NoopElection is always the master.
+func (ne NoopElection) FencingToken(ctx context.Context) (int64, error) {
+	return 0, nil
+}
+
 // Close does nothing because NoopElection is always the master.
 func (ne NoopElection) Close(ctx context.Context) error {
 	return nil
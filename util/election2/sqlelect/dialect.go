@@ -0,0 +1,44 @@
+// Copyright 2020 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sqlelect
+
+const (
+	// mySQLNowMicros is the current time of the MySQL server in microseconds.
+	mySQLNowMicros = "CAST(UNIX_TIMESTAMP(NOW(6)) * 1000000 AS SIGNED)"
+	// postgresNowMicros is the current time of the PostgreSQL server in
+	// microseconds. Unlike now(), clock_timestamp() is not frozen at the start
+	// of the transaction.
+	postgresNowMicros = "CAST(EXTRACT(EPOCH FROM clock_timestamp()) * 1000000 AS BIGINT)"
+)
+
+var mySQLDialect = dialect{
+	insert: "INSERT IGNORE INTO MasterLease(ResourceId,MasterId,ExpiryMicros,FencingToken) VALUES(?,'',0,0)",
+	acquire: `UPDATE MasterLease SET MasterId=?,ExpiryMicros=` + mySQLNowMicros + `+?,FencingToken=FencingToken+1
+		WHERE ResourceId=? AND ExpiryMicros<` + mySQLNowMicros,
+	selectToken: "SELECT FencingToken FROM MasterLease WHERE ResourceId=?",
+	renew: `UPDATE MasterLease SET ExpiryMicros=` + mySQLNowMicros + `+?
+		WHERE ResourceId=? AND FencingToken=? AND ExpiryMicros>=` + mySQLNowMicros,
+	release: "UPDATE MasterLease SET ExpiryMicros=0 WHERE ResourceId=? AND FencingToken=?",
+}
+
+var postgresDialect = dialect{
+	insert: "INSERT INTO master_lease(resource_id,master_id,expiry_micros,fencing_token) VALUES($1,'',0,0) ON CONFLICT DO NOTHING",
+	acquire: `UPDATE master_lease SET master_id=$1,expiry_micros=` + postgresNowMicros + `+$2,fencing_token=fencing_token+1
+		WHERE resource_id=$3 AND expiry_micros<` + postgresNowMicros,
+	selectToken: "SELECT fencing_token FROM master_lease WHERE resource_id=$1",
+	renew: `UPDATE master_lease SET expiry_micros=` + postgresNowMicros + `+$1
+		WHERE resource_id=$2 AND fencing_token=$3 AND expiry_micros>=` + postgresNowMicros,
+	release: "UPDATE master_lease SET expiry_micros=0 WHERE resource_id=$1 AND fencing_token=$2",
+}
@@ -0,0 +1,294 @@
+// Copyright 2020 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package sqlelect provides an implementation of master election based on
+// lease rows in a MySQL or PostgreSQL database, which can be the database used
+// for Trillian storage, so that deployments don't need to run etcd.
+//
+// Each resource has a single row holding the ID of the master, the expiry time
+// of its lease, and the fencing token of the mastership. An instance captures
+// mastership by updating the row once the lease has expired, and keeps it by
+// extending the lease in the background. Lease times are taken from the
+// database clock, so the clocks of the instances don't need to be in sync.
+package sqlelect
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/golang/glog"
+	"github.com/google/trillian/util/clock"
+	"github.com/google/trillian/util/election2"
+)
+
+// DefaultLeaseTTL is the default duration of mastership leases.
+const DefaultLeaseTTL = 10 * time.Second
+
+// dialect holds the statements used for elections in a database engine. The
+// statements take the arguments in the order documented for each of them.
+type dialect struct {
+	// insert creates the row of a resource if it doesn't exist.
+	// Args: resource.
+	insert string
+	// acquire takes the lease of a resource if it has expired, and increments
+	// the fencing token. Args: master, lease micros, resource.
+	acquire string
+	// selectToken returns the fencing token of a resource. Args: resource.
+	selectToken string
+	// renew extends the lease of a resource if it is still held under the
+	// fencing token. Args: lease micros, resource, token.
+	renew string
+	// release ends the lease of a resource held under the fencing token.
+	// Args: resource, token.
+	release string
+}
+
+// Election is an implementation of election2.Election based on a lease row in
+// a database.
+type Election struct {
+	db         *sql.DB
+	d          *dialect
+	resourceID string
+	instanceID string
+	ttl        time.Duration
+
+	mu sync.Mutex
+	// token is the fencing token of the current mastership, or zero if the
+	// instance is not the master.
+	token int64
+	// lost is closed when the current mastership ends.
+	lost chan struct{}
+	// stop ends the lease renewal of the current mastership.
+	stop context.CancelFunc
+}
+
+// Await blocks until the instance captures mastership.
+func (e *Election) Await(ctx context.Context) error {
+	e.mu.Lock()
+	held := e.token != 0
+	e.mu.Unlock()
+	if held {
+		return nil
+	}
+	for {
+		start := time.Now()
+		token, err := e.acquire(ctx)
+		if err != nil {
+			return err
+		}
+		if token != 0 {
+			e.becomeMaster(token, start.Add(e.ttl))
+			return nil
+		}
+		// Poll for the lease to expire.
+		if err := clock.SleepContext(ctx, e.ttl/4); err != nil {
+			return err
+		}
+	}
+}
+
+// acquire tries to take the lease of the resource, and returns the fencing
+// token of the new mastership, or zero if the lease is held by another master.
+func (e *Election) acquire(ctx context.Context) (int64, error) {
+	tx, err := e.db.BeginTx(ctx, nil /* opts */)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+	res, err := tx.ExecContext(ctx, e.d.acquire, e.instanceID, e.ttl.Microseconds(), e.resourceID)
+	if err != nil {
+		return 0, err
+	}
+	if n, err := res.RowsAffected(); err != nil || n == 0 {
+		return 0, err
+	}
+	var token int64
+	if err := tx.QueryRowContext(ctx, e.d.selectToken, e.resourceID).Scan(&token); err != nil {
+		return 0, err
+	}
+	if err := tx.Commit(); err != nil {
+		return 0, err
+	}
+	return token, nil
+}
+
+// becomeMaster records the mastership under the given token, and starts
+// renewing its lease, which is known to be valid until the deadline.
+func (e *Election) becomeMaster(token int64, deadline time.Time) {
+	ctx, cancel := context.WithCancel(context.Background())
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.token, e.lost, e.stop = token, make(chan struct{}), cancel
+	go e.renew(ctx, token, deadline)
+}
+
+// renew extends the lease of the mastership under the given token until the
+// context is canceled, or the lease can't be extended before the deadline.
+func (e *Election) renew(ctx context.Context, token int64, deadline time.Time) {
+	defer e.lose(token)
+	for {
+		if err := clock.SleepContext(ctx, e.ttl/3); err != nil {
+			return
+		}
+		start := time.Now()
+		rctx, cancel := context.WithDeadline(ctx, deadline)
+		res, err := e.db.ExecContext(rctx, e.d.renew, e.ttl.Microseconds(), e.resourceID, token)
+		cancel()
+		var n int64
+		if err == nil {
+			n, err = res.RowsAffected()
+		}
+		switch {
+		case ctx.Err() != nil:
+			return
+		case err == nil && n == 0:
+			glog.Warningf("%s: lease lost", e.resourceID)
+			return
+		case err == nil:
+			deadline = start.Add(e.ttl)
+		case !time.Now().Before(deadline):
+			glog.Errorf("%s: lease expired, last renewal failed: %v", e.resourceID, err)
+			return
+		default:
+			glog.Warningf("%s: failed to renew lease: %v", e.resourceID, err)
+		}
+	}
+}
+
+// lose ends the mastership under the given token, if it is the current one.
+func (e *Election) lose(token int64) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if e.token != token || token == 0 {
+		return
+	}
+	e.stop()
+	close(e.lost)
+	e.token, e.lost, e.stop = 0, nil, nil
+}
+
+// WithMastership returns a "mastership context" which remains active until the
+// instance stops being the master, or the passed in context is canceled.
+func (e *Election) WithMastership(ctx context.Context) (context.Context, error) {
+	cctx, cancel := context.WithCancel(ctx)
+	e.mu.Lock()
+	lost := e.lost
+	e.mu.Unlock()
+	if lost == nil {
+		cancel()
+		return cctx, nil
+	}
+	go func() {
+		defer cancel()
+		select {
+		case <-lost:
+		case <-cctx.Done():
+		}
+	}()
+	return cctx, nil
+}
+
+// FencingToken returns the fencing token of the current mastership, which is
+// incremented by each instance capturing mastership of the resource.
+func (e *Election) FencingToken(ctx context.Context) (int64, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if e.token == 0 {
+		return 0, election2.ErrNotMaster
+	}
+	return e.token, nil
+}
+
+// Resign releases mastership for this instance. The instance can be elected
+// again using Await. Idempotent, might be useful to retry if fails.
+func (e *Election) Resign(ctx context.Context) error {
+	e.mu.Lock()
+	token := e.token
+	e.mu.Unlock()
+	if token == 0 {
+		return nil // Resigning if not master is a no-op.
+	}
+	if _, err := e.db.ExecContext(ctx, e.d.release, e.resourceID, token); err != nil {
+		return err
+	}
+	e.lose(token)
+	return nil
+}
+
+// Close resigns and permanently stops participating in election. No other
+// method should be called after Close.
+func (e *Election) Close(ctx context.Context) error {
+	e.mu.Lock()
+	token := e.token
+	e.mu.Unlock()
+	// Stop renewing the lease even if resigning fails, so that it expires.
+	defer e.lose(token)
+	if ctx.Err() != nil {
+		// Do best effort on resigning, so that other instances can overtake
+		// mastership faster.
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(context.Background(), e.ttl)
+		defer cancel()
+	}
+	return e.Resign(ctx)
+}
+
+// Factory creates Election instances.
+type Factory struct {
+	db         *sql.DB
+	d          *dialect
+	instanceID string
+	lockDir    string
+	ttl        time.Duration
+}
+
+// NewMySQLFactory builds an election factory that uses the MasterLease table of
+// the given MySQL database, with leases of the given duration. The lock
+// directory is prepended to resource IDs, to separate unrelated elections.
+func NewMySQLFactory(instanceID string, db *sql.DB, lockDir string, ttl time.Duration) *Factory {
+	return newFactory(instanceID, db, &mySQLDialect, lockDir, ttl)
+}
+
+// NewPostgresFactory builds an election factory that uses the master_lease
+// table of the given PostgreSQL database, with leases of the given duration.
+// The lock directory is prepended to resource IDs, to separate unrelated
+// elections.
+func NewPostgresFactory(instanceID string, db *sql.DB, lockDir string, ttl time.Duration) *Factory {
+	return newFactory(instanceID, db, &postgresDialect, lockDir, ttl)
+}
+
+func newFactory(instanceID string, db *sql.DB, d *dialect, lockDir string, ttl time.Duration) *Factory {
+	if ttl <= 0 {
+		ttl = DefaultLeaseTTL
+	}
+	return &Factory{db: db, d: d, instanceID: instanceID, lockDir: lockDir, ttl: ttl}
+}
+
+// NewElection creates a specific Election instance.
+func (f *Factory) NewElection(ctx context.Context, resourceID string) (election2.Election, error) {
+	resourceID = f.lockDir + resourceID
+	if _, err := f.db.ExecContext(ctx, f.d.insert, resourceID); err != nil {
+		return nil, fmt.Errorf("failed to create lease for %s: %v", resourceID, err)
+	}
+	return &Election{
+		db:         f.db,
+		d:          f.d,
+		resourceID: resourceID,
+		instanceID: f.instanceID,
+		ttl:        f.ttl,
+	}, nil
+}
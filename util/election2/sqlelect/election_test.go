@@ -0,0 +1,130 @@
+// Copyright 2020 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sqlelect
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/google/trillian/storage/testdb"
+	"github.com/google/trillian/util/election2"
+	"github.com/google/trillian/util/election2/testonly"
+
+	pgtestdb "github.com/google/trillian/storage/postgres/testdb"
+	_ "github.com/lib/pq" // postgres driver
+)
+
+const testTTL = 2 * time.Second
+
+func TestMySQLElection(t *testing.T) {
+	testdb.SkipIfNoMySQL(t)
+	ctx := context.Background()
+	db, done, err := testdb.NewTrillianDB(ctx)
+	if err != nil {
+		t.Fatalf("NewTrillianDB(): %v", err)
+	}
+	defer done(ctx)
+
+	for _, nt := range testonly.Tests {
+		// Create a new Factory for each test for better isolation.
+		fact := NewMySQLFactory("testID", db, fmt.Sprintf("%s/resources/", nt.Name), testTTL)
+		t.Run(nt.Name, func(t *testing.T) {
+			nt.Run(t, fact)
+		})
+	}
+	t.Run("Takeover", func(t *testing.T) {
+		runTakeover(t, twoFactories(func(id string) election2.Factory {
+			return NewMySQLFactory(id, db, "takeover/", testTTL)
+		}))
+	})
+}
+
+func TestPostgresElection(t *testing.T) {
+	if !pgtestdb.PGAvailable() {
+		t.Skip("Skipping test as PostgreSQL not available")
+	}
+	ctx := context.Background()
+	db, done, err := pgtestdb.NewTrillianDB(ctx)
+	if err != nil {
+		t.Fatalf("NewTrillianDB(): %v", err)
+	}
+	defer done(ctx)
+
+	for _, nt := range testonly.Tests {
+		fact := NewPostgresFactory("testID", db, fmt.Sprintf("%s/resources/", nt.Name), testTTL)
+		t.Run(nt.Name, func(t *testing.T) {
+			nt.Run(t, fact)
+		})
+	}
+	t.Run("Takeover", func(t *testing.T) {
+		runTakeover(t, twoFactories(func(id string) election2.Factory {
+			return NewPostgresFactory(id, db, "takeover/", testTTL)
+		}))
+	})
+}
+
+func twoFactories(newFact func(id string) election2.Factory) [2]election2.Factory {
+	return [2]election2.Factory{newFact("inst1"), newFact("inst2")}
+}
+
+// runTakeover checks that a second instance only captures mastership once the
+// first one resigns, and does so with a greater fencing token.
+func runTakeover(t *testing.T, facts [2]election2.Factory) {
+	ctx := context.Background()
+	var els [2]election2.Election
+	for i, f := range facts {
+		e, err := f.NewElection(ctx, "res")
+		if err != nil {
+			t.Fatalf("NewElection(%d): %v", i, err)
+		}
+		defer e.Close(ctx)
+		els[i] = e
+	}
+
+	if err := els[0].Await(ctx); err != nil {
+		t.Fatalf("Await(0): %v", err)
+	}
+	token, err := els[0].FencingToken(ctx)
+	if err != nil {
+		t.Fatalf("FencingToken(0): %v", err)
+	}
+
+	// The lease is renewed, so the second instance stays blocked for longer
+	// than its duration.
+	cctx, cancel := context.WithTimeout(ctx, 2*testTTL)
+	defer cancel()
+	if err := els[1].Await(cctx); err != context.DeadlineExceeded {
+		t.Fatalf("Await(1): %v, want %v", err, context.DeadlineExceeded)
+	}
+
+	if err := els[0].Resign(ctx); err != nil {
+		t.Fatalf("Resign(0): %v", err)
+	}
+	if err := els[1].Await(ctx); err != nil {
+		t.Fatalf("Await(1): %v", err)
+	}
+	got, err := els[1].FencingToken(ctx)
+	if err != nil {
+		t.Fatalf("FencingToken(1): %v", err)
+	}
+	if got <= token {
+		t.Errorf("FencingToken(1): %d, want greater than %d", got, token)
+	}
+	if _, err := els[0].FencingToken(ctx); err != election2.ErrNotMaster {
+		t.Errorf("FencingToken(0): %v, want %v", err, election2.ErrNotMaster)
+	}
+}
@@ -25,6 +25,7 @@ import (
 type Errs struct {
 	Await          error
 	WithMastership error
+	FencingToken   error
 	Resign         error
 	Close          error
 }
@@ -88,6 +89,16 @@ func (d *Decorator) WithMastership(ctx context.Context) (context.Context, error)
 	return d.e.WithMastership(ctx)
 }
 
+// FencingToken returns the fencing token of the current mastership.
+func (d *Decorator) FencingToken(ctx context.Context) (int64, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if err := d.errs.FencingToken; err != nil {
+		return 0, err
+	}
+	return d.e.FencingToken(ctx)
+}
+
 // Resign releases mastership for this instance.
 func (d *Decorator) Resign(ctx context.Context) error {
 	d.mu.Lock()
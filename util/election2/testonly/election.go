@@ -29,6 +29,7 @@ var Factory factory
 type Election struct {
 	isMaster bool
 	revision int
+	token    int // The revision at which the instance became the master.
 	mu       sync.Mutex
 	cond     *sync.Cond
 }
@@ -54,6 +55,7 @@ func (e *Election) Await(ctx context.Context) error {
 	defer e.mu.Unlock()
 	if !e.isMaster {
 		e.update(true)
+		e.token = e.revision
 	}
 	return nil
 }
@@ -82,6 +84,16 @@ func (e *Election) WithMastership(ctx context.Context) (context.Context, error)
 	return cctx, nil
 }
 
+// FencingToken returns the revision at which the instance became the master.
+func (e *Election) FencingToken(ctx context.Context) (int64, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if !e.isMaster {
+		return 0, election2.ErrNotMaster
+	}
+	return int64(e.token), nil
+}
+
 // Resign resets mastership.
 func (e *Election) Resign(ctx context.Context) error {
 	e.mu.Lock()
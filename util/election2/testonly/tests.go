@@ -29,6 +29,8 @@ import (
 var Tests = []NamedTest{
 	{Name: "RunElectionAwait", Run: runElectionAwait},
 	{Name: "RunElectionWithMastership", Run: runElectionWithMastership},
+	{Name: "RunElectionFencingToken", Run: runElectionFencingToken},
+	{Name: "RunElectionFencingTokenGrows", Run: runElectionFencingTokenGrows},
 	{Name: "RunElectionResign", Run: runElectionResign},
 	{Name: "RunElectionClose", Run: runElectionClose},
 	{Name: "RunElectionLoop", Run: runElectionLoop},
@@ -150,6 +152,88 @@ func runElectionWithMastership(t *testing.T, f election2.Factory) {
 	}
 }
 
+// runElectionFencingToken tests the FencingToken call.
+func runElectionFencingToken(t *testing.T, f election2.Factory) {
+	tokenErr := errors.New("FencingToken error")
+	for _, tc := range []struct {
+		desc     string
+		beMaster bool
+		resign   bool
+		err      error
+		wantErr  error
+	}{
+		{desc: "master", beMaster: true},
+		{desc: "not-master", wantErr: election2.ErrNotMaster},
+		{desc: "resigned", beMaster: true, resign: true, wantErr: election2.ErrNotMaster},
+		{desc: "error", beMaster: true, err: tokenErr, wantErr: tokenErr},
+	} {
+		t.Run(tc.desc, func(t *testing.T) {
+			ctx := context.Background()
+			e, err := f.NewElection(ctx, tc.desc)
+			if err != nil {
+				t.Fatalf("NewElection(): %v", err)
+			}
+			d := NewDecorator(e)
+			defer d.Close(ctx)
+			d.Update(Errs{FencingToken: tc.err})
+
+			if tc.beMaster {
+				if err := d.Await(ctx); err != nil {
+					t.Fatalf("Await(): %v", err)
+				}
+			}
+			if tc.resign {
+				if err := d.Resign(ctx); err != nil {
+					t.Fatalf("Resign(): %v", err)
+				}
+			}
+			token, err := d.FencingToken(ctx)
+			if want := tc.wantErr; err != want {
+				t.Fatalf("FencingToken(): %v, want %v", err, want)
+			}
+			if err == nil && token <= 0 {
+				t.Errorf("FencingToken(): %d, want positive", token)
+			}
+		})
+	}
+}
+
+// runElectionFencingTokenGrows tests that each mastership gets a greater
+// fencing token than the previous ones.
+func runElectionFencingTokenGrows(t *testing.T, f election2.Factory) {
+	ctx := context.Background()
+	e, err := f.NewElection(ctx, "testID")
+	if err != nil {
+		t.Fatalf("NewElection(): %v", err)
+	}
+	defer e.Close(ctx)
+
+	var prev int64
+	for i := 0; i < 5; i++ {
+		if err := e.Await(ctx); err != nil {
+			t.Fatalf("Await(): %v", err)
+		}
+		token, err := e.FencingToken(ctx)
+		if err != nil {
+			t.Fatalf("FencingToken(): %v", err)
+		}
+		if token <= prev {
+			t.Errorf("FencingToken(): %d, want greater than %d", token, prev)
+		}
+		// The token does not change while the instance remains the master.
+		if err := e.Await(ctx); err != nil {
+			t.Fatalf("Await(): %v", err)
+		}
+		if got, err := e.FencingToken(ctx); err != nil || got != token {
+			t.Errorf("FencingToken(): %d, %v; want %d, nil", got, err, token)
+		}
+		if err := e.Resign(ctx); err != nil {
+			t.Fatalf("Resign(): %v", err)
+		}
+		prev = token
+	}
+}
+
 // runElectionResign tests the Resign call.
 func runElectionResign(t *testing.T, f election2.Factory) {
 	resignErr := errors.New("resign error")